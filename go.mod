@@ -47,6 +47,7 @@ require (
 	github.com/skip2/go-qrcode v0.0.0-20200519171959-a3b48390827e
 	github.com/smartystreets/goconvey v1.6.4 // indirect
 	github.com/stretchr/testify v1.6.1
+	github.com/whyrusleeping/mdns v0.0.0-20190826153040-b9b60ed33aa9
 	go.opentelemetry.io/otel v0.6.0
 	go.opentelemetry.io/otel/exporters/trace/jaeger v0.6.0
 	go.uber.org/zap v1.15.0
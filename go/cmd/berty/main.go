@@ -1,9 +1,11 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"flag"
 	"fmt"
+	"io/ioutil"
 	"log"
 	mrand "math/rand"
 	"net"
@@ -18,6 +20,7 @@ import (
 	"berty.tech/berty/v2/go/internal/grpcutil"
 	"berty.tech/berty/v2/go/internal/ipfsutil"
 	mc "berty.tech/berty/v2/go/internal/multipeer-connectivity-transport"
+	"berty.tech/berty/v2/go/internal/tinder"
 	"berty.tech/berty/v2/go/internal/tracer"
 	"berty.tech/berty/v2/go/pkg/banner"
 	"berty.tech/berty/v2/go/pkg/bertymessenger"
@@ -31,6 +34,7 @@ import (
 	datastore "github.com/ipfs/go-datastore"
 	sync_ds "github.com/ipfs/go-datastore/sync"
 	badger "github.com/ipfs/go-ds-badger"
+	keystore "github.com/ipfs/go-ipfs-keystore"
 	"github.com/ipfs/go-ipfs/core"
 	ipfs_log "github.com/ipfs/go-log"
 	"github.com/juju/fslock"
@@ -47,6 +51,7 @@ import (
 	grpc_trace "go.opentelemetry.io/otel/plugin/grpctrace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"golang.org/x/crypto/ssh/terminal"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -57,13 +62,17 @@ import (
 // DNS Resolve timeout
 const ResolveTimeout = time.Second * 10
 
+// keystorePassphraseEnv is the environment variable read for the passphrase
+// of the device keystore when it isn't given using a file or a prompt
+const keystorePassphraseEnv = "BERTY_KEYSTORE_PASSPHRASE"
+
 // Default ipfs bootstrap & rendezvous point server
 var (
-	DevRendezVousPoint = config.BertyDev.RendezVousPeer
-	DefaultBootstrap   = config.BertyDev.Bootstrap
-	DefaultSwarmAddrs  = config.BertyDev.DefaultSwarmAddrs
-	DefaultAPIAddrs    = config.BertyDev.DefaultAPIAddrs
-	APIConfig          = config.BertyDev.APIConfig
+	DevRendezVousPoints = strings.Join(config.BertyDev.RendezVousPeers, ",")
+	DefaultBootstrap    = config.BertyDev.Bootstrap
+	DefaultSwarmAddrs   = config.BertyDev.DefaultSwarmAddrs
+	DefaultAPIAddrs     = config.BertyDev.DefaultAPIAddrs
+	APIConfig           = config.BertyDev.APIConfig
 )
 
 // nolint: gocyclo
@@ -78,21 +87,31 @@ func main() {
 		globalLogToFile      string
 		globalTracer         string
 		globalLocalDiscovery bool
-
-		bannerLight           bool
-		bannerRandom          bool
-		daemonListeners       string
-		remoteDaemonAddr      string
-		datastorePath         string
-		rdvpMaddr             string
-		rdvpForce             bool
-		miniPort              uint
-		shareInviteOnDev      bool
-		shareInviteReset      bool
-		shareInviteNoTerminal bool
-		miniGroup             string
-		miniInMemory          bool
-		displayName           string
+		globalMDNS           bool
+
+		bannerLight            bool
+		bannerRandom           bool
+		daemonListeners        string
+		remoteDaemonAddr       string
+		datastorePath          string
+		rdvpMaddrs             string
+		rdvpForce              bool
+		miniPort               uint
+		shareInviteOnDev       bool
+		shareInviteReset       bool
+		shareInviteNoTerminal  bool
+		miniGroup              string
+		miniInMemory           bool
+		displayName            string
+		keystorePassphraseFile string
+		keystorePrompt         bool
+		forwardSecure          bool
+		messageKeyTTL          time.Duration
+		peerCacheTTL           time.Duration
+		pubsubDiscovery        bool
+		dhtFallbackDelay       time.Duration
+		localContactRequests   bool
+		accountMnemonic        bool
 	)
 
 	var (
@@ -114,18 +133,28 @@ func main() {
 	globalFlags.StringVar(&globalLogToFile, "logfile", "", "if specified, will log everything in JSON into a file and nothing on stderr")
 	globalFlags.StringVar(&globalTracer, "tracer", "", "specify \"stdout\" to output tracing on stdout or <hostname:port> to trace on jaeger")
 	globalFlags.BoolVar(&globalLocalDiscovery, "localdiscovery", true, "local discovery")
+	globalFlags.BoolVar(&globalMDNS, "mdns", true, "mdns discovery")
 	globalFlags.StringVar(&displayName, "display-name", safeDefaultDisplayName(), "display name")
 	bannerFlags.BoolVar(&bannerLight, "light", false, "light mode")
 	bannerFlags.BoolVar(&bannerRandom, "random", false, "pick a random quote")
 	daemonFlags.StringVar(&daemonListeners, "l", "/ip4/127.0.0.1/tcp/9091/grpc", "client listeners")
 	daemonFlags.StringVar(&datastorePath, "d", cacheleveldown.InMemoryDirectory, "datastore base directory")
-	daemonFlags.StringVar(&rdvpMaddr, "rdvp", DevRendezVousPoint, "rendezvous point maddr")
-	daemonFlags.BoolVar(&rdvpForce, "force-rdvp", false, "force connect to rendezvous point")
+	daemonFlags.StringVar(&rdvpMaddrs, "rdvp", DevRendezVousPoints, "comma-separated list of rendezvous point maddrs")
+	daemonFlags.BoolVar(&rdvpForce, "force-rdvp", false, "force connect to a rendezvous point")
+	daemonFlags.StringVar(&keystorePassphraseFile, "keystore-passphrase-file", "", "if specified, encrypts the device keystore using the passphrase read from this file (use /dev/fd/N to read it from a file descriptor)")
+	daemonFlags.BoolVar(&keystorePrompt, "keystore-prompt", false, "encrypts the device keystore using a passphrase read from the terminal")
+	daemonFlags.BoolVar(&accountMnemonic, "account-mnemonic", false, "when creating the account, derives its keys from a generated mnemonic sentence printed once so it can be written down")
+	daemonFlags.BoolVar(&forwardSecure, "forward-secure", false, "delete message keys once messages are opened, their plaintext is cached instead")
+	daemonFlags.DurationVar(&messageKeyTTL, "message-key-ttl", 0, "if specified with -forward-secure, delays the deletion of message keys, keys of messages not received within this duration are deleted too")
+	daemonFlags.BoolVar(&pubsubDiscovery, "pubsub-discovery", false, "also advertise and look up peers on pubsub topics derived from the rendezvous namespaces")
+	daemonFlags.DurationVar(&dhtFallbackDelay, "dht-fallback-delay", 0, "if specified, the DHT is only queried if the other discovery drivers found no peer within this delay")
+	daemonFlags.BoolVar(&localContactRequests, "local-contact-requests", false, "only look up the rendezvous points of the contact requests on the local network")
+	daemonFlags.DurationVar(&peerCacheTTL, "peer-cache-ttl", 0, "if specified, the peers found for each rendezvous point are persisted in the datastore for this duration")
 	miniFlags.StringVar(&miniGroup, "g", "", "group to join, leave empty to create a new group")
 	miniFlags.StringVar(&datastorePath, "d", cacheleveldown.InMemoryDirectory, "datastore base directory")
 	miniFlags.UintVar(&miniPort, "p", 0, "default IPFS listen port")
 	miniFlags.StringVar(&remoteDaemonAddr, "r", "", "remote berty daemon")
-	miniFlags.StringVar(&rdvpMaddr, "rdvp", DevRendezVousPoint, "rendezvous point maddr")
+	miniFlags.StringVar(&rdvpMaddrs, "rdvp", DevRendezVousPoints, "comma-separated list of rendezvous point maddrs")
 	miniFlags.BoolVar(&miniInMemory, "inmem", false, "disable persistence")
 	shareInviteFlags.BoolVar(&shareInviteOnDev, "dev-channel", false, "post qrcode on dev channel")
 	shareInviteFlags.BoolVar(&shareInviteReset, "reset", false, "reset contact reference")
@@ -238,7 +267,7 @@ func main() {
 			}
 			defer rootDS.Close()
 
-			rdvpeer, err := parseRdvpMaddr(ctx, rdvpMaddr, logger)
+			rdvpeers, err := parseRdvpMaddrs(ctx, rdvpMaddrs, logger)
 			if err != nil {
 				return errcode.TODO.Wrap(err)
 			}
@@ -256,9 +285,10 @@ func main() {
 				Logger:          l,
 				POIDebug:        globalPOIDebug,
 				Bootstrap:       DefaultBootstrap,
-				RendezVousPeer:  rdvpeer,
+				RendezVousPeers: rdvpeers,
 				DisplayName:     displayName,
 				LocalDiscovery:  globalLocalDiscovery,
+				MDNS:            globalMDNS,
 			})
 			if err != nil {
 				return errcode.TODO.Wrap(err)
@@ -278,8 +308,9 @@ func main() {
 			defer cleanup()
 
 			var (
-				api        ipfsutil.ExtendedCoreAPI
-				routingOut *ipfsutil.RoutingOut
+				api          ipfsutil.ExtendedCoreAPI
+				routingOut   *ipfsutil.RoutingOut
+				tinderDriver tinder.Driver
 			)
 			{
 				var err error
@@ -294,13 +325,25 @@ func main() {
 
 				var crouting <-chan *ipfsutil.RoutingOut
 
-				rdvpeer, err := parseRdvpMaddr(ctx, rdvpMaddr, logger)
+				rdvpeers, err := parseRdvpMaddrs(ctx, rdvpMaddrs, logger)
 				if err != nil {
 					return errcode.TODO.Wrap(err)
 				}
-				if rdvpeer != nil {
-					bopts.BootstrapAddrs = append(bopts.BootstrapAddrs, rdvpMaddr)
-					bopts.Routing, crouting = ipfsutil.NewTinderRouting(logger, rdvpeer, false, globalLocalDiscovery)
+				for _, rdvpeer := range rdvpeers {
+					rdvpaddrs, err := peer.AddrInfoToP2pAddrs(rdvpeer)
+					if err != nil {
+						return errcode.TODO.Wrap(err)
+					}
+
+					for _, maddr := range rdvpaddrs {
+						bopts.BootstrapAddrs = append(bopts.BootstrapAddrs, maddr.String())
+					}
+				}
+
+				// without rendezvous point, peers can still be found on the LAN
+				if len(rdvpeers) > 0 || globalMDNS {
+					policies := ipfsutil.TinderPolicies(dhtFallbackDelay, localContactRequests)
+					bopts.Routing, crouting = ipfsutil.NewTinderRouting(logger, rdvpeers, false, globalLocalDiscovery, globalMDNS, peerCacheTTL, policies)
 				}
 
 				var node *core.IpfsNode
@@ -324,21 +367,38 @@ func main() {
 					routingOut = <-crouting
 					defer routingOut.IpfsDHT.Close()
 
-					if rdvpForce {
+					tinderDriver = routingOut
+					if pubsubDiscovery {
+						psDriver := tinder.NewPubSubDiscovery(ctx, logger, node.PeerHost, api.PubSub())
+						tinderDriver = tinder.NewMultiDriver(logger, routingOut, psDriver)
+					}
+
+					if rdvpForce && len(rdvpeers) > 0 {
+						rdvpids := make([]peer.ID, len(rdvpeers))
+						for i, rdvpeer := range rdvpeers {
+							rdvpids[i] = rdvpeer.ID
+						}
+
 						go func() {
-							// monitor rdv peer
-							if err := monitorPeers(logger, node.PeerHost, rdvpeer.ID); err != nil {
+							// monitor rdv peers
+							if err := monitorPeers(logger, node.PeerHost, rdvpids...); err != nil {
 								logger.Error("monitorPeers", zap.Error(err))
 							}
 						}()
 
-						for {
-							if err := node.PeerHost.Connect(ctx, *rdvpeer); err != nil {
-								logger.Error("cannot dial rendez-vous point", zap.Error(err))
-							} else {
-								break
+						// wait until at least one rendezvous point is reachable
+						for connected := false; !connected; {
+							for _, rdvpeer := range rdvpeers {
+								if err := node.PeerHost.Connect(ctx, *rdvpeer); err != nil {
+									logger.Error("cannot dial rendez-vous point", zap.String("peer", rdvpeer.ID.String()), zap.Error(err))
+								} else {
+									connected = true
+								}
+							}
+
+							if !connected {
+								time.Sleep(time.Second)
 							}
-							time.Sleep(time.Second)
 						}
 					}
 				}
@@ -416,20 +476,50 @@ func main() {
 				}
 				defer rootDS.Close()
 
-				deviceDS := ipfsutil.NewDatastoreKeystore(ipfsutil.NewNamespacedDatastore(rootDS, datastore.NewKey("account")))
-				mk := bertyprotocol.NewMessageKeystore(ipfsutil.NewNamespacedDatastore(rootDS, datastore.NewKey("messages")))
+				passphrase, err := getKeystorePassphrase(keystorePassphraseFile, keystorePrompt)
+				if err != nil {
+					return errcode.TODO.Wrap(err)
+				}
+
+				var encryptedDeviceDS ipfsutil.EncryptedKeystore
+				accountDS := ipfsutil.NewNamespacedDatastore(rootDS, datastore.NewKey("account"))
+				deviceDS := ipfsutil.NewDatastoreKeystore(accountDS)
+				if len(passphrase) > 0 {
+					encryptedDeviceDS, err = ipfsutil.NewEncryptedDatastoreKeystore(accountDS, passphrase)
+					if err != nil {
+						return errcode.TODO.Wrap(err)
+					}
+					deviceDS = encryptedDeviceDS
+				}
+				deviceKS := bertyprotocol.NewDeviceKeystore(deviceDS)
+				if accountMnemonic {
+					if deviceKS, err = newMnemonicDeviceKeystore(deviceDS); err != nil {
+						return errcode.TODO.Wrap(err)
+					}
+				}
+
+				mk := bertyprotocol.NewMessageKeystoreWithOpts(ipfsutil.NewNamespacedDatastore(rootDS, datastore.NewKey("messages")), &bertyprotocol.MessageKeystoreOpts{
+					ForwardSecure: forwardSecure,
+					KeyTTL:        messageKeyTTL,
+				})
 
 				// initialize new protocol client
 				opts := bertyprotocol.Opts{
-					TinderDriver:    routingOut,
-					IpfsCoreAPI:     api,
-					Logger:          logger.Named("protocol"),
-					RootContext:     ctx,
-					RootDatastore:   rootDS,
-					MessageKeystore: mk,
-					DeviceKeystore:  bertyprotocol.NewDeviceKeystore(deviceDS),
-					OrbitCache:      bertyprotocol.NewOrbitDatastoreCache(ipfsutil.NewNamespacedDatastore(rootDS, datastore.NewKey("orbitdb"))),
+					IpfsCoreAPI:       api,
+					Logger:            logger.Named("protocol"),
+					RootContext:       ctx,
+					RootDatastore:     rootDS,
+					MessageKeystore:   mk,
+					DeviceKeystore:    deviceKS,
+					EncryptedKeystore: encryptedDeviceDS,
+					OrbitCache:        bertyprotocol.NewOrbitDatastoreCache(ipfsutil.NewNamespacedDatastore(rootDS, datastore.NewKey("orbitdb"))),
+				}
+
+				// a nil *RoutingOut would be a non-nil tinder.Driver
+				if tinderDriver != nil {
+					opts.TinderDriver = tinderDriver
 				}
+
 				protocol, err = bertyprotocol.New(opts)
 				if err != nil {
 					return errcode.TODO.Wrap(err)
@@ -447,6 +537,7 @@ func main() {
 					return errcode.TODO.Wrap(err)
 				}
 				messenger := bertymessenger.New(protocolClient, &bertymessenger.Opts{Logger: logger.Named("messenger")})
+				defer messenger.Close()
 				bertymessenger.RegisterMessengerServiceServer(grpcServer, messenger)
 			}
 
@@ -517,6 +608,7 @@ func main() {
 				return errcode.TODO.Wrap(err)
 			}
 			messenger := bertymessenger.New(protocolClient, &bertymessenger.Opts{Logger: logger.Named("messenger")})
+			defer messenger.Close()
 			ret, err := messenger.InstanceShareableBertyID(ctx, &bertymessenger.InstanceShareableBertyID_Request{DisplayName: displayName})
 			if err != nil {
 				return errcode.TODO.Wrap(err)
@@ -574,6 +666,7 @@ func main() {
 				return errcode.TODO.Wrap(err)
 			}
 			messenger := bertymessenger.New(protocolClient, &bertymessenger.Opts{Logger: logger.Named("messenger")})
+			defer messenger.Close()
 			ret, err := messenger.SystemInfo(ctx, &bertymessenger.SystemInfo_Request{})
 			if err != nil {
 				return errcode.TODO.Wrap(err)
@@ -688,12 +781,37 @@ func monitorPeers(l *zap.Logger, h host.Host, peers ...peer.ID) error {
 	}
 }
 
-func parseRdvpMaddr(ctx context.Context, rdvpMaddr string, logger *zap.Logger) (*peer.AddrInfo, error) {
-	if rdvpMaddr == "" {
+// parseRdvpMaddrs resolves a comma-separated list of rendezvous point maddrs
+func parseRdvpMaddrs(ctx context.Context, rdvpMaddrs string, logger *zap.Logger) ([]*peer.AddrInfo, error) {
+	rdvpeers, given := []*peer.AddrInfo(nil), 0
+	for _, rdvpMaddr := range strings.Split(rdvpMaddrs, ",") {
+		if rdvpMaddr = strings.TrimSpace(rdvpMaddr); rdvpMaddr == "" {
+			continue
+		}
+
+		given++
+
+		// an unreachable rendezvous point shouldn't prevent using the others
+		rdvpeer, err := parseRdvpMaddr(ctx, rdvpMaddr, logger)
+		if err != nil {
+			logger.Warn("unable to resolve rendezvous peer, skipping it", zap.String("maddr", rdvpMaddr), zap.Error(err))
+			continue
+		}
+
+		rdvpeers = append(rdvpeers, rdvpeer)
+	}
+
+	switch {
+	case given == 0:
 		logger.Debug("no rendezvous peer set")
-		return nil, nil
+	case len(rdvpeers) == 0:
+		return nil, errcode.TODO.Wrap(fmt.Errorf("unable to resolve any of the %d rendezvous peers", given))
 	}
 
+	return rdvpeers, nil
+}
+
+func parseRdvpMaddr(ctx context.Context, rdvpMaddr string, logger *zap.Logger) (*peer.AddrInfo, error) {
 	resoveCtx, cancel := context.WithTimeout(ctx, ResolveTimeout)
 	defer cancel()
 
@@ -711,6 +829,63 @@ func parseRdvpMaddr(ctx context.Context, rdvpMaddr string, logger *zap.Logger) (
 	return rdvpeer, nil
 }
 
+// newMnemonicDeviceKeystore returns a device keystore whose account keys are
+// derived from a generated mnemonic sentence, the sentence is only printed
+// when the account is created, an existing account is kept as is
+func newMnemonicDeviceKeystore(ks keystore.Keystore) (bertyprotocol.DeviceKeystore, error) {
+	exists, err := bertyprotocol.HasAccountKeys(ks)
+	if err != nil {
+		return nil, err
+	}
+
+	if exists {
+		return bertyprotocol.NewDeviceKeystore(ks), nil
+	}
+
+	mnemonic, err := bertyprotocol.GenerateAccountMnemonic()
+	if err != nil {
+		return nil, err
+	}
+
+	deviceKS, err := bertyprotocol.NewDeviceKeystoreFromMnemonic(ks, mnemonic)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(os.Stderr, "account mnemonic, write it down to restore the account:\n%s\n", mnemonic)
+
+	return deviceKS, nil
+}
+
+// getKeystorePassphrase returns the passphrase of the device keystore, it is
+// read from a file, a prompt or the BERTY_KEYSTORE_PASSPHRASE environment
+// variable so it doesn't appear in the process list nor in the shell history
+func getKeystorePassphrase(file string, prompt bool) ([]byte, error) {
+	switch {
+	case file != "":
+		passphrase, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		return bytes.TrimRight(passphrase, "\r\n"), nil
+
+	case prompt:
+		fmt.Fprint(os.Stderr, "keystore passphrase: ")
+		defer fmt.Fprintln(os.Stderr)
+
+		return terminal.ReadPassword(int(os.Stdin.Fd()))
+	}
+
+	passphrase := os.Getenv(keystorePassphraseEnv)
+	if passphrase != "" {
+		// avoid leaking the passphrase to child processes
+		_ = os.Unsetenv(keystorePassphraseEnv)
+	}
+
+	return []byte(passphrase), nil
+}
+
 func safeDefaultDisplayName() string {
 	var name string
 	current, err := user.Current()
@@ -27,8 +27,8 @@ import (
 )
 
 type Opts struct {
-	Bootstrap      []string
-	RendezVousPeer *peer.AddrInfo
+	Bootstrap       []string
+	RendezVousPeers []*peer.AddrInfo
 
 	RemoteAddr      string
 	GroupInvitation string
@@ -38,6 +38,7 @@ type Opts struct {
 	POIDebug        bool
 	DisplayName     string
 	LocalDiscovery  bool
+	MDNS            bool
 }
 
 var globalLogger *zap.Logger
@@ -64,27 +65,24 @@ func newService(ctx context.Context, logger *zap.Logger, opts *Opts) (bertyproto
 		}
 	}
 
-	if opts.RendezVousPeer == nil {
-		opts.RendezVousPeer = &peer.AddrInfo{}
-	}
-
-	rdvaddrs, err := peer.AddrInfoToP2pAddrs(opts.RendezVousPeer)
-	if err != nil {
-		panicUnlockFS(err, lock)
-	}
+	for _, rdvpeer := range opts.RendezVousPeers {
+		rdvaddrs, err := peer.AddrInfoToP2pAddrs(rdvpeer)
+		if err != nil {
+			panicUnlockFS(err, lock)
+		}
 
-	for _, maddr := range rdvaddrs {
-		opts.Bootstrap = append(opts.Bootstrap, maddr.String())
+		for _, maddr := range rdvaddrs {
+			opts.Bootstrap = append(opts.Bootstrap, maddr.String())
+		}
 	}
 
 	rootDS := sync_ds.MutexWrap(opts.RootDS)
 	ipfsDS := ipfsutil.NewNamespacedDatastore(rootDS, datastore.NewKey("ipfs"))
-	routingOpt, crouting := ipfsutil.NewTinderRouting(logger, opts.RendezVousPeer, false, opts.LocalDiscovery)
+	routingOpt, crouting := ipfsutil.NewTinderRouting(logger, opts.RendezVousPeers, false, opts.LocalDiscovery, opts.MDNS, 0, nil)
 	api, node, err := ipfsutil.NewCoreAPIFromDatastore(ctx, ipfsDS, &ipfsutil.CoreAPIConfig{
 		BootstrapAddrs: opts.Bootstrap,
 		SwarmAddrs:     swarmAddresses,
 		Routing:        routingOpt,
-		Options:        []ipfsutil.CoreAPIOption{ipfsutil.OptionMDNSDiscovery},
 	})
 	if err != nil {
 		panicUnlockFS(err, lock)
@@ -170,6 +168,7 @@ func Main(ctx context.Context, opts *Opts) error {
 	}
 
 	messenger := bertymessenger.New(client, &bertymessenger.Opts{Logger: opts.Logger.Named("messenger")})
+	defer messenger.Close()
 
 	config, err := client.InstanceGetConfiguration(ctx, &bertytypes.InstanceGetConfiguration_Request{})
 	if err != nil {
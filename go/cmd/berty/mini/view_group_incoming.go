@@ -1,6 +1,7 @@
 package mini
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"fmt"
@@ -68,6 +69,98 @@ func handlerGroupMemberDeviceAdded(_ context.Context, v *groupView, e *bertytype
 	return nil
 }
 
+func handlerGroupDeviceRevoked(_ context.Context, v *groupView, e *bertytypes.GroupMetadataEvent, isHistory bool) error {
+	casted := &bertytypes.GroupRevokeDevice{}
+	if err := casted.Unmarshal(e.Event); err != nil {
+		return err
+	}
+
+	v.muAggregates.Lock()
+	delete(v.devices, string(casted.RevokedDevicePK))
+	v.muAggregates.Unlock()
+
+	addToBuffer(&historyMessage{
+		messageType: messageTypeMeta,
+		payload:     []byte("a device has been revoked"),
+		sender:      casted.DevicePK,
+	}, e, v, isHistory)
+
+	return nil
+}
+
+func handlerGroupMemberLeft(_ context.Context, v *groupView, e *bertytypes.GroupMetadataEvent, isHistory bool) error {
+	casted := &bertytypes.GroupMemberLeft{}
+	if err := casted.Unmarshal(e.Event); err != nil {
+		return err
+	}
+
+	v.muAggregates.Lock()
+	for pk, d := range v.devices {
+		if bytes.Equal(d.MemberPK, casted.MemberPK) {
+			delete(v.devices, pk)
+		}
+	}
+	v.muAggregates.Unlock()
+
+	addToBuffer(&historyMessage{
+		messageType: messageTypeMeta,
+		payload:     []byte("a member has left the group"),
+		sender:      casted.DevicePK,
+	}, e, v, isHistory)
+
+	return nil
+}
+
+func handlerGroupEphemeralSettingsUpdated(_ context.Context, v *groupView, e *bertytypes.GroupMetadataEvent, isHistory bool) error {
+	casted := &bertytypes.GroupSetEphemeralSettings{}
+	if err := casted.Unmarshal(e.Event); err != nil {
+		return err
+	}
+
+	payload := []byte("disappearing messages disabled")
+	if casted.MessageTTL > 0 {
+		payload = []byte(fmt.Sprintf("disappearing messages enabled, messages will expire after %s", time.Duration(casted.MessageTTL)*time.Second))
+	}
+
+	addToBuffer(&historyMessage{
+		messageType: messageTypeMeta,
+		payload:     payload,
+		sender:      casted.DevicePK,
+	}, e, v, isHistory)
+
+	return nil
+}
+
+func handlerGroupDeviceCapabilitiesAnnounced(_ context.Context, v *groupView, e *bertytypes.GroupMetadataEvent, isHistory bool) error {
+	casted := &bertytypes.GroupAnnounceCapabilities{}
+	if err := casted.Unmarshal(e.Event); err != nil {
+		return err
+	}
+
+	addToBuffer(&historyMessage{
+		messageType: messageTypeMeta,
+		payload:     []byte(fmt.Sprintf("device supports group version %d", casted.MaxVersion)),
+		sender:      casted.DevicePK,
+	}, e, v, isHistory)
+
+	return nil
+}
+
+func handlerGroupVersionUpgraded(_ context.Context, v *groupView, e *bertytypes.GroupMetadataEvent, isHistory bool) error {
+	casted := &bertytypes.GroupUpgradeVersion{}
+	if err := casted.Unmarshal(e.Event); err != nil {
+		return err
+	}
+
+	addToBuffer(&historyMessage{
+		messageType: messageTypeMeta,
+		payload:     []byte(fmt.Sprintf("group upgraded to version %d", casted.Version)),
+		sender:      casted.DevicePK,
+	}, e, v, isHistory)
+
+	return nil
+}
+
 func handlerAccountContactRequestOutgoingSent(ctx context.Context, v *groupView, e *bertytypes.GroupMetadataEvent, isHistory bool) error {
 	casted := &bertytypes.AccountContactRequestSent{}
 	if err := casted.Unmarshal(e.Event); err != nil {
@@ -307,18 +400,36 @@ func metadataEventHandler(ctx context.Context, v *groupView, e *bertytypes.Group
 		bertytypes.EventTypeAccountContactRequestOutgoingSent:      handlerAccountContactRequestOutgoingSent,
 		bertytypes.EventTypeAccountContactRequestReferenceReset:    handlerNoop,
 		bertytypes.EventTypeAccountContactUnblocked:                nil, // do it later
+		bertytypes.EventTypeAccountContactVerified:                 handlerNoop,
+		bertytypes.EventTypeAccountGroupDeviceLinked:               handlerNoop,
 		bertytypes.EventTypeAccountGroupJoined:                     handlerAccountGroupJoined,
 		bertytypes.EventTypeAccountGroupLeft:                       handlerAccountGroupLeft,
 		bertytypes.EventTypeContactAliasKeyAdded:                   handlerContactAliasKeyAdded,
+		bertytypes.EventTypeContactRecoveryShareAdded:              handlerNoop,
+		bertytypes.EventTypeContactRecoveryShareReleased:           handlerNoop,
+		bertytypes.EventTypeGroupDeviceRevoked:                     handlerGroupDeviceRevoked,
 		bertytypes.EventTypeGroupDeviceSecretAdded:                 handlerGroupDeviceSecretAdded,
+		bertytypes.EventTypeGroupDeviceCapabilitiesAnnounced:       handlerGroupDeviceCapabilitiesAnnounced,
+		bertytypes.EventTypeGroupEphemeralSettingsUpdated:          handlerGroupEphemeralSettingsUpdated,
 		bertytypes.EventTypeGroupMemberDeviceAdded:                 handlerGroupMemberDeviceAdded,
+		bertytypes.EventTypeGroupMemberLeft:                        handlerGroupMemberLeft,
 		bertytypes.EventTypeGroupMetadataPayloadSent:               nil, // do it later
+		bertytypes.EventTypeGroupVersionUpgraded:                   handlerGroupVersionUpgraded,
 		bertytypes.EventTypeMultiMemberGroupAdminRoleGranted:       nil, // do it later
 		bertytypes.EventTypeMultiMemberGroupAliasResolverAdded:     handlerMultiMemberGroupAliasResolverAdded,
 		bertytypes.EventTypeMultiMemberGroupInitialMemberAnnounced: handlerMultiMemberGroupInitialMemberAnnounced,
 	}
 	logger.Debug("metadataEventHandler", zap.Stringer("event-type", e.Metadata.EventType))
 
+	if e.UnknownType {
+		addToBuffer(&historyMessage{
+			messageType: messageTypeMeta,
+			payload:     []byte(fmt.Sprintf("event of unknown type %d", e.Metadata.EventType)),
+			sender:      e.SignerDevicePK,
+		}, e, v, isHistory)
+		return
+	}
+
 	action, ok := actions[e.Metadata.EventType]
 	if !ok || action == nil {
 		v.messages.AppendErr(fmt.Errorf("action handler for %s not found", e.Metadata.EventType.String()))
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"berty.tech/berty/v2/go/pkg/bertymessenger"
 	"berty.tech/berty/v2/go/pkg/bertytypes"
@@ -161,6 +162,11 @@ func commandList() []*command {
 			help:  "Inspect a group store",
 			cmd:   debugInspectStoreCommand,
 		},
+		{
+			title: "debug discovery",
+			help:  "Shows the statistics of the discovery drivers",
+			cmd:   debugDiscoveryCommand,
+		},
 		{
 			title:     "/",
 			help:      "",
@@ -488,6 +494,40 @@ func debugListGroupCommand(ctx context.Context, v *groupView, cmd string) error
 	return nil
 }
 
+func debugDiscoveryCommand(ctx context.Context, v *groupView, cmd string) error {
+	rep, err := v.v.client.DebugDiscovery(ctx, &bertytypes.DebugDiscovery_Request{})
+	if err != nil {
+		return err
+	}
+
+	for _, d := range rep.Drivers {
+		v.messages.Append(&historyMessage{
+			messageType: messageTypeMeta,
+			payload: []byte(fmt.Sprintf("%s: advertise %d (%d failed, last %s), find peers %d (%d failed, last %s)",
+				d.Name,
+				d.AdvertiseCalls, d.AdvertiseFailures, time.Duration(d.AdvertiseLatency),
+				d.FindPeersCalls, d.FindPeersFailures, time.Duration(d.FindPeersLatency),
+			)),
+		})
+
+		for _, ns := range d.Namespaces {
+			v.messages.Append(&historyMessage{
+				messageType: messageTypeMeta,
+				payload:     []byte(fmt.Sprintf(" - %s: %d peers found, last at %s", ns.Namespace, ns.PeersFound, time.Unix(0, ns.LastFound).Format(time.Stamp))),
+			})
+		}
+
+		if d.LastError != "" {
+			v.messages.Append(&historyMessage{
+				messageType: messageTypeMeta,
+				payload:     []byte(fmt.Sprintf(" - last error at %s: %s", time.Unix(0, d.LastErrorAt).Format(time.Stamp), d.LastError)),
+			})
+		}
+	}
+
+	return nil
+}
+
 // func aliasProveCommand(ctx context.Context, v *groupView, cmd string) error {
 // 	if _, err := v.cg.MetadataStore().SendAliasProof(ctx); err != nil {
 // 		return err
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"berty.tech/berty/v2/go/internal/config"
 	"berty.tech/berty/v2/go/internal/ipfsutil"
@@ -40,20 +41,21 @@ import (
 )
 
 var (
-	defaultProtocolRendezVousPeer = config.BertyMobile.RendezVousPeer
-	defaultProtocolBootstrap      = config.BertyMobile.Bootstrap
-	defaultTracingHost            = config.BertyMobile.Tracing
-	defaultSwarmAddrs             = config.BertyMobile.DefaultSwarmAddrs
-	defaultAPIAddrs               = config.BertyMobile.DefaultAPIAddrs
-	APIConfig                     = config.BertyMobile.APIConfig
+	defaultProtocolRendezVousPeers = config.BertyMobile.RendezVousPeers
+	defaultProtocolBootstrap       = config.BertyMobile.Bootstrap
+	defaultTracingHost             = config.BertyMobile.Tracing
+	defaultSwarmAddrs              = config.BertyMobile.DefaultSwarmAddrs
+	defaultAPIAddrs                = config.BertyMobile.DefaultAPIAddrs
+	APIConfig                      = config.BertyMobile.APIConfig
 )
 
 type Protocol struct {
 	*Bridge
 
-	node    *core.IpfsNode
-	dht     *dht.IpfsDHT
-	service bertyprotocol.Service
+	node      *core.IpfsNode
+	dht       *dht.IpfsDHT
+	service   bertyprotocol.Service
+	messenger bertymessenger.Service
 
 	// protocol datastore
 	ds datastore.Batching
@@ -67,10 +69,20 @@ type ProtocolConfig struct {
 	poiDebug bool
 
 	swarmListeners []string
+	rdvPeers       []string
 	rootDirectory  string
 	tracing        bool
 	tracingPrefix  string
 	localDiscovery bool
+	peerCache      bool
+	mdns           bool
+
+	// discovery policies
+	dhtFallbackDelay     time.Duration
+	localContactRequests bool
+
+	// account
+	accountMnemonic string
 
 	// internal
 	coreAPI ipfsutil.ExtendedCoreAPI
@@ -110,10 +122,54 @@ func (pc *ProtocolConfig) AddSwarmListener(laddr string) {
 	pc.swarmListeners = append(pc.swarmListeners, laddr)
 }
 
+// AddRendezVousPeer adds a rendezvous point, the default ones are used if
+// none is added
+func (pc *ProtocolConfig) AddRendezVousPeer(maddr string) {
+	pc.rdvPeers = append(pc.rdvPeers, maddr)
+}
+
 func (pc *ProtocolConfig) DisableLocalDiscovery() {
 	pc.localDiscovery = false
 }
 
+// EnableMDNS allows finding peers on the LAN using mDNS, without DHT nor
+// rendezvous point
+func (pc *ProtocolConfig) EnableMDNS() {
+	pc.mdns = true
+}
+
+// SetDHTFallbackDelay only queries the DHT if the other drivers found no peer
+// within delayMS milliseconds, sparing the battery of the device
+func (pc *ProtocolConfig) SetDHTFallbackDelay(delayMS int64) {
+	pc.dhtFallbackDelay = time.Duration(delayMS) * time.Millisecond
+}
+
+// EnableLocalContactRequests only looks up the rendezvous points of the
+// contact requests on the local network, the DHT and the rendezvous points
+// are still used by the content routing
+func (pc *ProtocolConfig) EnableLocalContactRequests() {
+	pc.localContactRequests = true
+}
+
+// EnablePeerCache persists the peers found for each rendezvous point in the
+// repo, so they can be reached again right after a restart
+func (pc *ProtocolConfig) EnablePeerCache() {
+	pc.peerCache = true
+}
+
+// AccountMnemonic derives the keys of the account from a mnemonic sentence when
+// it is created, the sentence can later be used to restore the account, see
+// GenerateAccountMnemonic
+func (pc *ProtocolConfig) AccountMnemonic(mnemonic string) {
+	pc.accountMnemonic = mnemonic
+}
+
+// GenerateAccountMnemonic returns a new mnemonic sentence, to be written down
+// by the user before creating the account using ProtocolConfig.AccountMnemonic
+func GenerateAccountMnemonic() (string, error) {
+	return bertyprotocol.GenerateAccountMnemonic()
+}
+
 func NewProtocolBridge(config *ProtocolConfig) (*Protocol, error) {
 	// setup logger
 	var logger *zap.Logger
@@ -164,15 +220,38 @@ func newProtocolBridge(logger *zap.Logger, config *ProtocolConfig) (*Protocol, e
 			}
 			bopts.BootstrapAddrs = defaultProtocolBootstrap
 
-			var rdvpeer *peer.AddrInfo
 			var crouting <-chan *ipfsutil.RoutingOut
 
-			if rdvpeer, err = ipfsutil.ParseAndResolveIpfsAddr(ctx, defaultProtocolRendezVousPeer); err != nil {
-				return nil, errors.New("failed to parse rdvp multiaddr: " + defaultProtocolRendezVousPeer)
+			rdvpMaddrs := config.rdvPeers
+			if len(rdvpMaddrs) == 0 {
+				rdvpMaddrs = defaultProtocolRendezVousPeers
+			}
+
+			rdvpeers := []*peer.AddrInfo(nil)
+			for _, rdvpMaddr := range rdvpMaddrs {
+				// an unreachable rendezvous point shouldn't prevent using the others
+				rdvpeer, err := ipfsutil.ParseAndResolveIpfsAddr(ctx, rdvpMaddr)
+				if err != nil {
+					logger.Warn("failed to parse rdvp multiaddr, skipping it", zap.String("maddr", rdvpMaddr), zap.Error(err))
+					continue
+				}
+
+				rdvpeers = append(rdvpeers, rdvpeer)
+
+				// should be a valid rendezvous peer
+				bopts.BootstrapAddrs = append(bopts.BootstrapAddrs, rdvpMaddr)
+			}
+
+			if len(rdvpeers) == 0 {
+				return nil, errors.New("failed to resolve any rdvp multiaddr")
 			}
-			// should be a valid rendezvous peer
-			bopts.BootstrapAddrs = append(bopts.BootstrapAddrs, defaultProtocolRendezVousPeer)
-			bopts.Routing, crouting = ipfsutil.NewTinderRouting(logger, rdvpeer, false, config.localDiscovery)
+			var peerCacheTTL time.Duration
+			if config.peerCache {
+				peerCacheTTL = tinder.DefaultPeerCacheTTL
+			}
+
+			policies := ipfsutil.TinderPolicies(config.dhtFallbackDelay, config.localContactRequests)
+			bopts.Routing, crouting = ipfsutil.NewTinderRouting(logger, rdvpeers, false, config.localDiscovery, config.mdns, peerCacheTTL, policies)
 
 			if len(config.swarmListeners) > 0 {
 				bopts.SwarmAddrs = append(bopts.SwarmAddrs, config.swarmListeners...)
@@ -229,11 +308,12 @@ func newProtocolBridge(logger *zap.Logger, config *ProtocolConfig) (*Protocol, e
 
 		// initialize new protocol client
 		protocolOpts := bertyprotocol.Opts{
-			Logger:         logger.Named("bertyprotocol"),
-			OrbitDirectory: odbDir,
-			RootDatastore:  rootds,
-			IpfsCoreAPI:    api,
-			TinderDriver:   tinderDriver,
+			Logger:          logger.Named("bertyprotocol"),
+			OrbitDirectory:  odbDir,
+			RootDatastore:   rootds,
+			IpfsCoreAPI:     api,
+			TinderDriver:    tinderDriver,
+			AccountMnemonic: config.accountMnemonic,
 		}
 
 		service, err = bertyprotocol.New(protocolOpts)
@@ -281,12 +361,13 @@ func newProtocolBridge(logger *zap.Logger, config *ProtocolConfig) (*Protocol, e
 	}
 
 	// register messenger service
+	var messenger bertymessenger.Service
 	{
 		protocolClient, err := bertyprotocol.NewClient(service)
 		if err != nil {
 			return nil, errcode.TODO.Wrap(err)
 		}
-		messenger := bertymessenger.New(protocolClient, &bertymessenger.Opts{Logger: logger.Named("messenger")})
+		messenger = bertymessenger.New(protocolClient, &bertymessenger.Opts{Logger: logger.Named("messenger")})
 		bertymessenger.RegisterMessengerServiceServer(grpcServer, messenger)
 	}
 
@@ -304,9 +385,10 @@ func newProtocolBridge(logger *zap.Logger, config *ProtocolConfig) (*Protocol, e
 	return &Protocol{
 		Bridge: bridge,
 
-		service: service,
-		node:    node,
-		dht:     dht,
+		service:   service,
+		messenger: messenger,
+		node:      node,
+		dht:       dht,
 
 		ds: rootds,
 	}, nil
@@ -316,6 +398,9 @@ func (p *Protocol) Close() (err error) {
 	// Close bridge
 	p.Bridge.Close()
 
+	// close messenger before the protocol service it relies on
+	_ = p.messenger.Close()
+
 	// close service
 	err = p.service.Close() // keep service error
 
@@ -0,0 +1,131 @@
+package bip39
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"berty.tech/berty/v2/go/pkg/errcode"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	// EntropySize is the size of the entropy used by NewMnemonic, encoded in
+	// 24 words
+	EntropySize = 32
+
+	// SeedSize is the size of the seeds returned by NewSeed
+	SeedSize = 64
+
+	bitsPerWord     = 11
+	seedIterations  = 2048
+	seedSaltPrefix  = "mnemonic"
+	minEntropyBytes = 16
+	maxEntropyBytes = 32
+)
+
+var wordIndexes = func() map[string]int {
+	indexes := make(map[string]int, len(englishWordlist))
+	for i, w := range englishWordlist {
+		indexes[w] = i
+	}
+
+	return indexes
+}()
+
+// NewMnemonic encodes entropy as a mnemonic sentence, entropy must be
+// between 16 and 32 bytes long and a multiple of 4 bytes
+func NewMnemonic(entropy []byte) (string, error) {
+	if err := checkEntropySize(len(entropy)); err != nil {
+		return "", err
+	}
+
+	checksumBits := len(entropy) * 8 / 32
+	wordCount := (len(entropy)*8 + checksumBits) / bitsPerWord
+
+	// append the checksum bits to the entropy
+	checksum := sha256.Sum256(entropy)
+	data := new(big.Int).SetBytes(entropy)
+	data.Lsh(data, uint(checksumBits))
+	data.Or(data, big.NewInt(int64(checksum[0]>>(8-checksumBits))))
+
+	words := make([]string, wordCount)
+	mask := big.NewInt(1<<bitsPerWord - 1)
+	index := new(big.Int)
+
+	for i := wordCount - 1; i >= 0; i-- {
+		index.And(data, mask)
+		words[i] = englishWordlist[index.Int64()]
+		data.Rsh(data, bitsPerWord)
+	}
+
+	return strings.Join(words, " "), nil
+}
+
+// EntropyFromMnemonic decodes a mnemonic sentence and checks its checksum
+func EntropyFromMnemonic(mnemonic string) ([]byte, error) {
+	words := strings.Fields(mnemonic)
+
+	totalBits := len(words) * bitsPerWord
+	checksumBits := totalBits / 33
+	entropyBytes := (totalBits - checksumBits) / 8
+
+	if len(words) == 0 || totalBits%33 != 0 {
+		return nil, errcode.ErrInvalidInput.Wrap(fmt.Errorf("invalid word count %d", len(words)))
+	}
+
+	if err := checkEntropySize(entropyBytes); err != nil {
+		return nil, err
+	}
+
+	data := new(big.Int)
+	for _, w := range words {
+		index, ok := wordIndexes[strings.ToLower(w)]
+		if !ok {
+			return nil, errcode.ErrInvalidInput.Wrap(fmt.Errorf("unknown word %q", w))
+		}
+
+		data.Lsh(data, bitsPerWord)
+		data.Or(data, big.NewInt(int64(index)))
+	}
+
+	checksum := new(big.Int).And(data, big.NewInt(1<<uint(checksumBits)-1))
+	data.Rsh(data, uint(checksumBits))
+
+	// restore leading zero bytes
+	entropy := make([]byte, entropyBytes)
+	dataBytes := data.Bytes()
+	copy(entropy[entropyBytes-len(dataBytes):], dataBytes)
+
+	expected := sha256.Sum256(entropy)
+	if checksum.Int64() != int64(expected[0]>>(8-checksumBits)) {
+		return nil, errcode.ErrInvalidInput.Wrap(fmt.Errorf("invalid mnemonic checksum"))
+	}
+
+	return entropy, nil
+}
+
+// IsMnemonicValid returns true if mnemonic is made of known words and has a
+// valid checksum
+func IsMnemonicValid(mnemonic string) bool {
+	_, err := EntropyFromMnemonic(mnemonic)
+
+	return err == nil
+}
+
+// NewSeed derives a seed from a mnemonic sentence and an optional passphrase
+func NewSeed(mnemonic string, passphrase string) []byte {
+	normalized := strings.Join(strings.Fields(strings.ToLower(mnemonic)), " ")
+
+	return pbkdf2.Key([]byte(normalized), []byte(seedSaltPrefix+passphrase), seedIterations, SeedSize, sha512.New)
+}
+
+func checkEntropySize(size int) error {
+	if size < minEntropyBytes || size > maxEntropyBytes || size%4 != 0 {
+		return errcode.ErrInvalidInput.Wrap(fmt.Errorf("invalid entropy size %d", size))
+	}
+
+	return nil
+}
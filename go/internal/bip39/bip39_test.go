@@ -0,0 +1,71 @@
+package bip39
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWordlist(t *testing.T) {
+	require.Len(t, englishWordlist, 2048)
+	require.Len(t, wordIndexes, 2048)
+}
+
+func TestMnemonicVectors(t *testing.T) {
+	vectors := []struct {
+		entropy  string
+		mnemonic string
+		seed     string
+	}{
+		{
+			entropy:  "00000000000000000000000000000000",
+			mnemonic: "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+			seed:     "c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+		},
+		{
+			entropy:  "7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+			mnemonic: "legal winner thank year wave sausage worth useful legal winner thank yellow",
+		},
+		{
+			entropy:  "80808080808080808080808080808080",
+			mnemonic: "letter advice cage absurd amount doctor acoustic avoid letter advice cage above",
+		},
+		{
+			entropy:  "ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+			mnemonic: "zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo vote",
+			seed:     "dd48c104698c30cfe2b6142103248622fb7bb0ff692eebb00089b32d22484e1613912f0a5b694407be899ffd31ed3992c456cdf60f5d4564b8ba3f05a69890ad",
+		},
+	}
+
+	for _, v := range vectors {
+		entropy, err := hex.DecodeString(v.entropy)
+		require.NoError(t, err)
+
+		mnemonic, err := NewMnemonic(entropy)
+		require.NoError(t, err)
+		assert.Equal(t, v.mnemonic, mnemonic)
+
+		decoded, err := EntropyFromMnemonic(mnemonic)
+		require.NoError(t, err)
+		assert.Equal(t, entropy, decoded)
+
+		if v.seed != "" {
+			assert.Equal(t, v.seed, hex.EncodeToString(NewSeed(mnemonic, "TREZOR")))
+		}
+	}
+}
+
+func TestMnemonicInvalid(t *testing.T) {
+	_, err := NewMnemonic(make([]byte, 15))
+	assert.Error(t, err)
+
+	assert.False(t, IsMnemonicValid(""))
+	assert.False(t, IsMnemonicValid("abandon abandon abandon"))
+	assert.False(t, IsMnemonicValid(strings.Repeat("abandon ", 12)))
+	assert.False(t, IsMnemonicValid(strings.Repeat("abandon ", 11)+"berty"))
+	assert.True(t, IsMnemonicValid(strings.Repeat("abandon ", 11)+"about"))
+	assert.True(t, IsMnemonicValid(strings.ToUpper(strings.Repeat("abandon ", 11)+"about")))
+}
@@ -0,0 +1,3 @@
+// Package bip39 implements BIP39 mnemonic sentences, used to backup and
+// restore account keys.
+package bip39
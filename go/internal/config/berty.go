@@ -8,7 +8,7 @@ import (
 
 type BertyConfig struct {
 	Bootstrap         []string
-	RendezVousPeer    string
+	RendezVousPeers   []string
 	DefaultSwarmAddrs []string
 	Tracing           string
 	DefaultAPIAddrs   []string
@@ -17,7 +17,7 @@ type BertyConfig struct {
 
 var BertyDev = &BertyConfig{
 	Bootstrap:         ipfs_cfg.DefaultBootstrapAddresses,
-	RendezVousPeer:    "/dnsaddr/rdvp.berty.io/ipfs/QmdT7AmhhnbuwvCpa5PH1ySK9HJVB82jr3fo1bxMxBPW6p",
+	RendezVousPeers:   []string{"/dnsaddr/rdvp.berty.io/ipfs/QmdT7AmhhnbuwvCpa5PH1ySK9HJVB82jr3fo1bxMxBPW6p"},
 	DefaultSwarmAddrs: []string{mc.DefaultBind},
 	Tracing:           "bacon.berty.io:8880",
 	DefaultAPIAddrs:   []string{"/ip4/127.0.0.1/tcp/5001"},
@@ -31,7 +31,7 @@ var BertyDev = &BertyConfig{
 
 var BertyMobile = &BertyConfig{
 	Bootstrap:         ipfs_cfg.DefaultBootstrapAddresses,
-	RendezVousPeer:    "/ip4/163.172.106.31/tcp/4040/p2p/QmdT7AmhhnbuwvCpa5PH1ySK9HJVB82jr3fo1bxMxBPW6p",
+	RendezVousPeers:   []string{"/ip4/163.172.106.31/tcp/4040/p2p/QmdT7AmhhnbuwvCpa5PH1ySK9HJVB82jr3fo1bxMxBPW6p"},
 	DefaultSwarmAddrs: []string{mc.DefaultBind},
 	Tracing:           "bacon.berty.io:8880",
 	DefaultAPIAddrs:   []string{"/ip4/127.0.0.1/tcp/5001"},
@@ -0,0 +1,76 @@
+package cryptoutil
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	"berty.tech/berty/v2/go/pkg/errcode"
+	"github.com/libp2p/go-libp2p-core/crypto"
+)
+
+const (
+	safetyNumberVersion    = 0
+	safetyNumberIterations = 5200
+	safetyNumberChunks     = 6
+	safetyNumberChunkSize  = 5
+)
+
+// SafetyNumber returns a numeric fingerprint derived from two account public
+// keys, the result doesn't depend on the order of the keys so both parties
+// display the same value
+func SafetyNumber(a crypto.PubKey, b crypto.PubKey) (string, error) {
+	aBytes, err := a.Raw()
+	if err != nil {
+		return "", errcode.ErrSerialization.Wrap(err)
+	}
+
+	bBytes, err := b.Raw()
+	if err != nil {
+		return "", errcode.ErrSerialization.Wrap(err)
+	}
+
+	if bytes.Compare(aBytes, bBytes) > 0 {
+		aBytes, bBytes = bBytes, aBytes
+	}
+
+	return safetyNumberFingerprint(aBytes) + safetyNumberFingerprint(bBytes), nil
+}
+
+// SafetyNumberDigest returns a hash of a safety number, suitable to be stored
+// to detect later changes
+func SafetyNumberDigest(safetyNumber string) []byte {
+	digest := sha256.Sum256([]byte(safetyNumber))
+
+	return digest[:]
+}
+
+// safetyNumberFingerprint iterates a hash of pk and renders its first bytes
+// as groups of 5 digits
+func safetyNumberFingerprint(pk []byte) string {
+	version := make([]byte, 2)
+	binary.BigEndian.PutUint16(version, safetyNumberVersion)
+
+	digest := append(version, pk...)
+	for i := 0; i < safetyNumberIterations; i++ {
+		sum := sha512.Sum512(append(digest, pk...))
+		digest = sum[:]
+	}
+
+	out := strings.Builder{}
+	for i := 0; i < safetyNumberChunks; i++ {
+		chunk := digest[i*safetyNumberChunkSize : (i+1)*safetyNumberChunkSize]
+
+		value := uint64(0)
+		for _, b := range chunk {
+			value = value<<8 | uint64(b)
+		}
+
+		_, _ = fmt.Fprintf(&out, "%05d", value%100000)
+	}
+
+	return out.String()
+}
@@ -0,0 +1,127 @@
+package handshake
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"berty.tech/berty/v2/go/pkg/errcode"
+
+	ggio "github.com/gogo/protobuf/io"
+	p2pcrypto "github.com/libp2p/go-libp2p-core/crypto"
+)
+
+// DefaultStepTimeout is the time given to each step of a handshake unless
+// configured otherwise
+const DefaultStepTimeout = 10 * time.Second
+
+// Conn is implemented by streams supporting deadlines such as libp2p streams,
+// deadlines are used to interrupt pending reads and writes
+type Conn interface {
+	SetDeadline(t time.Time) error
+}
+
+// WithStepTimeout sets the time given to each step of a handshake started
+// with a context, 0 disables the per-step deadline
+func WithStepTimeout(timeout time.Duration) Option {
+	return func(hc *handshakeContext) {
+		hc.stepTimeout = timeout
+	}
+}
+
+// RequestContext init a handshake with the responder, using provided ggio
+// reader and writer, the handshake is aborted when ctx is done or when a step
+// exceeds its timeout, the error code of the step is then followed by
+// ErrHandshakeTimeout
+func RequestContext(ctx context.Context, conn Conn, reader ggio.Reader, writer ggio.Writer, ownAccountID p2pcrypto.PrivKey, peerAccountID p2pcrypto.PubKey, opts ...Option) error {
+	hc := newHandshakeContext(reader, writer, ownAccountID, peerAccountID, opts...)
+	hc.ctx, hc.conn = ctx, conn
+
+	return hc.request()
+}
+
+// ResponseContext handle the handshake inited by the requester, using
+// provided ggio reader and writer, the handshake is aborted when ctx is done
+// or when a step exceeds its timeout, the error code of the step is then
+// followed by ErrHandshakeTimeout
+func ResponseContext(ctx context.Context, conn Conn, reader ggio.Reader, writer ggio.Writer, ownAccountID p2pcrypto.PrivKey, opts ...Option) (p2pcrypto.PubKey, error) {
+	hc := newHandshakeContext(reader, writer, ownAccountID, nil, opts...)
+	hc.ctx, hc.conn = ctx, conn
+
+	return hc.response()
+}
+
+type handshakeStep struct {
+	errCode errcode.ErrCode
+	run     func() error
+}
+
+// runSteps runs the steps of a handshake in order, each of them must complete
+// before its own deadline and the context one
+func (hc *handshakeContext) runSteps(steps []handshakeStep) error {
+	if hc.conn != nil {
+		var wg sync.WaitGroup
+		stop := make(chan struct{})
+
+		// Interrupt pending reads and writes once the context is done
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			select {
+			case <-hc.ctx.Done():
+				_ = hc.conn.SetDeadline(time.Now())
+			case <-stop:
+			}
+		}()
+
+		defer func() {
+			close(stop)
+			wg.Wait()
+			_ = hc.conn.SetDeadline(time.Time{})
+		}()
+	}
+
+	for _, step := range steps {
+		deadline := hc.stepDeadline()
+		if hc.conn != nil {
+			_ = hc.conn.SetDeadline(deadline)
+		}
+
+		if err := hc.ctx.Err(); err != nil {
+			return step.errCode.Wrap(errcode.ErrHandshakeTimeout.Wrap(err))
+		}
+
+		if err := step.run(); err != nil {
+			// The stream error caused by the deadline is replaced so
+			// ErrHandshakeTimeout is the last code of the error
+			if ctxErr := hc.ctx.Err(); ctxErr != nil {
+				err = errcode.ErrHandshakeTimeout.Wrap(ctxErr)
+			} else if !deadline.IsZero() && !time.Now().Before(deadline) {
+				err = errcode.ErrHandshakeTimeout.Wrap(context.DeadlineExceeded)
+			}
+
+			return step.errCode.Wrap(err)
+		}
+	}
+
+	return nil
+}
+
+// stepDeadline returns the earliest of the step and the context deadlines,
+// deadlines are only applied when the handshake can interrupt the connection
+func (hc *handshakeContext) stepDeadline() time.Time {
+	if hc.conn == nil {
+		return time.Time{}
+	}
+
+	deadline, _ := hc.ctx.Deadline()
+
+	if hc.stepTimeout > 0 {
+		if stepDeadline := time.Now().Add(hc.stepTimeout); deadline.IsZero() || stepDeadline.Before(deadline) {
+			deadline = stepDeadline
+		}
+	}
+
+	return deadline
+}
@@ -52,5 +52,39 @@
 //			  |---------------------------------->|
 //			  |                                   |
 //
+// Proof of work:
+// --------------
+// The Responder can require a hashcash-style proof of work by setting a
+// difficulty in its Hello (see WithProofOfWorkDifficulty). The Requester
+// then looks for a nonce n so that sha256(a.b|n) starts with at least this
+// number of zero bits, and adds it to its Authenticate payload. Using a.b as
+// challenge prevents both precomputation and replay.
+//
+// Version negotiation:
+// --------------------
+// The Requester Hello includes the latest version of the handshake and the
+// optional capabilities supported by the Requester, the Responder Hello
+// includes the version and the capabilities selected by the Responder (the
+// lowest version and the capabilities supported by both peers). Each peer
+// then adds a hash of both hellos to its boxed payload (steps 3 and 4) so the
+// negotiation can't be downgraded by a third party. Peers predating the
+// negotiation ignore those fields and use LegacyVersion (see WithVersion and
+// WithSession).
+//
+// Secure channel:
+// ---------------
+// Once the handshake succeeded, both peers can derive a session key from a.b
+// and A.B (hkdf(a.b|A.B)). If both peers support HandshakeCapabilitySecureChannel,
+// the following messages on the stream can be sealed using a SecureChannel,
+// which uses a key derived from the session key for each direction and a
+// counter as nonce.
+//
+// Timeouts:
+// ---------
+// RequestContext and ResponseContext abort the handshake when the context is
+// done or when a step isn't completed in time (see WithStepTimeout), pending
+// reads and writes are interrupted using the deadline of the stream. The
+// error code of the aborted step is then followed by ErrHandshakeTimeout.
+//
 // See the documentation at https://berty.tech/protocol for more information.
 package handshake
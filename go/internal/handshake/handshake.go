@@ -1,7 +1,9 @@
 package handshake
 
 import (
+	"context"
 	crand "crypto/rand"
+	"time"
 
 	"golang.org/x/crypto/nacl/box"
 
@@ -27,6 +29,44 @@ type handshakeContext struct {
 	ownEphemeral    *[cryptoutil.KeySize]byte
 	peerEphemeral   *[cryptoutil.KeySize]byte
 	sharedEphemeral *[cryptoutil.KeySize]byte
+
+	// proof of work settings, see proof_of_work.go
+	powDifficulty     uint32
+	powMaxDifficulty  uint32
+	peerPoWDifficulty uint32
+
+	// version negotiation, see negotiation.go
+	version      uint32
+	capabilities []HandshakeCapability
+	ownHello     *HelloPayload
+	peerHello    *HelloPayload
+	session      *Session
+
+	// deadlines, see context.go
+	ctx         context.Context
+	conn        Conn
+	stepTimeout time.Duration
+}
+
+func newHandshakeContext(reader ggio.Reader, writer ggio.Writer, ownAccountID p2pcrypto.PrivKey, peerAccountID p2pcrypto.PubKey, opts ...Option) *handshakeContext {
+	hc := &handshakeContext{
+		reader:           reader,
+		writer:           writer,
+		ownAccountID:     ownAccountID,
+		peerAccountID:    peerAccountID,
+		sharedEphemeral:  &[cryptoutil.KeySize]byte{},
+		powMaxDifficulty: DefaultMaxProofOfWorkDifficulty,
+		version:          CurrentVersion,
+		capabilities:     supportedCapabilities,
+		ctx:              context.Background(),
+		stepTimeout:      DefaultStepTimeout,
+	}
+
+	for _, opt := range opts {
+		opt(hc)
+	}
+
+	return hc
 }
 
 // Generates own Ephemeral key pair and send pub key to peer
@@ -40,12 +80,20 @@ func (hc *handshakeContext) generateOwnEphemeralAndSendPubKey() error {
 	// Set own Ephemeral priv key in Handshake Context
 	hc.ownEphemeral = ownEphemeralPriv
 
-	// Send own Ephemeral pub key to peer
-	hello := HelloPayload{EphemeralPubKey: ownEphemeralPub[:]}
+	// Send own Ephemeral pub key to peer, along with the version and the
+	// capabilities offered (requester) or selected (responder)
+	hello := HelloPayload{
+		EphemeralPubKey:       ownEphemeralPub[:],
+		ProofOfWorkDifficulty: hc.powDifficulty,
+		Version:               hc.version,
+		Capabilities:          hc.capabilities,
+	}
 	if err := hc.writer.WriteMsg(&hello); err != nil {
 		return errcode.ErrStreamWrite.Wrap(err)
 	}
 
+	hc.ownHello = &hello
+
 	return nil
 }
 
@@ -65,6 +113,13 @@ func (hc *handshakeContext) receivePeerEphemeralPubKey() error {
 		return errcode.ErrSerialization.Wrap(err)
 	}
 
+	// Set the proof of work difficulty requested by peer, if any
+	hc.peerPoWDifficulty = hello.ProofOfWorkDifficulty
+
+	// Keep peer's hello for the version negotiation
+	hc.peerHello = &hello
+	hc.negotiate(&hello)
+
 	return nil
 }
 
@@ -24,6 +24,32 @@ var _ = math.Inf
 // proto package needs to be updated.
 const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
 
+type HandshakeCapability int32
+
+const (
+	HandshakeCapabilityUndefined HandshakeCapability = 0
+	// HandshakeCapabilitySecureChannel indicates that the messages following the handshake can be sealed using the session key
+	HandshakeCapabilitySecureChannel HandshakeCapability = 1
+)
+
+var HandshakeCapability_name = map[int32]string{
+	0: "HandshakeCapabilityUndefined",
+	1: "HandshakeCapabilitySecureChannel",
+}
+
+var HandshakeCapability_value = map[string]int32{
+	"HandshakeCapabilityUndefined":     0,
+	"HandshakeCapabilitySecureChannel": 1,
+}
+
+func (x HandshakeCapability) String() string {
+	return proto.EnumName(HandshakeCapability_name, int32(x))
+}
+
+func (HandshakeCapability) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_7dc780342ca42053, []int{0}
+}
+
 type BoxEnvelope struct {
 	Box                  []byte   `protobuf:"bytes,1,opt,name=box,proto3" json:"box,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -72,10 +98,16 @@ func (m *BoxEnvelope) GetBox() []byte {
 }
 
 type HelloPayload struct {
-	EphemeralPubKey      []byte   `protobuf:"bytes,1,opt,name=ephemeral_pub_key,json=ephemeralPubKey,proto3" json:"ephemeral_pub_key,omitempty"`
-	XXX_NoUnkeyedLiteral struct{} `json:"-"`
-	XXX_unrecognized     []byte   `json:"-"`
-	XXX_sizecache        int32    `json:"-"`
+	EphemeralPubKey []byte `protobuf:"bytes,1,opt,name=ephemeral_pub_key,json=ephemeralPubKey,proto3" json:"ephemeral_pub_key,omitempty"`
+	// proof_of_work_difficulty is the number of leading zero bits the responder requires from the requester proof of work, 0 if none is required
+	ProofOfWorkDifficulty uint32 `protobuf:"varint,2,opt,name=proof_of_work_difficulty,json=proofOfWorkDifficulty,proto3" json:"proof_of_work_difficulty,omitempty"`
+	// version is the latest version supported by the requester or the version selected by the responder, 0 for peers predating versioning
+	Version uint32 `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
+	// capabilities are the optional features supported by the requester or those selected by the responder
+	Capabilities         []HandshakeCapability `protobuf:"varint,4,rep,packed,name=capabilities,proto3,enum=handshake.HandshakeCapability" json:"capabilities,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}              `json:"-"`
+	XXX_unrecognized     []byte                `json:"-"`
+	XXX_sizecache        int32                 `json:"-"`
 }
 
 func (m *HelloPayload) Reset()         { *m = HelloPayload{} }
@@ -118,9 +150,34 @@ func (m *HelloPayload) GetEphemeralPubKey() []byte {
 	return nil
 }
 
+func (m *HelloPayload) GetProofOfWorkDifficulty() uint32 {
+	if m != nil {
+		return m.ProofOfWorkDifficulty
+	}
+	return 0
+}
+
+func (m *HelloPayload) GetVersion() uint32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *HelloPayload) GetCapabilities() []HandshakeCapability {
+	if m != nil {
+		return m.Capabilities
+	}
+	return nil
+}
+
 type RequesterAuthenticatePayload struct {
-	RequesterAccountId   []byte   `protobuf:"bytes,1,opt,name=requester_account_id,json=requesterAccountId,proto3" json:"requester_account_id,omitempty"`
-	RequesterAccountSig  []byte   `protobuf:"bytes,2,opt,name=requester_account_sig,json=requesterAccountSig,proto3" json:"requester_account_sig,omitempty"`
+	RequesterAccountId  []byte `protobuf:"bytes,1,opt,name=requester_account_id,json=requesterAccountId,proto3" json:"requester_account_id,omitempty"`
+	RequesterAccountSig []byte `protobuf:"bytes,2,opt,name=requester_account_sig,json=requesterAccountSig,proto3" json:"requester_account_sig,omitempty"`
+	// proof_of_work_nonce is the hashcash nonce solving the challenge requested by the responder
+	ProofOfWorkNonce []byte `protobuf:"bytes,3,opt,name=proof_of_work_nonce,json=proofOfWorkNonce,proto3" json:"proof_of_work_nonce,omitempty"`
+	// hello_digest is a hash of the hellos exchanged by both peers, it prevents the version negotiation from being tampered with
+	HelloDigest          []byte   `protobuf:"bytes,4,opt,name=hello_digest,json=helloDigest,proto3" json:"hello_digest,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -173,8 +230,24 @@ func (m *RequesterAuthenticatePayload) GetRequesterAccountSig() []byte {
 	return nil
 }
 
+func (m *RequesterAuthenticatePayload) GetProofOfWorkNonce() []byte {
+	if m != nil {
+		return m.ProofOfWorkNonce
+	}
+	return nil
+}
+
+func (m *RequesterAuthenticatePayload) GetHelloDigest() []byte {
+	if m != nil {
+		return m.HelloDigest
+	}
+	return nil
+}
+
 type ResponderAcceptPayload struct {
-	ResponderAccountSig  []byte   `protobuf:"bytes,1,opt,name=responder_account_sig,json=responderAccountSig,proto3" json:"responder_account_sig,omitempty"`
+	ResponderAccountSig []byte `protobuf:"bytes,1,opt,name=responder_account_sig,json=responderAccountSig,proto3" json:"responder_account_sig,omitempty"`
+	// hello_digest is a hash of the hellos exchanged by both peers, it prevents the version negotiation from being tampered with
+	HelloDigest          []byte   `protobuf:"bytes,2,opt,name=hello_digest,json=helloDigest,proto3" json:"hello_digest,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -220,6 +293,13 @@ func (m *ResponderAcceptPayload) GetResponderAccountSig() []byte {
 	return nil
 }
 
+func (m *ResponderAcceptPayload) GetHelloDigest() []byte {
+	if m != nil {
+		return m.HelloDigest
+	}
+	return nil
+}
+
 type RequesterAcknowledgePayload struct {
 	Success              bool     `protobuf:"varint,1,opt,name=success,proto3" json:"success,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
@@ -268,6 +348,7 @@ func (m *RequesterAcknowledgePayload) GetSuccess() bool {
 }
 
 func init() {
+	proto.RegisterEnum("handshake.HandshakeCapability", HandshakeCapability_name, HandshakeCapability_value)
 	proto.RegisterType((*BoxEnvelope)(nil), "handshake.BoxEnvelope")
 	proto.RegisterType((*HelloPayload)(nil), "handshake.HelloPayload")
 	proto.RegisterType((*RequesterAuthenticatePayload)(nil), "handshake.RequesterAuthenticatePayload")
@@ -278,28 +359,39 @@ func init() {
 func init() { proto.RegisterFile("go-internal/handshake.proto", fileDescriptor_7dc780342ca42053) }
 
 var fileDescriptor_7dc780342ca42053 = []byte{
-	// 328 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x64, 0x91, 0xcd, 0x4a, 0xc3, 0x40,
-	0x14, 0x85, 0x89, 0x82, 0x3f, 0x63, 0x41, 0x8d, 0x3f, 0x14, 0x2b, 0x55, 0xb2, 0x10, 0x11, 0xda,
-	0x88, 0x0a, 0x82, 0xbb, 0x16, 0x04, 0x45, 0x17, 0x25, 0xee, 0xdc, 0x84, 0x99, 0xc9, 0x75, 0x12,
-	0x3a, 0x9d, 0x1b, 0x33, 0x33, 0xda, 0xec, 0x7d, 0x38, 0x97, 0x3e, 0x82, 0xf4, 0x49, 0xc4, 0x69,
-	0x93, 0xa2, 0xdd, 0x9d, 0x33, 0xe7, 0x9e, 0x6f, 0xb8, 0x5c, 0xd2, 0x12, 0xd8, 0xc9, 0x94, 0x81,
-	0x42, 0x51, 0x19, 0xa6, 0x54, 0x25, 0x3a, 0xa5, 0x43, 0xe8, 0xe6, 0x05, 0x1a, 0xf4, 0xd7, 0xeb,
-	0x87, 0x83, 0x8e, 0xc8, 0x4c, 0x6a, 0x59, 0x97, 0xe3, 0x28, 0x14, 0x28, 0x30, 0x74, 0x13, 0xcc,
-	0xbe, 0x38, 0xe7, 0x8c, 0x53, 0xd3, 0x66, 0x70, 0x44, 0x36, 0xfa, 0x38, 0xbe, 0x55, 0x6f, 0x20,
-	0x31, 0x07, 0x7f, 0x8b, 0x2c, 0x33, 0x1c, 0x37, 0xbd, 0x63, 0xef, 0xb4, 0x11, 0xfd, 0xca, 0xe0,
-	0x86, 0x34, 0xee, 0x40, 0x4a, 0x1c, 0xd0, 0x52, 0x22, 0x4d, 0xfc, 0x33, 0xb2, 0x0d, 0x79, 0x0a,
-	0x23, 0x28, 0xa8, 0x8c, 0x73, 0xcb, 0xe2, 0x21, 0x94, 0xb3, 0xf9, 0xcd, 0x3a, 0x18, 0x58, 0xf6,
-	0x00, 0x65, 0xf0, 0xe1, 0x91, 0xc3, 0x08, 0x5e, 0x2d, 0x68, 0x03, 0x45, 0xcf, 0x9a, 0x14, 0x94,
-	0xc9, 0x38, 0x35, 0x50, 0xc1, 0xce, 0xc9, 0x6e, 0x51, 0xe5, 0x31, 0xe5, 0x1c, 0xad, 0x32, 0x71,
-	0x96, 0xcc, 0x78, 0x7e, 0x9d, 0xf5, 0xa6, 0xd1, 0x7d, 0xe2, 0x5f, 0x90, 0xbd, 0xc5, 0x86, 0xce,
-	0x44, 0x73, 0xc9, 0x55, 0x76, 0xfe, 0x57, 0x9e, 0x32, 0x11, 0x3c, 0x92, 0xfd, 0x08, 0x74, 0x8e,
-	0x2a, 0x71, 0xcf, 0x90, 0x9b, 0xea, 0x7f, 0x47, 0x9b, 0x25, 0x7f, 0x68, 0x5e, 0x45, 0x9b, 0xd7,
-	0x2a, 0xda, 0x35, 0x69, 0xcd, 0x77, 0xe2, 0x43, 0x85, 0xef, 0x12, 0x12, 0x51, 0xaf, 0xd4, 0x24,
-	0xab, 0xda, 0x72, 0x0e, 0x5a, 0x3b, 0xc8, 0x5a, 0x54, 0xd9, 0xfe, 0xd5, 0xe7, 0xa4, 0xed, 0x7d,
-	0x4d, 0xda, 0xde, 0xf7, 0xa4, 0xed, 0x3d, 0x9f, 0x30, 0x28, 0x4c, 0xd9, 0x35, 0xc0, 0xd3, 0xd0,
-	0xc9, 0x50, 0x60, 0xb8, 0x78, 0x60, 0xb6, 0xe2, 0xee, 0x74, 0xf9, 0x13, 0x00, 0x00, 0xff, 0xff,
-	0x86, 0x86, 0x66, 0xe2, 0x00, 0x02, 0x00, 0x00,
+	// 498 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x53, 0xc1, 0x6e, 0xd3, 0x40,
+	0x10, 0xad, 0xdb, 0x8a, 0xc2, 0xd6, 0x40, 0xd8, 0x50, 0x64, 0xd1, 0x2a, 0x84, 0x08, 0xa1, 0xa8,
+	0x52, 0x62, 0x54, 0x0e, 0xe5, 0xda, 0xb4, 0x48, 0x45, 0x48, 0x50, 0xb9, 0x42, 0x48, 0x48, 0xc8,
+	0x5a, 0xaf, 0xc7, 0xf6, 0x2a, 0xee, 0x8e, 0x59, 0xef, 0xb6, 0xf5, 0x1f, 0x72, 0x2c, 0x17, 0xce,
+	0x90, 0x2f, 0x41, 0xd9, 0xc6, 0x0e, 0x69, 0x72, 0x9b, 0x99, 0xf7, 0x9e, 0x66, 0xde, 0x5b, 0x2d,
+	0xd9, 0x4d, 0x71, 0x20, 0xa4, 0x06, 0x25, 0x59, 0xee, 0x67, 0x4c, 0xc6, 0x65, 0xc6, 0xc6, 0x30,
+	0x2c, 0x14, 0x6a, 0xa4, 0x0f, 0x9a, 0xc1, 0xf3, 0x41, 0x2a, 0x74, 0x66, 0xa2, 0x21, 0xc7, 0x0b,
+	0x3f, 0xc5, 0x14, 0x7d, 0xcb, 0x88, 0x4c, 0x62, 0x3b, 0xdb, 0xd8, 0xea, 0x56, 0xd9, 0x7b, 0x41,
+	0xb6, 0x47, 0x78, 0xfd, 0x5e, 0x5e, 0x42, 0x8e, 0x05, 0xd0, 0x16, 0xd9, 0x88, 0xf0, 0xda, 0x73,
+	0xba, 0x4e, 0xdf, 0x0d, 0xa6, 0x65, 0xef, 0x97, 0x43, 0xdc, 0x53, 0xc8, 0x73, 0x3c, 0x63, 0x55,
+	0x8e, 0x2c, 0xa6, 0xfb, 0xe4, 0x09, 0x14, 0x19, 0x5c, 0x80, 0x62, 0x79, 0x58, 0x98, 0x28, 0x1c,
+	0x43, 0x35, 0x13, 0x3c, 0x6e, 0x80, 0x33, 0x13, 0x7d, 0x84, 0x8a, 0x1e, 0x12, 0xaf, 0x50, 0x88,
+	0x49, 0x88, 0x49, 0x78, 0x85, 0x6a, 0x1c, 0xc6, 0x22, 0x49, 0x04, 0x37, 0xb9, 0xae, 0xbc, 0xf5,
+	0xae, 0xd3, 0x7f, 0x18, 0xec, 0x58, 0xfc, 0x73, 0xf2, 0x15, 0xd5, 0xf8, 0xa4, 0x01, 0xa9, 0x47,
+	0xb6, 0x2e, 0x41, 0x95, 0x02, 0xa5, 0xb7, 0x61, 0x79, 0x75, 0x4b, 0x47, 0xc4, 0xe5, 0xac, 0x60,
+	0x91, 0xc8, 0x85, 0x16, 0x50, 0x7a, 0x9b, 0xdd, 0x8d, 0xfe, 0xa3, 0x83, 0xce, 0x70, 0x1e, 0xc9,
+	0x69, 0x5d, 0x1d, 0xd7, 0xbc, 0x2a, 0x58, 0xd0, 0xf4, 0x7e, 0x3b, 0x64, 0x2f, 0x80, 0x1f, 0x06,
+	0x4a, 0x0d, 0xea, 0xc8, 0xe8, 0x0c, 0xa4, 0x16, 0x9c, 0x69, 0xa8, 0x3d, 0xbe, 0x21, 0x4f, 0x55,
+	0x8d, 0x87, 0x8c, 0x73, 0x34, 0x52, 0x87, 0x22, 0x9e, 0xd9, 0xa4, 0x0d, 0x76, 0x74, 0x0b, 0x7d,
+	0x88, 0xe9, 0x01, 0xd9, 0x59, 0x56, 0x94, 0x22, 0xb5, 0x36, 0xdd, 0xa0, 0x7d, 0x57, 0x72, 0x2e,
+	0x52, 0x3a, 0x20, 0xed, 0xc5, 0x74, 0x24, 0x4a, 0x0e, 0xd6, 0xb0, 0x1b, 0xb4, 0xfe, 0x0b, 0xe6,
+	0xd3, 0x74, 0x4e, 0x5f, 0x12, 0x37, 0x9b, 0x3e, 0x44, 0x18, 0x8b, 0x14, 0x4a, 0xed, 0x6d, 0x5a,
+	0xde, 0xb6, 0x9d, 0x9d, 0xd8, 0x51, 0x0f, 0xc9, 0xb3, 0x00, 0xca, 0x02, 0x65, 0x6c, 0x17, 0x41,
+	0xa1, 0x6b, 0x47, 0xf6, 0xbe, 0x19, 0xb2, 0x70, 0x9f, 0x53, 0xdf, 0x37, 0x97, 0xd5, 0xf7, 0xdd,
+	0x5d, 0xb8, 0xbe, 0xbc, 0xf0, 0x90, 0xec, 0xce, 0x83, 0xe4, 0x63, 0x89, 0x57, 0x39, 0xc4, 0x69,
+	0x93, 0xa3, 0x47, 0xb6, 0x4a, 0xc3, 0x39, 0x94, 0xa5, 0xdd, 0x73, 0x3f, 0xa8, 0xdb, 0xfd, 0xef,
+	0xa4, 0xbd, 0xe2, 0x9d, 0x68, 0x97, 0xec, 0xad, 0x18, 0x7f, 0x91, 0x31, 0x24, 0x42, 0x42, 0xdc,
+	0x5a, 0xa3, 0xaf, 0x48, 0x77, 0x05, 0xe3, 0x1c, 0xb8, 0x51, 0x70, 0x9c, 0x31, 0x29, 0x21, 0x6f,
+	0x39, 0xa3, 0x77, 0x37, 0x7f, 0x3b, 0x6b, 0x3f, 0x27, 0x1d, 0xe7, 0x66, 0xd2, 0x71, 0xfe, 0x4c,
+	0x3a, 0xce, 0xb7, 0xd7, 0x11, 0x28, 0x5d, 0x0d, 0x35, 0xf0, 0xcc, 0xb7, 0xa5, 0x9f, 0xa2, 0xbf,
+	0xfc, 0xa1, 0xa2, 0x7b, 0xf6, 0x5f, 0xbc, 0xfd, 0x37, 0x00, 0x3b, 0xd7, 0x9b, 0x90, 0x70, 0x03,
+	0x00, 0x00,
 }
 
 func (m *BoxEnvelope) Marshal() (dAtA []byte, err error) {
@@ -360,6 +452,34 @@ func (m *HelloPayload) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if len(m.Capabilities) > 0 {
+		dAtA2 := make([]byte, len(m.Capabilities)*10)
+		var j1 int
+		for _, num := range m.Capabilities {
+			for num >= 1<<7 {
+				dAtA2[j1] = uint8(uint64(num)&0x7f | 0x80)
+				num >>= 7
+				j1++
+			}
+			dAtA2[j1] = uint8(num)
+			j1++
+		}
+		i -= j1
+		copy(dAtA[i:], dAtA2[:j1])
+		i = encodeVarintHandshake(dAtA, i, uint64(j1))
+		i--
+		dAtA[i] = 0x22
+	}
+	if m.Version != 0 {
+		i = encodeVarintHandshake(dAtA, i, uint64(m.Version))
+		i--
+		dAtA[i] = 0x18
+	}
+	if m.ProofOfWorkDifficulty != 0 {
+		i = encodeVarintHandshake(dAtA, i, uint64(m.ProofOfWorkDifficulty))
+		i--
+		dAtA[i] = 0x10
+	}
 	if len(m.EphemeralPubKey) > 0 {
 		i -= len(m.EphemeralPubKey)
 		copy(dAtA[i:], m.EphemeralPubKey)
@@ -394,6 +514,20 @@ func (m *RequesterAuthenticatePayload) MarshalToSizedBuffer(dAtA []byte) (int, e
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if len(m.HelloDigest) > 0 {
+		i -= len(m.HelloDigest)
+		copy(dAtA[i:], m.HelloDigest)
+		i = encodeVarintHandshake(dAtA, i, uint64(len(m.HelloDigest)))
+		i--
+		dAtA[i] = 0x22
+	}
+	if len(m.ProofOfWorkNonce) > 0 {
+		i -= len(m.ProofOfWorkNonce)
+		copy(dAtA[i:], m.ProofOfWorkNonce)
+		i = encodeVarintHandshake(dAtA, i, uint64(len(m.ProofOfWorkNonce)))
+		i--
+		dAtA[i] = 0x1a
+	}
 	if len(m.RequesterAccountSig) > 0 {
 		i -= len(m.RequesterAccountSig)
 		copy(dAtA[i:], m.RequesterAccountSig)
@@ -435,6 +569,13 @@ func (m *ResponderAcceptPayload) MarshalToSizedBuffer(dAtA []byte) (int, error)
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if len(m.HelloDigest) > 0 {
+		i -= len(m.HelloDigest)
+		copy(dAtA[i:], m.HelloDigest)
+		i = encodeVarintHandshake(dAtA, i, uint64(len(m.HelloDigest)))
+		i--
+		dAtA[i] = 0x12
+	}
 	if len(m.ResponderAccountSig) > 0 {
 		i -= len(m.ResponderAccountSig)
 		copy(dAtA[i:], m.ResponderAccountSig)
@@ -519,6 +660,19 @@ func (m *HelloPayload) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovHandshake(uint64(l))
 	}
+	if m.ProofOfWorkDifficulty != 0 {
+		n += 1 + sovHandshake(uint64(m.ProofOfWorkDifficulty))
+	}
+	if m.Version != 0 {
+		n += 1 + sovHandshake(uint64(m.Version))
+	}
+	if len(m.Capabilities) > 0 {
+		l = 0
+		for _, e := range m.Capabilities {
+			l += sovHandshake(uint64(e))
+		}
+		n += 1 + sovHandshake(uint64(l)) + l
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -539,6 +693,14 @@ func (m *RequesterAuthenticatePayload) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovHandshake(uint64(l))
 	}
+	l = len(m.ProofOfWorkNonce)
+	if l > 0 {
+		n += 1 + l + sovHandshake(uint64(l))
+	}
+	l = len(m.HelloDigest)
+	if l > 0 {
+		n += 1 + l + sovHandshake(uint64(l))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -555,6 +717,10 @@ func (m *ResponderAcceptPayload) Size() (n int) {
 	if l > 0 {
 		n += 1 + l + sovHandshake(uint64(l))
 	}
+	l = len(m.HelloDigest)
+	if l > 0 {
+		n += 1 + l + sovHandshake(uint64(l))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -733,6 +899,113 @@ func (m *HelloPayload) Unmarshal(dAtA []byte) error {
 				m.EphemeralPubKey = []byte{}
 			}
 			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ProofOfWorkDifficulty", wireType)
+			}
+			m.ProofOfWorkDifficulty = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowHandshake
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.ProofOfWorkDifficulty |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Version", wireType)
+			}
+			m.Version = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowHandshake
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Version |= uint32(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType == 0 {
+				var v HandshakeCapability
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowHandshake
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					v |= HandshakeCapability(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				m.Capabilities = append(m.Capabilities, v)
+			} else if wireType == 2 {
+				var packedLen int
+				for shift := uint(0); ; shift += 7 {
+					if shift >= 64 {
+						return ErrIntOverflowHandshake
+					}
+					if iNdEx >= l {
+						return io.ErrUnexpectedEOF
+					}
+					b := dAtA[iNdEx]
+					iNdEx++
+					packedLen |= int(b&0x7F) << shift
+					if b < 0x80 {
+						break
+					}
+				}
+				if packedLen < 0 {
+					return ErrInvalidLengthHandshake
+				}
+				postIndex := iNdEx + packedLen
+				if postIndex < 0 {
+					return ErrInvalidLengthHandshake
+				}
+				if postIndex > l {
+					return io.ErrUnexpectedEOF
+				}
+				var elementCount int
+				if elementCount != 0 && len(m.Capabilities) == 0 {
+					m.Capabilities = make([]HandshakeCapability, 0, elementCount)
+				}
+				for iNdEx < postIndex {
+					var v HandshakeCapability
+					for shift := uint(0); ; shift += 7 {
+						if shift >= 64 {
+							return ErrIntOverflowHandshake
+						}
+						if iNdEx >= l {
+							return io.ErrUnexpectedEOF
+						}
+						b := dAtA[iNdEx]
+						iNdEx++
+						v |= HandshakeCapability(b&0x7F) << shift
+						if b < 0x80 {
+							break
+						}
+					}
+					m.Capabilities = append(m.Capabilities, v)
+				}
+			} else {
+				return fmt.Errorf("proto: wrong wireType = %d for field Capabilities", wireType)
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipHandshake(dAtA[iNdEx:])
@@ -855,6 +1128,74 @@ func (m *RequesterAuthenticatePayload) Unmarshal(dAtA []byte) error {
 				m.RequesterAccountSig = []byte{}
 			}
 			iNdEx = postIndex
+		case 3:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ProofOfWorkNonce", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowHandshake
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthHandshake
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthHandshake
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ProofOfWorkNonce = append(m.ProofOfWorkNonce[:0], dAtA[iNdEx:postIndex]...)
+			if m.ProofOfWorkNonce == nil {
+				m.ProofOfWorkNonce = []byte{}
+			}
+			iNdEx = postIndex
+		case 4:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field HelloDigest", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowHandshake
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthHandshake
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthHandshake
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.HelloDigest = append(m.HelloDigest[:0], dAtA[iNdEx:postIndex]...)
+			if m.HelloDigest == nil {
+				m.HelloDigest = []byte{}
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipHandshake(dAtA[iNdEx:])
@@ -943,6 +1284,40 @@ func (m *ResponderAcceptPayload) Unmarshal(dAtA []byte) error {
 				m.ResponderAccountSig = []byte{}
 			}
 			iNdEx = postIndex
+		case 2:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field HelloDigest", wireType)
+			}
+			var byteLen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowHandshake
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				byteLen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if byteLen < 0 {
+				return ErrInvalidLengthHandshake
+			}
+			postIndex := iNdEx + byteLen
+			if postIndex < 0 {
+				return ErrInvalidLengthHandshake
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.HelloDigest = append(m.HelloDigest[:0], dAtA[iNdEx:postIndex]...)
+			if m.HelloDigest == nil {
+				m.HelloDigest = []byte{}
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipHandshake(dAtA[iNdEx:])
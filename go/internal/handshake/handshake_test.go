@@ -1,6 +1,8 @@
 package handshake
 
 import (
+	"bytes"
+	"context"
 	crand "crypto/rand"
 	"sync"
 	"testing"
@@ -12,6 +14,7 @@ import (
 	"berty.tech/berty/v2/go/internal/testutil"
 	"berty.tech/berty/v2/go/pkg/errcode"
 
+	ggio "github.com/gogo/protobuf/io"
 	p2pcrypto "github.com/libp2p/go-libp2p-core/crypto"
 	p2phelpers "github.com/libp2p/go-libp2p-core/helpers"
 	p2pnetwork "github.com/libp2p/go-libp2p-core/network"
@@ -1316,3 +1319,437 @@ func TestInvalidResponderAcceptAck(t *testing.T) {
 		t.Logf("\tduration: %s", time.Since(start))
 	}
 }
+
+func TestProofOfWork(t *testing.T) {
+	challenge := make([]byte, 32)
+	_, err := crand.Read(challenge)
+	require.NoError(t, err)
+
+	nonce, err := solveProofOfWork(challenge, 12)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, proofOfWorkLeadingZeros(challenge, nonce), uint32(12))
+
+	require.NoError(t, verifyProofOfWork(challenge, nonce, 12))
+	require.NoError(t, verifyProofOfWork(challenge, nil, 0))
+	requireEqualFirstErrcode(t, errcode.ErrHandshakeProofOfWork, verifyProofOfWork(challenge, nil, 12))
+
+	otherChallenge := make([]byte, 32)
+	_, err = crand.Read(otherChallenge)
+	require.NoError(t, err)
+	if proofOfWorkLeadingZeros(otherChallenge, nonce) < 12 {
+		requireEqualFirstErrcode(t, errcode.ErrHandshakeProofOfWork, verifyProofOfWork(otherChallenge, nonce, 12))
+	}
+}
+
+func TestHandshakeProofOfWork(t *testing.T) {
+	testutil.SkipSlow(t)
+
+	t.Log("Requester solves the required proof of work")
+	{
+		start := time.Now()
+
+		var requesterTest requesterTestFunc = func(
+			t *testing.T,
+			stream p2pnetwork.Stream,
+			mh *mockedHandshake,
+		) {
+			defer p2phelpers.FullClose(stream)
+
+			err := Request(
+				stream,
+				mh.requester.accountID,
+				mh.responder.accountID.GetPublic(),
+			)
+			require.NoError(t, err, "handshake request failed")
+		}
+
+		var responderTest responderTestFunc = func(
+			t *testing.T,
+			stream p2pnetwork.Stream,
+			mh *mockedHandshake,
+			wg *sync.WaitGroup,
+		) {
+			defer wg.Done()
+			defer p2phelpers.FullClose(stream)
+
+			peerAccountID, err := Response(stream, mh.responder.accountID, WithProofOfWorkDifficulty(12))
+			require.NoError(t, err, "handshake response failed")
+			require.True(t, peerAccountID.Equals(mh.requester.accountID.GetPublic()))
+		}
+
+		runHandshakeTest(t, requesterTest, responderTest)
+		t.Logf("\tduration: %s", time.Since(start))
+	}
+
+	t.Log("Requester refuses a proof of work above its maximum")
+	{
+		start := time.Now()
+
+		var requesterTest requesterTestFunc = func(
+			t *testing.T,
+			stream p2pnetwork.Stream,
+			mh *mockedHandshake,
+		) {
+			defer p2phelpers.FullClose(stream)
+
+			err := Request(
+				stream,
+				mh.requester.accountID,
+				mh.responder.accountID.GetPublic(),
+				WithMaxProofOfWorkDifficulty(8),
+			)
+			requireEqualFirstErrcode(t, errcode.ErrHandshakeRequesterAuthenticate, err)
+			requireEqualLastErrcode(t, errcode.ErrHandshakeProofOfWork, err)
+		}
+
+		var responderTest responderTestFunc = func(
+			t *testing.T,
+			stream p2pnetwork.Stream,
+			mh *mockedHandshake,
+			wg *sync.WaitGroup,
+		) {
+			defer wg.Done()
+			defer p2phelpers.FullClose(stream)
+
+			_, err := Response(stream, mh.responder.accountID, WithProofOfWorkDifficulty(12))
+			requireEqualFirstErrcode(t, errcode.ErrHandshakeRequesterAuthenticate, err)
+			requireEqualLastErrcode(t, errcode.ErrStreamRead, err)
+		}
+
+		runHandshakeTest(t, requesterTest, responderTest)
+		t.Logf("\tduration: %s", time.Since(start))
+	}
+
+	t.Log("Requester sends authenticate without proof of work")
+	{
+		start := time.Now()
+
+		var requesterTest requesterTestFunc = func(
+			t *testing.T,
+			stream p2pnetwork.Stream,
+			mh *mockedHandshake,
+		) {
+			hc := newTestHandshakeContext(
+				stream,
+				mh.requester.accountID,
+				mh.responder.accountID.GetPublic(),
+			)
+
+			err := hc.sendRequesterHello()
+			require.NoError(t, err, "send RequesterHello failed")
+
+			err = hc.receiveResponderHello()
+			require.NoError(t, err, "receive ResponderHello failed")
+			require.Equal(t, uint32(12), hc.peerPoWDifficulty)
+
+			// Ignore the difficulty requested by the responder
+			hc.peerPoWDifficulty = 0
+
+			err = hc.sendRequesterAuthenticate()
+			require.NoError(t, err, "send RequesterAuthenticate failed")
+
+			p2phelpers.FullClose(stream)
+		}
+
+		var responderTest responderTestFunc = func(
+			t *testing.T,
+			stream p2pnetwork.Stream,
+			mh *mockedHandshake,
+			wg *sync.WaitGroup,
+		) {
+			defer wg.Done()
+			defer p2phelpers.FullClose(stream)
+
+			_, err := Response(stream, mh.responder.accountID, WithProofOfWorkDifficulty(12))
+			requireEqualFirstErrcode(t, errcode.ErrHandshakeRequesterAuthenticate, err)
+			requireEqualLastErrcode(t, errcode.ErrHandshakeProofOfWork, err)
+		}
+
+		runHandshakeTest(t, requesterTest, responderTest)
+		t.Logf("\tduration: %s", time.Since(start))
+	}
+}
+
+func TestHandshakeVersionNegotiation(t *testing.T) {
+	testutil.SkipSlow(t)
+
+	t.Log("Peers select the lowest version and the common capabilities")
+	{
+		start := time.Now()
+
+		var requesterTest requesterTestFunc = func(
+			t *testing.T,
+			stream p2pnetwork.Stream,
+			mh *mockedHandshake,
+		) {
+			defer p2phelpers.FullClose(stream)
+
+			session := &Session{}
+			err := Request(
+				stream,
+				mh.requester.accountID,
+				mh.responder.accountID.GetPublic(),
+				WithCapabilities(HandshakeCapability(1), HandshakeCapability(2)),
+				WithSession(session),
+			)
+			require.NoError(t, err, "handshake request failed")
+			require.Equal(t, CurrentVersion, session.Version)
+			require.Equal(t, []HandshakeCapability{HandshakeCapability(2)}, session.Capabilities)
+			require.Len(t, session.Key, cryptoutil.KeySize)
+		}
+
+		var responderTest responderTestFunc = func(
+			t *testing.T,
+			stream p2pnetwork.Stream,
+			mh *mockedHandshake,
+			wg *sync.WaitGroup,
+		) {
+			defer wg.Done()
+			defer p2phelpers.FullClose(stream)
+
+			session := &Session{}
+			_, err := Response(
+				stream,
+				mh.responder.accountID,
+				WithCapabilities(HandshakeCapability(2), HandshakeCapability(3)),
+				WithSession(session),
+			)
+			require.NoError(t, err, "handshake response failed")
+			require.Equal(t, CurrentVersion, session.Version)
+			require.True(t, session.HasCapability(HandshakeCapability(2)))
+			require.False(t, session.HasCapability(HandshakeCapability(3)))
+			require.Len(t, session.Key, cryptoutil.KeySize)
+		}
+
+		runHandshakeTest(t, requesterTest, responderTest)
+		t.Logf("\tduration: %s", time.Since(start))
+	}
+
+	t.Log("Requester uses the legacy version")
+	{
+		start := time.Now()
+
+		var requesterTest requesterTestFunc = func(
+			t *testing.T,
+			stream p2pnetwork.Stream,
+			mh *mockedHandshake,
+		) {
+			defer p2phelpers.FullClose(stream)
+
+			err := Request(
+				stream,
+				mh.requester.accountID,
+				mh.responder.accountID.GetPublic(),
+				WithVersion(LegacyVersion),
+			)
+			require.NoError(t, err, "handshake request failed")
+		}
+
+		var responderTest responderTestFunc = func(
+			t *testing.T,
+			stream p2pnetwork.Stream,
+			mh *mockedHandshake,
+			wg *sync.WaitGroup,
+		) {
+			defer wg.Done()
+			defer p2phelpers.FullClose(stream)
+
+			session := &Session{}
+			_, err := Response(stream, mh.responder.accountID, WithSession(session))
+			require.NoError(t, err, "handshake response failed")
+			require.Equal(t, LegacyVersion, session.Version)
+			require.Empty(t, session.Capabilities)
+		}
+
+		runHandshakeTest(t, requesterTest, responderTest)
+		t.Logf("\tduration: %s", time.Since(start))
+	}
+
+	t.Log("Requester hello is tampered with")
+	{
+		start := time.Now()
+
+		var requesterTest requesterTestFunc = func(
+			t *testing.T,
+			stream p2pnetwork.Stream,
+			mh *mockedHandshake,
+		) {
+			hc := newTestHandshakeContext(
+				stream,
+				mh.requester.accountID,
+				mh.responder.accountID.GetPublic(),
+			)
+			hc.version = CurrentVersion
+
+			err := hc.sendRequesterHello()
+			require.NoError(t, err, "send RequesterHello failed")
+
+			err = hc.receiveResponderHello()
+			require.NoError(t, err, "receive ResponderHello failed")
+
+			// Authenticate another hello than the one sent
+			hc.ownHello = &HelloPayload{
+				EphemeralPubKey: hc.ownHello.EphemeralPubKey,
+				Version:         CurrentVersion + 1,
+			}
+
+			err = hc.sendRequesterAuthenticate()
+			require.NoError(t, err, "send RequesterAuthenticate failed")
+
+			p2phelpers.FullClose(stream)
+		}
+
+		var responderTest responderTestFunc = func(
+			t *testing.T,
+			stream p2pnetwork.Stream,
+			mh *mockedHandshake,
+			wg *sync.WaitGroup,
+		) {
+			defer wg.Done()
+			defer p2phelpers.FullClose(stream)
+
+			_, err := Response(stream, mh.responder.accountID)
+			requireEqualFirstErrcode(t, errcode.ErrHandshakeRequesterAuthenticate, err)
+			requireEqualLastErrcode(t, errcode.ErrHandshakeNegotiation, err)
+		}
+
+		runHandshakeTest(t, requesterTest, responderTest)
+		t.Logf("\tduration: %s", time.Since(start))
+	}
+}
+
+func TestSecureChannel(t *testing.T) {
+	key := make([]byte, cryptoutil.KeySize)
+	_, err := crand.Read(key)
+	require.NoError(t, err)
+
+	capabilities := []HandshakeCapability{HandshakeCapabilitySecureChannel}
+	requesterSession := &Session{Version: CurrentVersion, Capabilities: capabilities, Key: key, asRequester: true}
+	responderSession := &Session{Version: CurrentVersion, Capabilities: capabilities, Key: key}
+
+	toResponder, toRequester := &bytes.Buffer{}, &bytes.Buffer{}
+
+	requester, err := NewSecureChannel(requesterSession, ggio.NewDelimitedReader(toRequester, 2048), ggio.NewDelimitedWriter(toResponder))
+	require.NoError(t, err)
+
+	responder, err := NewSecureChannel(responderSession, ggio.NewDelimitedReader(toResponder, 2048), ggio.NewDelimitedWriter(toRequester))
+	require.NoError(t, err)
+
+	// Messages are read in order in both directions
+	for i := 0; i < 3; i++ {
+		require.NoError(t, requester.WriteMsg(&RequesterAcknowledgePayload{Success: true}))
+	}
+	for i := 0; i < 3; i++ {
+		ack := &RequesterAcknowledgePayload{}
+		require.NoError(t, responder.ReadMsg(ack))
+		require.True(t, ack.Success)
+	}
+
+	require.NoError(t, responder.WriteMsg(&BoxEnvelope{Box: []byte("test")}))
+	env := &BoxEnvelope{}
+	require.NoError(t, requester.ReadMsg(env))
+	require.Equal(t, []byte("test"), env.Box)
+
+	// Replayed messages are rejected
+	require.NoError(t, requester.WriteMsg(&RequesterAcknowledgePayload{Success: true}))
+	replayed := append([]byte(nil), toResponder.Bytes()...)
+	require.NoError(t, responder.ReadMsg(&RequesterAcknowledgePayload{}))
+
+	toResponder.Write(replayed)
+	requireEqualFirstErrcode(t, errcode.ErrCryptoDecrypt, responder.ReadMsg(&RequesterAcknowledgePayload{}))
+
+	// The capability must have been negotiated
+	_, err = NewSecureChannel(&Session{Version: LegacyVersion, Key: key}, ggio.NewDelimitedReader(toRequester, 2048), ggio.NewDelimitedWriter(toResponder))
+	requireEqualFirstErrcode(t, errcode.ErrHandshakeNegotiation, err)
+}
+
+func TestHandshakeTimeout(t *testing.T) {
+	testutil.SkipSlow(t)
+
+	t.Log("Requester stalls after its hello")
+	{
+		start := time.Now()
+
+		var requesterTest requesterTestFunc = func(
+			t *testing.T,
+			stream p2pnetwork.Stream,
+			mh *mockedHandshake,
+		) {
+			hc := newTestHandshakeContext(
+				stream,
+				mh.requester.accountID,
+				mh.responder.accountID.GetPublic(),
+			)
+
+			err := hc.sendRequesterHello()
+			require.NoError(t, err, "send RequesterHello failed")
+
+			err = hc.receiveResponderHello()
+			require.NoError(t, err, "receive ResponderHello failed")
+
+			// Stall longer than the responder step timeout
+			time.Sleep(time.Second)
+
+			p2phelpers.FullClose(stream)
+		}
+
+		var responderTest responderTestFunc = func(
+			t *testing.T,
+			stream p2pnetwork.Stream,
+			mh *mockedHandshake,
+			wg *sync.WaitGroup,
+		) {
+			defer wg.Done()
+			defer p2phelpers.FullClose(stream)
+
+			reader := ggio.NewDelimitedReader(stream, 2048)
+			writer := ggio.NewDelimitedWriter(stream)
+
+			_, err := ResponseContext(context.Background(), stream, reader, writer, mh.responder.accountID, WithStepTimeout(200*time.Millisecond))
+			requireEqualFirstErrcode(t, errcode.ErrHandshakeRequesterAuthenticate, err)
+			requireEqualLastErrcode(t, errcode.ErrHandshakeTimeout, err)
+		}
+
+		runHandshakeTest(t, requesterTest, responderTest)
+		t.Logf("\tduration: %s", time.Since(start))
+	}
+
+	t.Log("Requester context is canceled while waiting for the responder")
+	{
+		start := time.Now()
+
+		var requesterTest requesterTestFunc = func(
+			t *testing.T,
+			stream p2pnetwork.Stream,
+			mh *mockedHandshake,
+		) {
+			defer p2phelpers.FullClose(stream)
+
+			ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+			defer cancel()
+
+			reader := ggio.NewDelimitedReader(stream, 2048)
+			writer := ggio.NewDelimitedWriter(stream)
+
+			err := RequestContext(ctx, stream, reader, writer, mh.requester.accountID, mh.responder.accountID.GetPublic())
+			requireEqualFirstErrcode(t, errcode.ErrHandshakeResponderHello, err)
+			requireEqualLastErrcode(t, errcode.ErrHandshakeTimeout, err)
+		}
+
+		var responderTest responderTestFunc = func(
+			t *testing.T,
+			stream p2pnetwork.Stream,
+			mh *mockedHandshake,
+			wg *sync.WaitGroup,
+		) {
+			defer wg.Done()
+			defer p2phelpers.FullClose(stream)
+
+			// Never answer the requester hello
+			time.Sleep(time.Second)
+		}
+
+		runHandshakeTest(t, requesterTest, responderTest)
+		t.Logf("\tduration: %s", time.Since(start))
+	}
+}
@@ -0,0 +1,173 @@
+package handshake
+
+import (
+	"crypto/subtle"
+	"fmt"
+
+	"berty.tech/berty/v2/go/internal/cryptoutil"
+	"berty.tech/berty/v2/go/pkg/errcode"
+)
+
+const (
+	// LegacyVersion is the version used by peers predating the version
+	// negotiation, their hellos don't include any version nor capability
+	LegacyVersion uint32 = 0
+
+	// CurrentVersion is the latest version of the handshake supported by
+	// this implementation
+	CurrentVersion uint32 = 1
+)
+
+// supportedCapabilities are the optional features offered to the peer unless
+// configured otherwise
+var supportedCapabilities = []HandshakeCapability{
+	HandshakeCapabilitySecureChannel,
+}
+
+// Session holds the outcome of a successful handshake
+type Session struct {
+	// Version is the version of the handshake negotiated with the peer
+	Version uint32
+
+	// Capabilities are the optional features supported by both peers
+	Capabilities []HandshakeCapability
+
+	// Key is a secret shared by both peers derived from a.b and A.B, see
+	// NewSecureChannel
+	Key []byte
+
+	asRequester bool
+}
+
+// HasCapability checks if a feature is supported by both peers
+func (s *Session) HasCapability(capability HandshakeCapability) bool {
+	for _, c := range s.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WithVersion sets the latest version of the handshake offered to the peer,
+// LegacyVersion makes the handshake compatible with peers predating the
+// version negotiation
+func WithVersion(version uint32) Option {
+	return func(hc *handshakeContext) {
+		hc.version = version
+	}
+}
+
+// WithCapabilities sets the optional features offered to the peer
+func WithCapabilities(capabilities ...HandshakeCapability) Option {
+	return func(hc *handshakeContext) {
+		hc.capabilities = capabilities
+	}
+}
+
+// WithSession fills session with the version and the capabilities negotiated
+// with the peer and with the session key once the handshake succeeded
+func WithSession(session *Session) Option {
+	return func(hc *handshakeContext) {
+		hc.session = session
+	}
+}
+
+// negotiate selects the lowest version and the capabilities supported by both
+// peers, the responder sends the result of the negotiation in its hello
+func (hc *handshakeContext) negotiate(peerHello *HelloPayload) {
+	if peerHello.Version < hc.version {
+		hc.version = peerHello.Version
+	}
+
+	if hc.version == LegacyVersion {
+		hc.capabilities = nil
+		return
+	}
+
+	capabilities := []HandshakeCapability(nil)
+	for _, c := range hc.capabilities {
+		for _, peerC := range peerHello.Capabilities {
+			if c == peerC {
+				capabilities = append(capabilities, c)
+				break
+			}
+		}
+	}
+
+	hc.capabilities = capabilities
+}
+
+// computeHelloDigest hashes the hellos exchanged by both peers, only the
+// fields known by this implementation are included
+func (hc *handshakeContext) computeHelloDigest(asRequester bool) ([]byte, error) {
+	if hc.ownHello == nil || hc.peerHello == nil {
+		return nil, errcode.ErrHandshakeNegotiation.Wrap(fmt.Errorf("hellos haven't been exchanged"))
+	}
+
+	requesterHello, responderHello := hc.ownHello, hc.peerHello
+	if !asRequester {
+		requesterHello, responderHello = hc.peerHello, hc.ownHello
+	}
+
+	hellos := make([][]byte, 2)
+	for i, hello := range []*HelloPayload{requesterHello, responderHello} {
+		helloBytes, err := (&HelloPayload{
+			EphemeralPubKey:       hello.EphemeralPubKey,
+			ProofOfWorkDifficulty: hello.ProofOfWorkDifficulty,
+			Version:               hello.Version,
+			Capabilities:          hello.Capabilities,
+		}).Marshal()
+		if err != nil {
+			return nil, errcode.ErrSerialization.Wrap(err)
+		}
+
+		hellos[i] = helloBytes
+	}
+
+	digest := cryptoutil.ConcatAndHashSha256(hellos[0], hellos[1])
+
+	return digest[:], nil
+}
+
+// checkHelloDigest compares the digest sent by the peer to the hellos
+// received, a digest is expected from any peer supporting the version
+// negotiation
+func (hc *handshakeContext) checkHelloDigest(asRequester bool, peerDigest []byte) error {
+	if len(peerDigest) == 0 && (hc.peerHello == nil || hc.peerHello.Version == LegacyVersion) {
+		return nil
+	}
+
+	digest, err := hc.computeHelloDigest(asRequester)
+	if err != nil {
+		return err
+	}
+
+	if subtle.ConstantTimeCompare(digest, peerDigest) != 1 {
+		return errcode.ErrHandshakeNegotiation.Wrap(fmt.Errorf("hellos have been tampered with"))
+	}
+
+	return nil
+}
+
+// sendsHelloDigest checks if the hellos must be authenticated, peers
+// predating the version negotiation ignore the digest
+func (hc *handshakeContext) sendsHelloDigest() bool {
+	return hc.ownHello != nil && hc.ownHello.Version != LegacyVersion
+}
+
+// newSession returns the outcome of the handshake
+func (hc *handshakeContext) newSession(asRequester bool) (*Session, error) {
+	key, err := hc.computeSessionKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Session{
+		Version:      hc.version,
+		Capabilities: hc.capabilities,
+		Key:          key,
+		asRequester:  asRequester,
+	}, nil
+}
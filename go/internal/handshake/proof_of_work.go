@@ -0,0 +1,94 @@
+package handshake
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+
+	"berty.tech/berty/v2/go/internal/cryptoutil"
+	"berty.tech/berty/v2/go/pkg/errcode"
+)
+
+// DefaultMaxProofOfWorkDifficulty is the highest difficulty a requester
+// accepts to solve unless configured otherwise, roughly a few seconds of work
+// on a mobile device
+const DefaultMaxProofOfWorkDifficulty = 24
+
+// maxProofOfWorkDifficulty is the highest difficulty that can be expressed
+// using a sha256 digest
+const maxProofOfWorkDifficulty = sha256.Size * 8
+
+// Option configures optional behaviors of a handshake
+type Option func(hc *handshakeContext)
+
+// WithProofOfWorkDifficulty makes the responder require a hashcash-style
+// proof of work with the given number of leading zero bits from the requester,
+// 0 disables it
+func WithProofOfWorkDifficulty(difficulty uint32) Option {
+	return func(hc *handshakeContext) {
+		hc.powDifficulty = difficulty
+	}
+}
+
+// WithMaxProofOfWorkDifficulty sets the highest proof of work difficulty the
+// requester accepts to solve, the handshake is aborted above this value
+func WithMaxProofOfWorkDifficulty(difficulty uint32) Option {
+	return func(hc *handshakeContext) {
+		hc.powMaxDifficulty = difficulty
+	}
+}
+
+// proofOfWorkLeadingZeros counts the leading zero bits of
+// sha256(challenge|nonce)
+func proofOfWorkLeadingZeros(challenge []byte, nonce []byte) uint32 {
+	digest := cryptoutil.ConcatAndHashSha256(challenge, nonce)
+
+	count := uint32(0)
+	for _, b := range digest[:] {
+		if b != 0 {
+			return count + uint32(bits.LeadingZeros8(b))
+		}
+		count += 8
+	}
+
+	return count
+}
+
+// solveProofOfWork looks for a nonce so that sha256(challenge|nonce) starts
+// with at least difficulty zero bits
+func solveProofOfWork(challenge []byte, difficulty uint32) ([]byte, error) {
+	if difficulty > maxProofOfWorkDifficulty {
+		return nil, errcode.ErrHandshakeProofOfWork.Wrap(fmt.Errorf("difficulty %d is not reachable", difficulty))
+	}
+
+	nonce := make([]byte, 8)
+	for counter := uint64(0); ; counter++ {
+		binary.BigEndian.PutUint64(nonce, counter)
+		if proofOfWorkLeadingZeros(challenge, nonce) >= difficulty {
+			return nonce, nil
+		}
+
+		if counter == ^uint64(0) {
+			return nil, errcode.ErrHandshakeProofOfWork.Wrap(fmt.Errorf("no solution found for difficulty %d", difficulty))
+		}
+	}
+}
+
+// verifyProofOfWork checks that nonce solves the challenge for the given
+// difficulty
+func verifyProofOfWork(challenge []byte, nonce []byte, difficulty uint32) error {
+	if difficulty == 0 {
+		return nil
+	}
+
+	if len(nonce) == 0 {
+		return errcode.ErrHandshakeProofOfWork.Wrap(fmt.Errorf("missing proof of work"))
+	}
+
+	if proofOfWorkLeadingZeros(challenge, nonce) < difficulty {
+		return errcode.ErrHandshakeProofOfWork.Wrap(fmt.Errorf("insufficient proof of work"))
+	}
+
+	return nil
+}
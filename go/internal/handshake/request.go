@@ -2,10 +2,10 @@ package handshake
 
 import (
 	"errors"
+	"fmt"
 
 	"golang.org/x/crypto/nacl/box"
 
-	"berty.tech/berty/v2/go/internal/cryptoutil"
 	"berty.tech/berty/v2/go/pkg/errcode"
 
 	ggio "github.com/gogo/protobuf/io"
@@ -14,41 +14,42 @@ import (
 )
 
 // RequestUsingReaderWriter init a handshake with the responder, using provided ggio reader and writer
-func RequestUsingReaderWriter(reader ggio.Reader, writer ggio.Writer, ownAccountID p2pcrypto.PrivKey, peerAccountID p2pcrypto.PubKey) error {
-	hc := &handshakeContext{
-		reader:          reader,
-		writer:          writer,
-		ownAccountID:    ownAccountID,
-		peerAccountID:   peerAccountID,
-		sharedEphemeral: &[cryptoutil.KeySize]byte{},
-	}
+func RequestUsingReaderWriter(reader ggio.Reader, writer ggio.Writer, ownAccountID p2pcrypto.PrivKey, peerAccountID p2pcrypto.PubKey, opts ...Option) error {
+	hc := newHandshakeContext(reader, writer, ownAccountID, peerAccountID, opts...)
 
-	// Handshake steps on requester side (see comments below)
-	if err := hc.sendRequesterHello(); err != nil {
-		return errcode.ErrHandshakeRequesterHello.Wrap(err)
-	}
-	if err := hc.receiveResponderHello(); err != nil {
-		return errcode.ErrHandshakeResponderHello.Wrap(err)
-	}
-	if err := hc.sendRequesterAuthenticate(); err != nil {
-		return errcode.ErrHandshakeRequesterAuthenticate.Wrap(err)
-	}
-	if err := hc.receiveResponderAccept(); err != nil {
-		return errcode.ErrHandshakeResponderAccept.Wrap(err)
-	}
-	if err := hc.sendRequesterAcknowledge(); err != nil {
-		return errcode.ErrHandshakeRequesterAcknowledge.Wrap(err)
-	}
-
-	return nil
+	return hc.request()
 }
 
 // Request init a handshake with the responder
-func Request(stream p2pnetwork.Stream, ownAccountID p2pcrypto.PrivKey, peerAccountID p2pcrypto.PubKey) error {
+func Request(stream p2pnetwork.Stream, ownAccountID p2pcrypto.PrivKey, peerAccountID p2pcrypto.PubKey, opts ...Option) error {
 	reader := ggio.NewDelimitedReader(stream, 2048)
 	writer := ggio.NewDelimitedWriter(stream)
 
-	return RequestUsingReaderWriter(reader, writer, ownAccountID, peerAccountID)
+	return RequestUsingReaderWriter(reader, writer, ownAccountID, peerAccountID, opts...)
+}
+
+func (hc *handshakeContext) request() error {
+	// Handshake steps on requester side (see comments below)
+	if err := hc.runSteps([]handshakeStep{
+		{errCode: errcode.ErrHandshakeRequesterHello, run: hc.sendRequesterHello},
+		{errCode: errcode.ErrHandshakeResponderHello, run: hc.receiveResponderHello},
+		{errCode: errcode.ErrHandshakeRequesterAuthenticate, run: hc.sendRequesterAuthenticate},
+		{errCode: errcode.ErrHandshakeResponderAccept, run: hc.receiveResponderAccept},
+		{errCode: errcode.ErrHandshakeRequesterAcknowledge, run: hc.sendRequesterAcknowledge},
+	}); err != nil {
+		return err
+	}
+
+	if hc.session != nil {
+		session, err := hc.newSession(true)
+		if err != nil {
+			return err
+		}
+
+		*hc.session = *session
+	}
+
+	return nil
 }
 
 // 1st step - Requester sends: a
@@ -72,13 +73,26 @@ func (hc *handshakeContext) receiveResponderHello() error {
 	return nil
 }
 
-// 3rd step - Requester sends: box[a.b|a.B](A,sig[A](a.b))
+// 3rd step - Requester sends: box[a.b|a.B](A,sig[A](a.b),pow(a.b))
 func (hc *handshakeContext) sendRequesterAuthenticate() error {
 	var (
 		request RequesterAuthenticatePayload
 		err     error
 	)
 
+	// Solve the proof of work requested by the responder, using shared_a_b
+	// as challenge so it can't be precomputed nor replayed
+	if hc.peerPoWDifficulty > 0 {
+		if hc.peerPoWDifficulty > hc.powMaxDifficulty {
+			return errcode.ErrHandshakeProofOfWork.Wrap(fmt.Errorf("requested difficulty %d exceeds accepted maximum %d", hc.peerPoWDifficulty, hc.powMaxDifficulty))
+		}
+
+		request.ProofOfWorkNonce, err = solveProofOfWork(hc.sharedEphemeral[:], hc.peerPoWDifficulty)
+		if err != nil {
+			return err
+		}
+	}
+
 	// Set own AccountID pub key and proof (shared_a_b signed by own AccountID)
 	// in RequesterAuthenticatePayload message before marshaling it
 	request.RequesterAccountId, err = p2pcrypto.MarshalPublicKey(hc.ownAccountID.GetPublic())
@@ -89,6 +103,15 @@ func (hc *handshakeContext) sendRequesterAuthenticate() error {
 	if err != nil {
 		return errcode.ErrCryptoSignature.Wrap(err)
 	}
+
+	// Authenticate the hellos so the negotiation can't be downgraded
+	if hc.sendsHelloDigest() {
+		request.HelloDigest, err = hc.computeHelloDigest(true)
+		if err != nil {
+			return err
+		}
+	}
+
 	requestBytes, err := request.Marshal()
 	if err != nil {
 		return errcode.ErrSerialization.Wrap(err)
@@ -161,7 +184,8 @@ func (hc *handshakeContext) receiveResponderAccept() error {
 		return errcode.ErrCryptoSignatureVerification
 	}
 
-	return nil
+	// Verify that the hellos haven't been tampered with
+	return hc.checkHelloDigest(true, response.HelloDigest)
 }
 
 // 5th step - Requester sends: ok
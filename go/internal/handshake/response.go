@@ -5,7 +5,6 @@ import (
 
 	"golang.org/x/crypto/nacl/box"
 
-	"berty.tech/berty/v2/go/internal/cryptoutil"
 	"berty.tech/berty/v2/go/pkg/errcode"
 
 	ggio "github.com/gogo/protobuf/io"
@@ -14,40 +13,42 @@ import (
 )
 
 // ResponseUsingReaderWriter handle the handshake inited by the requester, using provided ggio reader and writer
-func ResponseUsingReaderWriter(reader ggio.Reader, writer ggio.Writer, ownAccountID p2pcrypto.PrivKey) (p2pcrypto.PubKey, error) {
-	hc := &handshakeContext{
-		reader:          reader,
-		writer:          writer,
-		ownAccountID:    ownAccountID,
-		sharedEphemeral: &[cryptoutil.KeySize]byte{},
-	}
+func ResponseUsingReaderWriter(reader ggio.Reader, writer ggio.Writer, ownAccountID p2pcrypto.PrivKey, opts ...Option) (p2pcrypto.PubKey, error) {
+	hc := newHandshakeContext(reader, writer, ownAccountID, nil, opts...)
 
-	// Handshake steps on responder side (see comments below)
-	if err := hc.receiveRequesterHello(); err != nil {
-		return nil, errcode.ErrHandshakeRequesterHello.Wrap(err)
-	}
-	if err := hc.sendResponderHello(); err != nil {
-		return nil, errcode.ErrHandshakeResponderHello.Wrap(err)
-	}
-	if err := hc.receiveRequesterAuthenticate(); err != nil {
-		return nil, errcode.ErrHandshakeRequesterAuthenticate.Wrap(err)
-	}
-	if err := hc.sendResponderAccept(); err != nil {
-		return nil, errcode.ErrHandshakeResponderAccept.Wrap(err)
-	}
-	if err := hc.receiveRequesterAcknowledge(); err != nil {
-		return nil, errcode.ErrHandshakeRequesterAcknowledge.Wrap(err)
-	}
-
-	return hc.peerAccountID, nil
+	return hc.response()
 }
 
 // Response handle the handshake inited by the requester
-func Response(stream p2pnetwork.Stream, ownAccountID p2pcrypto.PrivKey) (p2pcrypto.PubKey, error) {
+func Response(stream p2pnetwork.Stream, ownAccountID p2pcrypto.PrivKey, opts ...Option) (p2pcrypto.PubKey, error) {
 	reader := ggio.NewDelimitedReader(stream, 2048)
 	writer := ggio.NewDelimitedWriter(stream)
 
-	return ResponseUsingReaderWriter(reader, writer, ownAccountID)
+	return ResponseUsingReaderWriter(reader, writer, ownAccountID, opts...)
+}
+
+func (hc *handshakeContext) response() (p2pcrypto.PubKey, error) {
+	// Handshake steps on responder side (see comments below)
+	if err := hc.runSteps([]handshakeStep{
+		{errCode: errcode.ErrHandshakeRequesterHello, run: hc.receiveRequesterHello},
+		{errCode: errcode.ErrHandshakeResponderHello, run: hc.sendResponderHello},
+		{errCode: errcode.ErrHandshakeRequesterAuthenticate, run: hc.receiveRequesterAuthenticate},
+		{errCode: errcode.ErrHandshakeResponderAccept, run: hc.sendResponderAccept},
+		{errCode: errcode.ErrHandshakeRequesterAcknowledge, run: hc.receiveRequesterAcknowledge},
+	}); err != nil {
+		return nil, err
+	}
+
+	if hc.session != nil {
+		session, err := hc.newSession(false)
+		if err != nil {
+			return nil, err
+		}
+
+		*hc.session = *session
+	}
+
+	return hc.peerAccountID, nil
 }
 
 // 1st step - Responder receives: a
@@ -71,7 +72,7 @@ func (hc *handshakeContext) sendResponderHello() error {
 	return nil
 }
 
-// 3rd step - Responder receives: box[a.b|a.B](A,sig[A](a.b))
+// 3rd step - Responder receives: box[a.b|a.B](A,sig[A](a.b),pow(a.b))
 func (hc *handshakeContext) receiveRequesterAuthenticate() error {
 	var (
 		boxEnvelope BoxEnvelope
@@ -105,6 +106,17 @@ func (hc *handshakeContext) receiveRequesterAuthenticate() error {
 	if err != nil {
 		return errcode.ErrDeserialization.Wrap(err)
 	}
+
+	// Verify the proof of work before any expensive operation
+	if err := verifyProofOfWork(hc.sharedEphemeral[:], request.ProofOfWorkNonce, hc.powDifficulty); err != nil {
+		return err
+	}
+
+	// Verify that the hellos haven't been tampered with
+	if err := hc.checkHelloDigest(false, request.HelloDigest); err != nil {
+		return err
+	}
+
 	hc.peerAccountID, err = p2pcrypto.UnmarshalPublicKey(request.RequesterAccountId)
 	if err != nil {
 		return errcode.ErrDeserialization.Wrap(err)
@@ -137,6 +149,15 @@ func (hc *handshakeContext) sendResponderAccept() error {
 	if err != nil {
 		return errcode.ErrCryptoSignature.Wrap(err)
 	}
+
+	// Authenticate the hellos so the negotiation can't be downgraded
+	if hc.sendsHelloDigest() {
+		response.HelloDigest, err = hc.computeHelloDigest(false)
+		if err != nil {
+			return err
+		}
+	}
+
 	responseBytes, err := response.Marshal()
 	if err != nil {
 		return errcode.ErrSerialization.Wrap(err)
@@ -0,0 +1,178 @@
+package handshake
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"io"
+	"io/ioutil"
+	"sync"
+
+	"golang.org/x/crypto/hkdf"
+	"golang.org/x/crypto/nacl/box"
+	"golang.org/x/crypto/nacl/secretbox"
+
+	"berty.tech/berty/v2/go/internal/cryptoutil"
+	"berty.tech/berty/v2/go/pkg/errcode"
+
+	ggio "github.com/gogo/protobuf/io"
+	"github.com/gogo/protobuf/proto"
+)
+
+// Info used to derive the keys of a secure channel from the session key, each
+// direction uses its own key so counter nonces are never reused
+var (
+	infoSessionKey         = []byte("berty handshake session key")
+	infoRequesterToChannel = []byte("berty secure channel requester")
+	infoResponderToChannel = []byte("berty secure channel responder")
+)
+
+// Computes the session key exported once the handshake succeeded:
+// hkdf(a.b|A.B)
+func (hc *handshakeContext) computeSessionKey() ([]byte, error) {
+	var sharedAccountID [cryptoutil.KeySize]byte
+
+	// Convert Ed25519 AccountID keys to X25519 keys
+	mongOwnAccountID, mongPeerAccountID, err := cryptoutil.EdwardsToMontgomery(
+		hc.ownAccountID,
+		hc.peerAccountID,
+	)
+	if err != nil {
+		return nil, errcode.ErrCryptoKeyConversion.Wrap(err)
+	}
+
+	// Compute shared key from AccountID keys (X25519 converted)
+	box.Precompute(&sharedAccountID, mongPeerAccountID, mongOwnAccountID)
+
+	secret := make([]byte, 0, 2*cryptoutil.KeySize)
+	secret = append(secret, hc.sharedEphemeral[:]...)
+	secret = append(secret, sharedAccountID[:]...)
+
+	return deriveKey(secret, infoSessionKey)
+}
+
+func deriveKey(secret []byte, info []byte) ([]byte, error) {
+	key, err := ioutil.ReadAll(io.LimitReader(hkdf.New(sha256.New, secret, nil, info), cryptoutil.KeySize))
+	if err != nil {
+		return nil, errcode.ErrCryptoKeyGeneration.Wrap(err)
+	}
+
+	return key, nil
+}
+
+// SecureChannel seals the messages exchanged on a stream after a handshake
+// using keys derived from the session key, messages must be read in the order
+// they have been written
+type SecureChannel struct {
+	reader ggio.Reader
+	writer ggio.Writer
+
+	muWrite      sync.Mutex
+	writeKey     *[cryptoutil.KeySize]byte
+	writeCounter uint64
+
+	muRead      sync.Mutex
+	readKey     *[cryptoutil.KeySize]byte
+	readCounter uint64
+}
+
+var (
+	_ ggio.Reader = (*SecureChannel)(nil)
+	_ ggio.Writer = (*SecureChannel)(nil)
+)
+
+// NewSecureChannel wraps the reader and the writer used for a handshake, the
+// peer must have negotiated HandshakeCapabilitySecureChannel
+func NewSecureChannel(session *Session, reader ggio.Reader, writer ggio.Writer) (*SecureChannel, error) {
+	if len(session.Key) != cryptoutil.KeySize {
+		return nil, errcode.ErrInvalidInput.Wrap(errors.New("invalid session key"))
+	}
+
+	if !session.HasCapability(HandshakeCapabilitySecureChannel) {
+		return nil, errcode.ErrHandshakeNegotiation.Wrap(errors.New("secure channel not supported by peer"))
+	}
+
+	requesterKey, err := deriveKey(session.Key, infoRequesterToChannel)
+	if err != nil {
+		return nil, err
+	}
+
+	responderKey, err := deriveKey(session.Key, infoResponderToChannel)
+	if err != nil {
+		return nil, err
+	}
+
+	writeKey, readKey := requesterKey, responderKey
+	if !session.asRequester {
+		writeKey, readKey = responderKey, requesterKey
+	}
+
+	sc := &SecureChannel{
+		reader: reader,
+		writer: writer,
+	}
+
+	if sc.writeKey, err = cryptoutil.KeySliceToArray(writeKey); err != nil {
+		return nil, errcode.ErrSerialization.Wrap(err)
+	}
+
+	if sc.readKey, err = cryptoutil.KeySliceToArray(readKey); err != nil {
+		return nil, errcode.ErrSerialization.Wrap(err)
+	}
+
+	return sc, nil
+}
+
+func counterNonce(counter uint64) *[cryptoutil.NonceSize]byte {
+	var nonce [cryptoutil.NonceSize]byte
+	binary.BigEndian.PutUint64(nonce[:8], counter)
+
+	return &nonce
+}
+
+// WriteMsg seals and sends a message to the peer
+func (sc *SecureChannel) WriteMsg(msg proto.Message) error {
+	msgBytes, err := proto.Marshal(msg)
+	if err != nil {
+		return errcode.ErrSerialization.Wrap(err)
+	}
+
+	sc.muWrite.Lock()
+	defer sc.muWrite.Unlock()
+
+	boxContent := secretbox.Seal(nil, msgBytes, counterNonce(sc.writeCounter), sc.writeKey)
+
+	if err := sc.writer.WriteMsg(&BoxEnvelope{Box: boxContent}); err != nil {
+		return errcode.ErrStreamWrite.Wrap(err)
+	}
+
+	sc.writeCounter++
+
+	return nil
+}
+
+// ReadMsg receives and opens a message sent by the peer, messages which have
+// been replayed, reordered or tampered with are rejected
+func (sc *SecureChannel) ReadMsg(msg proto.Message) error {
+	var boxEnvelope BoxEnvelope
+
+	sc.muRead.Lock()
+	defer sc.muRead.Unlock()
+
+	if err := sc.reader.ReadMsg(&boxEnvelope); err != nil {
+		return errcode.ErrStreamRead.Wrap(err)
+	}
+
+	msgBytes, ok := secretbox.Open(nil, boxEnvelope.Box, counterNonce(sc.readCounter), sc.readKey)
+	if !ok {
+		return errcode.ErrCryptoDecrypt.Wrap(errors.New("box opening failed"))
+	}
+
+	sc.readCounter++
+
+	if err := proto.Unmarshal(msgBytes, msg); err != nil {
+		return errcode.ErrDeserialization.Wrap(err)
+	}
+
+	return nil
+}
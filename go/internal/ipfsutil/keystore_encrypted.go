@@ -0,0 +1,439 @@
+package ipfsutil
+
+import (
+	crand "crypto/rand"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"berty.tech/berty/v2/go/pkg/errcode"
+	datastore "github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	"github.com/ipfs/go-ipfs/keystore"
+	"github.com/libp2p/go-libp2p-core/crypto"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// EncryptedKeystore is a keystore whose entries are encrypted at rest using a
+// key derived from a passphrase, entries can't be listed, read or written
+// while it is locked
+type EncryptedKeystore interface {
+	keystore.Keystore
+
+	// Lock forgets the derived key, it only protects the data at rest: keys
+	// already loaded from the keystore (i.e. cached by a device keystore) are
+	// kept in memory by their users
+	Lock()
+
+	// Unlock derives the key from the passphrase and checks it
+	Unlock(passphrase []byte) error
+
+	// IsLocked returns true if entries can't be accessed
+	IsLocked() bool
+
+	// ChangePassphrase re-encrypts all the entries using a new passphrase
+	ChangePassphrase(oldPassphrase []byte, newPassphrase []byte) error
+}
+
+const (
+	encryptedKeystoreSaltSize = 16
+	encryptedKeystoreTime     = 3
+	encryptedKeystoreMemory   = 64 * 1024
+	encryptedKeystoreThreads  = 4
+)
+
+// encryptedKeystoreParamsKey holds the key derivation parameters, the name
+// can't collide with a keystore entry
+var encryptedKeystoreParamsKey = datastore.NewKey("_encryption")
+
+// encryptedKeystoreCheck is encrypted along with the parameters to check
+// passphrases when unlocking
+var encryptedKeystoreCheck = []byte("berty encrypted keystore")
+
+type encryptedKeystoreParams struct {
+	Salt    []byte `json:"salt"`
+	Time    uint32 `json:"time"`
+	Memory  uint32 `json:"memory"`
+	Threads uint8  `json:"threads"`
+	Check   []byte `json:"check"`
+}
+
+func (p *encryptedKeystoreParams) deriveKey(passphrase []byte) []byte {
+	return argon2.IDKey(passphrase, p.Salt, p.Time, p.Memory, p.Threads, chacha20poly1305.KeySize)
+}
+
+type encryptedKeystore struct {
+	ds     datastore.Datastore
+	params *encryptedKeystoreParams
+	key    []byte
+	mu     sync.RWMutex
+}
+
+func (k *encryptedKeystore) Has(name string) (bool, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	if k.key == nil {
+		return false, errcode.ErrKeystoreLocked
+	}
+
+	return k.ds.Has(datastore.NewKey(name))
+}
+
+func (k *encryptedKeystore) Put(name string, key crypto.PrivKey) error {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	if k.key == nil {
+		return errcode.ErrKeystoreLocked
+	}
+
+	bytes, err := key.Bytes()
+	if err != nil {
+		return errcode.ErrSerialization.Wrap(err)
+	}
+
+	dsKey := datastore.NewKey(name)
+
+	sealed, err := sealKeystoreEntry(k.key, dsKey, bytes)
+	if err != nil {
+		return err
+	}
+
+	return k.ds.Put(dsKey, sealed)
+}
+
+func (k *encryptedKeystore) Get(name string) (crypto.PrivKey, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	if k.key == nil {
+		return nil, errcode.ErrKeystoreLocked
+	}
+
+	dsKey := datastore.NewKey(name)
+
+	sealed, err := k.ds.Get(dsKey)
+	if err == datastore.ErrNotFound {
+		return nil, keystore.ErrNoSuchKey
+	} else if err != nil {
+		return nil, err
+	}
+
+	bytes, err := openKeystoreEntry(k.key, dsKey, sealed)
+	if err != nil {
+		return nil, err
+	}
+
+	return crypto.UnmarshalPrivateKey(bytes)
+}
+
+func (k *encryptedKeystore) Delete(name string) error {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	if k.key == nil {
+		return errcode.ErrKeystoreLocked
+	}
+
+	return k.ds.Delete(datastore.NewKey(name))
+}
+
+func (k *encryptedKeystore) List() ([]string, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	if k.key == nil {
+		return nil, errcode.ErrKeystoreLocked
+	}
+
+	keys, err := k.listKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(keys))
+	for i, key := range keys {
+		names[i] = strings.TrimPrefix(key.String(), "/")
+	}
+
+	return names, nil
+}
+
+func (k *encryptedKeystore) Lock() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	for i := range k.key {
+		k.key[i] = 0
+	}
+
+	k.key = nil
+}
+
+func (k *encryptedKeystore) Unlock(passphrase []byte) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	key, err := k.checkPassphrase(passphrase)
+	if err != nil {
+		return err
+	}
+
+	k.key = key
+
+	return nil
+}
+
+func (k *encryptedKeystore) IsLocked() bool {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	return k.key == nil
+}
+
+func (k *encryptedKeystore) ChangePassphrase(oldPassphrase []byte, newPassphrase []byte) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if len(newPassphrase) == 0 {
+		return errcode.ErrInvalidInput.Wrap(fmt.Errorf("empty passphrase"))
+	}
+
+	oldKey, err := k.checkPassphrase(oldPassphrase)
+	if err != nil {
+		return err
+	}
+
+	params, newKey, err := newEncryptedKeystoreParams(newPassphrase)
+	if err != nil {
+		return err
+	}
+
+	keys, err := k.listKeys()
+	if err != nil {
+		return err
+	}
+
+	entries := map[datastore.Key][]byte{}
+	for _, dsKey := range keys {
+		sealed, err := k.ds.Get(dsKey)
+		if err != nil {
+			return err
+		}
+
+		bytes, err := openKeystoreEntry(oldKey, dsKey, sealed)
+		if err != nil {
+			return err
+		}
+
+		if entries[dsKey], err = sealKeystoreEntry(newKey, dsKey, bytes); err != nil {
+			return err
+		}
+	}
+
+	if err := k.writeEntries(params, entries); err != nil {
+		return err
+	}
+
+	k.params = params
+	k.key = newKey
+
+	return nil
+}
+
+// checkPassphrase returns the key derived from passphrase if it matches the
+// stored parameters
+func (k *encryptedKeystore) checkPassphrase(passphrase []byte) ([]byte, error) {
+	key := k.params.deriveKey(passphrase)
+
+	check, err := openKeystoreEntry(key, encryptedKeystoreParamsKey, k.params.Check)
+	if err != nil || string(check) != string(encryptedKeystoreCheck) {
+		return nil, errcode.ErrKeystoreInvalidPassphrase
+	}
+
+	return key, nil
+}
+
+// listKeys returns the keys of all the entries, excluding the parameters
+func (k *encryptedKeystore) listKeys() ([]datastore.Key, error) {
+	res, err := k.ds.Query(query.Query{KeysOnly: true})
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := res.Rest()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []datastore.Key(nil)
+	for _, e := range entries {
+		key := datastore.NewKey(e.Key)
+		if key.Equal(encryptedKeystoreParamsKey) {
+			continue
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// writeEntries stores the parameters along with the given entries, in a
+// single batch if supported by the datastore
+func (k *encryptedKeystore) writeEntries(params *encryptedKeystoreParams, entries map[datastore.Key][]byte) error {
+	paramsBytes, err := json.Marshal(params)
+	if err != nil {
+		return errcode.ErrSerialization.Wrap(err)
+	}
+
+	var w datastore.Write = k.ds
+	var commit = func() error { return nil }
+
+	if bds, ok := k.ds.(datastore.Batching); ok {
+		b, err := bds.Batch()
+		if err != nil {
+			return err
+		}
+
+		w, commit = b, b.Commit
+	}
+
+	for dsKey, value := range entries {
+		if err := w.Put(dsKey, value); err != nil {
+			return err
+		}
+	}
+
+	if err := w.Put(encryptedKeystoreParamsKey, paramsBytes); err != nil {
+		return err
+	}
+
+	return commit()
+}
+
+func newEncryptedKeystoreParams(passphrase []byte) (*encryptedKeystoreParams, []byte, error) {
+	params := &encryptedKeystoreParams{
+		Salt:    make([]byte, encryptedKeystoreSaltSize),
+		Time:    encryptedKeystoreTime,
+		Memory:  encryptedKeystoreMemory,
+		Threads: encryptedKeystoreThreads,
+	}
+
+	if _, err := crand.Read(params.Salt); err != nil {
+		return nil, nil, errcode.ErrCryptoRandomGeneration.Wrap(err)
+	}
+
+	key := params.deriveKey(passphrase)
+
+	check, err := sealKeystoreEntry(key, encryptedKeystoreParamsKey, encryptedKeystoreCheck)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	params.Check = check
+
+	return params, key, nil
+}
+
+// sealKeystoreEntry encrypts value, binding it to its datastore key
+func sealKeystoreEntry(key []byte, dsKey datastore.Key, value []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, errcode.ErrCryptoEncrypt.Wrap(err)
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(value)+aead.Overhead())
+	if _, err := crand.Read(nonce); err != nil {
+		return nil, errcode.ErrCryptoNonceGeneration.Wrap(err)
+	}
+
+	return aead.Seal(nonce, nonce, value, dsKey.Bytes()), nil
+}
+
+// openKeystoreEntry decrypts a value sealed by sealKeystoreEntry
+func openKeystoreEntry(key []byte, dsKey datastore.Key, sealed []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.NewX(key)
+	if err != nil {
+		return nil, errcode.ErrCryptoDecrypt.Wrap(err)
+	}
+
+	if len(sealed) < aead.NonceSize() {
+		return nil, errcode.ErrCryptoDecrypt.Wrap(fmt.Errorf("entry too short"))
+	}
+
+	value, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], dsKey.Bytes())
+	if err != nil {
+		return nil, errcode.ErrCryptoDecrypt.Wrap(err)
+	}
+
+	return value, nil
+}
+
+// NewEncryptedDatastoreKeystore returns an unlocked keystore encrypting its
+// entries in ds, plaintext entries written by NewDatastoreKeystore are
+// encrypted when it is used for the first time
+func NewEncryptedDatastoreKeystore(ds datastore.Datastore, passphrase []byte) (EncryptedKeystore, error) {
+	if len(passphrase) == 0 {
+		return nil, errcode.ErrInvalidInput.Wrap(fmt.Errorf("empty passphrase"))
+	}
+
+	k := &encryptedKeystore{ds: ds}
+
+	paramsBytes, err := ds.Get(encryptedKeystoreParamsKey)
+	switch err {
+	case nil:
+		k.params = &encryptedKeystoreParams{}
+		if err := json.Unmarshal(paramsBytes, k.params); err != nil {
+			return nil, errcode.ErrDeserialization.Wrap(err)
+		}
+
+		if err := k.Unlock(passphrase); err != nil {
+			return nil, err
+		}
+
+		return k, nil
+
+	case datastore.ErrNotFound:
+		// first use, migrate existing plaintext entries
+
+	default:
+		return nil, err
+	}
+
+	params, key, err := newEncryptedKeystoreParams(passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := k.listKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := map[datastore.Key][]byte{}
+	for _, dsKey := range keys {
+		bytes, err := ds.Get(dsKey)
+		if err != nil {
+			return nil, err
+		}
+
+		if entries[dsKey], err = sealKeystoreEntry(key, dsKey, bytes); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := k.writeEntries(params, entries); err != nil {
+		return nil, err
+	}
+
+	k.params = params
+	k.key = key
+
+	return k, nil
+}
+
+var _ EncryptedKeystore = (*encryptedKeystore)(nil)
@@ -3,6 +3,7 @@ package ipfsutil
 import (
 	"context"
 	"math/rand"
+	"time"
 
 	tinder "berty.tech/berty/v2/go/internal/tinder"
 	datastore "github.com/ipfs/go-datastore"
@@ -22,7 +23,35 @@ type RoutingOut struct {
 	tinder.Routing
 }
 
-func NewTinderRouting(logger *zap.Logger, rdvpeer *peer.AddrInfo, dhtclient bool, localDiscovery bool) (ipfs_p2p.RoutingOption, <-chan *RoutingOut) {
+// TinderPolicies returns the policies of the drivers used by
+// NewTinderRouting, the DHT is only queried if the other drivers found nothing
+// within dhtFallbackDelay when not 0, and the rendezvous points of the
+// contact requests are only looked up on the local network if
+// localContactRequests is true
+func TinderPolicies(dhtFallbackDelay time.Duration, localContactRequests bool) map[string]*tinder.DriverPolicy {
+	dhtPolicy := &tinder.DriverPolicy{}
+	policies := map[string]*tinder.DriverPolicy{
+		"dht": dhtPolicy,
+	}
+
+	if dhtFallbackDelay > 0 {
+		dhtPolicy.Priority = -1
+		dhtPolicy.FallbackDelay = dhtFallbackDelay
+	}
+
+	if localContactRequests {
+		dhtPolicy.NamespaceFilter = tinder.IsContentRoutingNamespace
+		policies["rdvp"] = &tinder.DriverPolicy{NamespaceFilter: tinder.IsContentRoutingNamespace}
+	}
+
+	return policies
+}
+
+// NewTinderRouting returns a routing option using the DHT, the rendezvous
+// points, the local discovery and mDNS to find peers, the peers found are
+// persisted in the datastore for peerCacheTTL if not 0, the drivers are used
+// according to the policies registered for their names
+func NewTinderRouting(logger *zap.Logger, rdvpeers []*peer.AddrInfo, dhtclient bool, localDiscovery bool, mdnsDiscovery bool, peerCacheTTL time.Duration, policies map[string]*tinder.DriverPolicy) (ipfs_p2p.RoutingOption, <-chan *RoutingOut) {
 	crout := make(chan *RoutingOut, 1)
 	return func(ctx context.Context, h host.Host, dstore datastore.Batching, validator record.Validator, bootstrapPeers ...peer.AddrInfo) (routing.Routing, error) {
 		defer close(crout)
@@ -42,10 +71,15 @@ func NewTinderRouting(logger *zap.Logger, rdvpeer *peer.AddrInfo, dhtclient bool
 
 		drivers := []tinder.Driver{}
 
-		if rdvpeer != nil {
-			h.Peerstore().AddAddrs(rdvpeer.ID, rdvpeer.Addrs, peerstore.PermanentAddrTTL)
+		if len(rdvpeers) > 0 {
+			rdvpids := make([]peer.ID, len(rdvpeers))
+			for i, rdvpeer := range rdvpeers {
+				h.Peerstore().AddAddrs(rdvpeer.ID, rdvpeer.Addrs, peerstore.PermanentAddrTTL)
+				rdvpids[i] = rdvpeer.ID
+			}
+
 			// @FIXME(gfanton): use rand as argument
-			rdvClient := tinder.NewRendezvousDiscovery(logger, h, rdvpeer.ID, rand.New(rand.NewSource(rand.Int63())))
+			rdvClient := tinder.NewRendezvousDiscovery(logger, h, rdvpids, rand.New(rand.NewSource(rand.Int63())))
 			drivers = append(drivers, rdvClient)
 		}
 
@@ -54,7 +88,16 @@ func NewTinderRouting(logger *zap.Logger, rdvpeer *peer.AddrInfo, dhtclient bool
 			drivers = append(drivers, localDiscovery)
 		}
 
-		tinderRouting := tinder.NewRouting(logger, "dht", dht, drivers...)
+		if mdnsDiscovery {
+			drivers = append(drivers, tinder.NewMDNSDiscovery(ctx, logger, h))
+		}
+
+		var peerCache *tinder.PeerCache
+		if peerCacheTTL > 0 {
+			peerCache = tinder.NewPeerCache(NewNamespacedDatastore(dstore, datastore.NewKey("tinder")), peerCacheTTL)
+		}
+
+		tinderRouting := tinder.NewRouting(logger, "dht", dht, peerCache, policies, drivers...)
 		crout <- &RoutingOut{dht, tinderRouting}
 
 		return tinderRouting, nil
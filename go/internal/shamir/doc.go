@@ -0,0 +1,3 @@
+// Package shamir implements Shamir's secret sharing over GF(2^8), a secret is
+// split in n shares so that any k of them are required to rebuild it.
+package shamir
@@ -0,0 +1,153 @@
+package shamir
+
+import (
+	crand "crypto/rand"
+	"fmt"
+
+	"berty.tech/berty/v2/go/pkg/errcode"
+)
+
+// MaxShares is the maximum number of shares a secret can be split in
+const MaxShares = 255
+
+// exp and log tables of GF(2^8) using the AES polynomial and 3 as generator
+var gfExp, gfLog = func() ([510]byte, [256]byte) {
+	var exp [510]byte
+	var log [256]byte
+
+	x := byte(1)
+	for i := 0; i < 255; i++ {
+		exp[i] = x
+		exp[i+255] = x
+		log[x] = byte(i)
+
+		// multiply by 3
+		hi := x & 0x80
+		x2 := x << 1
+		if hi != 0 {
+			x2 ^= 0x1b
+		}
+		x ^= x2
+	}
+
+	return exp, log
+}()
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+
+	return gfExp[int(gfLog[a])+int(gfLog[b])]
+}
+
+func gfDiv(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+
+	return gfExp[int(gfLog[a])+255-int(gfLog[b])]
+}
+
+// evaluate computes the value of the polynomial at x using Horner's method
+func evaluate(coefficients []byte, x byte) byte {
+	out := byte(0)
+	for i := len(coefficients) - 1; i >= 0; i-- {
+		out = gfMul(out, x) ^ coefficients[i]
+	}
+
+	return out
+}
+
+// Split divides secret in parts shares, threshold of them being required to
+// rebuild it, each share is one byte longer than the secret
+func Split(secret []byte, parts int, threshold int) ([][]byte, error) {
+	switch {
+	case len(secret) == 0:
+		return nil, errcode.ErrInvalidInput.Wrap(fmt.Errorf("empty secret"))
+	case parts < threshold:
+		return nil, errcode.ErrInvalidInput.Wrap(fmt.Errorf("parts can't be less than threshold"))
+	case parts > MaxShares:
+		return nil, errcode.ErrInvalidInput.Wrap(fmt.Errorf("parts can't exceed %d", MaxShares))
+	case threshold < 2:
+		return nil, errcode.ErrInvalidInput.Wrap(fmt.Errorf("threshold must be at least 2"))
+	}
+
+	shares := make([][]byte, parts)
+	for i := range shares {
+		shares[i] = make([]byte, len(secret)+1)
+
+		// the x coordinate is stored as the last byte, 0 is reserved for the secret
+		shares[i][len(secret)] = byte(i + 1)
+	}
+
+	coefficients := make([]byte, threshold)
+	for idx, b := range secret {
+		coefficients[0] = b
+		if _, err := crand.Read(coefficients[1:]); err != nil {
+			return nil, errcode.ErrCryptoRandomGeneration.Wrap(err)
+		}
+
+		for i := range shares {
+			shares[i][idx] = evaluate(coefficients, shares[i][len(secret)])
+		}
+	}
+
+	for i := range coefficients {
+		coefficients[i] = 0
+	}
+
+	return shares, nil
+}
+
+// Combine rebuilds a secret from at least threshold of its shares, a wrong
+// result is returned if not enough shares are given
+func Combine(shares [][]byte) ([]byte, error) {
+	if len(shares) < 2 {
+		return nil, errcode.ErrInvalidInput.Wrap(fmt.Errorf("at least 2 shares are required"))
+	}
+
+	size := len(shares[0])
+	if size < 2 {
+		return nil, errcode.ErrInvalidInput.Wrap(fmt.Errorf("invalid share size"))
+	}
+
+	xs := make([]byte, len(shares))
+	seen := map[byte]struct{}{}
+
+	for i, share := range shares {
+		if len(share) != size {
+			return nil, errcode.ErrInvalidInput.Wrap(fmt.Errorf("shares must have the same size"))
+		}
+
+		x := share[size-1]
+		if _, ok := seen[x]; ok || x == 0 {
+			return nil, errcode.ErrInvalidInput.Wrap(fmt.Errorf("invalid or duplicate share"))
+		}
+
+		seen[x] = struct{}{}
+		xs[i] = x
+	}
+
+	secret := make([]byte, size-1)
+	for idx := range secret {
+		// Lagrange interpolation at x = 0
+		value := byte(0)
+		for i, xi := range xs {
+			basis := byte(1)
+			for j, xj := range xs {
+				if i == j {
+					continue
+				}
+
+				basis = gfMul(basis, gfDiv(xj, xj^xi))
+			}
+
+			value ^= gfMul(shares[i][idx], basis)
+		}
+
+		secret[idx] = value
+	}
+
+	return secret, nil
+}
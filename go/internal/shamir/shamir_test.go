@@ -0,0 +1,63 @@
+package shamir
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitCombine(t *testing.T) {
+	secret := []byte("berty account recovery secret")
+
+	shares, err := Split(secret, 5, 3)
+	require.NoError(t, err)
+	require.Len(t, shares, 5)
+
+	for _, share := range shares {
+		assert.Len(t, share, len(secret)+1)
+	}
+
+	for _, subset := range [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}, {0, 1, 2, 3, 4}} {
+		parts := [][]byte(nil)
+		for _, i := range subset {
+			parts = append(parts, shares[i])
+		}
+
+		recovered, err := Combine(parts)
+		require.NoError(t, err)
+		assert.Equal(t, secret, recovered)
+	}
+
+	recovered, err := Combine(shares[:2])
+	require.NoError(t, err)
+	assert.NotEqual(t, secret, recovered)
+}
+
+func TestSplitInvalid(t *testing.T) {
+	_, err := Split(nil, 3, 2)
+	assert.Error(t, err)
+
+	_, err = Split([]byte("secret"), 2, 3)
+	assert.Error(t, err)
+
+	_, err = Split([]byte("secret"), 3, 1)
+	assert.Error(t, err)
+
+	_, err = Split([]byte("secret"), MaxShares+1, 2)
+	assert.Error(t, err)
+}
+
+func TestCombineInvalid(t *testing.T) {
+	shares, err := Split([]byte("secret"), 3, 2)
+	require.NoError(t, err)
+
+	_, err = Combine(shares[:1])
+	assert.Error(t, err)
+
+	_, err = Combine([][]byte{shares[0], shares[0]})
+	assert.Error(t, err)
+
+	_, err = Combine([][]byte{shares[0], shares[1][1:]})
+	assert.Error(t, err)
+}
@@ -0,0 +1,312 @@
+package tinder
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"sync"
+	"time"
+
+	p2p_discovery "github.com/libp2p/go-libp2p-core/discovery"
+	"github.com/libp2p/go-libp2p-core/host"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"go.uber.org/zap"
+)
+
+const (
+	// mdnsQueryInterval is the interval at which the LAN is queried while
+	// namespaces are looked up
+	mdnsQueryInterval = 5 * time.Second
+
+	// mdnsPeerTTL is the time during which a peer is returned once found
+	mdnsPeerTTL = time.Minute
+
+	// mdnsLookupTTL is the time after which a namespace which has only
+	// been looked up is not queried anymore
+	mdnsLookupTTL = 10 * time.Minute
+)
+
+type mdnsDiscovery struct {
+	logger    *zap.Logger
+	self      peer.ID
+	responder mdnsResponder
+	ctx       context.Context
+
+	queryInterval time.Duration
+	findTimeout   time.Duration
+	lookupTTL     time.Duration
+
+	advertised map[string]struct{}
+	lookups    map[string]*mdnsLookup
+	querying   bool
+	mux        sync.Mutex
+}
+
+// mdnsLookup holds the peers found for a namespace which has been looked up
+type mdnsLookup struct {
+	lastLookup time.Time
+
+	peers map[peer.ID]*pRecord
+	subs  map[chan peer.AddrInfo]struct{}
+}
+
+// mdnsDiscovery is a Driver
+var _ Driver = (*mdnsDiscovery)(nil)
+
+// NewMDNSDiscovery returns a driver advertising and finding peers on the LAN
+// using mDNS, a single service is published by the peer and carries the
+// hashes of the advertised namespaces, so they can't be read by other
+// devices of the LAN
+func NewMDNSDiscovery(ctx context.Context, logger *zap.Logger, host host.Host) Driver {
+	return newMDNSDiscovery(ctx, logger, host.ID(), newHostMDNSResponder(logger, host))
+}
+
+func newMDNSDiscovery(ctx context.Context, logger *zap.Logger, self peer.ID, responder mdnsResponder) *mdnsDiscovery {
+	d := &mdnsDiscovery{
+		logger:        logger.Named("tinder/mdns"),
+		self:          self,
+		responder:     responder,
+		ctx:           ctx,
+		queryInterval: mdnsQueryInterval,
+		findTimeout:   mdnsQueryInterval + mdnsQueryTimeout + time.Second,
+		lookupTTL:     mdnsLookupTTL,
+		advertised:    make(map[string]struct{}),
+		lookups:       make(map[string]*mdnsLookup),
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		if err := responder.Close(); err != nil {
+			d.logger.Warn("unable to close mdns responder", zap.Error(err))
+		}
+	}()
+
+	return d
+}
+
+// mdnsNamespaceTag returns the tag published for a namespace
+func mdnsNamespaceTag(ns string) string {
+	h := sha256.Sum256([]byte("berty/tinder/mdns/" + ns))
+	return hex.EncodeToString(h[:16])
+}
+
+// publish updates the tags published by the responder, d.mux must be held
+func (d *mdnsDiscovery) publish() error {
+	tags := make([]string, 0, len(d.advertised))
+	for ns := range d.advertised {
+		tags = append(tags, mdnsNamespaceTag(ns))
+	}
+
+	sort.Strings(tags)
+
+	return d.responder.Publish(tags)
+}
+
+func (d *mdnsDiscovery) Advertise(ctx context.Context, ns string, opts ...p2p_discovery.Option) (time.Duration, error) {
+	// Get options
+	var options p2p_discovery.Options
+	err := options.Apply(opts...)
+	if err != nil {
+		return 0, err
+	}
+
+	ttl := options.Ttl
+	if ttl == 0 {
+		ttl = 2 * time.Hour
+	}
+
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	if _, ok := d.advertised[ns]; ok {
+		return ttl, nil
+	}
+
+	d.advertised[ns] = struct{}{}
+	if err := d.publish(); err != nil {
+		delete(d.advertised, ns)
+		return 0, err
+	}
+
+	return ttl, nil
+}
+
+// FindPeers returns the peers found for the namespace, along with the peers
+// found during the next query of the LAN
+func (d *mdnsDiscovery) FindPeers(ctx context.Context, ns string, opts ...p2p_discovery.Option) (<-chan peer.AddrInfo, error) {
+	// Get options
+	var options p2p_discovery.Options
+	err := options.Apply(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	const maxLimit = 1000
+	limit := options.Limit
+	if limit == 0 || limit > maxLimit {
+		limit = maxLimit
+	}
+
+	sub := make(chan peer.AddrInfo, limit)
+
+	d.mux.Lock()
+	d.cleanLookups()
+
+	lookup, ok := d.lookups[ns]
+	if !ok {
+		lookup = &mdnsLookup{
+			peers: make(map[peer.ID]*pRecord),
+			subs:  make(map[chan peer.AddrInfo]struct{}),
+		}
+		d.lookups[ns] = lookup
+	}
+
+	lookup.lastLookup = time.Now()
+
+	currentTime := time.Now().Unix()
+	for p, rec := range lookup.peers {
+		if rec.expire < currentTime {
+			delete(lookup.peers, p)
+			continue
+		}
+
+		if len(sub) < limit {
+			sub <- rec.peer
+		}
+	}
+
+	lookup.subs[sub] = struct{}{}
+
+	if !d.querying {
+		d.querying = true
+		go d.queryLoop()
+	}
+	d.mux.Unlock()
+
+	cpeers := make(chan peer.AddrInfo, limit)
+	go func() {
+		defer close(cpeers)
+		defer func() {
+			d.mux.Lock()
+			delete(lookup.subs, sub)
+			d.mux.Unlock()
+		}()
+
+		timer := time.NewTimer(d.findTimeout)
+		defer timer.Stop()
+
+		for sent := 0; sent < limit; sent++ {
+			select {
+			case p := <-sub:
+				cpeers <- p
+			case <-timer.C:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return cpeers, nil
+}
+
+// cleanLookups forgets the namespaces which haven't been looked up recently,
+// d.mux must be held
+func (d *mdnsDiscovery) cleanLookups() {
+	for ns, lookup := range d.lookups {
+		if len(lookup.subs) == 0 && time.Since(lookup.lastLookup) > d.lookupTTL {
+			delete(d.lookups, ns)
+		}
+	}
+}
+
+// queryLoop queries the LAN periodically, it stops once no namespace is
+// looked up anymore
+func (d *mdnsDiscovery) queryLoop() {
+	ticker := time.NewTicker(d.queryInterval)
+	defer ticker.Stop()
+
+	for {
+		d.mux.Lock()
+		d.cleanLookups()
+		if len(d.lookups) == 0 || d.ctx.Err() != nil {
+			d.querying = false
+			d.mux.Unlock()
+			return
+		}
+		d.mux.Unlock()
+
+		records, err := d.responder.Query(d.ctx)
+		if err != nil && d.ctx.Err() == nil {
+			d.logger.Warn("unable to query the LAN", zap.Error(err))
+		}
+
+		d.handleRecords(records)
+
+		select {
+		case <-ticker.C:
+		case <-d.ctx.Done():
+		}
+	}
+}
+
+// handleRecords dispatches the peers found on the LAN to the namespaces they
+// advertise
+func (d *mdnsDiscovery) handleRecords(records []*mdnsRecord) {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	tags := make(map[string]*mdnsLookup, len(d.lookups))
+	for ns, lookup := range d.lookups {
+		tags[mdnsNamespaceTag(ns)] = lookup
+	}
+
+	now := time.Now()
+	expire := now.Add(mdnsPeerTTL).Unix()
+
+	for _, rec := range records {
+		if rec.peer.ID == d.self {
+			continue
+		}
+
+		for _, tag := range rec.tags {
+			lookup, ok := tags[tag]
+			if !ok {
+				continue
+			}
+
+			// peers answer every query, subscribers are only notified of the
+			// peers they don't know yet
+			if cur, ok := lookup.peers[rec.peer.ID]; ok && cur.expire >= now.Unix() {
+				cur.peer, cur.expire = rec.peer, expire
+				continue
+			}
+
+			lookup.peers[rec.peer.ID] = &pRecord{peer: rec.peer, expire: expire}
+
+			for sub := range lookup.subs {
+				select {
+				case sub <- rec.peer:
+				default:
+				}
+			}
+		}
+	}
+}
+
+func (d *mdnsDiscovery) Unregister(ctx context.Context, ns string) error {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	if _, ok := d.advertised[ns]; !ok {
+		return nil
+	}
+
+	delete(d.advertised, ns)
+
+	return d.publish()
+}
+
+func (*mdnsDiscovery) Name() string { return "mdns" }
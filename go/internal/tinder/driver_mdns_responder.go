@@ -0,0 +1,237 @@
+package tinder
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/host"
+	"github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr-net"
+	"github.com/whyrusleeping/mdns"
+	"go.uber.org/zap"
+)
+
+const (
+	// mdnsServiceName is the mDNS service published by berty peers
+	mdnsServiceName = "_berty-tinder._udp"
+
+	// mdnsQueryTimeout is the time during which the answers to a query are
+	// collected
+	mdnsQueryTimeout = time.Second
+
+	// mdnsTagsPerInstance is the number of tags sent in the TXT record of a
+	// service instance, the tags are split across several instances so each
+	// answer fits in a packet
+	mdnsTagsPerInstance = 16
+
+	// mdnsMaxTags is the maximum number of tags published by a peer
+	mdnsMaxTags = 8 * mdnsTagsPerInstance
+)
+
+// mdnsRecord is the service published by a peer of the LAN
+type mdnsRecord struct {
+	peer peer.AddrInfo
+	tags []string
+}
+
+// mdnsResponder answers the mDNS queries of the LAN and queries the services
+// published by the other peers
+type mdnsResponder interface {
+	// Publish replaces the tags published by the peer, nothing is published
+	// if tags is empty
+	Publish(tags []string) error
+
+	// Query returns the services currently published on the LAN
+	Query(ctx context.Context) ([]*mdnsRecord, error)
+
+	Close() error
+}
+
+// hostMDNSResponder is an mdnsResponder publishing the addresses of a host,
+// the tags are sent in the TXT records of its service instances following its
+// peer ID
+type hostMDNSResponder struct {
+	logger *zap.Logger
+	host   host.Host
+
+	servers []*mdns.Server
+	mux     sync.Mutex
+}
+
+func newHostMDNSResponder(logger *zap.Logger, h host.Host) mdnsResponder {
+	return &hostMDNSResponder{
+		logger: logger.Named("tinder/mdns"),
+		host:   h,
+	}
+}
+
+// listenAddrs returns the IPv4 TCP addresses of the host and their port
+func (r *hostMDNSResponder) listenAddrs() ([]net.IP, int, error) {
+	ips, port := []net.IP(nil), 0
+
+	for _, addr := range r.host.Addrs() {
+		na, err := manet.ToNetAddr(addr)
+		if err != nil {
+			continue
+		}
+
+		tcp, ok := na.(*net.TCPAddr)
+		if !ok || tcp.IP.To4() == nil || tcp.IP.IsLoopback() {
+			continue
+		}
+
+		if port == 0 {
+			port = tcp.Port
+		}
+
+		ips = append(ips, tcp.IP)
+	}
+
+	if len(ips) == 0 {
+		return nil, 0, fmt.Errorf("no IPv4 TCP address to publish")
+	}
+
+	return ips, port, nil
+}
+
+func (r *hostMDNSResponder) Publish(tags []string) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+
+	// the zone of a server can't be updated, the servers are restarted instead
+	r.shutdown()
+
+	if len(tags) == 0 {
+		return nil
+	}
+
+	if len(tags) > mdnsMaxTags {
+		r.logger.Warn("too many tags to publish, dropping some of them", zap.Int("tags", len(tags)))
+		tags = tags[:mdnsMaxTags]
+	}
+
+	ips, port, err := r.listenAddrs()
+	if err != nil {
+		return err
+	}
+
+	// each instance is answered by its own server so they are sent in
+	// separate packets
+	id := r.host.ID().Pretty()
+	for i, instanceTags := range splitMDNSTags(tags) {
+		instance := fmt.Sprintf("%s-%d", id, i)
+
+		zone, err := mdns.NewMDNSService(instance, mdnsServiceName, "", "", port, ips, append([]string{id}, instanceTags...))
+		if err != nil {
+			r.shutdown()
+			return err
+		}
+
+		server, err := mdns.NewServer(&mdns.Config{Zone: zone})
+		if err != nil {
+			r.shutdown()
+			return err
+		}
+
+		r.servers = append(r.servers, server)
+	}
+
+	return nil
+}
+
+// shutdown stops the servers of the published instances, r.mux must be held
+func (r *hostMDNSResponder) shutdown() {
+	for _, server := range r.servers {
+		if err := server.Shutdown(); err != nil {
+			r.logger.Warn("unable to stop mdns server", zap.Error(err))
+		}
+	}
+
+	r.servers = nil
+}
+
+// splitMDNSTags splits tags in groups of at most mdnsTagsPerInstance
+func splitMDNSTags(tags []string) [][]string {
+	groups := [][]string(nil)
+	for len(tags) > mdnsTagsPerInstance {
+		groups = append(groups, tags[:mdnsTagsPerInstance])
+		tags = tags[mdnsTagsPerInstance:]
+	}
+
+	if len(tags) > 0 {
+		groups = append(groups, tags)
+	}
+
+	return groups
+}
+
+func (r *hostMDNSResponder) Query(ctx context.Context) ([]*mdnsRecord, error) {
+	timeout := mdnsQueryTimeout
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < timeout {
+		timeout = time.Until(deadline)
+	}
+
+	entries := make(chan *mdns.ServiceEntry, 16)
+	records := []*mdnsRecord(nil)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		for entry := range entries {
+			rec, err := parseMDNSEntry(entry)
+			if err != nil {
+				r.logger.Debug("invalid mdns entry", zap.String("name", entry.Name), zap.Error(err))
+				continue
+			}
+
+			records = append(records, rec)
+		}
+	}()
+
+	err := mdns.Query(&mdns.QueryParam{
+		Service: mdnsServiceName,
+		Domain:  "local",
+		Timeout: timeout,
+		Entries: entries,
+	})
+
+	close(entries)
+	<-done
+
+	return records, err
+}
+
+// parseMDNSEntry returns the peer and the tags of a service found on the LAN
+func parseMDNSEntry(entry *mdns.ServiceEntry) (*mdnsRecord, error) {
+	if len(entry.InfoFields) == 0 {
+		return nil, fmt.Errorf("missing peer ID")
+	}
+
+	id, err := peer.Decode(entry.InfoFields[0])
+	if err != nil {
+		return nil, err
+	}
+
+	if entry.AddrV4 == nil {
+		return nil, fmt.Errorf("missing address")
+	}
+
+	addr, err := manet.FromNetAddr(&net.TCPAddr{IP: entry.AddrV4, Port: entry.Port})
+	if err != nil {
+		return nil, err
+	}
+
+	return &mdnsRecord{
+		peer: peer.AddrInfo{ID: id, Addrs: []ma.Multiaddr{addr}},
+		tags: entry.InfoFields[1:],
+	}, nil
+}
+
+func (r *hostMDNSResponder) Close() error {
+	return r.Publish(nil)
+}
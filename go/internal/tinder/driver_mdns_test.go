@@ -0,0 +1,214 @@
+package tinder
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"berty.tech/berty/v2/go/internal/testutil"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockedMDNSLAN holds the services published by mocked responders
+type mockedMDNSLAN struct {
+	records map[peer.ID]*mdnsRecord
+	mux     sync.Mutex
+}
+
+type mockedMDNSResponder struct {
+	lan     *mockedMDNSLAN
+	self    peer.AddrInfo
+	queries int
+	closed  bool
+}
+
+func (r *mockedMDNSResponder) Publish(tags []string) error {
+	r.lan.mux.Lock()
+	defer r.lan.mux.Unlock()
+
+	if len(tags) == 0 {
+		delete(r.lan.records, r.self.ID)
+		return nil
+	}
+
+	r.lan.records[r.self.ID] = &mdnsRecord{peer: r.self, tags: tags}
+
+	return nil
+}
+
+func (r *mockedMDNSResponder) Query(context.Context) ([]*mdnsRecord, error) {
+	r.lan.mux.Lock()
+	defer r.lan.mux.Unlock()
+
+	r.queries++
+
+	records := []*mdnsRecord(nil)
+	for _, rec := range r.lan.records {
+		records = append(records, rec)
+	}
+
+	return records, nil
+}
+
+func (r *mockedMDNSResponder) Close() error {
+	r.lan.mux.Lock()
+	r.closed = true
+	r.lan.mux.Unlock()
+
+	return r.Publish(nil)
+}
+
+func (r *mockedMDNSResponder) queryCount() int {
+	r.lan.mux.Lock()
+	defer r.lan.mux.Unlock()
+
+	return r.queries
+}
+
+func (lan *mockedMDNSLAN) published(id peer.ID) []string {
+	lan.mux.Lock()
+	defer lan.mux.Unlock()
+
+	if rec, ok := lan.records[id]; ok {
+		return rec.tags
+	}
+
+	return nil
+}
+
+func testingMDNSDiscovery(ctx context.Context, t *testing.T, lan *mockedMDNSLAN, id peer.ID) (*mdnsDiscovery, *mockedMDNSResponder) {
+	t.Helper()
+
+	r := &mockedMDNSResponder{lan: lan, self: peer.AddrInfo{ID: id}}
+
+	d := newMDNSDiscovery(ctx, testutil.Logger(t), id, r)
+	d.queryInterval = time.Millisecond * 50
+	d.findTimeout = time.Millisecond * 200
+	d.lookupTTL = time.Millisecond * 100
+
+	return d, r
+}
+
+func collectPeers(ch <-chan peer.AddrInfo) []peer.ID {
+	found := []peer.ID(nil)
+	for p := range ch {
+		found = append(found, p.ID)
+	}
+
+	return found
+}
+
+func TestMDNSNamespaceTag(t *testing.T) {
+	const ns = "berty/contact_req/namespace"
+
+	tag := mdnsNamespaceTag(ns)
+	assert.Equal(t, tag, mdnsNamespaceTag(ns))
+	assert.NotEqual(t, tag, mdnsNamespaceTag(ns+"2"))
+	assert.NotContains(t, tag, "berty")
+	assert.Len(t, tag, 32)
+}
+
+func TestSplitMDNSTags(t *testing.T) {
+	assert.Empty(t, splitMDNSTags(nil))
+
+	tags := make([]string, mdnsTagsPerInstance*2+1)
+	for i := range tags {
+		tags[i] = mdnsNamespaceTag(fmt.Sprintf("ns%d", i))
+	}
+
+	groups := splitMDNSTags(tags)
+	require.Len(t, groups, 3)
+	assert.Len(t, groups[0], mdnsTagsPerInstance)
+	assert.Len(t, groups[1], mdnsTagsPerInstance)
+	assert.Equal(t, tags[mdnsTagsPerInstance*2:], groups[2])
+}
+
+func TestMDNSDiscovery(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	lan := &mockedMDNSLAN{records: map[peer.ID]*mdnsRecord{}}
+
+	dA, _ := testingMDNSDiscovery(ctx, t, lan, peer.ID("peerA"))
+	dB, _ := testingMDNSDiscovery(ctx, t, lan, peer.ID("peerB"))
+
+	const testKey = "testkey"
+
+	_, err := dA.Advertise(ctx, testKey)
+	require.NoError(t, err)
+
+	_, err = dA.Advertise(ctx, "otherkey")
+	require.NoError(t, err)
+
+	// a single service carries every advertised namespace
+	assert.ElementsMatch(t, []string{mdnsNamespaceTag(testKey), mdnsNamespaceTag("otherkey")}, lan.published("peerA"))
+
+	ps, err := dB.FindPeers(ctx, testKey)
+	require.NoError(t, err)
+	assert.Equal(t, []peer.ID{"peerA"}, collectPeers(ps))
+
+	// looking up a namespace doesn't advertise it
+	assert.Nil(t, lan.published("peerB"))
+
+	// peers advertising other namespaces aren't found
+	ps, err = dB.FindPeers(ctx, "unknownkey")
+	require.NoError(t, err)
+	assert.Empty(t, collectPeers(ps))
+
+	// the own service of the peer is ignored
+	ps, err = dA.FindPeers(ctx, testKey)
+	require.NoError(t, err)
+	assert.Empty(t, collectPeers(ps))
+
+	require.NoError(t, dA.Unregister(ctx, testKey))
+	assert.Equal(t, []string{mdnsNamespaceTag("otherkey")}, lan.published("peerA"))
+
+	require.NoError(t, dA.Unregister(ctx, "otherkey"))
+	assert.Nil(t, lan.published("peerA"))
+}
+
+func TestMDNSDiscoveryCleanup(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	lan := &mockedMDNSLAN{records: map[peer.ID]*mdnsRecord{}}
+
+	dA, rA := testingMDNSDiscovery(ctx, t, lan, peer.ID("peerA"))
+
+	_, err := dA.Advertise(ctx, "testkey")
+	require.NoError(t, err)
+
+	// the LAN is only queried while namespaces are looked up
+	time.Sleep(time.Millisecond * 200)
+	assert.Equal(t, 0, rA.queryCount())
+
+	ps, err := dA.FindPeers(ctx, "testkey")
+	require.NoError(t, err)
+	collectPeers(ps)
+	assert.NotZero(t, rA.queryCount())
+
+	// lookups are forgotten once their TTL is elapsed, the queries stop
+	time.Sleep(time.Millisecond * 300)
+
+	dA.mux.Lock()
+	assert.Empty(t, dA.lookups)
+	assert.False(t, dA.querying)
+	dA.mux.Unlock()
+
+	queries := rA.queryCount()
+	time.Sleep(time.Millisecond * 200)
+	assert.Equal(t, queries, rA.queryCount())
+
+	// the service is withdrawn when the driver is done
+	cancel()
+	time.Sleep(time.Millisecond * 50)
+
+	lan.mux.Lock()
+	assert.True(t, rA.closed)
+	lan.mux.Unlock()
+	assert.Nil(t, lan.published("peerA"))
+}
@@ -14,16 +14,23 @@ import (
 // MultiDriver is a simple driver manager, that forward request across multiple driver
 type MultiDriver struct {
 	logger  *zap.Logger
-	drivers []Driver
+	drivers []*policyDriver
 
 	mapc map[string]context.CancelFunc
 	muc  sync.Mutex
 }
 
 func NewMultiDriver(logger *zap.Logger, drivers ...Driver) Driver {
+	return NewMultiDriverWithPolicies(logger, nil, drivers...)
+}
+
+// NewMultiDriverWithPolicies returns a MultiDriver using each driver
+// according to the policy registered for its name, drivers without policy
+// are used for every request
+func NewMultiDriverWithPolicies(logger *zap.Logger, policies map[string]*DriverPolicy, drivers ...Driver) Driver {
 	return &MultiDriver{
 		logger:  logger.Named("tinder/multi"),
-		drivers: drivers,
+		drivers: newPolicyDrivers(drivers, policies),
 		mapc:    make(map[string]context.CancelFunc),
 	}
 }
@@ -47,21 +54,32 @@ func (md *MultiDriver) Advertise(ctx context.Context, ns string, opts ...p2p_dis
 	md.muc.Unlock()
 
 	for _, driver := range md.drivers {
-		md.advertise(ctx, driver, ns, opts...)
+		if driver.policy.allows(ns) {
+			md.advertise(ctx, driver, ns, opts...)
+		}
 	}
 
 	return options.Ttl, nil
 }
 
-func (md *MultiDriver) advertise(ctx context.Context, d Driver, ns string, opts ...p2p_discovery.Option) {
+func (md *MultiDriver) advertise(ctx context.Context, d *policyDriver, ns string, opts ...p2p_discovery.Option) {
+	stats := d.stats
+
 	go func() {
 		for {
+			if !d.waitAdvertise(ctx, ns) {
+				return
+			}
+
+			start := time.Now()
 			ttl, err := d.Advertise(ctx, ns, opts...)
 			if err != nil {
 				if ctx.Err() != nil {
 					return
 				}
 
+				stats.advertiseDone(start, err)
+
 				md.logger.Warn("failed to advertise",
 					zap.String("driver", d.Name()),
 					zap.String("key", ns),
@@ -76,6 +94,8 @@ func (md *MultiDriver) advertise(ctx context.Context, d Driver, ns string, opts
 				}
 			}
 
+			stats.advertiseDone(start, nil)
+
 			md.logger.Debug("advertise success",
 				zap.String("driver", d.Name()),
 				zap.String("key", ns),
@@ -97,6 +117,8 @@ func (md *MultiDriver) advertise(ctx context.Context, d Driver, ns string, opts
 
 // FindPeers for MultiDriver doesn't care about duplicate peers, his only
 // job here is to dispatch FindPeers request across all the drivers.
+// Drivers with a fallback delay are only queried if the drivers with a
+// higher priority found nothing within this delay.
 func (md *MultiDriver) FindPeers(ctx context.Context, ns string, opts ...p2p_discovery.Option) (<-chan p2p_peer.AddrInfo, error) {
 	ctx, cancel := context.WithCancel(ctx)
 
@@ -110,19 +132,48 @@ func (md *MultiDriver) FindPeers(ctx context.Context, ns string, opts ...p2p_dis
 		Chan: reflect.ValueOf(ctx.Done()),
 	}
 
-	driverRefs := make([]string, 1)
-	for _, driver := range md.drivers {
-		ch, err := driver.FindPeers(ctx, ns, opts...)
-		if err != nil { // @TODO(gfanton): log this
-			md.logger.Warn("failed to run find peers",
-				zap.String("driver", driver.Name()),
-				zap.String("key", ns),
-				zap.Error(err))
+	// fallbacks are notified once a driver with a higher priority found a
+	// peer
+	type fallback struct {
+		priority int
+		found    chan struct{}
+	}
 
+	start := time.Now()
+	driverRefs := make([]*policyDriver, 1)
+	fallbacks := []*fallback(nil)
+	for _, driver := range md.drivers {
+		if !driver.policy.allows(ns) {
 			continue
 		}
 
-		driverRefs = append(driverRefs, driver.Name())
+		driver := driver
+		find := func() (<-chan p2p_peer.AddrInfo, error) {
+			ch, err := driver.FindPeers(ctx, ns, opts...)
+			driver.stats.findPeersStarted(err)
+			if err != nil { // @TODO(gfanton): log this
+				md.logger.Warn("failed to run find peers",
+					zap.String("driver", driver.Name()),
+					zap.String("key", ns),
+					zap.Error(err))
+			}
+
+			return ch, err
+		}
+
+		var ch <-chan p2p_peer.AddrInfo
+		if driver.policy.FallbackDelay > 0 {
+			fb := &fallback{priority: driver.policy.Priority, found: make(chan struct{})}
+			fallbacks = append(fallbacks, fb)
+			ch = driver.fallbackFindPeers(ctx, fb.found, find)
+		} else {
+			var err error
+			if ch, err = find(); err != nil {
+				continue
+			}
+		}
+
+		driverRefs = append(driverRefs, driver)
 		selCases = append(selCases, reflect.SelectCase{
 			Dir:  reflect.SelectRecv,
 			Chan: reflect.ValueOf(ch),
@@ -135,6 +186,7 @@ func (md *MultiDriver) FindPeers(ctx context.Context, ns string, opts ...p2p_dis
 	}
 
 	cpeers := make(chan p2p_peer.AddrInfo, ndrivers)
+	found := make([]bool, len(selCases))
 	go func() {
 		defer cancel()
 		defer close(cpeers)
@@ -159,11 +211,24 @@ func (md *MultiDriver) FindPeers(ctx context.Context, ns string, opts ...p2p_dis
 			peer := value.Interface().(p2p_peer.AddrInfo)
 			// fmt.Printf("[multi] found a peers: %v for %s\n", peer, ns)
 
+			driver := driverRefs[idx]
 			md.logger.Debug("found a peer",
-				zap.String("driver", driverRefs[idx]),
+				zap.String("driver", driver.Name()),
 				zap.String("key", ns),
 				zap.String("peer", peer.ID.String()))
 
+			driver.stats.peerFound(ns, start, !found[idx])
+			found[idx] = true
+
+			// the drivers with a lower priority don't need to be queried anymore
+			for i := 0; i < len(fallbacks); i++ {
+				if fallbacks[i].priority < driver.policy.Priority {
+					close(fallbacks[i].found)
+					fallbacks = append(fallbacks[:i], fallbacks[i+1:]...)
+					i--
+				}
+			}
+
 			// forward the peer
 			cpeers <- peer
 		}
@@ -183,10 +248,29 @@ func (md *MultiDriver) Unregister(ctx context.Context, ns string) error {
 
 	// unregister drivers
 	for _, driver := range md.drivers {
-		_ = driver.Unregister(ctx, ns) // @TODO(gfanton): log this
+		if driver.policy.allows(ns) {
+			_ = driver.Unregister(ctx, ns) // @TODO(gfanton): log this
+		}
 	}
 
 	return nil
 }
 
 func (*MultiDriver) Name() string { return "MultiDriver" }
+
+// Stats returns the statistics of each driver, followed by the ones of the
+// drivers they wrap
+func (md *MultiDriver) Stats() []*DriverStats {
+	stats := make([]*DriverStats, len(md.drivers))
+	for i, driver := range md.drivers {
+		stats[i] = driver.stats.snapshot()
+	}
+
+	for _, driver := range md.drivers {
+		if sp, ok := driver.Driver.(StatsProvider); ok {
+			stats = append(stats, sp.Stats()...)
+		}
+	}
+
+	return stats
+}
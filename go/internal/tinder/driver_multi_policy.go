@@ -0,0 +1,148 @@
+package tinder
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	p2p_peer "github.com/libp2p/go-libp2p-core/peer"
+)
+
+// DriverPolicy controls how a MultiDriver uses one of its drivers, the zero
+// value uses the driver for every request
+type DriverPolicy struct {
+	// Priority orders the drivers, drivers with a higher priority are
+	// considered faster than the others
+	Priority int
+
+	// NamespaceFilter restricts the driver to the namespaces for which it
+	// returns true, every namespace is allowed if nil
+	NamespaceFilter func(ns string) bool
+
+	// AdvertiseInterval is the minimum time between two advertise requests
+	// sent to the driver for a namespace
+	AdvertiseInterval time.Duration
+
+	// FallbackDelay delays the lookups of the driver, it is only queried if
+	// the drivers with a higher priority found nothing within this delay
+	FallbackDelay time.Duration
+}
+
+// contentRoutingNamespacePrefix prefixes the namespaces advertised by the
+// content routing of the node, see discovery.NewDiscoveryRouting
+const contentRoutingNamespacePrefix = "/provider/"
+
+// IsContentRoutingNamespace reports whether ns is advertised by the content
+// routing of the node rather than being a rendezvous point of the protocol,
+// it can be used as NamespaceFilter to keep a driver away from the
+// rendezvous points of the contact requests
+func IsContentRoutingNamespace(ns string) bool {
+	return strings.HasPrefix(ns, contentRoutingNamespacePrefix)
+}
+
+func (p *DriverPolicy) allows(ns string) bool {
+	return p.NamespaceFilter == nil || p.NamespaceFilter(ns)
+}
+
+// policyDriver is a driver managed by a MultiDriver along with its policy
+type policyDriver struct {
+	Driver
+
+	policy *DriverPolicy
+	stats  *driverStats
+
+	lastAdvertise    map[string]time.Time
+	lastAdvertiseMux sync.Mutex
+}
+
+// newPolicyDrivers returns the drivers sorted by priority, drivers keep
+// their order when they have the same priority
+func newPolicyDrivers(drivers []Driver, policies map[string]*DriverPolicy) []*policyDriver {
+	pdrivers := make([]*policyDriver, len(drivers))
+	for i, driver := range drivers {
+		policy, ok := policies[driver.Name()]
+		if !ok || policy == nil {
+			policy = &DriverPolicy{}
+		}
+
+		pdrivers[i] = &policyDriver{
+			Driver:        driver,
+			policy:        policy,
+			stats:         newDriverStats(driver.Name()),
+			lastAdvertise: make(map[string]time.Time),
+		}
+	}
+
+	sort.SliceStable(pdrivers, func(i, j int) bool {
+		return pdrivers[i].policy.Priority > pdrivers[j].policy.Priority
+	})
+
+	return pdrivers
+}
+
+// waitAdvertise waits until the driver can advertise ns again, it returns
+// false if ctx is done first
+func (d *policyDriver) waitAdvertise(ctx context.Context, ns string) bool {
+	if d.policy.AdvertiseInterval <= 0 {
+		return true
+	}
+
+	for {
+		if ctx.Err() != nil {
+			return false
+		}
+
+		d.lastAdvertiseMux.Lock()
+		wait := time.Until(d.lastAdvertise[ns].Add(d.policy.AdvertiseInterval))
+		if wait <= 0 {
+			d.lastAdvertise[ns] = time.Now()
+			d.lastAdvertiseMux.Unlock()
+			return true
+		}
+		d.lastAdvertiseMux.Unlock()
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// fallbackFindPeers runs the lookup of the driver once its fallback delay is
+// elapsed, unless found is closed first
+func (d *policyDriver) fallbackFindPeers(ctx context.Context, found <-chan struct{}, find func() (<-chan p2p_peer.AddrInfo, error)) <-chan p2p_peer.AddrInfo {
+	cpeers := make(chan p2p_peer.AddrInfo)
+
+	go func() {
+		defer close(cpeers)
+
+		timer := time.NewTimer(d.policy.FallbackDelay)
+		defer timer.Stop()
+
+		select {
+		case <-timer.C:
+		case <-found:
+			return
+		case <-ctx.Done():
+			return
+		}
+
+		ch, err := find()
+		if err != nil {
+			return
+		}
+
+		for peer := range ch {
+			select {
+			case cpeers <- peer:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return cpeers
+}
@@ -0,0 +1,137 @@
+package tinder
+
+import (
+	"sync"
+	"time"
+)
+
+// StatsProvider is implemented by drivers keeping statistics about the
+// drivers they manage
+type StatsProvider interface {
+	Stats() []*DriverStats
+}
+
+// maxNamespaceStats is the number of namespaces whose statistics are kept by
+// driver, rendezvous points rotate so the least recently found are dropped
+const maxNamespaceStats = 128
+
+// NamespaceStats holds the peers found by a driver for a namespace
+type NamespaceStats struct {
+	PeersFound uint64
+	LastFound  time.Time
+}
+
+// DriverStats holds the statistics of a driver managed by a MultiDriver
+type DriverStats struct {
+	Name string
+
+	AdvertiseCalls    uint64
+	AdvertiseFailures uint64
+	// AdvertiseLatency is the duration of the last advertise call
+	AdvertiseLatency time.Duration
+
+	FindPeersCalls    uint64
+	FindPeersFailures uint64
+	// FindPeersLatency is the time taken to find the first peer of the last
+	// lookup which returned peers
+	FindPeersLatency time.Duration
+
+	Namespaces map[string]*NamespaceStats
+
+	LastError   string
+	LastErrorAt time.Time
+}
+
+type driverStats struct {
+	mu    sync.Mutex
+	stats DriverStats
+}
+
+func newDriverStats(name string) *driverStats {
+	return &driverStats{
+		stats: DriverStats{
+			Name:       name,
+			Namespaces: make(map[string]*NamespaceStats),
+		},
+	}
+}
+
+func (s *driverStats) advertiseDone(start time.Time, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stats.AdvertiseCalls++
+	s.stats.AdvertiseLatency = time.Since(start)
+
+	if err != nil {
+		s.stats.AdvertiseFailures++
+		s.setLastError(err)
+	}
+}
+
+func (s *driverStats) findPeersStarted(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stats.FindPeersCalls++
+
+	if err != nil {
+		s.stats.FindPeersFailures++
+		s.setLastError(err)
+	}
+}
+
+func (s *driverStats) peerFound(ns string, start time.Time, first bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if first {
+		s.stats.FindPeersLatency = now.Sub(start)
+	}
+
+	nsStats, ok := s.stats.Namespaces[ns]
+	if !ok {
+		if len(s.stats.Namespaces) >= maxNamespaceStats {
+			s.dropOldestNamespace()
+		}
+
+		nsStats = &NamespaceStats{}
+		s.stats.Namespaces[ns] = nsStats
+	}
+
+	nsStats.PeersFound++
+	nsStats.LastFound = now
+}
+
+// dropOldestNamespace removes the statistics of the namespace whose peers
+// have been found the least recently, mu must be held
+func (s *driverStats) dropOldestNamespace() {
+	oldest := ""
+	for ns, nsStats := range s.stats.Namespaces {
+		if oldest == "" || nsStats.LastFound.Before(s.stats.Namespaces[oldest].LastFound) {
+			oldest = ns
+		}
+	}
+
+	delete(s.stats.Namespaces, oldest)
+}
+
+func (s *driverStats) setLastError(err error) {
+	s.stats.LastError = err.Error()
+	s.stats.LastErrorAt = time.Now()
+}
+
+func (s *driverStats) snapshot() *DriverStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stats := s.stats
+	stats.Namespaces = make(map[string]*NamespaceStats, len(s.stats.Namespaces))
+	for ns, nsStats := range s.stats.Namespaces {
+		nsStatsCopy := *nsStats
+		stats.Namespaces[ns] = &nsStatsCopy
+	}
+
+	return &stats
+}
@@ -2,6 +2,7 @@ package tinder
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -179,3 +180,182 @@ func TestMultiDriver_Unregister(t *testing.T) {
 		})
 	}
 }
+
+func TestMultiDriver_Stats(t *testing.T) {
+	const nMock = 2
+
+	logger := testutil.Logger(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ms := NewMockedDriverServer()
+	mn := p2p_mock.New(ctx)
+
+	peers := testingPeers(t, mn, nMock)
+	drivers := testingMockedDriverClients(t, ms, peers...)
+	md := NewMultiDriver(logger, drivers...)
+
+	sp, ok := md.(StatsProvider)
+	require.True(t, ok)
+
+	const testKey = "testkey"
+	_, err := md.Advertise(ctx, testKey, p2p_discovery.TTL(time.Minute))
+	require.NoError(t, err)
+
+	time.Sleep(time.Millisecond * 100)
+
+	ps, err := md.FindPeers(ctx, testKey)
+	require.NoError(t, err)
+
+	for range ps {
+	}
+
+	stats := sp.Stats()
+	require.Len(t, stats, nMock)
+
+	for _, s := range stats {
+		assert.Equal(t, "mock", s.Name)
+		assert.Equal(t, uint64(1), s.AdvertiseCalls)
+		assert.Equal(t, uint64(0), s.AdvertiseFailures)
+		assert.Equal(t, uint64(1), s.FindPeersCalls)
+		assert.Equal(t, uint64(0), s.FindPeersFailures)
+		assert.Empty(t, s.LastError)
+
+		require.Contains(t, s.Namespaces, testKey)
+		assert.Equal(t, uint64(nMock), s.Namespaces[testKey].PeersFound)
+		assert.False(t, s.Namespaces[testKey].LastFound.IsZero())
+	}
+}
+
+func TestMultiDriver_NestedStats(t *testing.T) {
+	logger := testutil.Logger(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ms := NewMockedDriverServer()
+	mn := p2p_mock.New(ctx)
+
+	peers := testingPeers(t, mn, 3)
+	drivers := testingMockedDriverClients(t, ms, peers...)
+	inner := NewMultiDriver(logger, drivers[:2]...)
+	md := NewMultiDriver(logger, inner, drivers[2])
+
+	sp, ok := md.(StatsProvider)
+	require.True(t, ok)
+
+	// the stats of the wrapped drivers follow the ones of the outer drivers
+	stats := sp.Stats()
+	require.Len(t, stats, 4)
+	assert.Equal(t, "MultiDriver", stats[0].Name)
+	assert.Equal(t, "mock", stats[1].Name)
+	assert.Equal(t, "mock", stats[2].Name)
+	assert.Equal(t, "mock", stats[3].Name)
+}
+
+func TestMultiDriver_Policies(t *testing.T) {
+	logger := testutil.Logger(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ms := NewMockedDriverServer()
+	mn := p2p_mock.New(ctx)
+
+	peers := testingPeers(t, mn, 2)
+	advertiser := NewMockedDriverClient(peers[0], ms)
+
+	named := func(name string) Driver {
+		d := NewMockedDriverClient(peers[1], ms)
+		return ComposeDriver(name, d, d, d)
+	}
+
+	const (
+		testKey    = "testkey"
+		contactKey = "contactkey"
+	)
+
+	md := NewMultiDriverWithPolicies(logger, map[string]*DriverPolicy{
+		"slow": {Priority: -1, FallbackDelay: time.Millisecond * 200},
+		"local": {
+			NamespaceFilter:   func(ns string) bool { return ns == contactKey },
+			AdvertiseInterval: time.Minute,
+		},
+	}, named("slow"), named("fast"), named("local"))
+
+	sp, ok := md.(StatsProvider)
+	require.True(t, ok)
+
+	statsOf := func(name string) *DriverStats {
+		for _, s := range sp.Stats() {
+			if s.Name == name {
+				return s
+			}
+		}
+
+		require.FailNow(t, "no stats for driver", name)
+		return nil
+	}
+
+	// drivers are sorted by priority
+	assert.Equal(t, "slow", sp.Stats()[2].Name)
+
+	// the slow driver isn't queried when a faster driver found a peer
+	_, err := advertiser.Advertise(ctx, testKey, p2p_discovery.TTL(time.Minute))
+	require.NoError(t, err)
+
+	ps, err := md.FindPeers(ctx, testKey)
+	require.NoError(t, err)
+
+	for range ps {
+	}
+
+	assert.Equal(t, uint64(1), statsOf("fast").FindPeersCalls)
+	assert.Equal(t, uint64(0), statsOf("slow").FindPeersCalls)
+	assert.Equal(t, uint64(0), statsOf("local").FindPeersCalls)
+
+	// the slow driver is queried once the fallback delay is elapsed
+	ps, err = md.FindPeers(ctx, "otherkey")
+	require.NoError(t, err)
+
+	for range ps {
+	}
+
+	assert.Equal(t, uint64(1), statsOf("slow").FindPeersCalls)
+
+	// advertise requests are filtered by namespace and rate limited
+	_, err = md.Advertise(ctx, testKey, p2p_discovery.TTL(time.Minute))
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		_, err = md.Advertise(ctx, contactKey, p2p_discovery.TTL(time.Minute))
+		require.NoError(t, err)
+	}
+
+	time.Sleep(time.Millisecond * 100)
+
+	assert.Equal(t, uint64(1), statsOf("local").AdvertiseCalls)
+	assert.True(t, ms.HasPeerRecord(contactKey, peers[1].ID()))
+}
+
+func TestDriverStats_NamespacesBounded(t *testing.T) {
+	s := newDriverStats("test")
+	start := time.Now()
+
+	for i := 0; i < maxNamespaceStats; i++ {
+		s.peerFound(fmt.Sprintf("ns%d", i), start, false)
+	}
+
+	// the least recently found namespace is dropped
+	s.peerFound("ns0", start, false)
+	s.peerFound("new", start, false)
+
+	stats := s.snapshot()
+	assert.Len(t, stats.Namespaces, maxNamespaceStats)
+	assert.Contains(t, stats.Namespaces, "ns0")
+	assert.Contains(t, stats.Namespaces, "new")
+	assert.NotContains(t, stats.Namespaces, "ns1")
+}
+
+func TestIsContentRoutingNamespace(t *testing.T) {
+	assert.True(t, IsContentRoutingNamespace("/provider/QmYwAPJzv5CZsnA625s3Xf2nemtYgPpHdWEz79ojWnPbdG"))
+	assert.False(t, IsContentRoutingNamespace("\x8f\x01/provider/"))
+}
@@ -0,0 +1,454 @@
+package tinder
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	ipfs_interface "github.com/ipfs/interface-go-ipfs-core"
+	p2p_discovery "github.com/libp2p/go-libp2p-core/discovery"
+	"github.com/libp2p/go-libp2p-core/host"
+	"github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+	"go.uber.org/zap"
+)
+
+const (
+	// pubsubAdvertiseInterval is the interval at which the presence of the
+	// peer is published on the topics it advertises
+	pubsubAdvertiseInterval = 30 * time.Second
+
+	// pubsubAnswerInterval is the minimum interval between two presence
+	// records sent on a topic in answer to queries
+	pubsubAnswerInterval = time.Second
+
+	// pubsubLookupTimeout is the time during which FindPeers waits for the
+	// answers to its query
+	pubsubLookupTimeout = 5 * time.Second
+
+	// pubsubLookupTTL is the time after which a topic which has only been
+	// looked up is unsubscribed
+	pubsubLookupTTL = 10 * time.Minute
+)
+
+type pubsubDiscovery struct {
+	logger *zap.Logger
+	host   host.Host
+	ps     ipfs_interface.PubSubAPI
+	ctx    context.Context
+
+	topics map[string]*pubsubTopic
+	mux    sync.Mutex
+}
+
+// pubsubTopic holds the subscription to the topic of a namespace and the
+// peers found on it
+type pubsubTopic struct {
+	name   string
+	cancel context.CancelFunc
+
+	advertiseCancel context.CancelFunc
+	advertiseTTL    time.Duration
+	lastPublish     time.Time
+	lastLookup      time.Time
+
+	peers map[peer.ID]*pRecord
+	subs  map[chan peer.AddrInfo]struct{}
+	mux   sync.Mutex
+}
+
+// pubsubDiscovery is a Driver
+var _ Driver = (*pubsubDiscovery)(nil)
+
+// NewPubSubDiscovery returns a driver publishing signed presence records on
+// pubsub topics derived from the namespaces, it finds peers as soon as any
+// peer of the mesh is connected. The pubsub API is only available once the
+// node is built, so the driver isn't part of the default routing and has to
+// be added by the caller.
+func NewPubSubDiscovery(ctx context.Context, logger *zap.Logger, host host.Host, ps ipfs_interface.PubSubAPI) Driver {
+	return &pubsubDiscovery{
+		logger: logger.Named("tinder/pubsub"),
+		host:   host,
+		ps:     ps,
+		ctx:    ctx,
+		topics: make(map[string]*pubsubTopic),
+	}
+}
+
+// pubsubTopicName returns the topic used for a namespace, namespaces are
+// hashed so they can't be read by the other peers of the mesh
+func pubsubTopicName(ns string) string {
+	h := sha256.Sum256([]byte("berty/tinder/pubsub/" + ns))
+	return "/berty/tinder/1.0.0/" + hex.EncodeToString(h[:])
+}
+
+// getTopic returns the topic of a namespace, it is subscribed if needed
+func (d *pubsubDiscovery) getTopic(ns string) (*pubsubTopic, error) {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	d.cleanTopics()
+
+	if t, ok := d.topics[ns]; ok {
+		return t, nil
+	}
+
+	ctx, cancel := context.WithCancel(d.ctx)
+
+	t := &pubsubTopic{
+		name:   pubsubTopicName(ns),
+		cancel: cancel,
+		peers:  make(map[peer.ID]*pRecord),
+		subs:   make(map[chan peer.AddrInfo]struct{}),
+
+		// a fresh topic must not look stale to the next cleanTopics
+		lastLookup: time.Now(),
+	}
+
+	sub, err := d.ps.Subscribe(ctx, t.name)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	go d.readTopic(ctx, t, sub)
+
+	d.topics[ns] = t
+
+	return t, nil
+}
+
+// cleanTopics unsubscribes from the topics which haven't been advertised nor
+// looked up recently
+func (d *pubsubDiscovery) cleanTopics() {
+	for ns, t := range d.topics {
+		t.mux.Lock()
+		stale := t.advertiseCancel == nil && len(t.subs) == 0 && time.Since(t.lastLookup) > pubsubLookupTTL
+		t.mux.Unlock()
+
+		if stale {
+			t.cancel()
+			delete(d.topics, ns)
+		}
+	}
+}
+
+func (d *pubsubDiscovery) readTopic(ctx context.Context, t *pubsubTopic, sub ipfs_interface.PubSubSubscription) {
+	defer sub.Close()
+
+	for {
+		msg, err := sub.Next(ctx)
+		if err != nil {
+			if ctx.Err() == nil {
+				d.logger.Warn("unable to read pubsub topic", zap.String("topic", t.name), zap.Error(err))
+			}
+
+			return
+		}
+
+		if msg.From() == d.host.ID() {
+			continue
+		}
+
+		rec, err := d.openRecord(t, msg.From(), msg.Data())
+		if err != nil {
+			d.logger.Debug("invalid presence record", zap.String("peer", msg.From().String()), zap.Error(err))
+			continue
+		}
+
+		if rec.Query {
+			d.answerQuery(t)
+			continue
+		}
+
+		d.handlePresence(t, rec)
+	}
+}
+
+// openRecord checks the signature and the validity of a presence record, it
+// must have been published by the peer it describes so other peers can't
+// replay it until it expires
+func (d *pubsubDiscovery) openRecord(t *pubsubTopic, from peer.ID, data []byte) (*PresenceRecord, error) {
+	signed := &SignedPresenceRecord{}
+	if err := signed.Unmarshal(data); err != nil {
+		return nil, err
+	}
+
+	rec := &PresenceRecord{}
+	if err := rec.Unmarshal(signed.Record); err != nil {
+		return nil, err
+	}
+
+	id, err := peer.IDFromBytes(rec.PeerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if id != from {
+		return nil, fmt.Errorf("record published by another peer")
+	}
+
+	pk, err := id.ExtractPublicKey()
+	if err != nil {
+		if pk = d.host.Peerstore().PubKey(id); pk == nil {
+			return nil, fmt.Errorf("unable to get public key of peer %s", id)
+		}
+	}
+
+	if ok, err := pk.Verify(signed.Record, signed.Signature); err != nil || !ok {
+		return nil, fmt.Errorf("invalid signature")
+	}
+
+	if rec.Topic != t.name {
+		return nil, fmt.Errorf("record sent for another topic")
+	}
+
+	if rec.Expire < time.Now().Unix() {
+		return nil, fmt.Errorf("record expired")
+	}
+
+	return rec, nil
+}
+
+func (d *pubsubDiscovery) handlePresence(t *pubsubTopic, rec *PresenceRecord) {
+	id, err := peer.IDFromBytes(rec.PeerID)
+	if err != nil {
+		return
+	}
+
+	info := peer.AddrInfo{ID: id}
+	for _, addrBytes := range rec.Addrs {
+		addr, err := ma.NewMultiaddrBytes(addrBytes)
+		if err != nil {
+			continue
+		}
+
+		info.Addrs = append(info.Addrs, addr)
+	}
+
+	t.mux.Lock()
+	defer t.mux.Unlock()
+
+	t.peers[id] = &pRecord{peer: info, expire: rec.Expire}
+
+	for sub := range t.subs {
+		select {
+		case sub <- info:
+		default:
+		}
+	}
+}
+
+// answerQuery publishes the presence of the peer if it advertises the topic
+func (d *pubsubDiscovery) answerQuery(t *pubsubTopic) {
+	t.mux.Lock()
+	advertised := t.advertiseCancel != nil
+	ttl := t.advertiseTTL
+	recent := time.Since(t.lastPublish) < pubsubAnswerInterval
+	t.mux.Unlock()
+
+	if !advertised || recent {
+		return
+	}
+
+	if err := d.publish(d.ctx, t, ttl, false); err != nil {
+		d.logger.Warn("unable to answer pubsub query", zap.String("topic", t.name), zap.Error(err))
+	}
+}
+
+// publish sends a signed presence record or query on the topic
+func (d *pubsubDiscovery) publish(ctx context.Context, t *pubsubTopic, ttl time.Duration, query bool) error {
+	sk := d.host.Peerstore().PrivKey(d.host.ID())
+	if sk == nil {
+		return fmt.Errorf("unable to get private key of host")
+	}
+
+	rec := &PresenceRecord{
+		PeerID: []byte(d.host.ID()),
+		Expire: time.Now().Add(ttl).Unix(),
+		Topic:  t.name,
+		Query:  query,
+	}
+
+	if !query {
+		for _, addr := range d.host.Peerstore().PeerInfo(d.host.ID()).Addrs {
+			rec.Addrs = append(rec.Addrs, addr.Bytes())
+		}
+	}
+
+	recBytes, err := rec.Marshal()
+	if err != nil {
+		return err
+	}
+
+	sig, err := sk.Sign(recBytes)
+	if err != nil {
+		return err
+	}
+
+	data, err := (&SignedPresenceRecord{Record: recBytes, Signature: sig}).Marshal()
+	if err != nil {
+		return err
+	}
+
+	if !query {
+		t.mux.Lock()
+		t.lastPublish = time.Now()
+		t.mux.Unlock()
+	}
+
+	return d.ps.Publish(ctx, t.name, data)
+}
+
+func (d *pubsubDiscovery) Advertise(ctx context.Context, ns string, opts ...p2p_discovery.Option) (time.Duration, error) {
+	// Get options
+	var options p2p_discovery.Options
+	err := options.Apply(opts...)
+	if err != nil {
+		return 0, err
+	}
+
+	ttl := options.Ttl
+	if ttl == 0 {
+		ttl = 2 * time.Hour
+	}
+
+	t, err := d.getTopic(ns)
+	if err != nil {
+		return 0, err
+	}
+
+	t.mux.Lock()
+	if t.advertiseCancel != nil {
+		t.advertiseCancel()
+	}
+
+	advertiseCtx, cancel := context.WithCancel(d.ctx)
+	t.advertiseCancel = cancel
+	t.advertiseTTL = ttl
+	t.mux.Unlock()
+
+	if err := d.publish(ctx, t, ttl, false); err != nil {
+		return 0, err
+	}
+
+	// Publish the presence periodically until unregistered, so peers joining
+	// the mesh find it without sending a query
+	go func() {
+		ticker := time.NewTicker(pubsubAdvertiseInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := d.publish(advertiseCtx, t, ttl, false); err != nil && advertiseCtx.Err() == nil {
+					d.logger.Warn("unable to publish presence", zap.String("topic", t.name), zap.Error(err))
+				}
+			case <-advertiseCtx.Done():
+				return
+			}
+		}
+	}()
+
+	return ttl, nil
+}
+
+// FindPeers returns the peers found on the topic of the namespace, along with
+// the peers answering the query sent on it
+func (d *pubsubDiscovery) FindPeers(ctx context.Context, ns string, opts ...p2p_discovery.Option) (<-chan peer.AddrInfo, error) {
+	// Get options
+	var options p2p_discovery.Options
+	err := options.Apply(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	const maxLimit = 1000
+	limit := options.Limit
+	if limit == 0 || limit > maxLimit {
+		limit = maxLimit
+	}
+
+	t, err := d.getTopic(ns)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := make(chan peer.AddrInfo, limit)
+
+	t.mux.Lock()
+	t.lastLookup = time.Now()
+
+	currentTime := time.Now().Unix()
+	for p, rec := range t.peers {
+		if rec.expire < currentTime {
+			delete(t.peers, p)
+			continue
+		}
+
+		if len(sub) < limit {
+			sub <- rec.peer
+		}
+	}
+
+	t.subs[sub] = struct{}{}
+	t.mux.Unlock()
+
+	if err := d.publish(ctx, t, pubsubLookupTimeout, true); err != nil {
+		d.logger.Warn("unable to send pubsub query", zap.String("topic", t.name), zap.Error(err))
+	}
+
+	cpeers := make(chan peer.AddrInfo, limit)
+	go func() {
+		defer close(cpeers)
+		defer func() {
+			t.mux.Lock()
+			delete(t.subs, sub)
+			t.mux.Unlock()
+		}()
+
+		timer := time.NewTimer(pubsubLookupTimeout)
+		defer timer.Stop()
+
+		for sent := 0; sent < limit; sent++ {
+			select {
+			case p := <-sub:
+				cpeers <- p
+			case <-timer.C:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return cpeers, nil
+}
+
+// Unregister stops publishing the presence of the peer on the topic of the
+// namespace
+func (d *pubsubDiscovery) Unregister(ctx context.Context, ns string) error {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+
+	t, ok := d.topics[ns]
+	if !ok {
+		return nil
+	}
+
+	t.mux.Lock()
+	if t.advertiseCancel != nil {
+		t.advertiseCancel()
+		t.advertiseCancel = nil
+	}
+	t.mux.Unlock()
+
+	d.cleanTopics()
+
+	return nil
+}
+
+func (*pubsubDiscovery) Name() string { return "pubsub" }
@@ -0,0 +1,50 @@
+package tinder
+
+import (
+	crand "crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPubSubOpenRecord(t *testing.T) {
+	sk, _, err := crypto.GenerateEd25519Key(crand.Reader)
+	require.NoError(t, err)
+
+	id, err := peer.IDFromPrivateKey(sk)
+	require.NoError(t, err)
+
+	topic := &pubsubTopic{name: pubsubTopicName("testkey")}
+
+	rec := &PresenceRecord{
+		PeerID: []byte(id),
+		Expire: time.Now().Add(time.Minute).Unix(),
+		Topic:  topic.name,
+	}
+
+	recBytes, err := rec.Marshal()
+	require.NoError(t, err)
+
+	sig, err := sk.Sign(recBytes)
+	require.NoError(t, err)
+
+	data, err := (&SignedPresenceRecord{Record: recBytes, Signature: sig}).Marshal()
+	require.NoError(t, err)
+
+	d := &pubsubDiscovery{}
+
+	opened, err := d.openRecord(topic, id, data)
+	require.NoError(t, err)
+	require.Equal(t, rec.Expire, opened.Expire)
+
+	// records replayed by another peer are refused
+	_, err = d.openRecord(topic, peer.ID("other peer"), data)
+	require.Error(t, err)
+
+	// records are bound to their topic
+	_, err = d.openRecord(&pubsubTopic{name: pubsubTopicName("otherkey")}, id, data)
+	require.Error(t, err)
+}
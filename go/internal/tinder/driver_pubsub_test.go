@@ -0,0 +1,97 @@
+package tinder_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"berty.tech/berty/v2/go/internal/ipfsutil"
+	"berty.tech/berty/v2/go/internal/testutil"
+	"berty.tech/berty/v2/go/internal/tinder"
+	p2p_discovery "github.com/libp2p/go-libp2p-core/discovery"
+	p2p_peer "github.com/libp2p/go-libp2p-core/peer"
+	p2p_mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPubSubDiscovery(t *testing.T) {
+	testutil.SkipSlow(t)
+
+	logger := testutil.Logger(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mn := p2p_mocknet.New(ctx)
+	rdvp, err := mn.GenPeer()
+	require.NoError(t, err)
+
+	_, cleanupRDVP := ipfsutil.TestingRDVP(ctx, t, rdvp)
+	defer cleanupRDVP()
+
+	opts := &ipfsutil.TestingAPIOpts{
+		Logger:  logger,
+		Mocknet: mn,
+		RDVPeer: rdvp.Peerstore().PeerInfo(rdvp.ID()),
+	}
+
+	apiA, cleanupA := ipfsutil.TestingCoreAPIUsingMockNet(ctx, t, opts)
+	defer cleanupA()
+
+	apiB, cleanupB := ipfsutil.TestingCoreAPIUsingMockNet(ctx, t, opts)
+	defer cleanupB()
+
+	require.NoError(t, mn.LinkAll())
+	require.NoError(t, mn.ConnectAllButSelf())
+
+	hostA, hostB := apiA.MockNode().PeerHost, apiB.MockNode().PeerHost
+	dA := tinder.NewMultiDriver(logger, tinder.NewPubSubDiscovery(ctx, logger, hostA, apiA.PubSub()))
+	dB := tinder.NewMultiDriver(logger, tinder.NewPubSubDiscovery(ctx, logger, hostB, apiB.PubSub()))
+
+	const testKey = "testkey"
+
+	// Subscribe on both sides first, so the pubsub mesh is formed before the
+	// presence record is published
+	ps, err := dB.FindPeers(ctx, testKey)
+	require.NoError(t, err)
+
+	for range ps {
+	}
+
+	_, err = dA.Advertise(ctx, testKey, p2p_discovery.TTL(time.Minute))
+	require.NoError(t, err)
+
+	time.Sleep(time.Second)
+
+	ps, err = dB.FindPeers(ctx, testKey)
+	require.NoError(t, err)
+
+	found := []p2p_peer.ID(nil)
+	for peer := range ps {
+		found = append(found, peer.ID)
+	}
+
+	assert.Contains(t, found, hostA.ID())
+
+	// Peers advertising other namespaces aren't found
+	ps, err = dB.FindPeers(ctx, "otherkey", p2p_discovery.Limit(1))
+	require.NoError(t, err)
+
+	found = nil
+	for peer := range ps {
+		found = append(found, peer.ID)
+	}
+
+	assert.Empty(t, found)
+
+	// Peers aren't found by themselves
+	ps, err = dA.FindPeers(ctx, testKey, p2p_discovery.Limit(1))
+	require.NoError(t, err)
+
+	found = nil
+	for peer := range ps {
+		found = append(found, peer.ID)
+	}
+
+	assert.NotContains(t, found, hostA.ID())
+}
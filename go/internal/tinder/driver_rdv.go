@@ -5,6 +5,7 @@ package tinder
 
 import (
 	"context"
+	"fmt"
 	"math"
 	mrand "math/rand"
 	"sync"
@@ -18,19 +19,36 @@ import (
 	p2p_rp "github.com/libp2p/go-libp2p-rendezvous"
 )
 
+const (
+	// rdvpAdvertiseReplicas is the number of rendezvous points a namespace
+	// is registered on
+	rdvpAdvertiseReplicas = 2
+
+	// rdvpMaxFailures is the number of consecutive failures after which a
+	// rendezvous point is considered unhealthy
+	rdvpMaxFailures = 3
+
+	// rdvpRetryDelay is the time after which an unhealthy rendezvous point
+	// is tried again
+	rdvpRetryDelay = time.Minute
+)
+
 type rendezvousDiscovery struct {
 	logger       *zap.Logger
-	rp           p2p_rp.RendezvousPoint
+	points       []*rdvPoint
 	peerCache    map[string]*rpCache
 	peerCacheMux sync.RWMutex
 	rng          *mrand.Rand
 	rngMux       sync.Mutex
+
+	registered    map[string]map[peer.ID]*rdvPoint
+	registeredMux sync.Mutex
 }
 
 type rpCache struct {
-	recs   map[peer.ID]*rpRecord
-	cookie []byte
-	mux    sync.Mutex
+	recs    map[peer.ID]*rpRecord
+	cookies map[peer.ID][]byte
+	mux     sync.Mutex
 }
 
 type rpRecord struct {
@@ -38,16 +56,95 @@ type rpRecord struct {
 	expire int64
 }
 
-func NewRendezvousDiscovery(logger *zap.Logger, host host.Host, rdvPeer peer.ID, rng *mrand.Rand) Driver {
-	rp := p2p_rp.NewRendezvousPoint(host, rdvPeer)
+// rdvPoint tracks the health of a rendezvous point, unhealthy points are
+// skipped until rdvpRetryDelay is elapsed
+type rdvPoint struct {
+	id peer.ID
+	rp p2p_rp.RendezvousPoint
+
+	failures  int
+	lastError error
+	retryAt   time.Time
+	mux       sync.Mutex
+}
+
+func (p *rdvPoint) healthy() bool {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	return p.failures < rdvpMaxFailures || time.Now().After(p.retryAt)
+}
+
+// done records the result of a request sent to the rendezvous point, requests
+// interrupted by ctx aren't taken into account
+func (p *rdvPoint) done(ctx context.Context, logger *zap.Logger, err error) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	p.mux.Lock()
+	defer p.mux.Unlock()
+
+	if err == nil {
+		if p.failures >= rdvpMaxFailures {
+			logger.Info("rendezvous point is healthy again", zap.String("peer", p.id.String()))
+		}
+
+		p.failures = 0
+		p.lastError = nil
+		return
+	}
+
+	p.failures++
+	p.lastError = err
+
+	if p.failures >= rdvpMaxFailures {
+		if p.failures == rdvpMaxFailures {
+			logger.Warn("rendezvous point is unhealthy", zap.String("peer", p.id.String()), zap.Error(err))
+		}
+
+		p.retryAt = time.Now().Add(rdvpRetryDelay)
+	}
+}
+
+// NewRendezvousDiscovery returns a driver using the given rendezvous points,
+// namespaces are registered on several of them and looked up on all the
+// healthy ones
+func NewRendezvousDiscovery(logger *zap.Logger, host host.Host, rdvPeers []peer.ID, rng *mrand.Rand) Driver {
+	points := make([]*rdvPoint, len(rdvPeers))
+	for i, rdvPeer := range rdvPeers {
+		points[i] = &rdvPoint{
+			id: rdvPeer,
+			rp: p2p_rp.NewRendezvousPoint(host, rdvPeer),
+		}
+	}
+
 	return &rendezvousDiscovery{
-		logger:    logger.Named("tinder/rdvp"),
-		rp:        rp,
-		rng:       rng,
-		peerCache: make(map[string]*rpCache),
+		logger:     logger.Named("tinder/rdvp"),
+		points:     points,
+		rng:        rng,
+		peerCache:  make(map[string]*rpCache),
+		registered: make(map[string]map[peer.ID]*rdvPoint),
 	}
 }
 
+// availablePoints returns the healthy rendezvous points, or all of them if
+// none is healthy
+func (c *rendezvousDiscovery) availablePoints() []*rdvPoint {
+	points := []*rdvPoint(nil)
+	for _, p := range c.points {
+		if p.healthy() {
+			points = append(points, p)
+		}
+	}
+
+	if len(points) == 0 {
+		return c.points
+	}
+
+	return points
+}
+
 func (c *rendezvousDiscovery) Advertise(ctx context.Context, ns string, opts ...discovery.Option) (time.Duration, error) {
 	// Get options
 	var options discovery.Options
@@ -65,11 +162,50 @@ func (c *rendezvousDiscovery) Advertise(ctx context.Context, ns string, opts ...
 		ttlSeconds = int(math.Round(ttl.Seconds()))
 	}
 
-	rttl, err := c.rp.Register(ctx, ns, ttlSeconds)
-	if err != nil {
-		return 0, err
+	var (
+		rttl       time.Duration
+		registered []*rdvPoint
+		lastErr    error
+	)
+
+	for _, p := range c.availablePoints() {
+		if len(registered) == rdvpAdvertiseReplicas {
+			break
+		}
+
+		ttl, err := p.rp.Register(ctx, ns, ttlSeconds)
+		p.done(ctx, c.logger, err)
+		if err != nil {
+			c.logger.Debug("failed to register on rendezvous point", zap.String("peer", p.id.String()), zap.String("key", ns), zap.Error(err))
+			lastErr = err
+			continue
+		}
+
+		if rttl == 0 || ttl < rttl {
+			rttl = ttl
+		}
+
+		registered = append(registered, p)
 	}
 
+	if len(registered) == 0 {
+		if lastErr == nil {
+			lastErr = fmt.Errorf("no rendezvous point available")
+		}
+
+		return 0, lastErr
+	}
+
+	c.registeredMux.Lock()
+	if _, ok := c.registered[ns]; !ok {
+		c.registered[ns] = make(map[peer.ID]*rdvPoint)
+	}
+
+	for _, p := range registered {
+		c.registered[ns][p.id] = p
+	}
+	c.registeredMux.Unlock()
+
 	return rttl, nil
 }
 
@@ -97,7 +233,10 @@ func (c *rendezvousDiscovery) FindPeers(ctx context.Context, ns string, opts ...
 		c.peerCacheMux.Lock()
 		cache, ok = c.peerCache[ns]
 		if !ok {
-			cache = &rpCache{recs: make(map[peer.ID]*rpRecord)}
+			cache = &rpCache{
+				recs:    make(map[peer.ID]*rpRecord),
+				cookies: make(map[peer.ID][]byte),
+			}
 			c.peerCache[ns] = cache
 		}
 		c.peerCacheMux.Unlock()
@@ -118,20 +257,10 @@ func (c *rendezvousDiscovery) FindPeers(ctx context.Context, ns string, opts ...
 		}
 	}
 
-	cookie := cache.cookie
-
 	// Discover new records if we don't have enough
 	if newCacheSize < limit {
 		// TODO: Should we return error even if we have valid cached results?
-		var regs []p2p_rp.Registration
-		var newCookie []byte
-		if regs, newCookie, err = c.rp.Discover(ctx, ns, limit, cookie); err == nil {
-			for _, reg := range regs {
-				rec := &rpRecord{peer: reg.Peer, expire: int64(reg.Ttl) + currentTime}
-				cache.recs[rec.peer.ID] = rec
-			}
-			cache.cookie = newCookie
-		}
+		err = c.discover(ctx, cache, ns, limit, currentTime)
 	}
 
 	// Randomize and fill channel with available records
@@ -171,8 +300,78 @@ func (c *rendezvousDiscovery) FindPeers(ctx context.Context, ns string, opts ...
 	return chPeer, err
 }
 
+// discover queries the rendezvous points in parallel and merges their
+// registrations in cache, an error is returned only if every query failed
+func (c *rendezvousDiscovery) discover(ctx context.Context, cache *rpCache, ns string, limit int, currentTime int64) error {
+	type discoverResult struct {
+		point  *rdvPoint
+		regs   []p2p_rp.Registration
+		cookie []byte
+		err    error
+	}
+
+	points := c.availablePoints()
+	cresults := make(chan *discoverResult, len(points))
+	for _, p := range points {
+		go func(p *rdvPoint, cookie []byte) {
+			regs, cookie, err := p.rp.Discover(ctx, ns, limit, cookie)
+			cresults <- &discoverResult{point: p, regs: regs, cookie: cookie, err: err}
+		}(p, cache.cookies[p.id])
+	}
+
+	var lastErr error
+	succeeded := false
+
+	for range points {
+		res := <-cresults
+		res.point.done(ctx, c.logger, res.err)
+		if res.err != nil {
+			c.logger.Debug("failed to discover on rendezvous point", zap.String("peer", res.point.id.String()), zap.String("key", ns), zap.Error(res.err))
+			lastErr = res.err
+			continue
+		}
+
+		succeeded = true
+
+		// the same peer may be registered on several rendezvous points, keep
+		// the registration expiring last
+		for _, reg := range res.regs {
+			rec := &rpRecord{peer: reg.Peer, expire: int64(reg.Ttl) + currentTime}
+			if prev, ok := cache.recs[rec.peer.ID]; ok && prev.expire > rec.expire {
+				continue
+			}
+
+			cache.recs[rec.peer.ID] = rec
+		}
+
+		cache.cookies[res.point.id] = res.cookie
+	}
+
+	if succeeded {
+		return nil
+	}
+
+	return lastErr
+}
+
+// Unregister removes the namespace from every rendezvous point it has been
+// registered on
 func (c *rendezvousDiscovery) Unregister(ctx context.Context, ns string) error {
-	return c.rp.Unregister(ctx, ns)
+	c.registeredMux.Lock()
+	registered := c.registered[ns]
+	delete(c.registered, ns)
+	c.registeredMux.Unlock()
+
+	var lastErr error
+	for _, p := range registered {
+		err := p.rp.Unregister(ctx, ns)
+		p.done(ctx, c.logger, err)
+		if err != nil {
+			lastErr = err
+		}
+	}
+
+	return lastErr
 }
 
 func (*rendezvousDiscovery) Name() string { return "rdvp" }
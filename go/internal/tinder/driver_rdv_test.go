@@ -0,0 +1,77 @@
+package tinder
+
+import (
+	"context"
+	mrand "math/rand"
+	"testing"
+	"time"
+
+	"berty.tech/berty/v2/go/internal/testutil"
+	p2p_discovery "github.com/libp2p/go-libp2p-core/discovery"
+	p2p_peer "github.com/libp2p/go-libp2p-core/peer"
+	p2p_rp "github.com/libp2p/go-libp2p-rendezvous"
+	p2p_rpdb "github.com/libp2p/go-libp2p-rendezvous/db/sqlite"
+	p2p_mock "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRendezvousDiscovery_Failover(t *testing.T) {
+	logger := testutil.Logger(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mn := p2p_mock.New(ctx)
+	peers := testingPeers(t, mn, 5)
+	clientA, clientB, rdvps := peers[0], peers[1], peers[2:]
+
+	// the first rendezvous point doesn't run the rendezvous service
+	rdvpIDs := make([]p2p_peer.ID, len(rdvps))
+	for i, rdvp := range rdvps {
+		rdvpIDs[i] = rdvp.ID()
+
+		if i == 0 {
+			continue
+		}
+
+		db, err := p2p_rpdb.OpenDB(ctx, ":memory:")
+		require.NoError(t, err)
+		defer db.Close()
+
+		p2p_rp.NewRendezvousService(rdvp, db)
+	}
+
+	require.NoError(t, mn.ConnectAllButSelf())
+
+	dA := NewRendezvousDiscovery(logger, clientA, rdvpIDs, mrand.New(mrand.NewSource(1)))
+	dB := NewRendezvousDiscovery(logger, clientB, rdvpIDs, mrand.New(mrand.NewSource(2)))
+
+	const testKey = "testkey"
+
+	// the namespace is registered on the healthy rendezvous points
+	_, err := dA.Advertise(ctx, testKey, p2p_discovery.TTL(time.Minute))
+	require.NoError(t, err)
+
+	ps, err := dB.FindPeers(ctx, testKey)
+	require.NoError(t, err)
+
+	found := []p2p_peer.ID(nil)
+	for peer := range ps {
+		found = append(found, peer.ID)
+	}
+
+	// peers registered on several rendezvous points are found once
+	assert.Equal(t, []p2p_peer.ID{clientA.ID()}, found)
+
+	// the unreachable rendezvous point is skipped once unhealthy
+	for i := 0; i < rdvpMaxFailures; i++ {
+		_, err = dB.FindPeers(ctx, "otherkey")
+		require.NoError(t, err)
+	}
+
+	for _, p := range dB.(*rendezvousDiscovery).availablePoints() {
+		assert.NotEqual(t, rdvps[0].ID(), p.id)
+	}
+
+	require.NoError(t, dA.Unregister(ctx, testKey))
+}
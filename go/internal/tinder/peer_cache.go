@@ -0,0 +1,203 @@
+package tinder
+
+import (
+	"context"
+	"encoding/base64"
+	"time"
+
+	datastore "github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	p2p_discovery "github.com/libp2p/go-libp2p-core/discovery"
+	p2p_peer "github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
+	"go.uber.org/zap"
+)
+
+// DefaultPeerCacheTTL is the time during which a peer found for a namespace
+// is kept in the peer cache unless configured otherwise
+const DefaultPeerCacheTTL = 24 * time.Hour
+
+// PeerCache persists the peers found for each namespace in a datastore, so
+// they can be reached again right after a restart
+type PeerCache struct {
+	ds  datastore.Batching
+	ttl time.Duration
+}
+
+// NewPeerCache returns a PeerCache storing peers in ds for ttl
+func NewPeerCache(ds datastore.Batching, ttl time.Duration) *PeerCache {
+	if ttl <= 0 {
+		ttl = DefaultPeerCacheTTL
+	}
+
+	return &PeerCache{
+		ds:  ds,
+		ttl: ttl,
+	}
+}
+
+func peerCacheNamespaceKey(ns string) datastore.Key {
+	return datastore.KeyWithNamespaces([]string{"peers", base64.RawURLEncoding.EncodeToString([]byte(ns))})
+}
+
+func peerCacheKey(ns string, p p2p_peer.ID) datastore.Key {
+	return peerCacheNamespaceKey(ns).ChildString(p.Pretty())
+}
+
+// Put stores or refreshes a peer found for ns, peers without addresses are
+// ignored
+func (c *PeerCache) Put(ns string, info p2p_peer.AddrInfo) error {
+	if len(info.Addrs) == 0 {
+		return nil
+	}
+
+	rec := &CachedPeer{
+		Addrs:  make([][]byte, len(info.Addrs)),
+		Expire: time.Now().Add(c.ttl).Unix(),
+	}
+
+	for i, addr := range info.Addrs {
+		rec.Addrs[i] = addr.Bytes()
+	}
+
+	value, err := rec.Marshal()
+	if err != nil {
+		return err
+	}
+
+	return c.ds.Put(peerCacheKey(ns, info.ID), value)
+}
+
+// Get returns up to limit peers found for ns which haven't expired yet, a
+// limit of 0 returns every peer, expired peers are deleted
+func (c *PeerCache) Get(ns string, limit int) ([]p2p_peer.AddrInfo, error) {
+	res, err := c.ds.Query(query.Query{Prefix: peerCacheNamespaceKey(ns).String()})
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := res.Rest()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	peers := []p2p_peer.AddrInfo(nil)
+
+	for _, e := range entries {
+		key := datastore.NewKey(e.Key)
+
+		rec := &CachedPeer{}
+		if err := rec.Unmarshal(e.Value); err != nil || rec.Expire < now {
+			if err := c.ds.Delete(key); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		if limit > 0 && len(peers) >= limit {
+			continue
+		}
+
+		id, err := p2p_peer.Decode(key.BaseNamespace())
+		if err != nil {
+			continue
+		}
+
+		info := p2p_peer.AddrInfo{ID: id}
+		for _, addrBytes := range rec.Addrs {
+			addr, err := ma.NewMultiaddrBytes(addrBytes)
+			if err != nil {
+				continue
+			}
+
+			info.Addrs = append(info.Addrs, addr)
+		}
+
+		peers = append(peers, info)
+	}
+
+	return peers, nil
+}
+
+// peerCacheDriver is a Driver
+var _ Driver = (*peerCacheDriver)(nil)
+
+type peerCacheDriver struct {
+	Driver
+
+	logger *zap.Logger
+	cache  *PeerCache
+}
+
+// NewPeerCacheDriver wraps a driver so the peers it finds are stored in
+// cache, FindPeers returns the cached peers first
+func NewPeerCacheDriver(logger *zap.Logger, driver Driver, cache *PeerCache) Driver {
+	return &peerCacheDriver{
+		Driver: driver,
+		logger: logger.Named("tinder/cache"),
+		cache:  cache,
+	}
+}
+
+func (d *peerCacheDriver) FindPeers(ctx context.Context, ns string, opts ...p2p_discovery.Option) (<-chan p2p_peer.AddrInfo, error) {
+	// Get options
+	var options p2p_discovery.Options
+	err := options.Apply(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	cached, err := d.cache.Get(ns, options.Limit)
+	if err != nil {
+		d.logger.Warn("unable to get cached peers", zap.String("key", ns), zap.Error(err))
+	}
+
+	ch, err := d.Driver.FindPeers(ctx, ns, opts...)
+	if err != nil {
+		if len(cached) == 0 {
+			return nil, err
+		}
+
+		d.logger.Warn("failed to run find peers, only cached peers are returned", zap.String("key", ns), zap.Error(err))
+	}
+
+	d.logger.Debug("found cached peers", zap.String("key", ns), zap.Int("count", len(cached)))
+
+	cpeers := make(chan p2p_peer.AddrInfo, len(cached))
+	for _, peer := range cached {
+		cpeers <- peer
+	}
+
+	if ch == nil {
+		close(cpeers)
+		return cpeers, nil
+	}
+
+	go func() {
+		defer close(cpeers)
+
+		for peer := range ch {
+			if err := d.cache.Put(ns, peer); err != nil {
+				d.logger.Warn("unable to cache peer", zap.String("key", ns), zap.Error(err))
+			}
+
+			select {
+			case cpeers <- peer:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return cpeers, nil
+}
+
+func (d *peerCacheDriver) Stats() []*DriverStats {
+	if sp, ok := d.Driver.(StatsProvider); ok {
+		return sp.Stats()
+	}
+
+	return nil
+}
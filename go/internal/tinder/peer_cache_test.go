@@ -0,0 +1,112 @@
+package tinder
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"berty.tech/berty/v2/go/internal/testutil"
+	datastore "github.com/ipfs/go-datastore"
+	ds_sync "github.com/ipfs/go-datastore/sync"
+	p2p_discovery "github.com/libp2p/go-libp2p-core/discovery"
+	p2p_host "github.com/libp2p/go-libp2p-core/host"
+	p2p_peer "github.com/libp2p/go-libp2p-core/peer"
+	p2p_mock "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeerCache(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mn := p2p_mock.New(ctx)
+	peers := testingPeers(t, mn, 3)
+
+	ds := ds_sync.MutexWrap(datastore.NewMapDatastore())
+	cache := NewPeerCache(ds, time.Minute)
+
+	const testKey = "test/key"
+
+	for _, peer := range peers[:2] {
+		require.NoError(t, cache.Put(testKey, *p2p_host.InfoFromHost(peer)))
+	}
+
+	// Expired peers are deleted
+	expired := &CachedPeer{Expire: time.Now().Add(-time.Minute).Unix()}
+	for _, addr := range peers[2].Addrs() {
+		expired.Addrs = append(expired.Addrs, addr.Bytes())
+	}
+
+	value, err := expired.Marshal()
+	require.NoError(t, err)
+	require.NoError(t, ds.Put(peerCacheKey(testKey, peers[2].ID()), value))
+
+	cached, err := cache.Get(testKey, 0)
+	require.NoError(t, err)
+	require.Len(t, cached, 2)
+
+	for _, peer := range peers[:2] {
+		assert.Contains(t, cached, *p2p_host.InfoFromHost(peer))
+	}
+
+	_, err = ds.Get(peerCacheKey(testKey, peers[2].ID()))
+	assert.Equal(t, datastore.ErrNotFound, err)
+
+	cached, err = cache.Get(testKey, 1)
+	require.NoError(t, err)
+	require.Len(t, cached, 1)
+
+	cached, err = cache.Get("other", 0)
+	require.NoError(t, err)
+	require.Len(t, cached, 0)
+
+	// Peers are kept across instances sharing the same datastore
+	cached, err = NewPeerCache(ds, time.Minute).Get(testKey, 0)
+	require.NoError(t, err)
+	require.Len(t, cached, 2)
+}
+
+func TestPeerCacheDriver(t *testing.T) {
+	logger := testutil.Logger(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ms := NewMockedDriverServer()
+	mn := p2p_mock.New(ctx)
+
+	peers := testingPeers(t, mn, 2)
+	drivers := testingMockedDriverClients(t, ms, peers...)
+
+	ds := ds_sync.MutexWrap(datastore.NewMapDatastore())
+	d := NewPeerCacheDriver(logger, NewMultiDriver(logger, drivers...), NewPeerCache(ds, time.Minute))
+
+	const testKey = "testkey"
+	_, err := d.Advertise(ctx, testKey, p2p_discovery.TTL(time.Minute))
+	require.NoError(t, err)
+
+	time.Sleep(time.Millisecond * 100)
+
+	ps, err := d.FindPeers(ctx, testKey)
+	require.NoError(t, err)
+
+	for range ps {
+	}
+
+	// Peers are still found by a new driver once they aren't advertised anymore
+	ms.Reset()
+
+	d = NewPeerCacheDriver(logger, NewMultiDriver(logger, drivers...), NewPeerCache(ds, time.Minute))
+
+	ps, err = d.FindPeers(ctx, testKey)
+	require.NoError(t, err)
+
+	found := []p2p_peer.AddrInfo(nil)
+	for peer := range ps {
+		found = append(found, peer)
+	}
+
+	for _, peer := range peers {
+		assert.Contains(t, found, *p2p_host.InfoFromHost(peer))
+	}
+}
@@ -12,6 +12,7 @@ type Routing interface {
 	p2p_routing.Routing
 
 	Driver
+	StatsProvider
 }
 
 type routing struct {
@@ -28,10 +29,24 @@ func (r *routing) Bootstrap(ctx context.Context) error {
 	return r.bootstrap(ctx)
 }
 
-func NewRouting(logger *zap.Logger, name string, r p2p_routing.Routing, drivers ...Driver) Routing {
+func (r *routing) Stats() []*DriverStats {
+	if sp, ok := r.Driver.(StatsProvider); ok {
+		return sp.Stats()
+	}
+
+	return nil
+}
+
+// NewRouting returns a Routing dispatching discovery requests across the
+// drivers and r according to policies, peers found are persisted in cache if
+// not nil
+func NewRouting(logger *zap.Logger, name string, r p2p_routing.Routing, cache *PeerCache, policies map[string]*DriverPolicy, drivers ...Driver) Routing {
 	rdisc := discovery.NewRoutingDiscovery(r)
 	drivers = append(drivers, ComposeDriver(name, rdisc, rdisc, nil))
-	md := NewMultiDriver(logger, drivers...)
+	md := NewMultiDriverWithPolicies(logger, policies, drivers...)
+	if cache != nil {
+		md = NewPeerCacheDriver(logger, md, cache)
+	}
 
 	cr := discovery.NewDiscoveryRouting(md)
 	return &routing{
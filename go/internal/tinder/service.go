@@ -10,9 +10,40 @@ type Service interface {
 	Driver
 }
 
-func NewService(logger *zap.Logger, drivers []Driver, stratFactory p2p_discovery.BackoffFactory, opts ...p2p_discovery.BackoffDiscoveryOption) (Service, error) {
-	mdriver := NewMultiDriver(logger, drivers...)
-	disc, err := p2p_discovery.NewBackoffDiscovery(mdriver, stratFactory, opts...)
+type serviceOptions struct {
+	backoffOpts []p2p_discovery.BackoffDiscoveryOption
+	policies    map[string]*DriverPolicy
+}
+
+// ServiceOption configures a tinder service
+type ServiceOption func(opts *serviceOptions) error
+
+// BackoffOptions passes opts to the backoff cache of the service
+func BackoffOptions(opts ...p2p_discovery.BackoffDiscoveryOption) ServiceOption {
+	return func(o *serviceOptions) error {
+		o.backoffOpts = append(o.backoffOpts, opts...)
+		return nil
+	}
+}
+
+// WithDriverPolicy sets the policy of the drivers named name
+func WithDriverPolicy(name string, policy DriverPolicy) ServiceOption {
+	return func(o *serviceOptions) error {
+		o.policies[name] = &policy
+		return nil
+	}
+}
+
+func NewService(logger *zap.Logger, drivers []Driver, stratFactory p2p_discovery.BackoffFactory, opts ...ServiceOption) (Service, error) {
+	options := serviceOptions{policies: make(map[string]*DriverPolicy)}
+	for _, opt := range opts {
+		if err := opt(&options); err != nil {
+			return nil, err
+		}
+	}
+
+	mdriver := NewMultiDriverWithPolicies(logger, options.policies, drivers...)
+	disc, err := p2p_discovery.NewBackoffDiscovery(mdriver, stratFactory, options.backoffOpts...)
 	if err != nil {
 		return nil, err
 	}
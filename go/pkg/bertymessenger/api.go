@@ -15,6 +15,9 @@ import (
 	"berty.tech/berty/v2/go/pkg/bertytypes"
 	"berty.tech/berty/v2/go/pkg/errcode"
 	"github.com/gogo/protobuf/proto"
+	ma "github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr-net"
+	"go.uber.org/zap"
 )
 
 func (s *service) DevShareInstanceBertyID(ctx context.Context, req *DevShareInstanceBertyID_Request) (*DevShareInstanceBertyID_Reply, error) {
@@ -37,28 +40,62 @@ func (s *service) InstanceShareableBertyID(ctx context.Context, req *InstanceSha
 	if req == nil {
 		req = &InstanceShareableBertyID_Request{}
 	}
+
+	bertyID, _, err := s.getOwnBertyID(ctx, req.DisplayName, req.Reset_, req.IncludeAddrs)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := InstanceShareableBertyID_Reply{
+		BertyID: bertyID,
+	}
+	bertyIDPayloadBytes, _ := proto.Marshal(ret.BertyID)
+	ret.BertyIDPayload = base64.StdEncoding.EncodeToString(bertyIDPayloadBytes)
+
+	// create QRCodes with standalone display_name variable
+	lightID := BertyID{
+		PublicRendezvousSeed: ret.BertyID.PublicRendezvousSeed,
+		AccountPK:            ret.BertyID.AccountPK,
+		Addrs:                ret.BertyID.Addrs,
+	}
+	lightIDBytes, _ := proto.Marshal(&lightID)
+	lightIDPayload := base64.StdEncoding.EncodeToString(lightIDBytes)
+	v := url.Values{}
+	v.Set("key", url.QueryEscape(lightIDPayload)) // double-encoding to keep "+" as "+" and not as spaces
+	if bertyID.DisplayName != "" {
+		v.Set("name", bertyID.DisplayName)
+	}
+	fragment := v.Encode()
+	ret.DeepLink = fmt.Sprintf("berty://id/#%s", fragment)
+	ret.HTMLURL = fmt.Sprintf("https://berty.tech/id#%s", fragment)
+	return &ret, nil
+}
+
+// getOwnBertyID enables contact requests if needed and returns the current
+// Berty ID along with the instance configuration
+func (s *service) getOwnBertyID(ctx context.Context, displayName string, reset bool, includeAddrs bool) (*BertyID, *bertytypes.InstanceGetConfiguration_Reply, error) {
 	config, err := s.protocol.InstanceGetConfiguration(ctx, &bertytypes.InstanceGetConfiguration_Request{})
 	if err != nil {
-		return nil, errcode.TODO.Wrap(err)
+		return nil, nil, errcode.TODO.Wrap(err)
 	}
 
 	s.logger.Debug("enable contact request (may be already done)")
 	_, err = s.protocol.ContactRequestEnable(ctx, &bertytypes.ContactRequestEnable_Request{})
 	if err != nil {
-		return nil, errcode.TODO.Wrap(err)
+		return nil, nil, errcode.TODO.Wrap(err)
 	}
 
-	if req.Reset_ {
+	if reset {
 		s.logger.Info("reset contact reference")
 		_, err = s.protocol.ContactRequestResetReference(ctx, &bertytypes.ContactRequestResetReference_Request{})
 		if err != nil {
-			return nil, errcode.TODO.Wrap(err)
+			return nil, nil, errcode.TODO.Wrap(err)
 		}
 	}
 
 	res, err := s.protocol.ContactRequestReference(ctx, &bertytypes.ContactRequestReference_Request{})
 	if err != nil {
-		return nil, errcode.TODO.Wrap(err)
+		return nil, nil, errcode.TODO.Wrap(err)
 	}
 
 	// if this call does not return a PublicRendezvousSeed, then we need to call Reset
@@ -66,46 +103,30 @@ func (s *service) InstanceShareableBertyID(ctx context.Context, req *InstanceSha
 		s.logger.Info("reset contact reference")
 		_, err = s.protocol.ContactRequestResetReference(ctx, &bertytypes.ContactRequestResetReference_Request{})
 		if err != nil {
-			return nil, errcode.TODO.Wrap(err)
+			return nil, nil, errcode.TODO.Wrap(err)
 		}
 	}
 	res, err = s.protocol.ContactRequestReference(ctx, &bertytypes.ContactRequestReference_Request{})
 	if err != nil {
-		return nil, errcode.TODO.Wrap(err)
+		return nil, nil, errcode.TODO.Wrap(err)
 	}
 
-	displayName := req.DisplayName
 	if displayName == "" {
 		// FIXME: get it from somewhere
 		displayName = "anonymous#1337"
 	}
 
-	ret := InstanceShareableBertyID_Reply{
-		BertyID: &BertyID{
-			DisplayName:          displayName,
-			PublicRendezvousSeed: res.PublicRendezvousSeed,
-			AccountPK:            config.AccountPK,
-		},
+	var addrs []string
+	if includeAddrs {
+		addrs = dialableAddrs(s.logger, config)
 	}
-	bertyIDPayloadBytes, _ := proto.Marshal(ret.BertyID)
-	ret.BertyIDPayload = base64.StdEncoding.EncodeToString(bertyIDPayloadBytes)
 
-	// create QRCodes with standalone display_name variable
-	lightID := BertyID{
-		PublicRendezvousSeed: ret.BertyID.PublicRendezvousSeed,
-		AccountPK:            ret.BertyID.AccountPK,
-	}
-	lightIDBytes, _ := proto.Marshal(&lightID)
-	lightIDPayload := base64.StdEncoding.EncodeToString(lightIDBytes)
-	v := url.Values{}
-	v.Set("key", url.QueryEscape(lightIDPayload)) // double-encoding to keep "+" as "+" and not as spaces
-	if displayName != "" {
-		v.Set("name", displayName)
-	}
-	fragment := v.Encode()
-	ret.DeepLink = fmt.Sprintf("berty://id/#%s", fragment)
-	ret.HTMLURL = fmt.Sprintf("https://berty.tech/id#%s", fragment)
-	return &ret, nil
+	return &BertyID{
+		DisplayName:          displayName,
+		PublicRendezvousSeed: res.PublicRendezvousSeed,
+		AccountPK:            config.AccountPK,
+		Addrs:                addrs,
+	}, config, nil
 }
 
 func (s *service) ParseDeepLink(ctx context.Context, req *ParseDeepLink_Request) (*ParseDeepLink_Reply, error) {
@@ -146,6 +167,9 @@ func (s *service) ParseDeepLink(ctx context.Context, req *ParseDeepLink_Request)
 	case "/group":
 		return ParseGroupInviteURLQuery(query)
 
+	case "/scan":
+		return ParseMutualScanURLQuery(query)
+
 	default:
 		return nil, errcode.ErrMessengerInvalidDeepLink
 	}
@@ -276,6 +300,7 @@ func (s *service) SendContactRequest(ctx context.Context, req *SendContactReques
 			PK:                   req.BertyID.AccountPK,
 			PublicRendezvousSeed: req.BertyID.PublicRendezvousSeed,
 			Metadata:             req.Metadata,
+			Addrs:                req.BertyID.Addrs,
 		},
 		OwnMetadata: req.OwnMetadata,
 	}
@@ -356,3 +381,24 @@ func (s *service) SendMessage(ctx context.Context, request *SendMessage_Request)
 
 	return nil, err
 }
+
+// dialableAddrs returns the addresses of the host which can be dialed by
+// other peers, unspecified and loopback addresses are filtered out
+func dialableAddrs(logger *zap.Logger, config *bertytypes.InstanceGetConfiguration_Reply) []string {
+	var addrs []string
+	for _, addr := range config.LocalAddrs {
+		maddr, err := ma.NewMultiaddr(addr)
+		if err != nil {
+			logger.Warn("unable to parse local address", zap.String("addr", addr), zap.Error(err))
+			continue
+		}
+
+		if manet.IsIPUnspecified(maddr) || manet.IsIPLoopback(maddr) {
+			continue
+		}
+
+		addrs = append(addrs, fmt.Sprintf("%s/p2p/%s", addr, config.PeerID))
+	}
+
+	return addrs
+}
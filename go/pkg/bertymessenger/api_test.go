@@ -49,6 +49,18 @@ func TestServiceInstanceShareableBertyID(t *testing.T) {
 	require.NoError(t, err)
 	testParseInstanceShareable(ctx, t, svc, ret5)
 	assert.Equal(t, ret4, ret5)
+	assert.Empty(t, ret5.BertyID.Addrs)
+
+	ret6, err := svc.InstanceShareableBertyID(ctx, &InstanceShareableBertyID_Request{IncludeAddrs: true})
+	require.NoError(t, err)
+	testParseInstanceShareable(ctx, t, svc, ret6)
+	assert.Equal(t, ret5.BertyID.AccountPK, ret6.BertyID.AccountPK)
+	require.NotEmpty(t, ret6.BertyID.Addrs)
+	for _, addr := range ret6.BertyID.Addrs {
+		assert.Contains(t, addr, "/p2p/")
+		assert.NotContains(t, addr, "/ip4/0.0.0.0/")
+		assert.NotContains(t, addr, "/ip4/127.0.0.1/")
+	}
 }
 
 func testParseInstanceShareable(ctx context.Context, t *testing.T, svc MessengerServiceServer, ret *InstanceShareableBertyID_Reply) {
@@ -70,6 +82,7 @@ func testParseInstanceShareable(ctx context.Context, t *testing.T, svc Messenger
 	assert.Equal(t, parsed1.BertyID.PublicRendezvousSeed, ret.BertyID.PublicRendezvousSeed)
 	assert.Equal(t, parsed1.BertyID.AccountPK, ret.BertyID.AccountPK)
 	assert.Equal(t, parsed1.BertyID.DisplayName, ret.BertyID.DisplayName)
+	assert.Equal(t, parsed1.BertyID.Addrs, ret.BertyID.Addrs)
 }
 
 func TestServiceParseDeepLink(t *testing.T) {
@@ -136,6 +149,60 @@ func TestServiceSendContactRequest(t *testing.T) {
 	assert.NotNil(t, ret)
 }
 
+func TestServiceMutualScan(t *testing.T) {
+	ctx := context.Background()
+	svc, cleanup := TestingService(ctx, t, &TestingServiceOpts{Logger: testutil.Logger(t)})
+	defer cleanup()
+
+	ret, err := svc.InstanceMutualScanCode(ctx, &InstanceMutualScanCode_Request{DisplayName: "Hello World! 👋"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, ret.BertyMutualScanCode.BertyID.AccountPK)
+	assert.NotEmpty(t, ret.BertyMutualScanCode.BertyID.PublicRendezvousSeed)
+	assert.Len(t, ret.BertyMutualScanCode.Secret, mutualScanSecretLength)
+	assert.NotEmpty(t, ret.BertyMutualScanCodePayload)
+
+	// codes are one-time
+	ret2, err := svc.InstanceMutualScanCode(ctx, &InstanceMutualScanCode_Request{DisplayName: "Hello World! 👋"})
+	require.NoError(t, err)
+	assert.NotEqual(t, ret.BertyMutualScanCode.Secret, ret2.BertyMutualScanCode.Secret)
+	assert.Equal(t, ret.BertyMutualScanCode.BertyID.AccountPK, ret2.BertyMutualScanCode.BertyID.AccountPK)
+
+	parsed, err := svc.ParseDeepLink(ctx, &ParseDeepLink_Request{Link: ret.DeepLink})
+	require.NoError(t, err)
+	assert.Equal(t, ParseDeepLink_BertyMutualScanCode, parsed.Kind)
+	assert.Equal(t, ret.BertyMutualScanCode.Secret, parsed.BertyMutualScanCode.Secret)
+	assert.Equal(t, ret.BertyMutualScanCode.BertyID.AccountPK, parsed.BertyMutualScanCode.BertyID.AccountPK)
+	assert.Equal(t, ret.BertyMutualScanCode.BertyID.PublicRendezvousSeed, parsed.BertyMutualScanCode.BertyID.PublicRendezvousSeed)
+	assert.Equal(t, ret.BertyMutualScanCode.BertyID.DisplayName, parsed.BertyMutualScanCode.BertyID.DisplayName)
+
+	_, err = svc.ParseDeepLink(ctx, &ParseDeepLink_Request{Link: "berty://scan/#code=blah"})
+	testSameErrcodes(t, err, errcode.ErrMessengerInvalidDeepLink)
+
+	// proof
+	accountPK := []byte("account pk")
+	metadata, err := proto.Marshal(&MutualScanContactMetadata{Proof: mutualScanProof(ret.BertyMutualScanCode.Secret, accountPK)})
+	require.NoError(t, err)
+	assert.True(t, checkMutualScanProof(ret.BertyMutualScanCode.Secret, accountPK, metadata))
+	assert.False(t, checkMutualScanProof(ret2.BertyMutualScanCode.Secret, accountPK, metadata))
+	assert.False(t, checkMutualScanProof(ret.BertyMutualScanCode.Secret, []byte("other pk"), metadata))
+	assert.False(t, checkMutualScanProof(ret.BertyMutualScanCode.Secret, accountPK, nil))
+
+	_, err = svc.MutualScanComplete(ctx, nil)
+	testSameErrcodes(t, err, errcode.ErrMissingInput)
+
+	// scanning a code from another account
+	parseRet, err := svc.ParseDeepLink(ctx, &ParseDeepLink_Request{Link: "https://berty.tech/id#key%3DCiDSJgvTIhdDfcZUhhZ8iPYvQVzwBBLRtbnlUX7sh5K9MRIg%252BK1qlkoN7RWQVnzmgveRI0HSLiyRFGa3KE9WNYgJmLQ%253D%26name%3Danonymous%25231337"})
+	require.NoError(t, err)
+
+	_, err = svc.MutualScanComplete(ctx, &MutualScanComplete_Request{
+		BertyMutualScanCode: &BertyMutualScanCode{
+			BertyID: parseRet.BertyID,
+			Secret:  ret2.BertyMutualScanCode.Secret,
+		},
+	})
+	require.NoError(t, err)
+}
+
 func TestSystemInfo(t *testing.T) {
 	ctx := context.Background()
 	svc, cleanup := TestingService(ctx, t, &TestingServiceOpts{Logger: testutil.Logger(t)})
@@ -67,21 +67,24 @@ func (AppMessageType) EnumDescriptor() ([]byte, []int) {
 type ParseDeepLink_Kind int32
 
 const (
-	ParseDeepLink_UnknownKind ParseDeepLink_Kind = 0
-	ParseDeepLink_BertyID     ParseDeepLink_Kind = 1
-	ParseDeepLink_BertyGroup  ParseDeepLink_Kind = 2
+	ParseDeepLink_UnknownKind         ParseDeepLink_Kind = 0
+	ParseDeepLink_BertyID             ParseDeepLink_Kind = 1
+	ParseDeepLink_BertyGroup          ParseDeepLink_Kind = 2
+	ParseDeepLink_BertyMutualScanCode ParseDeepLink_Kind = 3
 )
 
 var ParseDeepLink_Kind_name = map[int32]string{
 	0: "UnknownKind",
 	1: "BertyID",
 	2: "BertyGroup",
+	3: "BertyMutualScanCode",
 }
 
 var ParseDeepLink_Kind_value = map[string]int32{
-	"UnknownKind": 0,
-	"BertyID":     1,
-	"BertyGroup":  2,
+	"UnknownKind":         0,
+	"BertyID":             1,
+	"BertyGroup":          2,
+	"BertyMutualScanCode": 3,
 }
 
 func (x ParseDeepLink_Kind) String() string {
@@ -124,8 +127,10 @@ var xxx_messageInfo_InstanceShareableBertyID proto.InternalMessageInfo
 
 type InstanceShareableBertyID_Request struct {
 	// reset will regenerate a new link
-	Reset_               bool     `protobuf:"varint,1,opt,name=reset,proto3" json:"reset,omitempty"`
-	DisplayName          string   `protobuf:"bytes,2,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	Reset_      bool   `protobuf:"varint,1,opt,name=reset,proto3" json:"reset,omitempty"`
+	DisplayName string `protobuf:"bytes,2,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	// include_addrs will embed the current listening addresses in the link, allowing contacts to reach us without rendezvous
+	IncludeAddrs         bool     `protobuf:"varint,3,opt,name=include_addrs,json=includeAddrs,proto3" json:"include_addrs,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -169,6 +174,13 @@ func (m *InstanceShareableBertyID_Request) GetDisplayName() string {
 	return ""
 }
 
+func (m *InstanceShareableBertyID_Request) GetIncludeAddrs() bool {
+	if m != nil {
+		return m.IncludeAddrs
+	}
+	return false
+}
+
 type InstanceShareableBertyID_Reply struct {
 	BertyID              *BertyID `protobuf:"bytes,1,opt,name=berty_id,json=bertyId,proto3" json:"berty_id,omitempty"`
 	BertyIDPayload       string   `protobuf:"bytes,2,opt,name=berty_id_payload,json=bertyIdPayload,proto3" json:"berty_id_payload,omitempty"`
@@ -401,8 +413,10 @@ var xxx_messageInfo_DevShareInstanceBertyID proto.InternalMessageInfo
 
 type DevShareInstanceBertyID_Request struct {
 	// reset will regenerate a new link
-	Reset_               bool     `protobuf:"varint,1,opt,name=reset,proto3" json:"reset,omitempty"`
-	DisplayName          string   `protobuf:"bytes,2,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	Reset_      bool   `protobuf:"varint,1,opt,name=reset,proto3" json:"reset,omitempty"`
+	DisplayName string `protobuf:"bytes,2,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	// include_addrs will embed the current listening addresses in the link, allowing contacts to reach us without rendezvous
+	IncludeAddrs         bool     `protobuf:"varint,3,opt,name=include_addrs,json=includeAddrs,proto3" json:"include_addrs,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -446,6 +460,13 @@ func (m *DevShareInstanceBertyID_Request) GetDisplayName() string {
 	return ""
 }
 
+func (m *DevShareInstanceBertyID_Request) GetIncludeAddrs() bool {
+	if m != nil {
+		return m.IncludeAddrs
+	}
+	return false
+}
+
 type DevShareInstanceBertyID_Reply struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -545,12 +566,13 @@ func (m *ParseDeepLink_Request) GetLink() string {
 }
 
 type ParseDeepLink_Reply struct {
-	Kind                 ParseDeepLink_Kind `protobuf:"varint,1,opt,name=kind,proto3,enum=berty.messenger.ParseDeepLink_Kind" json:"kind,omitempty"`
-	BertyID              *BertyID           `protobuf:"bytes,3,opt,name=berty_id,json=bertyId,proto3" json:"berty_id,omitempty"`
-	BertyGroup           *BertyGroup        `protobuf:"bytes,4,opt,name=berty_group,json=bertyGroup,proto3" json:"berty_group,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}           `json:"-"`
-	XXX_unrecognized     []byte             `json:"-"`
-	XXX_sizecache        int32              `json:"-"`
+	Kind                 ParseDeepLink_Kind   `protobuf:"varint,1,opt,name=kind,proto3,enum=berty.messenger.ParseDeepLink_Kind" json:"kind,omitempty"`
+	BertyID              *BertyID             `protobuf:"bytes,3,opt,name=berty_id,json=bertyId,proto3" json:"berty_id,omitempty"`
+	BertyGroup           *BertyGroup          `protobuf:"bytes,4,opt,name=berty_group,json=bertyGroup,proto3" json:"berty_group,omitempty"`
+	BertyMutualScanCode  *BertyMutualScanCode `protobuf:"bytes,5,opt,name=berty_mutual_scan_code,json=bertyMutualScanCode,proto3" json:"berty_mutual_scan_code,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
 }
 
 func (m *ParseDeepLink_Reply) Reset()         { *m = ParseDeepLink_Reply{} }
@@ -598,6 +620,13 @@ func (m *ParseDeepLink_Reply) GetBertyGroup() *BertyGroup {
 	return nil
 }
 
+func (m *ParseDeepLink_Reply) GetBertyMutualScanCode() *BertyMutualScanCode {
+	if m != nil {
+		return m.BertyMutualScanCode
+	}
+	return nil
+}
+
 type SendContactRequest struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -712,6 +741,251 @@ func (m *SendContactRequest_Reply) XXX_DiscardUnknown() {
 
 var xxx_messageInfo_SendContactRequest_Reply proto.InternalMessageInfo
 
+type InstanceMutualScanCode struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *InstanceMutualScanCode) Reset()         { *m = InstanceMutualScanCode{} }
+func (m *InstanceMutualScanCode) String() string { return proto.CompactTextString(m) }
+func (*InstanceMutualScanCode) ProtoMessage()    {}
+func (*InstanceMutualScanCode) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fd3bf21e238da6aa, []int{5}
+}
+func (m *InstanceMutualScanCode) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_InstanceMutualScanCode.Unmarshal(m, b)
+}
+func (m *InstanceMutualScanCode) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_InstanceMutualScanCode.Marshal(b, m, deterministic)
+}
+func (m *InstanceMutualScanCode) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_InstanceMutualScanCode.Merge(m, src)
+}
+func (m *InstanceMutualScanCode) XXX_Size() int {
+	return xxx_messageInfo_InstanceMutualScanCode.Size(m)
+}
+func (m *InstanceMutualScanCode) XXX_DiscardUnknown() {
+	xxx_messageInfo_InstanceMutualScanCode.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_InstanceMutualScanCode proto.InternalMessageInfo
+
+type InstanceMutualScanCode_Request struct {
+	DisplayName string `protobuf:"bytes,1,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	// include_addrs will embed the current listening addresses in the code, allowing the contact to reach us without rendezvous
+	IncludeAddrs         bool     `protobuf:"varint,2,opt,name=include_addrs,json=includeAddrs,proto3" json:"include_addrs,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *InstanceMutualScanCode_Request) Reset()         { *m = InstanceMutualScanCode_Request{} }
+func (m *InstanceMutualScanCode_Request) String() string { return proto.CompactTextString(m) }
+func (*InstanceMutualScanCode_Request) ProtoMessage()    {}
+func (*InstanceMutualScanCode_Request) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fd3bf21e238da6aa, []int{5, 0}
+}
+func (m *InstanceMutualScanCode_Request) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_InstanceMutualScanCode_Request.Unmarshal(m, b)
+}
+func (m *InstanceMutualScanCode_Request) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_InstanceMutualScanCode_Request.Marshal(b, m, deterministic)
+}
+func (m *InstanceMutualScanCode_Request) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_InstanceMutualScanCode_Request.Merge(m, src)
+}
+func (m *InstanceMutualScanCode_Request) XXX_Size() int {
+	return xxx_messageInfo_InstanceMutualScanCode_Request.Size(m)
+}
+func (m *InstanceMutualScanCode_Request) XXX_DiscardUnknown() {
+	xxx_messageInfo_InstanceMutualScanCode_Request.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_InstanceMutualScanCode_Request proto.InternalMessageInfo
+
+func (m *InstanceMutualScanCode_Request) GetDisplayName() string {
+	if m != nil {
+		return m.DisplayName
+	}
+	return ""
+}
+
+func (m *InstanceMutualScanCode_Request) GetIncludeAddrs() bool {
+	if m != nil {
+		return m.IncludeAddrs
+	}
+	return false
+}
+
+type InstanceMutualScanCode_Reply struct {
+	BertyMutualScanCode        *BertyMutualScanCode `protobuf:"bytes,1,opt,name=berty_mutual_scan_code,json=bertyMutualScanCode,proto3" json:"berty_mutual_scan_code,omitempty"`
+	BertyMutualScanCodePayload string               `protobuf:"bytes,2,opt,name=berty_mutual_scan_code_payload,json=bertyMutualScanCodePayload,proto3" json:"berty_mutual_scan_code_payload,omitempty"`
+	DeepLink                   string               `protobuf:"bytes,3,opt,name=deep_link,json=deepLink,proto3" json:"deep_link,omitempty"`
+	XXX_NoUnkeyedLiteral       struct{}             `json:"-"`
+	XXX_unrecognized           []byte               `json:"-"`
+	XXX_sizecache              int32                `json:"-"`
+}
+
+func (m *InstanceMutualScanCode_Reply) Reset()         { *m = InstanceMutualScanCode_Reply{} }
+func (m *InstanceMutualScanCode_Reply) String() string { return proto.CompactTextString(m) }
+func (*InstanceMutualScanCode_Reply) ProtoMessage()    {}
+func (*InstanceMutualScanCode_Reply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fd3bf21e238da6aa, []int{5, 1}
+}
+func (m *InstanceMutualScanCode_Reply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_InstanceMutualScanCode_Reply.Unmarshal(m, b)
+}
+func (m *InstanceMutualScanCode_Reply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_InstanceMutualScanCode_Reply.Marshal(b, m, deterministic)
+}
+func (m *InstanceMutualScanCode_Reply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_InstanceMutualScanCode_Reply.Merge(m, src)
+}
+func (m *InstanceMutualScanCode_Reply) XXX_Size() int {
+	return xxx_messageInfo_InstanceMutualScanCode_Reply.Size(m)
+}
+func (m *InstanceMutualScanCode_Reply) XXX_DiscardUnknown() {
+	xxx_messageInfo_InstanceMutualScanCode_Reply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_InstanceMutualScanCode_Reply proto.InternalMessageInfo
+
+func (m *InstanceMutualScanCode_Reply) GetBertyMutualScanCode() *BertyMutualScanCode {
+	if m != nil {
+		return m.BertyMutualScanCode
+	}
+	return nil
+}
+
+func (m *InstanceMutualScanCode_Reply) GetBertyMutualScanCodePayload() string {
+	if m != nil {
+		return m.BertyMutualScanCodePayload
+	}
+	return ""
+}
+
+func (m *InstanceMutualScanCode_Reply) GetDeepLink() string {
+	if m != nil {
+		return m.DeepLink
+	}
+	return ""
+}
+
+type MutualScanComplete struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *MutualScanComplete) Reset()         { *m = MutualScanComplete{} }
+func (m *MutualScanComplete) String() string { return proto.CompactTextString(m) }
+func (*MutualScanComplete) ProtoMessage()    {}
+func (*MutualScanComplete) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fd3bf21e238da6aa, []int{6}
+}
+func (m *MutualScanComplete) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MutualScanComplete.Unmarshal(m, b)
+}
+func (m *MutualScanComplete) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MutualScanComplete.Marshal(b, m, deterministic)
+}
+func (m *MutualScanComplete) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MutualScanComplete.Merge(m, src)
+}
+func (m *MutualScanComplete) XXX_Size() int {
+	return xxx_messageInfo_MutualScanComplete.Size(m)
+}
+func (m *MutualScanComplete) XXX_DiscardUnknown() {
+	xxx_messageInfo_MutualScanComplete.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MutualScanComplete proto.InternalMessageInfo
+
+type MutualScanComplete_Request struct {
+	BertyMutualScanCode  *BertyMutualScanCode `protobuf:"bytes,1,opt,name=berty_mutual_scan_code,json=bertyMutualScanCode,proto3" json:"berty_mutual_scan_code,omitempty"`
+	Metadata             []byte               `protobuf:"bytes,2,opt,name=metadata,proto3" json:"metadata,omitempty"`
+	OwnMetadata          []byte               `protobuf:"bytes,3,opt,name=own_metadata,json=ownMetadata,proto3" json:"own_metadata,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *MutualScanComplete_Request) Reset()         { *m = MutualScanComplete_Request{} }
+func (m *MutualScanComplete_Request) String() string { return proto.CompactTextString(m) }
+func (*MutualScanComplete_Request) ProtoMessage()    {}
+func (*MutualScanComplete_Request) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fd3bf21e238da6aa, []int{6, 0}
+}
+func (m *MutualScanComplete_Request) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MutualScanComplete_Request.Unmarshal(m, b)
+}
+func (m *MutualScanComplete_Request) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MutualScanComplete_Request.Marshal(b, m, deterministic)
+}
+func (m *MutualScanComplete_Request) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MutualScanComplete_Request.Merge(m, src)
+}
+func (m *MutualScanComplete_Request) XXX_Size() int {
+	return xxx_messageInfo_MutualScanComplete_Request.Size(m)
+}
+func (m *MutualScanComplete_Request) XXX_DiscardUnknown() {
+	xxx_messageInfo_MutualScanComplete_Request.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MutualScanComplete_Request proto.InternalMessageInfo
+
+func (m *MutualScanComplete_Request) GetBertyMutualScanCode() *BertyMutualScanCode {
+	if m != nil {
+		return m.BertyMutualScanCode
+	}
+	return nil
+}
+
+func (m *MutualScanComplete_Request) GetMetadata() []byte {
+	if m != nil {
+		return m.Metadata
+	}
+	return nil
+}
+
+func (m *MutualScanComplete_Request) GetOwnMetadata() []byte {
+	if m != nil {
+		return m.OwnMetadata
+	}
+	return nil
+}
+
+type MutualScanComplete_Reply struct {
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *MutualScanComplete_Reply) Reset()         { *m = MutualScanComplete_Reply{} }
+func (m *MutualScanComplete_Reply) String() string { return proto.CompactTextString(m) }
+func (*MutualScanComplete_Reply) ProtoMessage()    {}
+func (*MutualScanComplete_Reply) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fd3bf21e238da6aa, []int{6, 1}
+}
+func (m *MutualScanComplete_Reply) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MutualScanComplete_Reply.Unmarshal(m, b)
+}
+func (m *MutualScanComplete_Reply) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MutualScanComplete_Reply.Marshal(b, m, deterministic)
+}
+func (m *MutualScanComplete_Reply) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MutualScanComplete_Reply.Merge(m, src)
+}
+func (m *MutualScanComplete_Reply) XXX_Size() int {
+	return xxx_messageInfo_MutualScanComplete_Reply.Size(m)
+}
+func (m *MutualScanComplete_Reply) XXX_DiscardUnknown() {
+	xxx_messageInfo_MutualScanComplete_Reply.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MutualScanComplete_Reply proto.InternalMessageInfo
+
 type SendAck struct {
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
@@ -722,7 +996,7 @@ func (m *SendAck) Reset()         { *m = SendAck{} }
 func (m *SendAck) String() string { return proto.CompactTextString(m) }
 func (*SendAck) ProtoMessage()    {}
 func (*SendAck) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fd3bf21e238da6aa, []int{5}
+	return fileDescriptor_fd3bf21e238da6aa, []int{7}
 }
 func (m *SendAck) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SendAck.Unmarshal(m, b)
@@ -754,7 +1028,7 @@ func (m *SendAck_Request) Reset()         { *m = SendAck_Request{} }
 func (m *SendAck_Request) String() string { return proto.CompactTextString(m) }
 func (*SendAck_Request) ProtoMessage()    {}
 func (*SendAck_Request) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fd3bf21e238da6aa, []int{5, 0}
+	return fileDescriptor_fd3bf21e238da6aa, []int{7, 0}
 }
 func (m *SendAck_Request) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SendAck_Request.Unmarshal(m, b)
@@ -798,7 +1072,7 @@ func (m *SendAck_Reply) Reset()         { *m = SendAck_Reply{} }
 func (m *SendAck_Reply) String() string { return proto.CompactTextString(m) }
 func (*SendAck_Reply) ProtoMessage()    {}
 func (*SendAck_Reply) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fd3bf21e238da6aa, []int{5, 1}
+	return fileDescriptor_fd3bf21e238da6aa, []int{7, 1}
 }
 func (m *SendAck_Reply) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SendAck_Reply.Unmarshal(m, b)
@@ -828,7 +1102,7 @@ func (m *SendMessage) Reset()         { *m = SendMessage{} }
 func (m *SendMessage) String() string { return proto.CompactTextString(m) }
 func (*SendMessage) ProtoMessage()    {}
 func (*SendMessage) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fd3bf21e238da6aa, []int{6}
+	return fileDescriptor_fd3bf21e238da6aa, []int{8}
 }
 func (m *SendMessage) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SendMessage.Unmarshal(m, b)
@@ -860,7 +1134,7 @@ func (m *SendMessage_Request) Reset()         { *m = SendMessage_Request{} }
 func (m *SendMessage_Request) String() string { return proto.CompactTextString(m) }
 func (*SendMessage_Request) ProtoMessage()    {}
 func (*SendMessage_Request) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fd3bf21e238da6aa, []int{6, 0}
+	return fileDescriptor_fd3bf21e238da6aa, []int{8, 0}
 }
 func (m *SendMessage_Request) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SendMessage_Request.Unmarshal(m, b)
@@ -904,7 +1178,7 @@ func (m *SendMessage_Reply) Reset()         { *m = SendMessage_Reply{} }
 func (m *SendMessage_Reply) String() string { return proto.CompactTextString(m) }
 func (*SendMessage_Reply) ProtoMessage()    {}
 func (*SendMessage_Reply) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fd3bf21e238da6aa, []int{6, 1}
+	return fileDescriptor_fd3bf21e238da6aa, []int{8, 1}
 }
 func (m *SendMessage_Reply) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SendMessage_Reply.Unmarshal(m, b)
@@ -928,6 +1202,7 @@ type BertyID struct {
 	PublicRendezvousSeed []byte   `protobuf:"bytes,1,opt,name=public_rendezvous_seed,json=publicRendezvousSeed,proto3" json:"public_rendezvous_seed,omitempty"`
 	AccountPK            []byte   `protobuf:"bytes,2,opt,name=account_pk,json=accountPk,proto3" json:"account_pk,omitempty"`
 	DisplayName          string   `protobuf:"bytes,3,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	Addrs                []string `protobuf:"bytes,4,rep,name=addrs,proto3" json:"addrs,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -937,7 +1212,7 @@ func (m *BertyID) Reset()         { *m = BertyID{} }
 func (m *BertyID) String() string { return proto.CompactTextString(m) }
 func (*BertyID) ProtoMessage()    {}
 func (*BertyID) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fd3bf21e238da6aa, []int{7}
+	return fileDescriptor_fd3bf21e238da6aa, []int{9}
 }
 func (m *BertyID) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_BertyID.Unmarshal(m, b)
@@ -978,6 +1253,13 @@ func (m *BertyID) GetDisplayName() string {
 	return ""
 }
 
+func (m *BertyID) GetAddrs() []string {
+	if m != nil {
+		return m.Addrs
+	}
+	return nil
+}
+
 type BertyGroup struct {
 	Group                *bertytypes.Group `protobuf:"bytes,1,opt,name=group,proto3" json:"group,omitempty"`
 	DisplayName          string            `protobuf:"bytes,2,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
@@ -990,7 +1272,7 @@ func (m *BertyGroup) Reset()         { *m = BertyGroup{} }
 func (m *BertyGroup) String() string { return proto.CompactTextString(m) }
 func (*BertyGroup) ProtoMessage()    {}
 func (*BertyGroup) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fd3bf21e238da6aa, []int{8}
+	return fileDescriptor_fd3bf21e238da6aa, []int{10}
 }
 func (m *BertyGroup) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_BertyGroup.Unmarshal(m, b)
@@ -1024,6 +1306,102 @@ func (m *BertyGroup) GetDisplayName() string {
 	return ""
 }
 
+// BertyMutualScanCode is a one-time code exchanged in person, both sides scan the code of the other
+type BertyMutualScanCode struct {
+	BertyID *BertyID `protobuf:"bytes,1,opt,name=berty_id,json=bertyId,proto3" json:"berty_id,omitempty"`
+	// secret is an ephemeral secret proving to the code owner that the request comes from someone who scanned it
+	Secret               []byte   `protobuf:"bytes,2,opt,name=secret,proto3" json:"secret,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *BertyMutualScanCode) Reset()         { *m = BertyMutualScanCode{} }
+func (m *BertyMutualScanCode) String() string { return proto.CompactTextString(m) }
+func (*BertyMutualScanCode) ProtoMessage()    {}
+func (*BertyMutualScanCode) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fd3bf21e238da6aa, []int{11}
+}
+func (m *BertyMutualScanCode) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_BertyMutualScanCode.Unmarshal(m, b)
+}
+func (m *BertyMutualScanCode) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_BertyMutualScanCode.Marshal(b, m, deterministic)
+}
+func (m *BertyMutualScanCode) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_BertyMutualScanCode.Merge(m, src)
+}
+func (m *BertyMutualScanCode) XXX_Size() int {
+	return xxx_messageInfo_BertyMutualScanCode.Size(m)
+}
+func (m *BertyMutualScanCode) XXX_DiscardUnknown() {
+	xxx_messageInfo_BertyMutualScanCode.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_BertyMutualScanCode proto.InternalMessageInfo
+
+func (m *BertyMutualScanCode) GetBertyID() *BertyID {
+	if m != nil {
+		return m.BertyID
+	}
+	return nil
+}
+
+func (m *BertyMutualScanCode) GetSecret() []byte {
+	if m != nil {
+		return m.Secret
+	}
+	return nil
+}
+
+// MutualScanContactMetadata is sent as own_metadata of contact requests issued by MutualScanComplete
+type MutualScanContactMetadata struct {
+	// proof is a HMAC-SHA256 of the requester account public key keyed with the scanned code secret
+	Proof                []byte   `protobuf:"bytes,1,opt,name=proof,proto3" json:"proof,omitempty"`
+	OwnMetadata          []byte   `protobuf:"bytes,2,opt,name=own_metadata,json=ownMetadata,proto3" json:"own_metadata,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *MutualScanContactMetadata) Reset()         { *m = MutualScanContactMetadata{} }
+func (m *MutualScanContactMetadata) String() string { return proto.CompactTextString(m) }
+func (*MutualScanContactMetadata) ProtoMessage()    {}
+func (*MutualScanContactMetadata) Descriptor() ([]byte, []int) {
+	return fileDescriptor_fd3bf21e238da6aa, []int{12}
+}
+func (m *MutualScanContactMetadata) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_MutualScanContactMetadata.Unmarshal(m, b)
+}
+func (m *MutualScanContactMetadata) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_MutualScanContactMetadata.Marshal(b, m, deterministic)
+}
+func (m *MutualScanContactMetadata) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_MutualScanContactMetadata.Merge(m, src)
+}
+func (m *MutualScanContactMetadata) XXX_Size() int {
+	return xxx_messageInfo_MutualScanContactMetadata.Size(m)
+}
+func (m *MutualScanContactMetadata) XXX_DiscardUnknown() {
+	xxx_messageInfo_MutualScanContactMetadata.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_MutualScanContactMetadata proto.InternalMessageInfo
+
+func (m *MutualScanContactMetadata) GetProof() []byte {
+	if m != nil {
+		return m.Proof
+	}
+	return nil
+}
+
+func (m *MutualScanContactMetadata) GetOwnMetadata() []byte {
+	if m != nil {
+		return m.OwnMetadata
+	}
+	return nil
+}
+
 type AppMessageTyped struct {
 	Type                 AppMessageType `protobuf:"varint,1,opt,name=type,proto3,enum=berty.messenger.AppMessageType" json:"type,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}       `json:"-"`
@@ -1035,7 +1413,7 @@ func (m *AppMessageTyped) Reset()         { *m = AppMessageTyped{} }
 func (m *AppMessageTyped) String() string { return proto.CompactTextString(m) }
 func (*AppMessageTyped) ProtoMessage()    {}
 func (*AppMessageTyped) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fd3bf21e238da6aa, []int{9}
+	return fileDescriptor_fd3bf21e238da6aa, []int{13}
 }
 func (m *AppMessageTyped) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_AppMessageTyped.Unmarshal(m, b)
@@ -1074,7 +1452,7 @@ func (m *UserMessageAttachment) Reset()         { *m = UserMessageAttachment{} }
 func (m *UserMessageAttachment) String() string { return proto.CompactTextString(m) }
 func (*UserMessageAttachment) ProtoMessage()    {}
 func (*UserMessageAttachment) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fd3bf21e238da6aa, []int{10}
+	return fileDescriptor_fd3bf21e238da6aa, []int{14}
 }
 func (m *UserMessageAttachment) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_UserMessageAttachment.Unmarshal(m, b)
@@ -1122,7 +1500,7 @@ func (m *PayloadUserMessage) Reset()         { *m = PayloadUserMessage{} }
 func (m *PayloadUserMessage) String() string { return proto.CompactTextString(m) }
 func (*PayloadUserMessage) ProtoMessage()    {}
 func (*PayloadUserMessage) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fd3bf21e238da6aa, []int{11}
+	return fileDescriptor_fd3bf21e238da6aa, []int{15}
 }
 func (m *PayloadUserMessage) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_PayloadUserMessage.Unmarshal(m, b)
@@ -1182,7 +1560,7 @@ func (m *PayloadUserReaction) Reset()         { *m = PayloadUserReaction{} }
 func (m *PayloadUserReaction) String() string { return proto.CompactTextString(m) }
 func (*PayloadUserReaction) ProtoMessage()    {}
 func (*PayloadUserReaction) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fd3bf21e238da6aa, []int{12}
+	return fileDescriptor_fd3bf21e238da6aa, []int{16}
 }
 func (m *PayloadUserReaction) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_PayloadUserReaction.Unmarshal(m, b)
@@ -1228,7 +1606,7 @@ func (m *PayloadGroupInvitation) Reset()         { *m = PayloadGroupInvitation{}
 func (m *PayloadGroupInvitation) String() string { return proto.CompactTextString(m) }
 func (*PayloadGroupInvitation) ProtoMessage()    {}
 func (*PayloadGroupInvitation) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fd3bf21e238da6aa, []int{13}
+	return fileDescriptor_fd3bf21e238da6aa, []int{17}
 }
 func (m *PayloadGroupInvitation) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_PayloadGroupInvitation.Unmarshal(m, b)
@@ -1274,7 +1652,7 @@ func (m *PayloadSetGroupName) Reset()         { *m = PayloadSetGroupName{} }
 func (m *PayloadSetGroupName) String() string { return proto.CompactTextString(m) }
 func (*PayloadSetGroupName) ProtoMessage()    {}
 func (*PayloadSetGroupName) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fd3bf21e238da6aa, []int{14}
+	return fileDescriptor_fd3bf21e238da6aa, []int{18}
 }
 func (m *PayloadSetGroupName) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_PayloadSetGroupName.Unmarshal(m, b)
@@ -1320,7 +1698,7 @@ func (m *PayloadAcknowledge) Reset()         { *m = PayloadAcknowledge{} }
 func (m *PayloadAcknowledge) String() string { return proto.CompactTextString(m) }
 func (*PayloadAcknowledge) ProtoMessage()    {}
 func (*PayloadAcknowledge) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fd3bf21e238da6aa, []int{15}
+	return fileDescriptor_fd3bf21e238da6aa, []int{19}
 }
 func (m *PayloadAcknowledge) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_PayloadAcknowledge.Unmarshal(m, b)
@@ -1364,7 +1742,7 @@ func (m *SystemInfo) Reset()         { *m = SystemInfo{} }
 func (m *SystemInfo) String() string { return proto.CompactTextString(m) }
 func (*SystemInfo) ProtoMessage()    {}
 func (*SystemInfo) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fd3bf21e238da6aa, []int{16}
+	return fileDescriptor_fd3bf21e238da6aa, []int{20}
 }
 func (m *SystemInfo) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SystemInfo.Unmarshal(m, b)
@@ -1394,7 +1772,7 @@ func (m *SystemInfo_Request) Reset()         { *m = SystemInfo_Request{} }
 func (m *SystemInfo_Request) String() string { return proto.CompactTextString(m) }
 func (*SystemInfo_Request) ProtoMessage()    {}
 func (*SystemInfo_Request) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fd3bf21e238da6aa, []int{16, 0}
+	return fileDescriptor_fd3bf21e238da6aa, []int{20, 0}
 }
 func (m *SystemInfo_Request) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SystemInfo_Request.Unmarshal(m, b)
@@ -1434,7 +1812,7 @@ func (m *SystemInfo_Reply) Reset()         { *m = SystemInfo_Reply{} }
 func (m *SystemInfo_Reply) String() string { return proto.CompactTextString(m) }
 func (*SystemInfo_Reply) ProtoMessage()    {}
 func (*SystemInfo_Reply) Descriptor() ([]byte, []int) {
-	return fileDescriptor_fd3bf21e238da6aa, []int{16, 1}
+	return fileDescriptor_fd3bf21e238da6aa, []int{20, 1}
 }
 func (m *SystemInfo_Reply) XXX_Unmarshal(b []byte) error {
 	return xxx_messageInfo_SystemInfo_Reply.Unmarshal(m, b)
@@ -1542,6 +1920,12 @@ func init() {
 	proto.RegisterType((*SendContactRequest)(nil), "berty.messenger.SendContactRequest")
 	proto.RegisterType((*SendContactRequest_Request)(nil), "berty.messenger.SendContactRequest.Request")
 	proto.RegisterType((*SendContactRequest_Reply)(nil), "berty.messenger.SendContactRequest.Reply")
+	proto.RegisterType((*InstanceMutualScanCode)(nil), "berty.messenger.InstanceMutualScanCode")
+	proto.RegisterType((*InstanceMutualScanCode_Request)(nil), "berty.messenger.InstanceMutualScanCode.Request")
+	proto.RegisterType((*InstanceMutualScanCode_Reply)(nil), "berty.messenger.InstanceMutualScanCode.Reply")
+	proto.RegisterType((*MutualScanComplete)(nil), "berty.messenger.MutualScanComplete")
+	proto.RegisterType((*MutualScanComplete_Request)(nil), "berty.messenger.MutualScanComplete.Request")
+	proto.RegisterType((*MutualScanComplete_Reply)(nil), "berty.messenger.MutualScanComplete.Reply")
 	proto.RegisterType((*SendAck)(nil), "berty.messenger.SendAck")
 	proto.RegisterType((*SendAck_Request)(nil), "berty.messenger.SendAck.Request")
 	proto.RegisterType((*SendAck_Reply)(nil), "berty.messenger.SendAck.Reply")
@@ -1550,6 +1934,8 @@ func init() {
 	proto.RegisterType((*SendMessage_Reply)(nil), "berty.messenger.SendMessage.Reply")
 	proto.RegisterType((*BertyID)(nil), "berty.messenger.BertyID")
 	proto.RegisterType((*BertyGroup)(nil), "berty.messenger.BertyGroup")
+	proto.RegisterType((*BertyMutualScanCode)(nil), "berty.messenger.BertyMutualScanCode")
+	proto.RegisterType((*MutualScanContactMetadata)(nil), "berty.messenger.MutualScanContactMetadata")
 	proto.RegisterType((*AppMessageTyped)(nil), "berty.messenger.AppMessageTyped")
 	proto.RegisterType((*UserMessageAttachment)(nil), "berty.messenger.UserMessageAttachment")
 	proto.RegisterType((*PayloadUserMessage)(nil), "berty.messenger.PayloadUserMessage")
@@ -1565,94 +1951,109 @@ func init() {
 func init() { proto.RegisterFile("bertymessenger.proto", fileDescriptor_fd3bf21e238da6aa) }
 
 var fileDescriptor_fd3bf21e238da6aa = []byte{
-	// 1379 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x57, 0xcf, 0x6e, 0xdb, 0xc6,
-	0x13, 0xfe, 0xe9, 0x8f, 0x2d, 0x69, 0x24, 0xdb, 0xcc, 0xda, 0x49, 0x04, 0x05, 0xf9, 0xc9, 0x61,
-	0x02, 0xc3, 0x49, 0x53, 0xb9, 0x75, 0x02, 0xf4, 0xd2, 0x43, 0xad, 0xb8, 0x4d, 0x0c, 0xdb, 0x81,
-	0x40, 0xdb, 0x2d, 0x5a, 0xa0, 0x61, 0x29, 0x72, 0x2c, 0xb3, 0x12, 0x97, 0x2c, 0xb9, 0x54, 0xa0,
-	0x14, 0xe8, 0x03, 0xf4, 0xd4, 0xe7, 0xe8, 0x0b, 0xf4, 0x50, 0xf4, 0x01, 0x7a, 0x2b, 0x7a, 0x29,
-	0xda, 0x83, 0x0e, 0x3a, 0xe6, 0xd2, 0x57, 0x28, 0x76, 0xb9, 0xa4, 0x28, 0x4b, 0x4e, 0x62, 0xa5,
-	0xbd, 0xed, 0xcc, 0x7e, 0xfb, 0xcd, 0xce, 0xb7, 0x33, 0xcb, 0x25, 0xac, 0xb5, 0xd1, 0x67, 0x03,
-	0x07, 0x83, 0x00, 0x69, 0x07, 0xfd, 0x86, 0xe7, 0xbb, 0xcc, 0x25, 0x2b, 0xc2, 0xdb, 0x48, 0xdc,
-	0xb5, 0x77, 0x3b, 0x36, 0x3b, 0x0b, 0xdb, 0x0d, 0xd3, 0x75, 0xb6, 0x3a, 0x6e, 0xc7, 0xdd, 0x12,
-	0xb8, 0x76, 0x78, 0x2a, 0x2c, 0x61, 0x88, 0x51, 0xb4, 0xbe, 0xa6, 0x88, 0xf5, 0x6c, 0xe0, 0x61,
-	0x10, 0x79, 0xd4, 0x9f, 0xb3, 0x50, 0xdd, 0xa3, 0x01, 0x33, 0xa8, 0x89, 0x47, 0x67, 0x86, 0x8f,
-	0x46, 0xbb, 0x87, 0x4d, 0x8e, 0xda, 0xdb, 0xad, 0x35, 0xa1, 0xa0, 0xe1, 0x37, 0x21, 0x06, 0x8c,
-	0xac, 0xc1, 0x82, 0x8f, 0x01, 0xb2, 0x6a, 0x66, 0x3d, 0xb3, 0x59, 0xd4, 0x22, 0x83, 0xdc, 0x82,
-	0x8a, 0x65, 0x07, 0x5e, 0xcf, 0x18, 0xe8, 0xd4, 0x70, 0xb0, 0x9a, 0x5d, 0xcf, 0x6c, 0x96, 0xb4,
-	0xb2, 0xf4, 0x3d, 0x35, 0x1c, 0xac, 0xfd, 0x95, 0x81, 0x05, 0x0d, 0xbd, 0xde, 0x80, 0x7c, 0x04,
-	0x45, 0x11, 0x5e, 0xb7, 0x2d, 0xc1, 0x52, 0xde, 0xae, 0x36, 0xce, 0xe5, 0xd3, 0x90, 0x91, 0x9b,
-	0xe5, 0xd1, 0xb0, 0x5e, 0x90, 0x86, 0x56, 0x10, 0xa8, 0x3d, 0x8b, 0x7c, 0x08, 0x4a, 0xcc, 0xa0,
-	0x7b, 0xc6, 0xa0, 0xe7, 0x1a, 0x56, 0x14, 0xb2, 0x49, 0x46, 0xc3, 0xfa, 0xb2, 0xc4, 0xb7, 0xa2,
-	0x19, 0x6d, 0x59, 0x2e, 0x93, 0x36, 0xb9, 0x0b, 0x25, 0x0b, 0xd1, 0xd3, 0x7b, 0x36, 0xed, 0x56,
-	0x73, 0x62, 0x59, 0x65, 0x34, 0xac, 0x17, 0x77, 0x11, 0xbd, 0x03, 0x9b, 0x76, 0xb5, 0xa2, 0x25,
-	0x47, 0x64, 0x03, 0x8a, 0x67, 0xcc, 0xe9, 0xe9, 0xa1, 0xdf, 0xab, 0xe6, 0x05, 0x52, 0x6c, 0xe8,
-	0xc9, 0xf1, 0xe1, 0xc1, 0x89, 0x76, 0xa0, 0x15, 0xf8, 0xe4, 0x89, 0xdf, 0x53, 0xff, 0xcc, 0xc2,
-	0xea, 0xa4, 0x6a, 0x8f, 0x7d, 0x37, 0xf4, 0x6a, 0xad, 0xb1, 0x70, 0x1b, 0x50, 0xec, 0x70, 0x9f,
-	0xee, 0x75, 0x45, 0xd6, 0x95, 0x88, 0x4a, 0xe0, 0x5a, 0xfb, 0x5a, 0x41, 0x4c, 0xb6, 0xba, 0xe4,
-	0x26, 0x40, 0x84, 0x4b, 0x09, 0x59, 0x12, 0x1e, 0x21, 0xe3, 0xdf, 0x89, 0x8c, 0x07, 0x50, 0x8e,
-	0x44, 0x10, 0x93, 0x52, 0xc9, 0x1b, 0xb3, 0x95, 0x14, 0x51, 0x9a, 0xcb, 0xa3, 0x61, 0x1d, 0xc6,
-	0xb6, 0x06, 0xed, 0x64, 0x4c, 0x3e, 0x86, 0xd5, 0x14, 0xdb, 0x39, 0x55, 0xaf, 0x8e, 0x86, 0xf5,
-	0x2b, 0xe3, 0x85, 0xb1, 0xb0, 0x57, 0xda, 0xe7, 0x5d, 0xff, 0x85, 0xb6, 0xa7, 0x70, 0x7d, 0x17,
-	0xfb, 0x42, 0xdd, 0xb8, 0x40, 0xff, 0xcd, 0xba, 0x2c, 0x48, 0x3d, 0xd5, 0x9f, 0xb2, 0xb0, 0xd4,
-	0x32, 0xfc, 0x00, 0xe3, 0xbd, 0xd6, 0x6e, 0x8e, 0xe9, 0x09, 0xe4, 0x45, 0x4a, 0x19, 0x41, 0x20,
-	0xc6, 0xb5, 0xdf, 0x92, 0xa3, 0xf8, 0x00, 0xf2, 0x5d, 0x9b, 0x46, 0xd5, 0xbc, 0xbc, 0x7d, 0x7b,
-	0xea, 0x0c, 0x26, 0x68, 0x1b, 0xfb, 0x36, 0xb5, 0x34, 0xb1, 0x60, 0xa2, 0x15, 0x72, 0x73, 0xb5,
-	0xc2, 0xb9, 0x2a, 0xc8, 0xbf, 0x55, 0x15, 0xa8, 0x0f, 0x21, 0xcf, 0x77, 0x47, 0x56, 0xa0, 0x7c,
-	0x42, 0xbb, 0xd4, 0x7d, 0x4e, 0xb9, 0xa9, 0xfc, 0x8f, 0x94, 0x21, 0x0e, 0xad, 0x64, 0xc8, 0x32,
-	0xa4, 0xd6, 0x2b, 0x59, 0xf5, 0xc7, 0x0c, 0x90, 0x23, 0xa4, 0xd6, 0x23, 0x97, 0x32, 0xc3, 0x64,
-	0x52, 0xb3, 0xda, 0xf7, 0x99, 0xb1, 0x7e, 0x6f, 0xdf, 0xf3, 0x35, 0x28, 0x3a, 0xc8, 0x0c, 0xcb,
-	0x60, 0x86, 0x38, 0xc6, 0x8a, 0x96, 0xd8, 0xfc, 0x98, 0xdd, 0xe7, 0x54, 0x4f, 0xe6, 0x73, 0x62,
-	0xbe, 0xec, 0x3e, 0xa7, 0x87, 0xd2, 0x35, 0x3e, 0xe6, 0x00, 0x0a, 0x7c, 0xaf, 0x3b, 0x66, 0xb7,
-	0xa6, 0x5f, 0xbe, 0x3b, 0xef, 0x03, 0xf0, 0x0d, 0x1b, 0x1d, 0xe4, 0x99, 0x88, 0x7d, 0x34, 0x97,
-	0x46, 0xc3, 0x7a, 0xe9, 0x30, 0xf2, 0xee, 0xed, 0x6a, 0x25, 0x09, 0xd8, 0xb3, 0xc6, 0x41, 0x4d,
-	0x28, 0xf3, 0xa0, 0x12, 0x54, 0xdb, 0xbf, 0x7c, 0xe0, 0x2a, 0x14, 0x24, 0xaf, 0x2c, 0xe2, 0xd8,
-	0x1c, 0x07, 0xf9, 0x21, 0x93, 0x1c, 0x12, 0x79, 0x08, 0xd7, 0xbc, 0xb0, 0xdd, 0xb3, 0x4d, 0xdd,
-	0x47, 0x6a, 0xe1, 0x8b, 0xbe, 0x1b, 0x06, 0x7a, 0x80, 0x18, 0xa9, 0x5f, 0xd1, 0xd6, 0xa2, 0x59,
-	0x2d, 0x99, 0x3c, 0x42, 0xb4, 0x78, 0x76, 0x86, 0x69, 0xba, 0x21, 0x65, 0x7c, 0x3b, 0xa9, 0xec,
-	0x76, 0x22, 0x6f, 0x6b, 0x5f, 0x2b, 0x49, 0x40, 0xab, 0x3b, 0xd5, 0x5c, 0xb9, 0xa9, 0xe6, 0x52,
-	0x3f, 0x4f, 0x57, 0x0a, 0xd9, 0x84, 0x85, 0xf4, 0x5d, 0x45, 0x64, 0x05, 0x44, 0x9f, 0xa1, 0xa8,
-	0x18, 0x23, 0xc0, 0x1b, 0xf4, 0xad, 0xfa, 0x09, 0xac, 0xec, 0x78, 0x9e, 0x54, 0xf4, 0x78, 0xe0,
-	0xa1, 0x45, 0x1e, 0x40, 0x9e, 0x73, 0xc9, 0x36, 0xac, 0x4f, 0x15, 0xd8, 0x24, 0x5e, 0x13, 0x60,
-	0xf5, 0x19, 0x5c, 0x3d, 0x09, 0xd0, 0x97, 0x13, 0x3b, 0x8c, 0x19, 0xe6, 0x99, 0x83, 0x94, 0xcd,
-	0xc5, 0x46, 0x14, 0xc8, 0x85, 0xbe, 0x2d, 0xf7, 0xcb, 0x87, 0xea, 0xef, 0x19, 0x20, 0xf2, 0x76,
-	0x4c, 0xc5, 0x99, 0x8f, 0x9d, 0x40, 0xbe, 0xed, 0x5a, 0x03, 0x49, 0x2f, 0xc6, 0xe4, 0x09, 0x94,
-	0x8d, 0x64, 0xd3, 0x41, 0x35, 0xb7, 0x9e, 0xdb, 0x2c, 0x6f, 0x6f, 0x4c, 0xf1, 0xcd, 0xcc, 0x51,
-	0x4b, 0x2f, 0xe5, 0x77, 0x77, 0x80, 0x94, 0xe9, 0x96, 0xc1, 0x50, 0x5c, 0x24, 0xb9, 0x66, 0xe5,
-	0xe5, 0xb0, 0x5e, 0xe4, 0xce, 0x5d, 0x83, 0xa1, 0x96, 0x8c, 0xd4, 0xaf, 0x60, 0x35, 0x95, 0x93,
-	0x86, 0x86, 0xc9, 0x6c, 0x97, 0xce, 0x97, 0xd4, 0x1a, 0x2c, 0xa0, 0xe3, 0x7e, 0x1d, 0x8b, 0x16,
-	0x19, 0x6a, 0x08, 0xd7, 0x64, 0x04, 0x51, 0x18, 0x7b, 0xb4, 0x6f, 0x33, 0x63, 0xfe, 0x20, 0xe9,
-	0x36, 0x8b, 0xbe, 0x69, 0xe5, 0x97, 0xc3, 0x7a, 0xdc, 0x5d, 0x49, 0x9b, 0xa9, 0xcf, 0x92, 0xc4,
-	0x8e, 0x90, 0x3d, 0x8e, 0xbf, 0xba, 0x73, 0x9f, 0x56, 0xaa, 0x78, 0xc5, 0x58, 0x35, 0x92, 0x62,
-	0xd8, 0x31, 0xf9, 0xfd, 0xda, 0x43, 0x6b, 0xde, 0x62, 0xb8, 0x06, 0x8b, 0xcc, 0xf0, 0x3b, 0xc8,
-	0x64, 0x00, 0x69, 0xa9, 0x7f, 0x64, 0x01, 0x8e, 0x06, 0x01, 0x43, 0x67, 0x8f, 0x9e, 0xba, 0xb5,
-	0x52, 0x72, 0xd7, 0xd4, 0x7e, 0xc9, 0xc6, 0x1f, 0xac, 0x9b, 0x00, 0x01, 0x33, 0x7c, 0x86, 0x96,
-	0x6e, 0x44, 0x9f, 0xcc, 0x9c, 0x56, 0x92, 0x9e, 0x1d, 0x46, 0x6e, 0x43, 0x81, 0x86, 0x8e, 0x6e,
-	0x7a, 0xa1, 0xe0, 0xce, 0x35, 0x61, 0x34, 0xac, 0x2f, 0x3e, 0x0d, 0x9d, 0x47, 0xad, 0x13, 0x6d,
-	0x91, 0x86, 0xce, 0x23, 0x2f, 0x14, 0x0f, 0x15, 0x57, 0xef, 0xa3, 0x1f, 0xd8, 0x2e, 0x95, 0xcd,
-	0x5f, 0xea, 0xb8, 0x9f, 0x46, 0x0e, 0x72, 0x1b, 0x96, 0x38, 0x47, 0xc7, 0xf5, 0xdd, 0x90, 0xd9,
-	0x54, 0x56, 0x94, 0x56, 0xa1, 0xa1, 0xf3, 0x38, 0xf6, 0x91, 0xbb, 0xa0, 0xb8, 0x1e, 0xfa, 0x06,
-	0xb3, 0x69, 0x47, 0x0f, 0xc4, 0xa6, 0xab, 0x0b, 0x82, 0x69, 0x25, 0xf1, 0x47, 0xb9, 0x90, 0x1b,
-	0x50, 0x3a, 0x73, 0x03, 0x16, 0xdd, 0x07, 0x8b, 0x02, 0x53, 0xe4, 0x0e, 0x71, 0x3e, 0x04, 0xf2,
-	0x86, 0x6f, 0x9e, 0x55, 0x0b, 0x91, 0xd4, 0x7c, 0xcc, 0x6f, 0xcc, 0x78, 0x73, 0xc5, 0xe8, 0xc6,
-	0x94, 0x26, 0xb9, 0x0e, 0x85, 0xbe, 0x19, 0xe8, 0x3e, 0x9e, 0x56, 0x4b, 0x91, 0x74, 0x7d, 0x33,
-	0xd0, 0xf0, 0x94, 0xa7, 0xd4, 0x0e, 0xed, 0x9e, 0xa5, 0x33, 0xdb, 0xc1, 0x2a, 0x44, 0xb2, 0x08,
-	0xcf, 0xb1, 0xed, 0xe0, 0xbd, 0x17, 0xb0, 0x3c, 0x79, 0x12, 0x64, 0x09, 0x4a, 0x27, 0xd4, 0xc2,
-	0x53, 0x9b, 0x22, 0xff, 0x4a, 0xf2, 0xcf, 0xe6, 0xb8, 0xcf, 0x94, 0x0c, 0x51, 0xa0, 0x92, 0x6e,
-	0x10, 0x25, 0x4b, 0x56, 0x61, 0xe5, 0x5c, 0x41, 0x2b, 0x39, 0x0e, 0x4b, 0x97, 0x9b, 0x92, 0xe7,
-	0x4c, 0xa9, 0x02, 0x51, 0x16, 0xb6, 0x7f, 0x5d, 0x04, 0xe5, 0x30, 0x2e, 0x88, 0x23, 0xf4, 0xfb,
-	0xb6, 0x89, 0xe4, 0xbb, 0x8b, 0xdf, 0xec, 0xe4, 0xfd, 0xa9, 0x2a, 0xba, 0x08, 0xda, 0x88, 0xeb,
-	0x63, 0xeb, 0x32, 0x4b, 0x78, 0x19, 0xb9, 0x33, 0x5f, 0xbd, 0xe4, 0xfe, 0x14, 0xcf, 0x0c, 0x54,
-	0x12, 0xf5, 0xde, 0x1b, 0xa2, 0x79, 0xc0, 0x6f, 0x2f, 0x7c, 0x0b, 0x92, 0xf7, 0xa6, 0x68, 0x2e,
-	0x40, 0x26, 0x81, 0x1b, 0x97, 0x58, 0xc1, 0x83, 0x7f, 0x79, 0xee, 0x7d, 0x48, 0x36, 0x5e, 0xf3,
-	0xd0, 0x8b, 0x03, 0xdd, 0x79, 0x2d, 0x8e, 0xd3, 0xf7, 0x66, 0x3d, 0xa2, 0xc8, 0x3b, 0xd3, 0xea,
-	0x4c, 0x81, 0x92, 0x40, 0x77, 0xdf, 0x0c, 0xcc, 0xa3, 0x7d, 0x36, 0xf1, 0x22, 0x21, 0x77, 0x66,
-	0xae, 0x94, 0xb3, 0x09, 0xbf, 0xfa, 0x1a, 0x14, 0x27, 0xde, 0x4f, 0xde, 0x57, 0x64, 0x7d, 0x26,
-	0x7c, 0xc7, 0x1c, 0x2b, 0xf3, 0xff, 0x57, 0x20, 0x38, 0xd9, 0x71, 0xfa, 0x2a, 0x23, 0xd3, 0x0f,
-	0xeb, 0xf1, 0x64, 0x42, 0x79, 0xeb, 0xd5, 0x20, 0xaf, 0x37, 0x68, 0x6e, 0x7e, 0xb1, 0x21, 0x5f,
-	0x1e, 0x68, 0x9e, 0x6d, 0x89, 0xe1, 0x16, 0xff, 0x65, 0xee, 0x76, 0xb6, 0x26, 0xff, 0xb6, 0xdb,
-	0x8b, 0xe2, 0xe7, 0xf8, 0xc1, 0x3f, 0x01, 0x00, 0x00, 0xff, 0xff, 0x6f, 0x18, 0x50, 0x82, 0x86,
-	0x0f, 0x00, 0x00,
+	// 1619 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xc4, 0x58, 0x4f, 0x73, 0xdb, 0x4c,
+	0x19, 0xc7, 0x7f, 0x12, 0x5b, 0x8f, 0x9d, 0xc4, 0xef, 0x26, 0x6f, 0x62, 0xf4, 0x4e, 0xeb, 0xbc,
+	0x6a, 0x27, 0x93, 0xbe, 0xbc, 0x75, 0x20, 0x65, 0x86, 0x0b, 0x07, 0xec, 0x04, 0xda, 0x4c, 0x92,
+	0x8e, 0x51, 0x12, 0x18, 0x98, 0xa1, 0x42, 0x96, 0x36, 0x8e, 0xb0, 0xb4, 0x12, 0xd2, 0x2a, 0xc5,
+	0x65, 0x86, 0x0f, 0x50, 0xbe, 0x07, 0x07, 0x6e, 0xdc, 0xe1, 0xd6, 0x4f, 0xc0, 0x85, 0x81, 0x61,
+	0x3c, 0x8c, 0x8f, 0xbd, 0x70, 0xe5, 0xc8, 0xec, 0x6a, 0x25, 0xcb, 0x96, 0xdc, 0x26, 0x69, 0x0b,
+	0xb7, 0x7d, 0x9e, 0xfd, 0xed, 0xf3, 0x6f, 0x7f, 0xfb, 0x68, 0x57, 0xb0, 0xd1, 0xc7, 0x3e, 0x1d,
+	0x39, 0x38, 0x08, 0x30, 0x19, 0x60, 0xbf, 0xed, 0xf9, 0x2e, 0x75, 0xd1, 0x1a, 0xd7, 0xb6, 0x13,
+	0xb5, 0xfc, 0x78, 0x60, 0xd1, 0xab, 0xb0, 0xdf, 0x36, 0x5c, 0x67, 0x6f, 0xe0, 0x0e, 0xdc, 0x3d,
+	0x8e, 0xeb, 0x87, 0x97, 0x5c, 0xe2, 0x02, 0x1f, 0x45, 0xeb, 0xe5, 0x06, 0x5f, 0x4f, 0x47, 0x1e,
+	0x0e, 0x22, 0x8d, 0xf2, 0xaf, 0x22, 0x34, 0x8f, 0x48, 0x40, 0x75, 0x62, 0xe0, 0xb3, 0x2b, 0xdd,
+	0xc7, 0x7a, 0xdf, 0xc6, 0x5d, 0x86, 0x3a, 0x3a, 0x94, 0x07, 0x50, 0x51, 0xf1, 0xaf, 0x43, 0x1c,
+	0x50, 0xb4, 0x01, 0x4b, 0x3e, 0x0e, 0x30, 0x6d, 0x16, 0xb6, 0x0b, 0xbb, 0x55, 0x35, 0x12, 0xd0,
+	0x97, 0x50, 0x37, 0xad, 0xc0, 0xb3, 0xf5, 0x91, 0x46, 0x74, 0x07, 0x37, 0x8b, 0xdb, 0x85, 0x5d,
+	0x49, 0xad, 0x09, 0xdd, 0x73, 0xdd, 0xc1, 0xe8, 0x01, 0xac, 0x58, 0xc4, 0xb0, 0x43, 0x13, 0x6b,
+	0xba, 0x69, 0xfa, 0x41, 0xb3, 0xc4, 0x0d, 0xd4, 0x85, 0xb2, 0xc3, 0x74, 0xf2, 0x3f, 0x0a, 0xb0,
+	0xa4, 0x62, 0xcf, 0x1e, 0xa1, 0x1f, 0x40, 0x95, 0xc7, 0xa8, 0x59, 0x26, 0x77, 0x55, 0xdb, 0x6f,
+	0xb6, 0xe7, 0x92, 0x6e, 0x8b, 0xf0, 0xba, 0xb5, 0xc9, 0xb8, 0x55, 0x11, 0x82, 0x5a, 0xe1, 0xa8,
+	0x23, 0x13, 0x7d, 0x1f, 0x1a, 0xb1, 0x05, 0xcd, 0xd3, 0x47, 0xb6, 0xab, 0x9b, 0x51, 0x5c, 0x5d,
+	0x34, 0x19, 0xb7, 0x56, 0x05, 0xbe, 0x17, 0xcd, 0xa8, 0xab, 0x62, 0x99, 0x90, 0xd1, 0x23, 0x90,
+	0x4c, 0x8c, 0x3d, 0xcd, 0xb6, 0xc8, 0x90, 0x87, 0x2a, 0x75, 0xeb, 0x93, 0x71, 0xab, 0x7a, 0x88,
+	0xb1, 0x77, 0x62, 0x91, 0xa1, 0x5a, 0x35, 0xc5, 0x08, 0xed, 0x40, 0xf5, 0x8a, 0x3a, 0xb6, 0x16,
+	0xfa, 0x76, 0xb3, 0xcc, 0x91, 0x3c, 0xa0, 0x67, 0xe7, 0xa7, 0x27, 0x17, 0xea, 0x89, 0x5a, 0x61,
+	0x93, 0x17, 0xbe, 0xad, 0xfc, 0xbd, 0x08, 0xeb, 0xb3, 0xa5, 0x7d, 0xea, 0xbb, 0xa1, 0x27, 0xf7,
+	0xa6, 0xd5, 0xdd, 0x81, 0xea, 0x80, 0xe9, 0x34, 0x6f, 0xc8, 0xb3, 0xae, 0x47, 0xa6, 0x38, 0xae,
+	0x77, 0xac, 0x56, 0xf8, 0x64, 0x6f, 0x88, 0xee, 0x01, 0x44, 0xb8, 0x54, 0xb5, 0x25, 0xae, 0x61,
+	0xb5, 0x96, 0xff, 0x9d, 0x94, 0xf1, 0x04, 0x6a, 0x51, 0x11, 0xf8, 0xa4, 0xa8, 0xe4, 0x17, 0xf9,
+	0x95, 0xe4, 0x5e, 0xba, 0xab, 0x93, 0x71, 0x0b, 0xa6, 0xb2, 0x0a, 0xfd, 0x64, 0x8c, 0x7e, 0x08,
+	0xeb, 0x29, 0x6b, 0x73, 0x55, 0xfd, 0x7c, 0x32, 0x6e, 0x7d, 0x36, 0x5d, 0x18, 0x17, 0xf6, 0xb3,
+	0xfe, 0xbc, 0xea, 0x53, 0xd4, 0xf6, 0xf7, 0x05, 0xd8, 0x3a, 0xc4, 0xd7, 0xbc, 0xbc, 0x31, 0x8d,
+	0xff, 0xe7, 0xec, 0xad, 0x88, 0xaa, 0x2b, 0x6f, 0x4a, 0xb0, 0xd2, 0xd3, 0xfd, 0x00, 0xc7, 0x19,
+	0xc9, 0xf7, 0xa6, 0x31, 0x20, 0x28, 0xf3, 0xc4, 0x0b, 0xdc, 0x0b, 0x1f, 0xcb, 0x6f, 0x8a, 0xf1,
+	0x86, 0x7d, 0x0f, 0xca, 0x43, 0x8b, 0x44, 0x9c, 0x5f, 0xdd, 0x7f, 0x90, 0xd9, 0xa9, 0x19, 0xb3,
+	0xed, 0x63, 0x8b, 0x98, 0x2a, 0x5f, 0x30, 0x73, 0x60, 0x4a, 0x77, 0x3a, 0x30, 0x73, 0x5c, 0x29,
+	0x7f, 0x18, 0x57, 0x7c, 0xd8, 0x8c, 0xac, 0x39, 0x21, 0x0d, 0x75, 0x5b, 0x0b, 0x0c, 0x9d, 0x68,
+	0x86, 0x6b, 0xe2, 0xe6, 0x12, 0x37, 0xfc, 0x30, 0xdf, 0xf0, 0x29, 0x47, 0x9f, 0x19, 0x3a, 0x39,
+	0x70, 0x4d, 0xdc, 0xdd, 0x9a, 0x8c, 0x5b, 0xeb, 0x39, 0x13, 0xea, 0x7a, 0x3f, 0xab, 0x54, 0x4e,
+	0xa1, 0xcc, 0x2a, 0x82, 0xd6, 0xa0, 0x76, 0x41, 0x86, 0xc4, 0x7d, 0x49, 0x98, 0xd8, 0xf8, 0x06,
+	0xaa, 0x41, 0x9c, 0x6e, 0xa3, 0x80, 0x56, 0x21, 0x15, 0x73, 0xa3, 0x88, 0xb6, 0x20, 0xcf, 0x43,
+	0xa3, 0xa4, 0xfc, 0xb1, 0x00, 0xe8, 0x0c, 0x13, 0xf3, 0xc0, 0x25, 0x54, 0x37, 0xa8, 0xd8, 0x40,
+	0xf9, 0x75, 0x61, 0xba, 0x99, 0x1f, 0xde, 0xa6, 0x64, 0xa8, 0x3a, 0x98, 0xea, 0xa6, 0x4e, 0x75,
+	0x4e, 0xbc, 0xba, 0x9a, 0xc8, 0x8c, 0x98, 0xee, 0x4b, 0xa2, 0x25, 0xf3, 0x25, 0x3e, 0x5f, 0x73,
+	0x5f, 0x92, 0x53, 0xa1, 0x9a, 0x72, 0xee, 0x3f, 0x45, 0xd8, 0x8c, 0x99, 0x3f, 0x9b, 0x89, 0xfc,
+	0xe3, 0x69, 0xbc, 0xf3, 0x54, 0x2f, 0xdc, 0x80, 0xea, 0xc5, 0x1c, 0xaa, 0xbf, 0x4e, 0x08, 0xbb,
+	0x78, 0x9f, 0x0b, 0x9f, 0x6a, 0x9f, 0x51, 0x1f, 0xee, 0xe7, 0xfb, 0x9c, 0x6b, 0x49, 0xf7, 0x27,
+	0xe3, 0x96, 0x9c, 0x63, 0x35, 0xee, 0x4d, 0x72, 0x7f, 0xe1, 0xdc, 0x2d, 0x9a, 0x94, 0xf2, 0xcf,
+	0x02, 0xa0, 0xb4, 0x11, 0xc7, 0xb3, 0x31, 0xc5, 0xf2, 0x9f, 0x53, 0x3c, 0xf9, 0x7f, 0x54, 0xe9,
+	0x63, 0x31, 0x2b, 0x80, 0x0a, 0x3b, 0x05, 0x1d, 0x63, 0x28, 0x6b, 0xb7, 0xff, 0x54, 0x7d, 0x0d,
+	0xc0, 0x92, 0xd2, 0x07, 0x98, 0x9d, 0x11, 0x1e, 0x47, 0x77, 0x65, 0x32, 0x6e, 0x49, 0xa7, 0x91,
+	0xf6, 0xe8, 0x50, 0x95, 0x04, 0xe0, 0xc8, 0x9c, 0x3a, 0x35, 0xa0, 0xc6, 0x9c, 0x0a, 0x90, 0x7c,
+	0x7c, 0x7b, 0xc7, 0x4d, 0xa8, 0x08, 0xbb, 0xa2, 0xa1, 0xc7, 0xe2, 0xd4, 0xc9, 0x1f, 0x0a, 0x49,
+	0x5f, 0x40, 0xdf, 0x85, 0x4d, 0x2f, 0xec, 0xdb, 0x96, 0xa1, 0xf9, 0x98, 0x98, 0xf8, 0xd5, 0xb5,
+	0x1b, 0x06, 0x5a, 0x80, 0x71, 0x74, 0xae, 0xeb, 0xea, 0x46, 0x34, 0xab, 0x26, 0x93, 0x67, 0x18,
+	0x9b, 0x2c, 0x3b, 0xdd, 0x30, 0xdc, 0x90, 0x50, 0x16, 0x4e, 0x2a, 0xbb, 0x4e, 0xa4, 0xed, 0x1d,
+	0xab, 0x92, 0x00, 0xf4, 0x86, 0x99, 0xd3, 0x57, 0xca, 0x9e, 0xbe, 0x0d, 0x58, 0x8a, 0x4e, 0x5d,
+	0x79, 0xbb, 0xb4, 0x2b, 0xa9, 0x91, 0xa0, 0xfc, 0x2c, 0xdd, 0xb2, 0xd0, 0x2e, 0x2c, 0xa5, 0x3f,
+	0xe7, 0x48, 0x70, 0x27, 0xba, 0xce, 0x71, 0x88, 0x1a, 0x01, 0x6e, 0xf0, 0x65, 0x53, 0xdc, 0xdc,
+	0xee, 0xf7, 0x11, 0x1a, 0xdb, 0x26, 0x2c, 0x07, 0xd8, 0xf0, 0x31, 0x15, 0xe4, 0x13, 0x92, 0x72,
+	0x0e, 0xdf, 0x4c, 0xfb, 0xe2, 0xad, 0x35, 0x26, 0x1d, 0x4b, 0xdf, 0xf3, 0x5d, 0xf7, 0x52, 0x14,
+	0x3d, 0x12, 0x32, 0x6c, 0x2d, 0x66, 0xd8, 0xaa, 0xfc, 0x08, 0xd6, 0x3a, 0x9e, 0x27, 0xe8, 0x72,
+	0x3e, 0xf2, 0xb0, 0x89, 0x9e, 0x40, 0x99, 0x95, 0x44, 0x7c, 0x4a, 0x5b, 0x99, 0xf0, 0x67, 0xf1,
+	0x2a, 0x07, 0x2b, 0x2f, 0xe0, 0xf3, 0x8b, 0x00, 0xfb, 0x62, 0xa2, 0x43, 0xa9, 0x6e, 0x5c, 0x39,
+	0x98, 0xd0, 0x3b, 0x59, 0x43, 0x0d, 0x28, 0x85, 0xbe, 0x25, 0xca, 0xce, 0x86, 0xca, 0x5f, 0x0b,
+	0x80, 0x44, 0x8b, 0x49, 0xf9, 0xb9, 0x9b, 0x75, 0x04, 0xe5, 0xbe, 0x6b, 0x8e, 0x84, 0x79, 0x3e,
+	0x46, 0xcf, 0xa0, 0xa6, 0x27, 0x41, 0xb3, 0x6b, 0x4a, 0x69, 0xb7, 0xb6, 0xbf, 0x93, 0xb1, 0x97,
+	0x9b, 0xa3, 0x9a, 0x5e, 0xca, 0x1a, 0x60, 0x80, 0x09, 0xd5, 0x4c, 0x9d, 0x62, 0x7e, 0x19, 0x28,
+	0x75, 0xeb, 0x6f, 0xc7, 0xad, 0x2a, 0x53, 0x1e, 0xea, 0x14, 0xab, 0xc9, 0x48, 0xf9, 0x25, 0xac,
+	0xa7, 0x72, 0x52, 0xb1, 0x6e, 0x50, 0xcb, 0x25, 0x77, 0x4b, 0x6a, 0x03, 0x96, 0xb0, 0xe3, 0xfe,
+	0x2a, 0x2e, 0x5a, 0x24, 0x28, 0x21, 0x6c, 0x0a, 0x0f, 0x9c, 0xdf, 0x47, 0xe4, 0xda, 0xa2, 0xfa,
+	0xdd, 0x9d, 0xa4, 0x7b, 0x48, 0xf4, 0xa9, 0xa8, 0xbd, 0x1d, 0xb7, 0xe2, 0xd6, 0x91, 0xf4, 0x10,
+	0xe5, 0x45, 0x92, 0xd8, 0x19, 0xa6, 0x4f, 0xe3, 0xfb, 0xf5, 0x9d, 0x77, 0x2b, 0x75, 0x06, 0xf9,
+	0x58, 0xd1, 0x13, 0x32, 0x74, 0x0c, 0x76, 0x5f, 0xb1, 0xb1, 0x79, 0x57, 0x32, 0x6c, 0xc2, 0x32,
+	0xd5, 0xfd, 0x81, 0x38, 0x6e, 0x92, 0x2a, 0x24, 0xe5, 0x6f, 0x45, 0x80, 0xb3, 0x51, 0x40, 0xb1,
+	0x73, 0x44, 0x2e, 0x5d, 0x59, 0x4a, 0x1a, 0xa9, 0xfc, 0x97, 0xe4, 0x1b, 0x7e, 0x0f, 0x20, 0xa0,
+	0xba, 0x4f, 0xb1, 0xa9, 0xe9, 0xd1, 0xdd, 0xb8, 0xa4, 0x4a, 0x42, 0xd3, 0xa1, 0xe8, 0x01, 0x54,
+	0x48, 0xe8, 0x68, 0x86, 0x17, 0x72, 0xdb, 0xa5, 0x2e, 0x4c, 0xc6, 0xad, 0xe5, 0xe7, 0xa1, 0x73,
+	0xd0, 0xbb, 0x50, 0x97, 0x49, 0xe8, 0x1c, 0x78, 0x21, 0x7f, 0x92, 0xb8, 0xda, 0x35, 0xf6, 0x03,
+	0xcb, 0x25, 0xa2, 0xb3, 0x49, 0x03, 0xf7, 0x27, 0x91, 0x82, 0xdd, 0x2a, 0x98, 0x8d, 0x81, 0xeb,
+	0xbb, 0x21, 0xb5, 0x88, 0x60, 0x94, 0x5a, 0x27, 0xa1, 0xf3, 0x34, 0xd6, 0xa1, 0x47, 0xd0, 0x70,
+	0x3d, 0xec, 0xeb, 0xd4, 0x22, 0x03, 0x2d, 0xe0, 0x41, 0xf3, 0xdb, 0xa2, 0xa4, 0xae, 0x25, 0xfa,
+	0x28, 0x17, 0xf4, 0x05, 0x48, 0x57, 0x6e, 0x40, 0xa3, 0xb6, 0xb6, 0xcc, 0x31, 0x55, 0xa6, 0xe0,
+	0xfb, 0x83, 0xa0, 0xac, 0xfb, 0xc6, 0x55, 0xb3, 0x12, 0x95, 0x9a, 0x8d, 0xd9, 0xe7, 0x20, 0x0e,
+	0xae, 0x1a, 0x7d, 0x0e, 0x84, 0x88, 0xb6, 0xa0, 0x72, 0x6d, 0x04, 0x9a, 0x8f, 0x2f, 0x9b, 0x52,
+	0x54, 0xba, 0x6b, 0x23, 0x50, 0xf1, 0x25, 0x4b, 0xa9, 0x1f, 0x5a, 0xb6, 0xa9, 0x51, 0xcb, 0xc1,
+	0x4d, 0x88, 0xca, 0xc2, 0x35, 0xe7, 0x96, 0x83, 0xbf, 0x7a, 0x05, 0xab, 0xb3, 0x3b, 0x81, 0x56,
+	0x40, 0xba, 0x20, 0x26, 0xbe, 0xb4, 0x08, 0x66, 0xb7, 0x4e, 0x76, 0x0d, 0x9d, 0x9e, 0xb3, 0x46,
+	0x01, 0x35, 0xa0, 0x9e, 0x3e, 0x20, 0x8d, 0x22, 0x5a, 0x87, 0xb5, 0x39, 0x42, 0x37, 0x4a, 0x0c,
+	0x96, 0xa6, 0x5b, 0xa3, 0xcc, 0x2c, 0xa5, 0x08, 0xd2, 0x58, 0xda, 0xff, 0x53, 0x15, 0x1a, 0xa7,
+	0x31, 0x21, 0xce, 0xb0, 0x7f, 0x6d, 0x19, 0x18, 0xfd, 0x6e, 0xf1, 0x13, 0x1e, 0x7d, 0x27, 0xc3,
+	0xa2, 0x45, 0xd0, 0x76, 0xcc, 0x8f, 0xbd, 0xdb, 0x2c, 0x61, 0x34, 0x72, 0x73, 0xdf, 0xb7, 0xe8,
+	0xeb, 0x8c, 0x9d, 0x1c, 0x54, 0xe2, 0xf5, 0xab, 0x1b, 0xa2, 0x99, 0xc3, 0xdf, 0x2e, 0x7c, 0xf4,
+	0xa1, 0x6f, 0x67, 0xcc, 0x2c, 0x40, 0x26, 0x8e, 0xdb, 0xb7, 0x58, 0xc1, 0x9c, 0xff, 0x62, 0xee,
+	0x8d, 0x87, 0x76, 0xde, 0xf3, 0x58, 0x8b, 0x1d, 0x3d, 0x7c, 0x2f, 0x8e, 0x99, 0xb7, 0xf3, 0xde,
+	0x1e, 0xe8, 0x5b, 0xd9, 0xea, 0x64, 0x40, 0x89, 0xa3, 0x47, 0x37, 0x03, 0x33, 0x6f, 0xbf, 0x59,
+	0xf4, 0x78, 0x40, 0x8b, 0x59, 0x30, 0x0b, 0x4c, 0xbc, 0x3e, 0xbe, 0xf9, 0x02, 0x91, 0x67, 0xf6,
+	0xee, 0x9c, 0x93, 0x67, 0x16, 0xf4, 0x8e, 0x3c, 0x73, 0xc1, 0xcc, 0xdb, 0x4f, 0x67, 0xae, 0x95,
+	0xe8, 0x61, 0x6e, 0x85, 0xc4, 0x6c, 0x62, 0x5f, 0x79, 0x0f, 0x8a, 0x19, 0x3e, 0x4e, 0x2e, 0xc9,
+	0x68, 0x3b, 0x17, 0xde, 0x31, 0xa6, 0x0c, 0xb8, 0xff, 0x0e, 0x04, 0x33, 0x76, 0x9e, 0x6e, 0xd9,
+	0x28, 0xfb, 0x13, 0x60, 0x3a, 0x99, 0x98, 0xfc, 0xf2, 0xdd, 0x20, 0xcf, 0x1e, 0x75, 0x77, 0x7f,
+	0xbe, 0x13, 0x61, 0x28, 0x36, 0xae, 0xf6, 0xf8, 0x70, 0x8f, 0xfd, 0x29, 0x1c, 0x0e, 0xf6, 0x66,
+	0x7f, 0x32, 0xf6, 0x97, 0xf9, 0x3f, 0xc1, 0x27, 0xff, 0x1d, 0x00, 0x37, 0xba, 0xf5, 0xe5, 0x7d,
+	0x14, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -1680,6 +2081,10 @@ type MessengerServiceClient interface {
 	ParseDeepLink(ctx context.Context, in *ParseDeepLink_Request, opts ...grpc.CallOption) (*ParseDeepLink_Reply, error)
 	// SendContactRequest takes the payload received from ParseDeepLink and send a contact request using the Berty Protocol.
 	SendContactRequest(ctx context.Context, in *SendContactRequest_Request, opts ...grpc.CallOption) (*SendContactRequest_Reply, error)
+	// InstanceMutualScanCode returns a one-time code to be shown to a contact met in person, incoming requests proving they scanned it are accepted automatically.
+	InstanceMutualScanCode(ctx context.Context, in *InstanceMutualScanCode_Request, opts ...grpc.CallOption) (*InstanceMutualScanCode_Reply, error)
+	// MutualScanComplete takes the payload of a scanned mutual scan code, pre-accepts the contact and sends a contact request to it.
+	MutualScanComplete(ctx context.Context, in *MutualScanComplete_Request, opts ...grpc.CallOption) (*MutualScanComplete_Reply, error)
 	// SendMessage sends a message to a group
 	SendMessage(ctx context.Context, in *SendMessage_Request, opts ...grpc.CallOption) (*SendMessage_Reply, error)
 	// SendAck sends an acknowledge payload for given message id
@@ -1740,6 +2145,24 @@ func (c *messengerServiceClient) SendContactRequest(ctx context.Context, in *Sen
 	return out, nil
 }
 
+func (c *messengerServiceClient) InstanceMutualScanCode(ctx context.Context, in *InstanceMutualScanCode_Request, opts ...grpc.CallOption) (*InstanceMutualScanCode_Reply, error) {
+	out := new(InstanceMutualScanCode_Reply)
+	err := c.cc.Invoke(ctx, "/berty.messenger.MessengerService/InstanceMutualScanCode", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messengerServiceClient) MutualScanComplete(ctx context.Context, in *MutualScanComplete_Request, opts ...grpc.CallOption) (*MutualScanComplete_Reply, error) {
+	out := new(MutualScanComplete_Reply)
+	err := c.cc.Invoke(ctx, "/berty.messenger.MessengerService/MutualScanComplete", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *messengerServiceClient) SendMessage(ctx context.Context, in *SendMessage_Request, opts ...grpc.CallOption) (*SendMessage_Reply, error) {
 	out := new(SendMessage_Reply)
 	err := c.cc.Invoke(ctx, "/berty.messenger.MessengerService/SendMessage", in, out, opts...)
@@ -1782,6 +2205,10 @@ type MessengerServiceServer interface {
 	ParseDeepLink(context.Context, *ParseDeepLink_Request) (*ParseDeepLink_Reply, error)
 	// SendContactRequest takes the payload received from ParseDeepLink and send a contact request using the Berty Protocol.
 	SendContactRequest(context.Context, *SendContactRequest_Request) (*SendContactRequest_Reply, error)
+	// InstanceMutualScanCode returns a one-time code to be shown to a contact met in person, incoming requests proving they scanned it are accepted automatically.
+	InstanceMutualScanCode(context.Context, *InstanceMutualScanCode_Request) (*InstanceMutualScanCode_Reply, error)
+	// MutualScanComplete takes the payload of a scanned mutual scan code, pre-accepts the contact and sends a contact request to it.
+	MutualScanComplete(context.Context, *MutualScanComplete_Request) (*MutualScanComplete_Reply, error)
 	// SendMessage sends a message to a group
 	SendMessage(context.Context, *SendMessage_Request) (*SendMessage_Reply, error)
 	// SendAck sends an acknowledge payload for given message id
@@ -1808,6 +2235,12 @@ func (*UnimplementedMessengerServiceServer) ParseDeepLink(ctx context.Context, r
 func (*UnimplementedMessengerServiceServer) SendContactRequest(ctx context.Context, req *SendContactRequest_Request) (*SendContactRequest_Reply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SendContactRequest not implemented")
 }
+func (*UnimplementedMessengerServiceServer) InstanceMutualScanCode(ctx context.Context, req *InstanceMutualScanCode_Request) (*InstanceMutualScanCode_Reply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method InstanceMutualScanCode not implemented")
+}
+func (*UnimplementedMessengerServiceServer) MutualScanComplete(ctx context.Context, req *MutualScanComplete_Request) (*MutualScanComplete_Reply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MutualScanComplete not implemented")
+}
 func (*UnimplementedMessengerServiceServer) SendMessage(ctx context.Context, req *SendMessage_Request) (*SendMessage_Reply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SendMessage not implemented")
 }
@@ -1912,6 +2345,42 @@ func _MessengerService_SendContactRequest_Handler(srv interface{}, ctx context.C
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MessengerService_InstanceMutualScanCode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(InstanceMutualScanCode_Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessengerServiceServer).InstanceMutualScanCode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/berty.messenger.MessengerService/InstanceMutualScanCode",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessengerServiceServer).InstanceMutualScanCode(ctx, req.(*InstanceMutualScanCode_Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MessengerService_MutualScanComplete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MutualScanComplete_Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessengerServiceServer).MutualScanComplete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/berty.messenger.MessengerService/MutualScanComplete",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessengerServiceServer).MutualScanComplete(ctx, req.(*MutualScanComplete_Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _MessengerService_SendMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(SendMessage_Request)
 	if err := dec(in); err != nil {
@@ -1990,6 +2459,14 @@ var _MessengerService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "SendContactRequest",
 			Handler:    _MessengerService_SendContactRequest_Handler,
 		},
+		{
+			MethodName: "InstanceMutualScanCode",
+			Handler:    _MessengerService_InstanceMutualScanCode_Handler,
+		},
+		{
+			MethodName: "MutualScanComplete",
+			Handler:    _MessengerService_MutualScanComplete_Handler,
+		},
 		{
 			MethodName: "SendMessage",
 			Handler:    _MessengerService_SendMessage_Handler,
@@ -0,0 +1,203 @@
+package bertymessenger
+
+import (
+	"context"
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"time"
+
+	"berty.tech/berty/v2/go/pkg/bertytypes"
+	"berty.tech/berty/v2/go/pkg/errcode"
+	"github.com/gogo/protobuf/proto"
+	"go.uber.org/zap"
+)
+
+// MutualScanCodeTTL is the duration during which incoming requests proving the
+// knowledge of a mutual scan code are accepted automatically.
+// The secret of a code is only kept in memory, requests received after a
+// restart or once the service is closed must be accepted manually.
+const MutualScanCodeTTL = time.Minute * 10
+
+const mutualScanSecretLength = 32
+
+// InstanceMutualScanCode returns a one-time code to be scanned in person
+func (s *service) InstanceMutualScanCode(ctx context.Context, req *InstanceMutualScanCode_Request) (*InstanceMutualScanCode_Reply, error) {
+	if req == nil {
+		req = &InstanceMutualScanCode_Request{}
+	}
+
+	bertyID, config, err := s.getOwnBertyID(ctx, req.DisplayName, false, req.IncludeAddrs)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := make([]byte, mutualScanSecretLength)
+	if _, err := crand.Read(secret); err != nil {
+		return nil, errcode.ErrCryptoRandomGeneration.Wrap(err)
+	}
+
+	if err := s.watchMutualScan(config.AccountGroupPK, secret); err != nil {
+		return nil, err
+	}
+
+	ret := InstanceMutualScanCode_Reply{
+		BertyMutualScanCode: &BertyMutualScanCode{
+			BertyID: bertyID,
+			Secret:  secret,
+		},
+	}
+	payload, _ := proto.Marshal(ret.BertyMutualScanCode)
+	ret.BertyMutualScanCodePayload = base64.StdEncoding.EncodeToString(payload)
+
+	// create QRCodes with standalone display_name variable
+	lightCode := BertyMutualScanCode{
+		BertyID: &BertyID{
+			PublicRendezvousSeed: bertyID.PublicRendezvousSeed,
+			AccountPK:            bertyID.AccountPK,
+			Addrs:                bertyID.Addrs,
+		},
+		Secret: secret,
+	}
+	lightCodeBytes, _ := proto.Marshal(&lightCode)
+	v := url.Values{}
+	v.Set("code", url.QueryEscape(base64.StdEncoding.EncodeToString(lightCodeBytes))) // double-encoding to keep "+" as "+" and not as spaces
+	if bertyID.DisplayName != "" {
+		v.Set("name", bertyID.DisplayName)
+	}
+	ret.DeepLink = fmt.Sprintf("berty://scan/#%s", v.Encode())
+
+	return &ret, nil
+}
+
+// MutualScanComplete pre-accepts the owner of a scanned code and sends it a contact request
+func (s *service) MutualScanComplete(ctx context.Context, req *MutualScanComplete_Request) (*MutualScanComplete_Reply, error) {
+	if req == nil || req.BertyMutualScanCode == nil || len(req.BertyMutualScanCode.Secret) == 0 {
+		return nil, errcode.ErrMissingInput
+	}
+
+	config, err := s.protocol.InstanceGetConfiguration(ctx, &bertytypes.InstanceGetConfiguration_Request{})
+	if err != nil {
+		return nil, errcode.TODO.Wrap(err)
+	}
+
+	ownMetadata, err := proto.Marshal(&MutualScanContactMetadata{
+		Proof:       mutualScanProof(req.BertyMutualScanCode.Secret, config.AccountPK),
+		OwnMetadata: req.OwnMetadata,
+	})
+	if err != nil {
+		return nil, errcode.ErrSerialization.Wrap(err)
+	}
+
+	// Enqueuing an outgoing request marks the contact as pre-accepted, its own
+	// request will be completed automatically by the protocol
+	if _, err := s.SendContactRequest(ctx, &SendContactRequest_Request{
+		BertyID:     req.BertyMutualScanCode.BertyID,
+		Metadata:    req.Metadata,
+		OwnMetadata: ownMetadata,
+	}); err != nil {
+		return nil, err
+	}
+
+	return &MutualScanComplete_Reply{}, nil
+}
+
+// watchMutualScan accepts the first incoming request proving the knowledge of
+// secret, until the code expires or the service is closed
+func (s *service) watchMutualScan(accountGroupPK []byte, secret []byte) error {
+	ctx, cancel := context.WithTimeout(s.ctx, MutualScanCodeTTL)
+
+	sub, err := s.protocol.GroupMetadataSubscribe(ctx, &bertytypes.GroupMetadataSubscribe_Request{GroupPK: accountGroupPK})
+	if err != nil {
+		cancel()
+		return errcode.TODO.Wrap(err)
+	}
+
+	go func() {
+		defer cancel()
+
+		for {
+			evt, err := sub.Recv()
+			if err != nil {
+				if ctx.Err() == nil {
+					s.logger.Warn("mutual scan subscription closed", zap.Error(err))
+				}
+				return
+			}
+
+			if evt.Metadata == nil || evt.Metadata.EventType != bertytypes.EventTypeAccountContactRequestIncomingReceived {
+				continue
+			}
+
+			e := &bertytypes.AccountContactRequestReceived{}
+			if err := e.Unmarshal(evt.Event); err != nil {
+				continue
+			}
+
+			if !checkMutualScanProof(secret, e.ContactPK, e.ContactMetadata) {
+				continue
+			}
+
+			if _, err := s.protocol.ContactRequestAccept(ctx, &bertytypes.ContactRequestAccept_Request{ContactPK: e.ContactPK}); err != nil {
+				s.logger.Error("unable to accept mutual scan contact request", zap.Error(err))
+				continue
+			}
+
+			s.logger.Info("mutual scan contact request accepted")
+			return
+		}
+	}()
+
+	return nil
+}
+
+func ParseMutualScanURLQuery(query url.Values) (*ParseDeepLink_Reply, error) {
+	ret := ParseDeepLink_Reply{
+		Kind:                ParseDeepLink_BertyMutualScanCode,
+		BertyMutualScanCode: &BertyMutualScanCode{},
+	}
+
+	code := query.Get("code")
+	if code == "" {
+		return nil, errcode.ErrMessengerInvalidDeepLink
+	}
+
+	payload, err := base64.StdEncoding.DecodeString(code)
+	if err != nil {
+		return nil, errcode.ErrMessengerInvalidDeepLink.Wrap(err)
+	}
+
+	if err := proto.Unmarshal(payload, ret.BertyMutualScanCode); err != nil {
+		return nil, errcode.ErrMessengerInvalidDeepLink.Wrap(err)
+	}
+
+	bertyID := ret.BertyMutualScanCode.BertyID
+	if bertyID == nil || len(bertyID.PublicRendezvousSeed) == 0 || len(bertyID.AccountPK) == 0 || len(ret.BertyMutualScanCode.Secret) != mutualScanSecretLength {
+		return nil, errcode.ErrMessengerInvalidDeepLink
+	}
+
+	if name := query.Get("name"); name != "" {
+		bertyID.DisplayName = name
+	}
+
+	return &ret, nil
+}
+
+func mutualScanProof(secret []byte, accountPK []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write(accountPK)
+
+	return mac.Sum(nil)
+}
+
+func checkMutualScanProof(secret []byte, accountPK []byte, metadata []byte) bool {
+	m := &MutualScanContactMetadata{}
+	if err := proto.Unmarshal(metadata, m); err != nil || len(m.Proof) == 0 {
+		return false
+	}
+
+	return hmac.Equal(m.Proof, mutualScanProof(secret, accountPK))
+}
@@ -1,17 +1,29 @@
 package bertymessenger
 
 import (
+	"context"
 	"time"
 
 	"berty.tech/berty/v2/go/pkg/bertyprotocol"
 	"go.uber.org/zap"
 )
 
-func New(client bertyprotocol.ProtocolServiceClient, opts *Opts) MessengerServiceServer {
+// Service is a MessengerServiceServer which must be closed once unused, to
+// stop the background tasks it started
+type Service interface {
+	MessengerServiceServer
+
+	Close() error
+}
+
+func New(client bertyprotocol.ProtocolServiceClient, opts *Opts) Service {
+	ctx, cancel := context.WithCancel(context.Background())
 	svc := service{
 		protocol:  client,
 		logger:    opts.Logger,
 		startedAt: time.Now(),
+		ctx:       ctx,
+		cancel:    cancel,
 	}
 	return &svc
 }
@@ -24,6 +36,13 @@ type service struct {
 	logger    *zap.Logger
 	protocol  bertyprotocol.ProtocolServiceClient
 	startedAt time.Time
+	ctx       context.Context
+	cancel    context.CancelFunc
 }
 
-var _ MessengerServiceServer = (*service)(nil)
+var _ Service = (*service)(nil)
+
+func (s *service) Close() error {
+	s.cancel()
+	return nil
+}
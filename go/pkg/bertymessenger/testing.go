@@ -30,5 +30,8 @@ func TestingService(ctx context.Context, t *testing.T, opts *TestingServiceOpts)
 		time.Sleep(10 * time.Millisecond)
 	}
 	server := New(opts.Client, &Opts{Logger: opts.Logger})
-	return server, cleanup
+	return server, func() {
+		_ = server.Close()
+		cleanup()
+	}
 }
@@ -0,0 +1,209 @@
+package bertyprotocol
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"fmt"
+	"sync"
+	"time"
+
+	"berty.tech/berty/v2/go/internal/cryptoutil"
+	"berty.tech/berty/v2/go/pkg/bertytypes"
+	"berty.tech/berty/v2/go/pkg/errcode"
+	"github.com/ipfs/go-ipfs/keystore"
+	"github.com/libp2p/go-libp2p-core/crypto"
+	"golang.org/x/crypto/nacl/box"
+)
+
+// accountRecoverySecret returns the secret allowing to rebuild both account
+// keys, the seed of the account key followed by the one of the proof key
+func accountRecoverySecret(devKS DeviceKeystore) ([]byte, error) {
+	sk, err := devKS.AccountPrivKey()
+	if err != nil {
+		return nil, errcode.ErrInternal.Wrap(err)
+	}
+
+	proofSK, err := devKS.AccountProofPrivKey()
+	if err != nil {
+		return nil, errcode.ErrInternal.Wrap(err)
+	}
+
+	skSeed, err := cryptoutil.SeedFromEd25519PrivateKey(sk)
+	if err != nil {
+		return nil, err
+	}
+
+	proofSeed, err := cryptoutil.SeedFromEd25519PrivateKey(proofSK)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(append([]byte(nil), skSeed...), proofSeed...), nil
+}
+
+// accountKeysFromRecoverySecret rebuilds the account keys from a secret
+// returned by accountRecoverySecret
+func accountKeysFromRecoverySecret(secret []byte) (crypto.PrivKey, crypto.PrivKey, error) {
+	if len(secret) != ed25519.SeedSize*2 {
+		return nil, nil, errcode.ErrInvalidInput.Wrap(fmt.Errorf("invalid recovery secret size"))
+	}
+
+	keys := make([]crypto.PrivKey, 2)
+	for i := range keys {
+		stdKey := ed25519.NewKeyFromSeed(secret[i*ed25519.SeedSize : (i+1)*ed25519.SeedSize])
+
+		sk, _, err := crypto.KeyPairFromStdKey(&stdKey)
+		if err != nil {
+			return nil, nil, errcode.ErrCryptoKeyConversion.Wrap(err)
+		}
+
+		keys[i] = sk
+	}
+
+	return keys[0], keys[1], nil
+}
+
+// NewDeviceKeystoreFromRecoverySecret creates a new deviceKeystore instance whose account keys are rebuilt from a recovery secret, the device key is generated when required
+func NewDeviceKeystoreFromRecoverySecret(ks keystore.Keystore, secret []byte) (DeviceKeystore, error) {
+	sk, proofSK, err := accountKeysFromRecoverySecret(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return newDeviceKeystoreWithAccountKeys(ks, sk, proofSK)
+}
+
+// sealRecoveryShare encrypts a recovery share from ownSK to recipientPK
+func sealRecoveryShare(ownSK crypto.PrivKey, recipientPK crypto.PubKey, share *bertytypes.RecoveryShare) ([]byte, []byte, error) {
+	mongPriv, mongPub, err := cryptoutil.EdwardsToMontgomery(ownSK, recipientPK)
+	if err != nil {
+		return nil, nil, errcode.ErrCryptoKeyConversion.Wrap(err)
+	}
+
+	payload, err := share.Marshal()
+	if err != nil {
+		return nil, nil, errcode.ErrSerialization.Wrap(err)
+	}
+
+	nonce, err := cryptoutil.GenerateNonce()
+	if err != nil {
+		return nil, nil, errcode.ErrCryptoNonceGeneration.Wrap(err)
+	}
+
+	return nonce[:], box.Seal(nil, payload, nonce, mongPub, mongPriv), nil
+}
+
+// openRecoveryShare decrypts a recovery share sent by senderPK to ownSK
+func openRecoveryShare(ownSK crypto.PrivKey, senderPK crypto.PubKey, nonceBytes []byte, encryptedShare []byte) (*bertytypes.RecoveryShare, error) {
+	mongPriv, mongPub, err := cryptoutil.EdwardsToMontgomery(ownSK, senderPK)
+	if err != nil {
+		return nil, errcode.ErrCryptoKeyConversion.Wrap(err)
+	}
+
+	nonce, err := cryptoutil.NonceSliceToArray(nonceBytes)
+	if err != nil {
+		return nil, errcode.ErrCryptoDecrypt.Wrap(err)
+	}
+
+	payload, ok := box.Open(nil, encryptedShare, nonce, mongPub, mongPriv)
+	if !ok {
+		return nil, errcode.ErrCryptoDecrypt
+	}
+
+	share := &bertytypes.RecoveryShare{}
+	if err := share.Unmarshal(payload); err != nil {
+		return nil, errcode.ErrDeserialization.Wrap(err)
+	}
+
+	return share, nil
+}
+
+// checkRecoverySecret verifies that secret rebuilds the account identified
+// by accountPK
+func checkRecoverySecret(secret []byte, accountPK []byte) bool {
+	sk, _, err := accountKeysFromRecoverySecret(secret)
+	if err != nil {
+		return false
+	}
+
+	pk, err := sk.GetPublic().Raw()
+	if err != nil {
+		return false
+	}
+
+	return bytes.Equal(pk, accountPK)
+}
+
+// recoveryRequestTTL is the time during which a request received from a
+// contact allows to release its share
+const recoveryRequestTTL = 24 * time.Hour
+
+// recoveryRequests holds the requests to release a share received from
+// contacts over authenticated streams, keyed by lost account then by the new
+// account of its owner
+type recoveryRequests struct {
+	requests   map[string]map[string]time.Time
+	muRequests sync.Mutex
+}
+
+func newRecoveryRequests() *recoveryRequests {
+	return &recoveryRequests{requests: map[string]map[string]time.Time{}}
+}
+
+func (r *recoveryRequests) add(lostPK []byte, newPK []byte, receivedAt time.Time) {
+	r.muRequests.Lock()
+	defer r.muRequests.Unlock()
+
+	if _, ok := r.requests[string(lostPK)]; !ok {
+		r.requests[string(lostPK)] = map[string]time.Time{}
+	}
+
+	r.requests[string(lostPK)][string(newPK)] = receivedAt
+}
+
+func (r *recoveryRequests) has(lostPK []byte, newPK []byte, now time.Time) bool {
+	r.muRequests.Lock()
+	defer r.muRequests.Unlock()
+
+	receivedAt, ok := r.requests[string(lostPK)][string(newPK)]
+
+	return ok && now.Sub(receivedAt) < recoveryRequestTTL
+}
+
+func (r *recoveryRequests) remove(lostPK []byte, newPK []byte) {
+	r.muRequests.Lock()
+	defer r.muRequests.Unlock()
+
+	delete(r.requests[string(lostPK)], string(newPK))
+	if len(r.requests[string(lostPK)]) == 0 {
+		delete(r.requests, string(lostPK))
+	}
+}
+
+// list returns the pending requests, dropping the expired ones
+func (r *recoveryRequests) list(now time.Time) []*bertytypes.AccountRecoveryRequest {
+	r.muRequests.Lock()
+	defer r.muRequests.Unlock()
+
+	ret := []*bertytypes.AccountRecoveryRequest(nil)
+	for lostPK, requests := range r.requests {
+		for newPK, receivedAt := range requests {
+			if now.Sub(receivedAt) >= recoveryRequestTTL {
+				delete(requests, newPK)
+				continue
+			}
+
+			ret = append(ret, &bertytypes.AccountRecoveryRequest{
+				AccountPK:  []byte(lostPK),
+				ContactPK:  []byte(newPK),
+				ReceivedAt: receivedAt.Unix(),
+			})
+		}
+
+		if len(requests) == 0 {
+			delete(r.requests, lostPK)
+		}
+	}
+
+	return ret
+}
@@ -2,9 +2,12 @@ package bertyprotocol
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"berty.tech/berty/v2/go/pkg/bertytypes"
 	"berty.tech/berty/v2/go/pkg/errcode"
+	cid "github.com/ipfs/go-cid"
 )
 
 func (s *service) AppMetadataSend(ctx context.Context, req *bertytypes.AppMetadataSend_Request) (*bertytypes.AppMetadataSend_Reply, error) {
@@ -26,9 +29,55 @@ func (s *service) AppMessageSend(ctx context.Context, req *bertytypes.AppMessage
 		return nil, errcode.ErrGroupMissing.Wrap(err)
 	}
 
-	if _, err := g.MessageStore().AddMessage(ctx, req.Payload); err != nil {
+	if req.TTL < 0 {
+		return nil, errcode.ErrInvalidInput.Wrap(fmt.Errorf("invalid ttl"))
+	}
+
+	// the shortest lifetime applies
+	ttl := time.Duration(req.TTL) * time.Second
+	if groupTTL := g.MetadataStore().GetMessageTTL(); groupTTL > 0 && (ttl == 0 || groupTTL < ttl) {
+		ttl = groupTTL
+	}
+
+	if _, err := g.MessageStore().AddEphemeralMessage(ctx, req.Payload, ttl); err != nil {
 		return nil, errcode.ErrOrbitDBAppend.Wrap(err)
 	}
 
 	return &bertytypes.AppMessageSend_Reply{}, nil
 }
+
+func (s *service) AppMessageRedact(ctx context.Context, req *bertytypes.AppMessageRedact_Request) (*bertytypes.AppMessageRedact_Reply, error) {
+	g, err := s.getContextGroupForID(req.GroupPK)
+	if err != nil {
+		return nil, errcode.ErrGroupMissing.Wrap(err)
+	}
+
+	id, err := cid.Cast(req.MessageID)
+	if err != nil {
+		return nil, errcode.ErrDeserialization.Wrap(err)
+	}
+
+	if _, err := g.MessageStore().AddRedaction(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return &bertytypes.AppMessageRedact_Reply{}, nil
+}
+
+func (s *service) AppMessageEdit(ctx context.Context, req *bertytypes.AppMessageEdit_Request) (*bertytypes.AppMessageEdit_Reply, error) {
+	g, err := s.getContextGroupForID(req.GroupPK)
+	if err != nil {
+		return nil, errcode.ErrGroupMissing.Wrap(err)
+	}
+
+	id, err := cid.Cast(req.MessageID)
+	if err != nil {
+		return nil, errcode.ErrDeserialization.Wrap(err)
+	}
+
+	if _, err := g.MessageStore().AddEdit(ctx, id, req.Payload); err != nil {
+		return nil, err
+	}
+
+	return &bertytypes.AppMessageEdit_Reply{}, nil
+}
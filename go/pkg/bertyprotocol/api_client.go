@@ -27,6 +27,16 @@ func (s *service) InstanceGetConfiguration(ctx context.Context, req *bertytypes.
 		listeners[i] = addr.String()
 	}
 
+	maddrs, err = s.ipfsCoreAPI.Swarm().LocalAddrs(ctx)
+	if err != nil {
+		return nil, errcode.TODO.Wrap(err)
+	}
+
+	localAddrs := make([]string, len(maddrs))
+	for i, addr := range maddrs {
+		localAddrs[i] = addr.String()
+	}
+
 	member, err := s.accountGroup.MemberPubKey().Raw()
 	if err != nil {
 		return nil, errcode.ErrSerialization.Wrap(err)
@@ -43,5 +53,6 @@ func (s *service) InstanceGetConfiguration(ctx context.Context, req *bertytypes.
 		AccountGroupPK: s.accountGroup.Group().PublicKey,
 		PeerID:         key.ID().Pretty(),
 		Listeners:      listeners,
+		LocalAddrs:     localAddrs,
 	}, nil
 }
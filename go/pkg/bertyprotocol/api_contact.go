@@ -1,11 +1,17 @@
 package bertyprotocol
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
 
+	"berty.tech/berty/v2/go/internal/cryptoutil"
 	"berty.tech/berty/v2/go/pkg/bertytypes"
 	"berty.tech/berty/v2/go/pkg/errcode"
 	"github.com/libp2p/go-libp2p-core/crypto"
+	"go.uber.org/zap"
 )
 
 func (s *service) ContactAliasKeySend(ctx context.Context, req *bertytypes.ContactAliasKeySend_Request) (*bertytypes.ContactAliasKeySend_Reply, error) {
@@ -46,3 +52,78 @@ func (s *service) ContactUnblock(ctx context.Context, req *bertytypes.ContactUnb
 
 	return &bertytypes.ContactUnblock_Reply{}, nil
 }
+
+func (s *service) ContactVerificationCode(ctx context.Context, req *bertytypes.ContactVerificationCode_Request) (*bertytypes.ContactVerificationCode_Reply, error) {
+	pk, err := crypto.UnmarshalEd25519PublicKey(req.ContactPK)
+	if err != nil {
+		return nil, errcode.ErrDeserialization.Wrap(err)
+	}
+
+	code, err := s.contactSafetyNumber(pk)
+	if err != nil {
+		return nil, err
+	}
+
+	verifiedDigest, keyChanged, err := s.accountGroup.MetadataStore().GetContactVerification(pk)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := &bertytypes.ContactVerificationCode_Reply{Code: code}
+
+	switch {
+	case keyChanged:
+		// another key has been verified for this contact
+		ret.KeyChanged = true
+	case len(verifiedDigest) > 0:
+		ret.Verified = bytes.Equal(verifiedDigest, cryptoutil.SafetyNumberDigest(code))
+		ret.KeyChanged = !ret.Verified
+	}
+
+	if ret.KeyChanged {
+		s.logger.Warn("safety number of a verified contact has changed", zap.String("contact", base64.StdEncoding.EncodeToString(req.ContactPK)))
+	}
+
+	return ret, nil
+}
+
+func (s *service) ContactVerify(ctx context.Context, req *bertytypes.ContactVerify_Request) (*bertytypes.ContactVerify_Reply, error) {
+	pk, err := crypto.UnmarshalEd25519PublicKey(req.ContactPK)
+	if err != nil {
+		return nil, errcode.ErrDeserialization.Wrap(err)
+	}
+
+	code, err := s.contactSafetyNumber(pk)
+	if err != nil {
+		return nil, err
+	}
+
+	// ignore any formatting done while displaying the code
+	if strings.Join(strings.Fields(req.Code), "") != code {
+		return nil, errcode.ErrInvalidInput.Wrap(fmt.Errorf("safety number mismatch"))
+	}
+
+	if _, err := s.accountGroup.MetadataStore().ContactVerified(ctx, pk, cryptoutil.SafetyNumberDigest(code)); err != nil {
+		return nil, errcode.ErrOrbitDBAppend.Wrap(err)
+	}
+
+	return &bertytypes.ContactVerify_Reply{}, nil
+}
+
+func (s *service) contactSafetyNumber(contactPK crypto.PubKey) (string, error) {
+	accSK, err := s.deviceKeystore.AccountPrivKey()
+	if err != nil {
+		return "", errcode.ErrInternal.Wrap(err)
+	}
+
+	if accSK.GetPublic().Equals(contactPK) {
+		return "", errcode.ErrInvalidInput
+	}
+
+	code, err := cryptoutil.SafetyNumber(accSK.GetPublic(), contactPK)
+	if err != nil {
+		return "", errcode.ErrCryptoKeyConversion.Wrap(err)
+	}
+
+	return code, nil
+}
@@ -0,0 +1,89 @@
+package bertyprotocol
+
+import (
+	"context"
+	crand "crypto/rand"
+	"testing"
+
+	"berty.tech/berty/v2/go/internal/testutil"
+	"berty.tech/berty/v2/go/pkg/bertytypes"
+	"berty.tech/berty/v2/go/pkg/errcode"
+	"github.com/libp2p/go-libp2p-core/crypto"
+	libp2p_mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContactVerificationCode_KeyChanged(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pt, cleanup := NewTestingProtocol(ctx, t, &TestingOpts{
+		Mocknet: libp2p_mocknet.New(ctx),
+		Logger:  testutil.Logger(t),
+	})
+	defer cleanup()
+
+	svc := pt.Service.(*service)
+
+	newContact := func(seed []byte) []byte {
+		_, pk, err := crypto.GenerateEd25519Key(crand.Reader)
+		require.NoError(t, err)
+
+		pkBytes, err := pk.Raw()
+		require.NoError(t, err)
+
+		_, err = svc.accountGroup.MetadataStore().ContactRequestIncomingReceived(ctx, &bertytypes.ShareableContact{
+			PK:                   pkBytes,
+			PublicRendezvousSeed: seed,
+		})
+		require.NoError(t, err)
+
+		return pkBytes
+	}
+
+	seed := make([]byte, 32)
+	_, err := crand.Read(seed)
+	require.NoError(t, err)
+
+	contactPK := newContact(seed)
+
+	// Unknown contacts have no verification code
+	_, unknownPK, err := crypto.GenerateEd25519Key(crand.Reader)
+	require.NoError(t, err)
+
+	unknownPKBytes, err := unknownPK.Raw()
+	require.NoError(t, err)
+
+	_, err = svc.ContactVerificationCode(ctx, &bertytypes.ContactVerificationCode_Request{ContactPK: unknownPKBytes})
+	require.Equal(t, errcode.ErrContactNotFound.Code(), errcode.Code(err))
+
+	ret, err := svc.ContactVerificationCode(ctx, &bertytypes.ContactVerificationCode_Request{ContactPK: contactPK})
+	require.NoError(t, err)
+	require.False(t, ret.Verified)
+	require.False(t, ret.KeyChanged)
+
+	_, err = svc.ContactVerify(ctx, &bertytypes.ContactVerify_Request{ContactPK: contactPK, Code: ret.Code})
+	require.NoError(t, err)
+
+	ret, err = svc.ContactVerificationCode(ctx, &bertytypes.ContactVerificationCode_Request{ContactPK: contactPK})
+	require.NoError(t, err)
+	require.True(t, ret.Verified)
+	require.False(t, ret.KeyChanged)
+
+	// The same contact comes back with another key
+	changedPK := newContact(seed)
+
+	ret, err = svc.ContactVerificationCode(ctx, &bertytypes.ContactVerificationCode_Request{ContactPK: changedPK})
+	require.NoError(t, err)
+	require.False(t, ret.Verified)
+	require.True(t, ret.KeyChanged)
+
+	// Verifying the new key clears the warning
+	_, err = svc.ContactVerify(ctx, &bertytypes.ContactVerify_Request{ContactPK: changedPK, Code: ret.Code})
+	require.NoError(t, err)
+
+	ret, err = svc.ContactVerificationCode(ctx, &bertytypes.ContactVerificationCode_Request{ContactPK: changedPK})
+	require.NoError(t, err)
+	require.True(t, ret.Verified)
+	require.False(t, ret.KeyChanged)
+}
@@ -2,13 +2,16 @@ package bertyprotocol
 
 import (
 	"fmt"
+	"sort"
+	"time"
 
 	"context"
 
+	"berty.tech/berty/v2/go/internal/tinder"
+
 	"berty.tech/berty/v2/go/pkg/bertytypes"
 	"berty.tech/berty/v2/go/pkg/errcode"
 	"berty.tech/go-orbit-db/stores/operation"
-	"github.com/gogo/protobuf/proto"
 	"github.com/libp2p/go-libp2p-core/crypto"
 	"go.uber.org/zap"
 )
@@ -109,25 +112,16 @@ func (s *service) DebugInspectGroupStore(req *bertytypes.DebugInspectGroupStore_
 				nexts     = make([][]byte, len(e.GetNext()))
 			)
 
-			if op, err := operation.ParseOperation(e); err != nil {
-				s.logger.Error("unable to parse operation", zap.Error(err))
-			} else if meta, event, err := openGroupEnvelope(cg.group, op.GetValue()); err != nil {
-				s.logger.Error("unable to open group envelope", zap.Error(err))
-			} else if metaEvent, err := newGroupMetadataEventFromEntry(log, e, meta, event, cg.group); err != nil {
-				s.logger.Error("unable to get group metadata event from entry", zap.Error(err))
+			if metaEvent, event, err := openMetadataEntry(log, e, cg.group); err != nil {
+				s.logger.Error("unable to open metadata entry", zap.Error(err))
 			} else {
 				payload = metaEvent.Event
 				eventType = metaEvent.Metadata.EventType
 
-				if typeData, ok := eventTypesMapper[metaEvent.Metadata.EventType]; ok {
-					p := proto.Clone(typeData.Message)
-					if err := proto.Unmarshal(metaEvent.Event, p); err == nil {
-						if msg, ok := p.(eventDeviceSigned); ok {
-							devicePK = msg.GetDevicePK()
-						}
-					}
-				} else {
-					s.logger.Error("unable to get message struct for event type", zap.String("event_type", metaEvent.Metadata.EventType.String()))
+				// events of an unknown type only have a device when their
+				// signature has been verified
+				if msg, ok := event.(eventDeviceSigned); ok {
+					devicePK = msg.GetDevicePK()
 				}
 			}
 
@@ -169,3 +163,82 @@ func (s *service) DebugGroup(ctx context.Context, request *bertytypes.DebugGroup
 
 	return rep, nil
 }
+
+func (s *service) DebugDiscovery(ctx context.Context, request *bertytypes.DebugDiscovery_Request) (*bertytypes.DebugDiscovery_Reply, error) {
+	sp, ok := s.tinderDriver.(tinder.StatsProvider)
+	if !ok {
+		return nil, errcode.ErrNotImplemented.Wrap(fmt.Errorf("discovery driver doesn't provide statistics"))
+	}
+
+	rep := &bertytypes.DebugDiscovery_Reply{}
+
+	for _, stats := range sp.Stats() {
+		driver := &bertytypes.DiscoveryDriverStats{
+			Name:              stats.Name,
+			AdvertiseCalls:    stats.AdvertiseCalls,
+			AdvertiseFailures: stats.AdvertiseFailures,
+			AdvertiseLatency:  stats.AdvertiseLatency.Nanoseconds(),
+			FindPeersCalls:    stats.FindPeersCalls,
+			FindPeersFailures: stats.FindPeersFailures,
+			FindPeersLatency:  stats.FindPeersLatency.Nanoseconds(),
+			LastError:         stats.LastError,
+			LastErrorAt:       unixNanoOrZero(stats.LastErrorAt),
+		}
+
+		namespaces := make([]string, 0, len(stats.Namespaces))
+		for ns := range stats.Namespaces {
+			namespaces = append(namespaces, ns)
+		}
+
+		sort.Strings(namespaces)
+
+		for _, ns := range namespaces {
+			driver.Namespaces = append(driver.Namespaces, &bertytypes.DiscoveryNamespaceStats{
+				Namespace:  ns,
+				PeersFound: stats.Namespaces[ns].PeersFound,
+				LastFound:  unixNanoOrZero(stats.Namespaces[ns].LastFound),
+			})
+		}
+
+		rep.Drivers = append(rep.Drivers, driver)
+	}
+
+	return rep, nil
+}
+
+func unixNanoOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+
+	return t.UnixNano()
+}
+
+func (s *service) DebugListUndecryptableMessages(req *bertytypes.DebugListUndecryptableMessages_Request, srv ProtocolService_DebugListUndecryptableMessagesServer) error {
+	cg, err := s.getContextGroupForID(req.GroupPK)
+	if err != nil {
+		return errcode.ErrInvalidInput.Wrap(err)
+	}
+
+	for _, u := range cg.messageStore.listUndecryptableMessages() {
+		errStr := ""
+		if u.err != nil {
+			errStr = u.err.Error()
+		}
+
+		if err := srv.SendMsg(&bertytypes.DebugListUndecryptableMessages_Reply{
+			CID:          u.entry.GetHash().Bytes(),
+			DevicePK:     u.devicePK,
+			Counter:      u.counter,
+			Reason:       u.reason,
+			KnownCounter: u.knownCounter,
+			Error:        errStr,
+			Attempts:     u.attempts,
+			FirstSeen:    u.firstSeen.UnixNano(),
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
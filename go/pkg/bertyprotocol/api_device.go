@@ -0,0 +1,73 @@
+package bertyprotocol
+
+import (
+	"context"
+
+	"berty.tech/berty/v2/go/pkg/bertytypes"
+	"berty.tech/berty/v2/go/pkg/errcode"
+	"github.com/libp2p/go-libp2p-core/crypto"
+	"go.uber.org/zap"
+)
+
+// DeviceRevoke revokes a device of the account, the revocation is sent to the
+// account group, to the contact groups where the device uses the same key and
+// to the multi-member groups where it linked its group device key
+func (s *service) DeviceRevoke(ctx context.Context, req *bertytypes.DeviceRevoke_Request) (*bertytypes.DeviceRevoke_Reply, error) {
+	devicePK, err := crypto.UnmarshalEd25519PublicKey(req.DevicePK)
+	if err != nil {
+		return nil, errcode.ErrDeserialization.Wrap(err)
+	}
+
+	if _, err := s.accountGroup.MetadataStore().RevokeDevice(ctx, devicePK); err != nil {
+		return nil, err
+	}
+
+	for _, contact := range s.accountGroup.MetadataStore().ListContactsByStatus(bertytypes.ContactStateAdded) {
+		contactPK, err := contact.GetPubKey()
+		if err != nil {
+			continue
+		}
+
+		cg, err := s.activateContactGroup(contactPK)
+		if err != nil {
+			s.logger.Warn("unable to open contact group", zap.Error(err))
+			continue
+		}
+
+		// the device might never have joined the contact group
+		if _, err := cg.MetadataStore().RevokeDevice(ctx, devicePK); err != nil {
+			s.logger.Warn("unable to revoke device in contact group", zap.Error(err))
+		}
+	}
+
+	for _, g := range s.accountGroup.MetadataStore().ListMultiMemberGroups() {
+		groupPK, err := g.GetPubKey()
+		if err != nil {
+			continue
+		}
+
+		// the device never joined the group
+		groupDevicePK, err := s.accountGroup.MetadataStore().GetGroupDevice(groupPK, devicePK)
+		if err != nil {
+			continue
+		}
+
+		if err := s.activateGroup(groupPK); err != nil {
+			s.logger.Warn("unable to open multi-member group", zap.Error(err))
+			continue
+		}
+
+		cg, err := s.getContextGroupForID(g.PublicKey)
+		if err != nil {
+			continue
+		}
+
+		// the revocation is signed using the member key, shared by the devices
+		// of the account
+		if _, err := cg.MetadataStore().RevokeDevice(ctx, groupDevicePK); err != nil {
+			s.logger.Warn("unable to revoke device in multi-member group", zap.Error(err))
+		}
+	}
+
+	return &bertytypes.DeviceRevoke_Reply{}, nil
+}
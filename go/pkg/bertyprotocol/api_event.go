@@ -158,7 +158,12 @@ func (s *service) GroupMessageList(req *bertytypes.GroupMessageList_Request, sub
 		return errcode.ErrGroupMemberUnknownGroupID.Wrap(err)
 	}
 
-	messages, err := cg.MessageStore().ListMessages(sub.Context())
+	list := cg.MessageStore().ListLatestMessages
+	if req.History {
+		list = cg.MessageStore().ListMessages
+	}
+
+	messages, err := list(sub.Context())
 	if err != nil {
 		return err
 	}
@@ -2,6 +2,8 @@ package bertyprotocol
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	"berty.tech/berty/v2/go/pkg/bertytypes"
 	"berty.tech/berty/v2/go/pkg/errcode"
@@ -54,13 +56,54 @@ func (s *service) GroupInfo(_ context.Context, req *bertytypes.GroupInfo_Request
 		return nil, errcode.ErrSerialization.Wrap(err)
 	}
 
+	ttl := time.Duration(0)
+	version, upgradableVersion := uint32(0), uint32(0)
+	if cg, err := s.getContextGroupForID(g.PublicKey); err == nil {
+		ttl = cg.MetadataStore().GetMessageTTL()
+		version = cg.MetadataStore().GetGroupVersion()
+		upgradableVersion = cg.MetadataStore().GetUpgradableGroupVersion()
+	}
+
 	return &bertytypes.GroupInfo_Reply{
-		Group:    g,
-		MemberPK: member,
-		DevicePK: device,
+		Group:             g,
+		MemberPK:          member,
+		DevicePK:          device,
+		MessageTTL:        int64(ttl / time.Second),
+		Version:           version,
+		UpgradableVersion: upgradableVersion,
 	}, nil
 }
 
+func (s *service) GroupEphemeralSettingsSet(ctx context.Context, req *bertytypes.GroupEphemeralSettingsSet_Request) (*bertytypes.GroupEphemeralSettingsSet_Reply, error) {
+	if req.MessageTTL < 0 {
+		return nil, errcode.ErrInvalidInput.Wrap(fmt.Errorf("invalid message ttl"))
+	}
+
+	cg, err := s.getContextGroupForID(req.GroupPK)
+	if err != nil {
+		return nil, errcode.ErrGroupMemberUnknownGroupID.Wrap(err)
+	}
+
+	if _, err := cg.MetadataStore().SetEphemeralSettings(ctx, time.Duration(req.MessageTTL)*time.Second); err != nil {
+		return nil, err
+	}
+
+	return &bertytypes.GroupEphemeralSettingsSet_Reply{}, nil
+}
+
+func (s *service) GroupVersionUpgrade(ctx context.Context, req *bertytypes.GroupVersionUpgrade_Request) (*bertytypes.GroupVersionUpgrade_Reply, error) {
+	cg, err := s.getContextGroupForID(req.GroupPK)
+	if err != nil {
+		return nil, errcode.ErrGroupMemberUnknownGroupID.Wrap(err)
+	}
+
+	if _, err := cg.MetadataStore().UpgradeVersion(ctx, req.Version); err != nil {
+		return nil, err
+	}
+
+	return &bertytypes.GroupVersionUpgrade_Reply{}, nil
+}
+
 func (s *service) ActivateGroup(ctx context.Context, req *bertytypes.ActivateGroup_Request) (*bertytypes.ActivateGroup_Reply, error) {
 	pk, err := crypto.UnmarshalEd25519PublicKey(req.GroupPK)
 	if err != nil {
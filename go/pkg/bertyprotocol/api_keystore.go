@@ -0,0 +1,46 @@
+package bertyprotocol
+
+import (
+	"context"
+
+	"berty.tech/berty/v2/go/pkg/bertytypes"
+	"berty.tech/berty/v2/go/pkg/errcode"
+)
+
+func (s *service) KeystoreLock(context.Context, *bertytypes.KeystoreLock_Request) (*bertytypes.KeystoreLock_Reply, error) {
+	if s.encryptedKeystore == nil {
+		return nil, errcode.ErrKeystoreNotEncrypted
+	}
+
+	s.encryptedKeystore.Lock()
+
+	return &bertytypes.KeystoreLock_Reply{}, nil
+}
+
+func (s *service) KeystoreUnlock(ctx context.Context, req *bertytypes.KeystoreUnlock_Request) (*bertytypes.KeystoreUnlock_Reply, error) {
+	if s.encryptedKeystore == nil {
+		return nil, errcode.ErrKeystoreNotEncrypted
+	}
+
+	if err := s.encryptedKeystore.Unlock(req.Passphrase); err != nil {
+		return nil, err
+	}
+
+	return &bertytypes.KeystoreUnlock_Reply{}, nil
+}
+
+func (s *service) KeystorePassphraseChange(ctx context.Context, req *bertytypes.KeystorePassphraseChange_Request) (*bertytypes.KeystorePassphraseChange_Reply, error) {
+	if s.encryptedKeystore == nil {
+		return nil, errcode.ErrKeystoreNotEncrypted
+	}
+
+	if len(req.NewPassphrase) == 0 {
+		return nil, errcode.ErrMissingInput
+	}
+
+	if err := s.encryptedKeystore.ChangePassphrase(req.OldPassphrase, req.NewPassphrase); err != nil {
+		return nil, err
+	}
+
+	return &bertytypes.KeystorePassphraseChange_Reply{}, nil
+}
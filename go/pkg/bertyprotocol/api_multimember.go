@@ -62,12 +62,27 @@ func (s *service) MultiMemberGroupLeave(ctx context.Context, req *bertytypes.Mul
 		return nil, errcode.ErrDeserialization.Wrap(err)
 	}
 
+	if err := s.activateGroup(pk); err != nil {
+		return nil, errcode.ErrGroupMissing.Wrap(err)
+	}
+
+	cg, err := s.getContextGroupForID(req.GroupPK)
+	if err != nil {
+		return nil, errcode.ErrGroupMissing.Wrap(err)
+	}
+
+	// let the remaining members know that they have to rotate their keys
+	if _, err := cg.MetadataStore().MemberLeave(ctx); err != nil {
+		return nil, errcode.ErrOrbitDBAppend.Wrap(err)
+	}
+
 	_, err = s.accountGroup.MetadataStore().GroupLeave(ctx, pk)
 	if err != nil {
 		return nil, errcode.ErrOrbitDBAppend.Wrap(err)
 	}
 
-	_ = s.deactivateGroup(pk)
+	// the stores are kept open until the departure has been sent to a peer
+	go s.deactivateGroupOnceExchanged(pk, cg)
 
 	return &bertytypes.MultiMemberGroupLeave_Reply{}, nil
 }
@@ -0,0 +1,368 @@
+package bertyprotocol
+
+import (
+	"bytes"
+	"context"
+	crand "crypto/rand"
+	"fmt"
+	"time"
+
+	"berty.tech/berty/v2/go/internal/shamir"
+	"berty.tech/berty/v2/go/pkg/bertytypes"
+	"berty.tech/berty/v2/go/pkg/errcode"
+	ipfs_options "github.com/ipfs/interface-go-ipfs-core/options"
+	"github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	"go.uber.org/zap"
+)
+
+const recoverySplitIDSize = 16
+
+// recoveryRequestProtocol is used by a new account to request the shares of
+// its lost account from the contacts holding them
+const recoveryRequestProtocol = protocol.ID("/berty/account_recovery/1.0.0")
+
+func (s *service) AccountRecoverySharesSend(ctx context.Context, req *bertytypes.AccountRecoverySharesSend_Request) (*bertytypes.AccountRecoverySharesSend_Reply, error) {
+	if req.Threshold < 2 || int(req.Threshold) > len(req.ContactPKs) {
+		return nil, errcode.ErrInvalidInput.Wrap(fmt.Errorf("threshold must be between 2 and the number of contacts"))
+	}
+
+	contacts := make([]crypto.PubKey, len(req.ContactPKs))
+	seen := map[string]struct{}{}
+
+	for i, contactPK := range req.ContactPKs {
+		if _, ok := seen[string(contactPK)]; ok {
+			return nil, errcode.ErrInvalidInput.Wrap(fmt.Errorf("duplicate contact"))
+		}
+		seen[string(contactPK)] = struct{}{}
+
+		pk, err := crypto.UnmarshalEd25519PublicKey(contactPK)
+		if err != nil {
+			return nil, errcode.ErrDeserialization.Wrap(err)
+		}
+
+		if !s.accountGroup.MetadataStore().checkContactStatus(pk, bertytypes.ContactStateAdded) {
+			return nil, errcode.ErrInvalidInput.Wrap(fmt.Errorf("recovery shares can only be sent to added contacts"))
+		}
+
+		contacts[i] = pk
+	}
+
+	secret, err := accountRecoverySecret(s.deviceKeystore)
+	if err != nil {
+		return nil, err
+	}
+
+	shares, err := shamir.Split(secret, len(contacts), int(req.Threshold))
+	if err != nil {
+		return nil, err
+	}
+
+	splitID := make([]byte, recoverySplitIDSize)
+	if _, err := crand.Read(splitID); err != nil {
+		return nil, errcode.ErrCryptoRandomGeneration.Wrap(err)
+	}
+
+	accountPK, err := s.accountGroup.MemberPubKey().Raw()
+	if err != nil {
+		return nil, errcode.ErrSerialization.Wrap(err)
+	}
+
+	for i, pk := range contacts {
+		cg, err := s.activateContactGroup(pk)
+		if err != nil {
+			return nil, errcode.ErrGroupMissing.Wrap(err)
+		}
+
+		if _, err := cg.MetadataStore().ContactSendRecoveryShare(ctx, pk, &bertytypes.RecoveryShare{
+			AccountPK: accountPK,
+			Threshold: req.Threshold,
+			Share:     shares[i],
+			SplitID:   splitID,
+		}); err != nil {
+			return nil, errcode.ErrOrbitDBAppend.Wrap(err)
+		}
+	}
+
+	return &bertytypes.AccountRecoverySharesSend_Reply{}, nil
+}
+
+func (s *service) AccountRecoverySharesRequest(ctx context.Context, req *bertytypes.AccountRecoverySharesRequest_Request) (*bertytypes.AccountRecoverySharesRequest_Reply, error) {
+	lostPK, err := crypto.UnmarshalEd25519PublicKey(req.AccountPK)
+	if err != nil {
+		return nil, errcode.ErrDeserialization.Wrap(err)
+	}
+
+	if lostPK.Equals(s.accountGroup.MemberPubKey()) {
+		return nil, errcode.ErrInvalidInput.Wrap(fmt.Errorf("shares must be requested from a new account"))
+	}
+
+	contacts := make([]crypto.PubKey, len(req.ContactPKs))
+	for i, contactPK := range req.ContactPKs {
+		pk, err := crypto.UnmarshalEd25519PublicKey(contactPK)
+		if err != nil {
+			return nil, errcode.ErrDeserialization.Wrap(err)
+		}
+
+		// the contacts must have been added through a contact request, the
+		// streams opened to them are authenticated to the current account
+		if !s.accountGroup.MetadataStore().checkContactStatus(pk, bertytypes.ContactStateAdded) {
+			return nil, errcode.ErrInvalidInput.Wrap(fmt.Errorf("recovery shares can only be requested from added contacts"))
+		}
+
+		contacts[i] = pk
+	}
+
+	ret := &bertytypes.AccountRecoverySharesRequest_Reply{}
+
+	for i, pk := range contacts {
+		accepted, err := s.requestRecoveryShare(ctx, pk, req.AccountPK)
+		if err != nil {
+			s.logger.Warn("unable to request recovery share", zap.Error(err))
+			continue
+		}
+
+		if accepted {
+			ret.ContactPKs = append(ret.ContactPKs, req.ContactPKs[i])
+		}
+	}
+
+	return ret, nil
+}
+
+// requestRecoveryShare asks a contact to release the share of lostPK to the
+// current account, over a stream opened to one of the peers of the contact
+// group
+func (s *service) requestRecoveryShare(ctx context.Context, contactPK crypto.PubKey, lostPK []byte) (bool, error) {
+	cg, err := s.activateContactGroup(contactPK)
+	if err != nil {
+		return false, errcode.ErrGroupMissing.Wrap(err)
+	}
+
+	peers, err := s.ipfsCoreAPI.PubSub().Peers(ctx, ipfs_options.PubSub.Topic(cg.MetadataStore().Address().String()))
+	if err != nil {
+		return false, errcode.TODO.Wrap(err)
+	}
+
+	// the peers of the contact group are either devices of the contact or
+	// other devices of the current account, the handshake fails on the latter
+	for _, p := range peers {
+		stream, err := s.NewAuthenticatedStream(ctx, p, contactPK, recoveryRequestProtocol)
+		if err != nil {
+			continue
+		}
+
+		reply := &bertytypes.RecoveryShareRequestReply{}
+		err = stream.WriteMsg(&bertytypes.RecoveryShareRequest{AccountPK: lostPK})
+		if err == nil {
+			err = stream.ReadMsg(reply)
+		}
+		_ = stream.Close()
+
+		if err != nil {
+			continue
+		}
+
+		return reply.Accepted, nil
+	}
+
+	return false, errcode.ErrContactStreamRefused.Wrap(fmt.Errorf("no device of the contact accepted the request"))
+}
+
+// handleRecoveryRequest records the requests of contacts to release the share
+// of their lost account, the share is released once the request has been
+// approved using AccountRecoveryShareRelease
+func (s *service) handleRecoveryRequest(stream *AuthenticatedStream) {
+	defer func() { _ = stream.Close() }()
+
+	req := &bertytypes.RecoveryShareRequest{}
+	if err := stream.ReadMsg(req); err != nil {
+		s.logger.Warn("unable to read recovery request", zap.Error(err))
+		return
+	}
+
+	newPK, err := stream.ContactPK().Raw()
+	if err != nil {
+		return
+	}
+
+	shares, err := s.recoverySharesOf(req.AccountPK)
+	accepted := err == nil && len(shares) > 0 && !bytes.Equal(newPK, req.AccountPK)
+
+	if accepted {
+		s.recoveryRequests.add(req.AccountPK, newPK, time.Now())
+		s.logger.Info("recovery share requested by contact", zap.Binary("account", req.AccountPK), zap.Binary("contact", newPK))
+	}
+
+	if err := stream.WriteMsg(&bertytypes.RecoveryShareRequestReply{Accepted: accepted}); err != nil {
+		s.logger.Warn("unable to reply to recovery request", zap.Error(err))
+	}
+}
+
+// recoverySharesOf returns the shares entrusted by the account lostPK
+func (s *service) recoverySharesOf(lostPKBytes []byte) ([]*bertytypes.RecoveryShare, error) {
+	lostPK, err := crypto.UnmarshalEd25519PublicKey(lostPKBytes)
+	if err != nil {
+		return nil, errcode.ErrDeserialization.Wrap(err)
+	}
+
+	if !s.accountGroup.MetadataStore().checkContactStatus(lostPK, bertytypes.ContactStateAdded) {
+		return nil, errcode.ErrInvalidInput.Wrap(fmt.Errorf("no recovery share entrusted by this account"))
+	}
+
+	lostCG, err := s.activateContactGroup(lostPK)
+	if err != nil {
+		return nil, errcode.ErrGroupMissing.Wrap(err)
+	}
+
+	accSK, err := s.deviceKeystore.AccountPrivKey()
+	if err != nil {
+		return nil, errcode.ErrInternal.Wrap(err)
+	}
+
+	shares := []*bertytypes.RecoveryShare(nil)
+	for _, evt := range lostCG.MetadataStore().ListRecoveryShares() {
+		share, err := openRecoveryShare(accSK, lostPK, evt.Nonce, evt.EncryptedShare)
+		if err != nil {
+			s.logger.Warn("unable to open recovery share", zap.Error(err))
+			continue
+		}
+
+		if bytes.Equal(share.AccountPK, lostPKBytes) {
+			shares = append(shares, share)
+		}
+	}
+
+	return shares, nil
+}
+
+func (s *service) AccountRecoveryRequestList(ctx context.Context, req *bertytypes.AccountRecoveryRequestList_Request) (*bertytypes.AccountRecoveryRequestList_Reply, error) {
+	return &bertytypes.AccountRecoveryRequestList_Reply{
+		Requests: s.recoveryRequests.list(time.Now()),
+	}, nil
+}
+
+func (s *service) AccountRecoveryShareRelease(ctx context.Context, req *bertytypes.AccountRecoveryShareRelease_Request) (*bertytypes.AccountRecoveryShareRelease_Reply, error) {
+	lostPK, err := crypto.UnmarshalEd25519PublicKey(req.AccountPK)
+	if err != nil {
+		return nil, errcode.ErrDeserialization.Wrap(err)
+	}
+
+	contactPK, err := crypto.UnmarshalEd25519PublicKey(req.ContactPK)
+	if err != nil {
+		return nil, errcode.ErrDeserialization.Wrap(err)
+	}
+
+	if lostPK.Equals(contactPK) {
+		return nil, errcode.ErrInvalidInput.Wrap(fmt.Errorf("shares must be released to the new account"))
+	}
+
+	// the request has been received over a stream authenticated to the new
+	// account, which was added through a contact request
+	if !s.recoveryRequests.has(req.AccountPK, req.ContactPK, time.Now()) {
+		return nil, errcode.ErrInvalidInput.Wrap(fmt.Errorf("no recovery request received from this contact"))
+	}
+
+	if !s.accountGroup.MetadataStore().checkContactStatus(contactPK, bertytypes.ContactStateAdded) {
+		return nil, errcode.ErrInvalidInput.Wrap(fmt.Errorf("recovery shares can only be released to added contacts"))
+	}
+
+	shares, err := s.recoverySharesOf(req.AccountPK)
+	if err != nil {
+		return nil, err
+	} else if len(shares) == 0 {
+		return nil, errcode.ErrMissingInput.Wrap(fmt.Errorf("no recovery share entrusted by this account"))
+	}
+
+	contactCG, err := s.activateContactGroup(contactPK)
+	if err != nil {
+		return nil, errcode.ErrGroupMissing.Wrap(err)
+	}
+
+	for _, share := range shares {
+		if _, err := contactCG.MetadataStore().ContactReleaseRecoveryShare(ctx, contactPK, share); err != nil {
+			return nil, errcode.ErrOrbitDBAppend.Wrap(err)
+		}
+	}
+
+	s.recoveryRequests.remove(req.AccountPK, req.ContactPK)
+
+	return &bertytypes.AccountRecoveryShareRelease_Reply{}, nil
+}
+
+func (s *service) AccountRecoverySharesCollect(ctx context.Context, req *bertytypes.AccountRecoverySharesCollect_Request) (*bertytypes.AccountRecoverySharesCollect_Reply, error) {
+	if _, err := crypto.UnmarshalEd25519PublicKey(req.AccountPK); err != nil {
+		return nil, errcode.ErrDeserialization.Wrap(err)
+	}
+
+	accSK, err := s.deviceKeystore.AccountPrivKey()
+	if err != nil {
+		return nil, errcode.ErrInternal.Wrap(err)
+	}
+
+	type recoverySplit struct {
+		threshold uint32
+		shares    map[byte][]byte
+	}
+
+	// shares are grouped by split as only shares generated together can be combined
+	splits := map[string]*recoverySplit{}
+
+	for _, contact := range s.accountGroup.MetadataStore().ListContactsByStatus(bertytypes.ContactStateAdded) {
+		contactPK, err := contact.GetPubKey()
+		if err != nil {
+			continue
+		}
+
+		cg, err := s.activateContactGroup(contactPK)
+		if err != nil {
+			s.logger.Warn("unable to open contact group", zap.Error(err))
+			continue
+		}
+
+		for _, evt := range cg.MetadataStore().ListReleasedRecoveryShares() {
+			share, err := openRecoveryShare(accSK, contactPK, evt.Nonce, evt.EncryptedShare)
+			if err != nil || !bytes.Equal(share.AccountPK, req.AccountPK) || len(share.Share) < 2 {
+				continue
+			}
+
+			split, ok := splits[string(share.SplitID)]
+			if !ok {
+				split = &recoverySplit{threshold: share.Threshold, shares: map[byte][]byte{}}
+				splits[string(share.SplitID)] = split
+			}
+
+			// the last byte of a share is its x coordinate
+			split.shares[share.Share[len(share.Share)-1]] = share.Share
+		}
+	}
+
+	ret := &bertytypes.AccountRecoverySharesCollect_Reply{}
+
+	for _, split := range splits {
+		if uint32(len(split.shares)) > ret.Collected {
+			ret.Collected, ret.Threshold = uint32(len(split.shares)), split.threshold
+		}
+
+		if uint32(len(split.shares)) < split.threshold {
+			continue
+		}
+
+		parts := make([][]byte, 0, len(split.shares))
+		for _, share := range split.shares {
+			parts = append(parts, share)
+		}
+
+		secret, err := shamir.Combine(parts)
+		if err != nil || !checkRecoverySecret(secret, req.AccountPK) {
+			s.logger.Warn("unable to rebuild recovery secret from collected shares")
+			continue
+		}
+
+		ret.Collected, ret.Threshold, ret.RecoverySecret = uint32(len(split.shares)), split.threshold, secret
+
+		break
+	}
+
+	return ret, nil
+}
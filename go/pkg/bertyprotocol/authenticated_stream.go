@@ -0,0 +1,186 @@
+package bertyprotocol
+
+import (
+	"context"
+	"fmt"
+
+	"berty.tech/berty/v2/go/internal/handshake"
+	"berty.tech/berty/v2/go/pkg/bertytypes"
+	"berty.tech/berty/v2/go/pkg/errcode"
+	ggio "github.com/gogo/protobuf/io"
+	"github.com/gogo/protobuf/proto"
+	"github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/network"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	"go.uber.org/zap"
+)
+
+// authenticatedStreamMaxMsgSize is the maximum size of a message exchanged on
+// an authenticated stream, including the handshake messages
+const authenticatedStreamMaxMsgSize = 1024 * 1024
+
+// defaultAuthenticatedStreamStates are the contact states allowed to use an
+// authenticated stream unless specified otherwise
+var defaultAuthenticatedStreamStates = []bertytypes.ContactState{bertytypes.ContactStateAdded}
+
+// AuthenticatedStreamHandler handles the streams opened by a contact using a
+// protocol registered with SetAuthenticatedStreamHandler, the handler is in
+// charge of closing the stream
+type AuthenticatedStreamHandler func(stream *AuthenticatedStream)
+
+// AuthenticatedStream is a libp2p stream on which a handshake has been
+// performed with the account of a contact, messages exchanged using ReadMsg
+// and WriteMsg are sealed using the session key
+type AuthenticatedStream struct {
+	network.Stream
+
+	contactPK     crypto.PubKey
+	secureChannel *handshake.SecureChannel
+}
+
+var (
+	_ ggio.Reader = (*AuthenticatedStream)(nil)
+	_ ggio.Writer = (*AuthenticatedStream)(nil)
+)
+
+// ContactPK returns the account public key of the contact authenticated on
+// the stream
+func (s *AuthenticatedStream) ContactPK() crypto.PubKey {
+	return s.contactPK
+}
+
+// ReadMsg receives and opens a message sent by the contact
+func (s *AuthenticatedStream) ReadMsg(msg proto.Message) error {
+	return s.secureChannel.ReadMsg(msg)
+}
+
+// WriteMsg seals and sends a message to the contact
+func (s *AuthenticatedStream) WriteMsg(msg proto.Message) error {
+	return s.secureChannel.WriteMsg(msg)
+}
+
+// SetAuthenticatedStreamHandler registers a handler for the streams opened by
+// contacts using pid, the handshake is performed before calling the handler
+// and streams from accounts which aren't in one of the allowed states (only
+// ContactStateAdded if none is given) are refused
+func (s *service) SetAuthenticatedStreamHandler(pid protocol.ID, handler AuthenticatedStreamHandler, allowedStates ...bertytypes.ContactState) {
+	if len(allowedStates) == 0 {
+		allowedStates = defaultAuthenticatedStreamStates
+	}
+
+	s.ipfsCoreAPI.SetStreamHandler(pid, func(stream network.Stream) {
+		as, err := s.acceptAuthenticatedStream(stream, allowedStates)
+		if err != nil {
+			s.logger.Warn("authenticated stream refused",
+				zap.String("protocol", string(pid)),
+				zap.String("peer", stream.Conn().RemotePeer().Pretty()),
+				zap.Error(err),
+			)
+			_ = stream.Reset()
+			return
+		}
+
+		handler(as)
+	})
+}
+
+// RemoveAuthenticatedStreamHandler unregisters the handler of pid
+func (s *service) RemoveAuthenticatedStreamHandler(pid protocol.ID) {
+	s.ipfsCoreAPI.RemoveStreamHandler(pid)
+}
+
+// NewAuthenticatedStream opens a stream with a peer and performs a handshake
+// with the account of the contact, the contact must be in one of the allowed
+// states (only ContactStateAdded if none is given)
+func (s *service) NewAuthenticatedStream(ctx context.Context, p peer.ID, contactPK crypto.PubKey, pid protocol.ID, allowedStates ...bertytypes.ContactState) (*AuthenticatedStream, error) {
+	if len(allowedStates) == 0 {
+		allowedStates = defaultAuthenticatedStreamStates
+	}
+
+	if !s.accountGroup.MetadataStore().checkContactStatus(contactPK, allowedStates...) {
+		return nil, errcode.ErrContactStreamRefused.Wrap(fmt.Errorf("contact state not allowed"))
+	}
+
+	accSK, err := s.deviceKeystore.AccountPrivKey()
+	if err != nil {
+		return nil, errcode.ErrInternal.Wrap(err)
+	}
+
+	stream, err := s.ipfsCoreAPI.NewStream(ctx, p, pid)
+	if err != nil {
+		return nil, errcode.ErrStreamWrite.Wrap(err)
+	}
+
+	hsCtx, cancel := s.contactRequestsLimits.handshakeContext(ctx)
+	defer cancel()
+
+	reader := ggio.NewDelimitedReader(stream, authenticatedStreamMaxMsgSize)
+	writer := ggio.NewDelimitedWriter(stream)
+
+	session := &handshake.Session{}
+	if err := handshake.RequestContext(hsCtx, stream, reader, writer, accSK, contactPK,
+		handshake.WithStepTimeout(s.contactRequestsLimits.HandshakeStepTimeout),
+		handshake.WithSession(session),
+	); err != nil {
+		_ = stream.Reset()
+		return nil, err
+	}
+
+	sc, err := handshake.NewSecureChannel(session, reader, writer)
+	if err != nil {
+		_ = stream.Reset()
+		return nil, err
+	}
+
+	return &AuthenticatedStream{
+		Stream:        stream,
+		contactPK:     contactPK,
+		secureChannel: sc,
+	}, nil
+}
+
+// acceptAuthenticatedStream performs the handshake on a stream opened by a
+// contact, the limits and the proof of work of contact requests apply since
+// the peer is only authenticated at the end of the handshake
+func (s *service) acceptAuthenticatedStream(stream network.Stream, allowedStates []bertytypes.ContactState) (*AuthenticatedStream, error) {
+	if err := s.streamsLimiter.allow(stream.Conn().RemotePeer()); err != nil {
+		return nil, err
+	}
+
+	accSK, err := s.deviceKeystore.AccountPrivKey()
+	if err != nil {
+		return nil, errcode.ErrInternal.Wrap(err)
+	}
+
+	ctx, cancel := s.contactRequestsLimits.handshakeContext(s.ctx)
+	defer cancel()
+
+	reader := ggio.NewDelimitedReader(stream, authenticatedStreamMaxMsgSize)
+	writer := ggio.NewDelimitedWriter(stream)
+
+	session := &handshake.Session{}
+	contactPK, err := handshake.ResponseContext(ctx, stream, reader, writer, accSK,
+		handshake.WithProofOfWorkDifficulty(s.contactRequestsLimits.ProofOfWorkDifficulty),
+		handshake.WithStepTimeout(s.contactRequestsLimits.HandshakeStepTimeout),
+		handshake.WithSession(session),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if !s.accountGroup.MetadataStore().checkContactStatus(contactPK, allowedStates...) {
+		return nil, errcode.ErrContactStreamRefused.Wrap(fmt.Errorf("contact state not allowed"))
+	}
+
+	sc, err := handshake.NewSecureChannel(session, reader, writer)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AuthenticatedStream{
+		Stream:        stream,
+		contactPK:     contactPK,
+		secureChannel: sc,
+	}, nil
+}
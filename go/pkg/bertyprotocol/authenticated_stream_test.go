@@ -0,0 +1,127 @@
+package bertyprotocol
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"berty.tech/berty/v2/go/internal/testutil"
+	"berty.tech/berty/v2/go/pkg/bertytypes"
+	"berty.tech/berty/v2/go/pkg/errcode"
+	"github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/protocol"
+	libp2p_mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthenticatedStream(t *testing.T) {
+	testutil.SkipSlow(t)
+
+	const testProtocol = protocol.ID("/berty/test_authenticated_stream/1.0.0")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*20)
+	defer cancel()
+
+	opts := TestingOpts{
+		Mocknet: libp2p_mocknet.New(ctx),
+		Logger:  testutil.Logger(t),
+	}
+
+	pts, cleanup := newTestingProtocolWithMockedPeers(ctx, t, &opts, 2)
+	defer cleanup()
+
+	require.NoError(t, opts.Mocknet.ConnectAllButSelf())
+
+	svcs := make([]*service, len(pts))
+	contacts := make([]*bertytypes.ShareableContact, len(pts))
+	pks := make([]crypto.PubKey, len(pts))
+
+	for i, pt := range pts {
+		svcs[i] = pt.Service.(*service)
+
+		config, err := pt.Client.InstanceGetConfiguration(ctx, &bertytypes.InstanceGetConfiguration_Request{})
+		require.NoError(t, err)
+
+		ref, err := pt.Client.ContactRequestResetReference(ctx, &bertytypes.ContactRequestResetReference_Request{})
+		require.NoError(t, err)
+
+		contacts[i] = &bertytypes.ShareableContact{
+			PK:                   config.AccountPK,
+			PublicRendezvousSeed: ref.PublicRendezvousSeed,
+		}
+
+		pks[i], err = crypto.UnmarshalEd25519PublicKey(config.AccountPK)
+		require.NoError(t, err)
+	}
+
+	received := make(chan *bertytypes.ShareableContact, 1)
+	svcs[0].SetAuthenticatedStreamHandler(testProtocol, func(stream *AuthenticatedStream) {
+		defer stream.Close()
+
+		if !stream.ContactPK().Equals(pks[1]) {
+			return
+		}
+
+		msg := &bertytypes.ShareableContact{}
+		if err := stream.ReadMsg(msg); err != nil {
+			return
+		}
+
+		received <- msg
+
+		_ = stream.WriteMsg(msg)
+	})
+	defer svcs[0].RemoveAuthenticatedStreamHandler(testProtocol)
+
+	peer0 := pts[0].IPFS.MockNode().Identity
+
+	// Accounts aren't contacts yet
+	_, err := svcs[1].NewAuthenticatedStream(ctx, peer0, pks[0], testProtocol)
+	require.Equal(t, errcode.ErrContactStreamRefused.Code(), errcode.Code(err))
+
+	for i := range svcs {
+		other := contacts[len(svcs)-1-i]
+		otherPK := pks[len(svcs)-1-i]
+
+		_, err := svcs[i].accountGroup.MetadataStore().ContactRequestIncomingReceived(ctx, other)
+		require.NoError(t, err)
+
+		_, err = svcs[i].accountGroup.MetadataStore().ContactRequestIncomingAccept(ctx, otherPK)
+		require.NoError(t, err)
+	}
+
+	stream, err := svcs[1].NewAuthenticatedStream(ctx, peer0, pks[0], testProtocol)
+	require.NoError(t, err)
+	require.True(t, stream.ContactPK().Equals(pks[0]))
+
+	sent := &bertytypes.ShareableContact{Metadata: []byte("hello")}
+	require.NoError(t, stream.WriteMsg(sent))
+
+	select {
+	case msg := <-received:
+		require.Equal(t, sent.Metadata, msg.Metadata)
+	case <-ctx.Done():
+		t.Fatal("message not received")
+	}
+
+	echo := &bertytypes.ShareableContact{}
+	require.NoError(t, stream.ReadMsg(echo))
+	require.Equal(t, sent.Metadata, echo.Metadata)
+	require.NoError(t, stream.Close())
+
+	// Streams from blocked contacts are refused once authenticated
+	_, err = svcs[0].accountGroup.MetadataStore().ContactBlock(ctx, pks[1])
+	require.NoError(t, err)
+
+	stream, err = svcs[1].NewAuthenticatedStream(ctx, peer0, pks[0], testProtocol)
+	if err == nil {
+		require.Error(t, stream.ReadMsg(&bertytypes.ShareableContact{}))
+		_ = stream.Reset()
+	}
+
+	select {
+	case <-received:
+		t.Fatal("stream from a blocked contact handled")
+	default:
+	}
+}
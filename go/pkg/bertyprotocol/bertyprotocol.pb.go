@@ -29,57 +29,78 @@ const _ = proto.GoGoProtoPackageIsVersion3 // please upgrade the proto package
 func init() { proto.RegisterFile("bertyprotocol.proto", fileDescriptor_047e04c733cf8554) }
 
 var fileDescriptor_047e04c733cf8554 = []byte{
-	// 787 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x97, 0xdf, 0x4f, 0x13, 0x4b,
-	0x14, 0xc7, 0xd3, 0x97, 0x9b, 0xdc, 0xc9, 0xbd, 0xc0, 0x1d, 0x2e, 0xa8, 0x04, 0xf9, 0x21, 0x16,
-	0x04, 0xb5, 0x05, 0x89, 0x89, 0xf1, 0xad, 0xfc, 0x08, 0xa9, 0x42, 0x62, 0x4a, 0x48, 0x8c, 0x44,
-	0x93, 0xd9, 0xed, 0xa1, 0xac, 0x2c, 0x33, 0xeb, 0xcc, 0xb4, 0xb1, 0xaf, 0x3e, 0x19, 0x1f, 0xfc,
-	0x0f, 0xfc, 0x5f, 0xcd, 0xcc, 0x0e, 0x93, 0xce, 0x74, 0x67, 0xbb, 0xf5, 0x6d, 0x99, 0xef, 0xe7,
-	0x7c, 0xbf, 0x67, 0x4f, 0x4f, 0xa7, 0x01, 0xcd, 0x47, 0xc0, 0xe5, 0x30, 0xe3, 0x4c, 0xb2, 0x98,
-	0xa5, 0x0d, 0xfd, 0x80, 0x67, 0xf4, 0x61, 0xe3, 0xee, 0x74, 0x69, 0x4e, 0xff, 0x2d, 0x87, 0x19,
-	0x88, 0xfc, 0xf0, 0xc5, 0x8f, 0x07, 0x68, 0xf6, 0x9d, 0x91, 0xcf, 0x81, 0x0f, 0x92, 0x18, 0x70,
-	0x17, 0xe1, 0x36, 0x15, 0x92, 0xd0, 0x18, 0x8e, 0xbf, 0x66, 0x8c, 0xcb, 0x23, 0x22, 0x09, 0xde,
-	0x6a, 0xe4, 0x66, 0x79, 0xf5, 0x38, 0xd0, 0xe8, 0xc0, 0x97, 0x3e, 0x08, 0xb9, 0x54, 0x9f, 0x0c,
-	0x66, 0xe9, 0x10, 0x0f, 0xd0, 0xfd, 0x3b, 0xed, 0x04, 0xe4, 0x21, 0xa3, 0x57, 0x49, 0xaf, 0xcf,
-	0x89, 0x4c, 0x18, 0xc5, 0xcf, 0x0b, 0x2d, 0x7c, 0xcc, 0x26, 0x3e, 0xad, 0x8a, 0xab, 0x5c, 0x81,
-	0xee, 0x1d, 0x32, 0x2a, 0x49, 0x2c, 0x4d, 0x79, 0x07, 0xae, 0x80, 0x03, 0x8d, 0x01, 0x3f, 0x73,
-	0x7c, 0x02, 0x94, 0x4d, 0xdd, 0xa9, 0x48, 0xab, 0xd0, 0x5b, 0xb4, 0xe0, 0x02, 0x47, 0x89, 0x20,
-	0x51, 0x0a, 0xb8, 0xcc, 0xc4, 0x30, 0x36, 0xf0, 0x49, 0x25, 0x56, 0xc5, 0x7d, 0x46, 0xff, 0xbb,
-	0xf2, 0x31, 0xd5, 0x69, 0xdb, 0x25, 0x0e, 0x39, 0x62, 0xc3, 0xb6, 0xaa, 0xa0, 0x2a, 0xeb, 0x5b,
-	0x0d, 0x2d, 0xfb, 0x2f, 0x2f, 0x60, 0x64, 0xaa, 0x7b, 0xa5, 0x73, 0x1a, 0x45, 0x6d, 0x78, 0x73,
-	0x9a, 0x12, 0xd5, 0x44, 0x17, 0x61, 0x97, 0x3a, 0x07, 0xda, 0xc5, 0x65, 0xef, 0xa0, 0x80, 0xc0,
-	0xca, 0x16, 0x82, 0x85, 0x63, 0x6d, 0xc5, 0x31, 0x64, 0xb2, 0x74, 0xac, 0x39, 0x52, 0x69, 0xac,
-	0x16, 0x0d, 0x6d, 0x4c, 0x4c, 0x78, 0x77, 0xd2, 0xc6, 0x28, 0xa6, 0xea, 0xc6, 0x18, 0x56, 0xc5,
-	0x75, 0xd0, 0x3f, 0x46, 0x3e, 0x48, 0x59, 0x7c, 0x83, 0xd7, 0x8b, 0x2a, 0xb5, 0x64, 0xcd, 0x57,
-	0xcb, 0x10, 0xe5, 0xf9, 0x1e, 0xcd, 0x98, 0xd3, 0x0b, 0x1a, 0x69, 0xd7, 0x8d, 0xa2, 0x12, 0x23,
-	0x5a, 0xdf, 0xf5, 0x72, 0x48, 0x39, 0xf7, 0xd0, 0xbc, 0x39, 0x6f, 0xa5, 0x09, 0x11, 0x6f, 0x61,
-	0xa8, 0x3f, 0xef, 0xc2, 0xd7, 0x1d, 0x25, 0x6c, 0xc6, 0x66, 0x05, 0x52, 0x05, 0x65, 0x68, 0xf1,
-	0xac, 0x9f, 0xca, 0xe4, 0x0c, 0x6e, 0x23, 0xe0, 0x27, 0x9c, 0xf5, 0xb3, 0x43, 0x0e, 0x44, 0x02,
-	0x76, 0xef, 0x9c, 0x62, 0xc8, 0xc6, 0x6d, 0x57, 0x83, 0xcd, 0x8e, 0xf9, 0xfa, 0x1b, 0x96, 0x50,
-	0x5c, 0x6e, 0xa1, 0x90, 0xc0, 0x8e, 0x05, 0x50, 0xb3, 0x63, 0xbe, 0x7a, 0x0a, 0x64, 0xe0, 0xdf,
-	0x4a, 0x85, 0x4c, 0x60, 0xc7, 0x42, 0xac, 0x8a, 0xfb, 0x55, 0x43, 0x75, 0x5f, 0xd7, 0x33, 0xef,
-	0x80, 0x60, 0xe9, 0x00, 0xb8, 0x5a, 0xc9, 0x94, 0x09, 0xc0, 0xaf, 0x4b, 0x3d, 0x0b, 0x6b, 0x6c,
-	0x3f, 0xaf, 0xfe, 0xa8, 0x56, 0xf5, 0xf7, 0xbd, 0x86, 0x56, 0xc6, 0xf8, 0xee, 0x6d, 0x42, 0x3b,
-	0x2c, 0x85, 0x13, 0x4e, 0xa8, 0xc4, 0xfb, 0xe5, 0xe6, 0x0e, 0x6c, 0x3b, 0xda, 0x9b, 0xae, 0x48,
-	0xb5, 0xf2, 0xb3, 0x86, 0xd6, 0x7c, 0xb0, 0x4d, 0x07, 0x89, 0xd4, 0xbf, 0x64, 0x66, 0x05, 0x5f,
-	0x96, 0xfa, 0xfa, 0xb8, 0x6d, 0x67, 0x7f, 0xda, 0x32, 0xd5, 0xd0, 0x25, 0x9a, 0x6d, 0x65, 0xd9,
-	0x19, 0x48, 0xd2, 0x25, 0x92, 0xe8, 0x6f, 0xdb, 0x63, 0xc7, 0xc7, 0x53, 0x6d, 0xda, 0xa3, 0x09,
-	0x94, 0xb9, 0x28, 0xb4, 0x20, 0x04, 0xe9, 0x81, 0xf6, 0xde, 0x18, 0xaf, 0xb2, 0x62, 0xe0, 0xa2,
-	0x18, 0x83, 0x94, 0xf3, 0x35, 0x5a, 0xd4, 0x6f, 0x65, 0x43, 0xfb, 0x91, 0x88, 0x79, 0x12, 0xf9,
-	0xdf, 0xdf, 0x62, 0x28, 0x70, 0xd5, 0x39, 0xf0, 0xf1, 0x00, 0xa8, 0xdc, 0xad, 0x61, 0x40, 0x0b,
-	0xe6, 0x3c, 0xef, 0xc1, 0x06, 0xed, 0x14, 0xd5, 0xba, 0x8c, 0xcd, 0x59, 0x09, 0xb2, 0x77, 0x31,
-	0x9f, 0xd0, 0x7f, 0x4e, 0xfc, 0x69, 0x22, 0x24, 0xde, 0x0c, 0xb7, 0xa7, 0xf4, 0x69, 0x5e, 0xe3,
-	0x12, 0xcd, 0x8d, 0xc6, 0x6a, 0xfb, 0x7a, 0xb0, 0x2b, 0xc7, 0x7d, 0x72, 0xf3, 0x6d, 0xf4, 0xb7,
-	0xd9, 0xb1, 0x2b, 0x86, 0x0b, 0x70, 0x75, 0x6e, 0xed, 0x96, 0x83, 0xba, 0xfa, 0x60, 0x2f, 0xd0,
-	0xbf, 0xad, 0x58, 0x26, 0x03, 0x22, 0x41, 0x4b, 0xd8, 0xdb, 0xb3, 0x51, 0xcd, 0x5a, 0xae, 0x95,
-	0x32, 0x66, 0xcd, 0x8f, 0x80, 0x38, 0xc6, 0xee, 0x9a, 0x7b, 0x6a, 0x60, 0xcd, 0xc7, 0x29, 0x65,
-	0xfe, 0x51, 0x99, 0x47, 0xfd, 0x9e, 0x9a, 0x9a, 0x3e, 0x17, 0x63, 0xe6, 0x8e, 0x1a, 0x34, 0xf7,
-	0xa9, 0x2c, 0x1d, 0xee, 0xd6, 0x30, 0x47, 0x8b, 0x5a, 0x6a, 0x53, 0x91, 0x41, 0x9c, 0xab, 0xe7,
-	0x92, 0x71, 0x7f, 0xd7, 0x8b, 0xa1, 0xc0, 0x6f, 0x55, 0x10, 0xce, 0x33, 0x4f, 0x11, 0xd2, 0x44,
-	0x3e, 0xaa, 0xd5, 0xf1, 0x52, 0x77, 0x4a, 0x0f, 0xc3, 0x40, 0x96, 0x0e, 0x0f, 0xb6, 0x3e, 0xd4,
-	0x8d, 0x0e, 0xf1, 0x75, 0x53, 0x3f, 0x36, 0x7b, 0xac, 0x99, 0xdd, 0xf4, 0x9a, 0xce, 0x7f, 0x37,
-	0xd1, 0x5f, 0xfa, 0x69, 0xff, 0x77, 0x00, 0x00, 0x00, 0xff, 0xff, 0x63, 0xf1, 0xc0, 0x13, 0xf5,
-	0x0c, 0x00, 0x00,
+	// 1134 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x98, 0x51, 0x6f, 0x1c, 0x35,
+	0x10, 0xc7, 0x75, 0x2f, 0x48, 0x58, 0xd0, 0x16, 0x97, 0x06, 0x08, 0x25, 0x6d, 0x93, 0xa6, 0xa1,
+	0xa5, 0xbd, 0xa4, 0x44, 0x48, 0x88, 0xb7, 0xf4, 0x72, 0x8a, 0x42, 0x13, 0xa9, 0xba, 0x53, 0x2a,
+	0x44, 0x45, 0x25, 0x9f, 0x77, 0x72, 0xb7, 0x64, 0x63, 0x2f, 0xb6, 0xef, 0xc4, 0x4a, 0x48, 0x48,
+	0x3c, 0x21, 0x1e, 0xf8, 0x06, 0x7c, 0x30, 0xbe, 0x0d, 0xb2, 0xd7, 0xe7, 0x9e, 0xbd, 0xeb, 0xdd,
+	0x3d, 0xde, 0xae, 0x9e, 0xdf, 0xfc, 0xff, 0xb3, 0xb3, 0xeb, 0xb1, 0x1b, 0x74, 0x7b, 0x02, 0x42,
+	0x15, 0xb9, 0xe0, 0x8a, 0x53, 0x9e, 0xf5, 0xcd, 0x0f, 0x7c, 0xc3, 0x2c, 0xf6, 0x97, 0xab, 0x9b,
+	0xb7, 0xcc, 0xbf, 0x55, 0x91, 0x83, 0x2c, 0x17, 0xbf, 0xfe, 0x77, 0x0f, 0xdd, 0x7c, 0x65, 0xc3,
+	0x63, 0x10, 0x8b, 0x94, 0x02, 0x4e, 0x10, 0x3e, 0x65, 0x52, 0x11, 0x46, 0x61, 0xf8, 0x6b, 0xce,
+	0x85, 0x3a, 0x26, 0x8a, 0xe0, 0xbd, 0x7e, 0x29, 0x56, 0x66, 0x57, 0x81, 0xfe, 0x08, 0x7e, 0x99,
+	0x83, 0x54, 0x9b, 0xbb, 0xed, 0x60, 0x9e, 0x15, 0x78, 0x81, 0x3e, 0x5d, 0xc6, 0x4e, 0x40, 0x0d,
+	0x38, 0xbb, 0x4c, 0xa7, 0x73, 0x41, 0x54, 0xca, 0x19, 0x7e, 0x56, 0x2b, 0x11, 0x62, 0xce, 0xf1,
+	0xab, 0xae, 0xb8, 0xf6, 0x1d, 0xa1, 0x0f, 0x8e, 0x41, 0x3f, 0xe7, 0x08, 0x16, 0xfc, 0x0a, 0xf0,
+	0x03, 0x2f, 0x79, 0x35, 0xe4, 0xf4, 0xef, 0x35, 0x21, 0x56, 0xf3, 0x25, 0x14, 0x52, 0x71, 0x01,
+	0x67, 0x9c, 0x5e, 0x05, 0x9a, 0xab, 0xa1, 0x88, 0x66, 0x80, 0x68, 0xcd, 0x1f, 0xd0, 0x8d, 0xe5,
+	0xea, 0x05, 0xcb, 0xb4, 0xea, 0x4e, 0x6d, 0x4a, 0x19, 0x74, 0xba, 0x0f, 0x9a, 0x21, 0xdb, 0xf9,
+	0xe5, 0xfa, 0x2b, 0x22, 0x65, 0x3e, 0x13, 0x44, 0xc2, 0x60, 0x46, 0xd8, 0x14, 0x82, 0xce, 0xc7,
+	0xb0, 0x48, 0xe7, 0x1b, 0x70, 0xed, 0x2b, 0xd1, 0x27, 0x03, 0xce, 0x14, 0xa1, 0xca, 0xa6, 0x8f,
+	0xe0, 0x12, 0x04, 0x30, 0x0a, 0xf8, 0xa9, 0xa7, 0x13, 0xa1, 0x9c, 0xeb, 0x93, 0x8e, 0xb4, 0x36,
+	0xbd, 0x46, 0x77, 0x7c, 0xe0, 0x38, 0x95, 0x64, 0x92, 0x01, 0x6e, 0x12, 0xb1, 0x8c, 0x33, 0xfc,
+	0xb2, 0x13, 0xab, 0xed, 0x7e, 0x46, 0x1f, 0xfb, 0xe1, 0x21, 0x33, 0x6e, 0x8f, 0x1b, 0x14, 0x86,
+	0xcc, 0x33, 0xdb, 0xeb, 0x82, 0x6a, 0xaf, 0x3f, 0x7a, 0xe8, 0x6e, 0xf8, 0xf0, 0x12, 0x56, 0xba,
+	0xfa, 0xbc, 0xb1, 0x4f, 0xab, 0xa8, 0x33, 0xdf, 0x5f, 0x27, 0x45, 0x17, 0x91, 0x20, 0xec, 0x53,
+	0x63, 0x60, 0x09, 0x6e, 0x7a, 0x06, 0x0d, 0x44, 0x86, 0x45, 0x2d, 0x58, 0xdb, 0xd6, 0x23, 0x4a,
+	0x21, 0x57, 0x8d, 0x6d, 0x2d, 0x91, 0x4e, 0x6d, 0x75, 0x68, 0xec, 0x8b, 0xa1, 0x44, 0x24, 0x6d,
+	0x5f, 0x8c, 0x66, 0xba, 0x7e, 0x31, 0x96, 0xb5, 0xb3, 0xc3, 0x86, 0x5f, 0x64, 0xd5, 0xd9, 0xb1,
+	0x1a, 0x8a, 0xcc, 0x8e, 0x00, 0xb1, 0xb3, 0xc3, 0xae, 0x5e, 0xb0, 0x49, 0xcd, 0xec, 0xf0, 0x83,
+	0x91, 0xd9, 0x51, 0x81, 0xfc, 0x3d, 0xfc, 0x1a, 0x44, 0x7a, 0x99, 0x52, 0x33, 0x5a, 0x07, 0x3c,
+	0x89, 0xec, 0xe1, 0x90, 0x6a, 0xde, 0xc3, 0x35, 0xb4, 0x36, 0xbd, 0x40, 0x1f, 0xae, 0x02, 0x05,
+	0xde, 0x8e, 0x26, 0x17, 0xce, 0xe0, 0x7e, 0x23, 0xa3, 0x65, 0x0b, 0xf4, 0xd9, 0x11, 0xa5, 0x7c,
+	0xce, 0xd4, 0x08, 0x28, 0x5f, 0x80, 0x28, 0xc6, 0x33, 0x22, 0x40, 0x9a, 0x2f, 0xb8, 0xef, 0xa5,
+	0x47, 0x39, 0x67, 0xf7, 0xb4, 0x33, 0xbf, 0xdc, 0xba, 0xb5, 0x8c, 0x95, 0x0b, 0xb6, 0x6e, 0x13,
+	0x1a, 0xd9, 0xba, 0x2d, 0x29, 0xba, 0x88, 0xdf, 0xd0, 0x66, 0x40, 0xd9, 0xf8, 0x59, 0x2a, 0x15,
+	0x6e, 0x94, 0x5b, 0x01, 0x9d, 0xff, 0xb3, 0xee, 0x09, 0xda, 0xfd, 0x77, 0xf4, 0x79, 0x5d, 0x8d,
+	0x23, 0xc8, 0x80, 0x48, 0xc0, 0x07, 0xad, 0x4f, 0x63, 0x49, 0xe7, 0xdf, 0x5f, 0x23, 0xa3, 0xf1,
+	0x1d, 0x0c, 0x78, 0x96, 0x01, 0xed, 0xf4, 0x0e, 0x2c, 0xba, 0xce, 0x3b, 0x78, 0x97, 0xa2, 0x8b,
+	0x98, 0xa2, 0xdb, 0xf6, 0xd3, 0x3c, 0xca, 0x52, 0x22, 0x5f, 0x42, 0x61, 0xbe, 0xbe, 0xda, 0xf1,
+	0xb1, 0x4a, 0x38, 0xc7, 0x47, 0x1d, 0x48, 0x6d, 0x94, 0xa3, 0x8d, 0xf3, 0x79, 0xa6, 0xd2, 0x73,
+	0xb8, 0x9e, 0x80, 0x38, 0x11, 0x7c, 0x9e, 0x0f, 0x04, 0x10, 0x05, 0xd8, 0x3f, 0xc3, 0xeb, 0x21,
+	0x67, 0xf7, 0xb8, 0x1b, 0x6c, 0x67, 0x76, 0x18, 0xff, 0x9e, 0xa7, 0x0c, 0x37, 0x4b, 0x68, 0x24,
+	0x32, 0xb3, 0x23, 0xa8, 0x9d, 0xd9, 0x61, 0xf4, 0x0c, 0xc8, 0x22, 0x3c, 0xe5, 0x6b, 0x99, 0xc8,
+	0xcc, 0x8e, 0xb1, 0xda, 0xee, 0x9f, 0x1e, 0xda, 0x0d, 0xe3, 0xa6, 0xe7, 0x23, 0x90, 0x3c, 0x5b,
+	0x80, 0xd0, 0x23, 0x3e, 0xe3, 0x12, 0xf0, 0x77, 0x8d, 0x9a, 0xb5, 0x39, 0xae, 0x9e, 0x6f, 0xff,
+	0x57, 0xae, 0xae, 0xef, 0xcf, 0x1e, 0xda, 0xaa, 0xf0, 0xc9, 0x75, 0xca, 0x46, 0x3c, 0x83, 0x13,
+	0x41, 0x98, 0xc2, 0x87, 0xcd, 0xe2, 0x1e, 0xec, 0x2a, 0x7a, 0xbe, 0x5e, 0x92, 0x2e, 0xe5, 0xef,
+	0x1e, 0xba, 0x1f, 0x82, 0xa7, 0x6c, 0x91, 0xaa, 0x72, 0xc8, 0x97, 0x9f, 0xe0, 0x37, 0x8d, 0xba,
+	0x21, 0xee, 0xca, 0x39, 0x5c, 0x37, 0x4d, 0x17, 0xf4, 0x06, 0xdd, 0x3c, 0xca, 0xf3, 0x73, 0x50,
+	0x24, 0x21, 0x8a, 0x98, 0xdd, 0xf6, 0xd0, 0xdf, 0xb5, 0x7e, 0xd4, 0xb9, 0x6d, 0xb7, 0x50, 0xf6,
+	0xe0, 0x35, 0x01, 0x29, 0xc9, 0x14, 0x8c, 0xf6, 0x4e, 0x35, 0xcb, 0x05, 0x23, 0x07, 0x6f, 0x05,
+	0xd2, 0xca, 0x6f, 0xd1, 0xad, 0x77, 0xeb, 0x23, 0x48, 0x08, 0x55, 0x78, 0x37, 0x92, 0x56, 0x86,
+	0x9d, 0xfa, 0x4e, 0x1b, 0x56, 0xa9, 0x7c, 0x98, 0xa4, 0x2a, 0x5a, 0xb9, 0x0e, 0xb6, 0x56, 0x6e,
+	0x21, 0xad, 0x3c, 0x43, 0x1b, 0xe6, 0x7d, 0xb8, 0x76, 0xcd, 0x27, 0x92, 0x8a, 0x74, 0x12, 0x4e,
+	0x9e, 0x7a, 0x28, 0x72, 0xe9, 0xf1, 0xe0, 0xe1, 0x02, 0x98, 0x3a, 0xe8, 0x61, 0x40, 0x77, 0xec,
+	0x7a, 0xd9, 0x3d, 0x67, 0xf4, 0xa4, 0x2e, 0xd7, 0x67, 0x9c, 0xcf, 0x56, 0x94, 0x5d, 0xda, 0xbc,
+	0x45, 0x1f, 0x79, 0xf6, 0xe6, 0xb8, 0x7c, 0x14, 0x2f, 0xcf, 0x3b, 0x25, 0x3b, 0x3c, 0xc6, 0x1b,
+	0x74, 0x6b, 0xd5, 0xd6, 0xc8, 0xef, 0x46, 0xab, 0xf2, 0xd4, 0xdb, 0x8b, 0x3f, 0x45, 0xef, 0xdb,
+	0xdd, 0x71, 0xc9, 0x71, 0x0d, 0xae, 0xd7, 0x9d, 0xdc, 0xdd, 0x68, 0xdc, 0xde, 0x9f, 0xcc, 0xd2,
+	0x30, 0x9f, 0xc1, 0x35, 0x08, 0x92, 0x8d, 0x41, 0xa9, 0x94, 0x4d, 0xe5, 0x18, 0x54, 0x70, 0x7f,
+	0x8a, 0x72, 0x91, 0xfb, 0x53, 0x13, 0x6f, 0x8f, 0x4d, 0x83, 0xbc, 0x06, 0x21, 0x53, 0xce, 0x2e,
+	0xf2, 0xa9, 0x20, 0x09, 0x04, 0xc7, 0x66, 0x0d, 0x11, 0x39, 0x36, 0xeb, 0x49, 0x7b, 0xf5, 0x3c,
+	0xa2, 0x2a, 0x5d, 0x10, 0x05, 0x06, 0x0a, 0xae, 0x9e, 0x5e, 0x2c, 0x72, 0xf5, 0x0c, 0x19, 0x3b,
+	0x84, 0x8e, 0x81, 0x78, 0xc2, 0x0f, 0x83, 0x3f, 0x32, 0x90, 0x5a, 0xe9, 0xed, 0x16, 0x4a, 0x8b,
+	0xff, 0xa4, 0xc5, 0x27, 0xf3, 0xa9, 0xfe, 0x32, 0xcc, 0xba, 0xac, 0x88, 0x7b, 0xd1, 0xa8, 0x78,
+	0x48, 0xe5, 0x59, 0x71, 0xd0, 0xc3, 0x02, 0x6d, 0x98, 0xd0, 0x29, 0x93, 0x39, 0xd0, 0x32, 0x3a,
+	0x56, 0x5c, 0x84, 0xfb, 0xb9, 0x1e, 0x8a, 0xdc, 0x24, 0xa2, 0x70, 0xe9, 0x79, 0x86, 0x90, 0x21,
+	0xca, 0x56, 0xdd, 0xab, 0xa6, 0xfa, 0x5d, 0xfa, 0x22, 0x0e, 0xd8, 0x59, 0x67, 0xd6, 0xf4, 0xa9,
+	0x69, 0x6e, 0x66, 0xc1, 0xac, 0xf3, 0x83, 0x91, 0x59, 0x57, 0x81, 0xb4, 0xf2, 0x5f, 0x3d, 0xb4,
+	0xe5, 0xfa, 0x76, 0xc1, 0x12, 0xa0, 0xa2, 0xc8, 0x95, 0xfe, 0x3f, 0xbb, 0xdd, 0x86, 0x32, 0x38,
+	0x78, 0x9b, 0xe1, 0xc8, 0xc1, 0xdb, 0x9a, 0x64, 0x9a, 0xf6, 0x62, 0xef, 0xc7, 0x5d, 0x9b, 0x05,
+	0x74, 0xb6, 0x6f, 0x7e, 0xee, 0x4f, 0xf9, 0x7e, 0x7e, 0x35, 0xdd, 0xf7, 0xfe, 0x58, 0x38, 0x79,
+	0xcf, 0xfc, 0x3a, 0xfc, 0x6f, 0x00, 0x74, 0x4c, 0xac, 0x0e, 0x44, 0x14, 0x00, 0x00,
 }
 
 // Reference imports to suppress errors if they are not otherwise used.
@@ -98,6 +119,14 @@ type ProtocolServiceClient interface {
 	InstanceExportData(ctx context.Context, in *bertytypes.InstanceExportData_Request, opts ...grpc.CallOption) (*bertytypes.InstanceExportData_Reply, error)
 	// InstanceGetConfiguration gets current configuration of this protocol instance
 	InstanceGetConfiguration(ctx context.Context, in *bertytypes.InstanceGetConfiguration_Request, opts ...grpc.CallOption) (*bertytypes.InstanceGetConfiguration_Reply, error)
+	// DeviceRevoke revokes a lost or compromised device of the account in the account group and in contact groups
+	DeviceRevoke(ctx context.Context, in *bertytypes.DeviceRevoke_Request, opts ...grpc.CallOption) (*bertytypes.DeviceRevoke_Reply, error)
+	// KeystoreLock forgets the key protecting the device keystore, no key can be read from it until it is unlocked, keys already in use by the instance are kept in memory
+	KeystoreLock(ctx context.Context, in *bertytypes.KeystoreLock_Request, opts ...grpc.CallOption) (*bertytypes.KeystoreLock_Reply, error)
+	// KeystoreUnlock unlocks the device keystore using its passphrase
+	KeystoreUnlock(ctx context.Context, in *bertytypes.KeystoreUnlock_Request, opts ...grpc.CallOption) (*bertytypes.KeystoreUnlock_Reply, error)
+	// KeystorePassphraseChange re-encrypts the device keystore using a new passphrase
+	KeystorePassphraseChange(ctx context.Context, in *bertytypes.KeystorePassphraseChange_Request, opts ...grpc.CallOption) (*bertytypes.KeystorePassphraseChange_Reply, error)
 	// ContactRequestReference retrieves the information required to create a reference (types.ie. included in a shareable link) to the current account
 	ContactRequestReference(ctx context.Context, in *bertytypes.ContactRequestReference_Request, opts ...grpc.CallOption) (*bertytypes.ContactRequestReference_Reply, error)
 	// ContactRequestDisable disables incoming contact requests
@@ -116,6 +145,20 @@ type ProtocolServiceClient interface {
 	ContactBlock(ctx context.Context, in *bertytypes.ContactBlock_Request, opts ...grpc.CallOption) (*bertytypes.ContactBlock_Reply, error)
 	// ContactUnblock unblocks a contact from sending requests
 	ContactUnblock(ctx context.Context, in *bertytypes.ContactUnblock_Request, opts ...grpc.CallOption) (*bertytypes.ContactUnblock_Reply, error)
+	// ContactVerificationCode returns the safety number shared with a contact and its verification state
+	ContactVerificationCode(ctx context.Context, in *bertytypes.ContactVerificationCode_Request, opts ...grpc.CallOption) (*bertytypes.ContactVerificationCode_Reply, error)
+	// ContactVerify marks the safety number shared with a contact as verified
+	ContactVerify(ctx context.Context, in *bertytypes.ContactVerify_Request, opts ...grpc.CallOption) (*bertytypes.ContactVerify_Reply, error)
+	// AccountRecoverySharesSend splits the account recovery secret and entrusts a share to each of the given contacts
+	AccountRecoverySharesSend(ctx context.Context, in *bertytypes.AccountRecoverySharesSend_Request, opts ...grpc.CallOption) (*bertytypes.AccountRecoverySharesSend_Reply, error)
+	// AccountRecoverySharesRequest asks the contacts holding the shares of a lost account to release them, over streams authenticated to the current account
+	AccountRecoverySharesRequest(ctx context.Context, in *bertytypes.AccountRecoverySharesRequest_Request, opts ...grpc.CallOption) (*bertytypes.AccountRecoverySharesRequest_Reply, error)
+	// AccountRecoveryRequestList lists the requests to release a share received from contacts
+	AccountRecoveryRequestList(ctx context.Context, in *bertytypes.AccountRecoveryRequestList_Request, opts ...grpc.CallOption) (*bertytypes.AccountRecoveryRequestList_Reply, error)
+	// AccountRecoveryShareRelease gives back the share entrusted by a lost account to the new account of its owner, once it has requested it
+	AccountRecoveryShareRelease(ctx context.Context, in *bertytypes.AccountRecoveryShareRelease_Request, opts ...grpc.CallOption) (*bertytypes.AccountRecoveryShareRelease_Reply, error)
+	// AccountRecoverySharesCollect gathers the shares released by contacts and rebuilds the recovery secret of a lost account
+	AccountRecoverySharesCollect(ctx context.Context, in *bertytypes.AccountRecoverySharesCollect_Request, opts ...grpc.CallOption) (*bertytypes.AccountRecoverySharesCollect_Reply, error)
 	// ContactAliasKeySend send an alias key to a contact, the contact will be able to assert that your account is being present on a multi-member group
 	ContactAliasKeySend(ctx context.Context, in *bertytypes.ContactAliasKeySend_Request, opts ...grpc.CallOption) (*bertytypes.ContactAliasKeySend_Reply, error)
 	// MultiMemberGroupCreate creates a new multi-member group
@@ -134,6 +177,10 @@ type ProtocolServiceClient interface {
 	AppMetadataSend(ctx context.Context, in *bertytypes.AppMetadataSend_Request, opts ...grpc.CallOption) (*bertytypes.AppMetadataSend_Reply, error)
 	// AppMessageSend adds an app event to the message store, the message is encrypted using a derived key and readable by current group members
 	AppMessageSend(ctx context.Context, in *bertytypes.AppMessageSend_Request, opts ...grpc.CallOption) (*bertytypes.AppMessageSend_Reply, error)
+	// AppMessageRedact retracts a message previously sent by the current device
+	AppMessageRedact(ctx context.Context, in *bertytypes.AppMessageRedact_Request, opts ...grpc.CallOption) (*bertytypes.AppMessageRedact_Reply, error)
+	// AppMessageEdit replaces the payload of a message previously sent by the current device
+	AppMessageEdit(ctx context.Context, in *bertytypes.AppMessageEdit_Request, opts ...grpc.CallOption) (*bertytypes.AppMessageEdit_Reply, error)
 	// GroupMetadataSubscribe subscribes to a group metadata updates (types.or it can also retrieve the history)
 	GroupMetadataSubscribe(ctx context.Context, in *bertytypes.GroupMetadataSubscribe_Request, opts ...grpc.CallOption) (ProtocolService_GroupMetadataSubscribeClient, error)
 	// GroupMessageSubscribe subscribes to a group message updates (types.or it can also retrieve the history)
@@ -144,6 +191,10 @@ type ProtocolServiceClient interface {
 	GroupMessageList(ctx context.Context, in *bertytypes.GroupMessageList_Request, opts ...grpc.CallOption) (ProtocolService_GroupMessageListClient, error)
 	// GroupInfo retrieves information about a group
 	GroupInfo(ctx context.Context, in *bertytypes.GroupInfo_Request, opts ...grpc.CallOption) (*bertytypes.GroupInfo_Reply, error)
+	// GroupEphemeralSettingsSet sets the lifetime of the messages of a group, messages are hidden and their keys deleted once expired
+	GroupEphemeralSettingsSet(ctx context.Context, in *bertytypes.GroupEphemeralSettingsSet_Request, opts ...grpc.CallOption) (*bertytypes.GroupEphemeralSettingsSet_Reply, error)
+	// GroupVersionUpgrade upgrades a group to a new version of the group format once every device of the group supports it
+	GroupVersionUpgrade(ctx context.Context, in *bertytypes.GroupVersionUpgrade_Request, opts ...grpc.CallOption) (*bertytypes.GroupVersionUpgrade_Reply, error)
 	// ActivateGroup explicitly opens a group, groups are automatically enabled when actions are performed on them
 	ActivateGroup(ctx context.Context, in *bertytypes.ActivateGroup_Request, opts ...grpc.CallOption) (*bertytypes.ActivateGroup_Reply, error)
 	// DeactivateGroup closes a group
@@ -151,6 +202,10 @@ type ProtocolServiceClient interface {
 	DebugListGroups(ctx context.Context, in *bertytypes.DebugListGroups_Request, opts ...grpc.CallOption) (ProtocolService_DebugListGroupsClient, error)
 	DebugInspectGroupStore(ctx context.Context, in *bertytypes.DebugInspectGroupStore_Request, opts ...grpc.CallOption) (ProtocolService_DebugInspectGroupStoreClient, error)
 	DebugGroup(ctx context.Context, in *bertytypes.DebugGroup_Request, opts ...grpc.CallOption) (*bertytypes.DebugGroup_Reply, error)
+	// DebugDiscovery returns the statistics of the discovery drivers, such as the peers found by each of them
+	DebugDiscovery(ctx context.Context, in *bertytypes.DebugDiscovery_Request, opts ...grpc.CallOption) (*bertytypes.DebugDiscovery_Reply, error)
+	// DebugListUndecryptableMessages lists the messages of a group which can't be opened yet, they are retried when new chain keys are received
+	DebugListUndecryptableMessages(ctx context.Context, in *bertytypes.DebugListUndecryptableMessages_Request, opts ...grpc.CallOption) (ProtocolService_DebugListUndecryptableMessagesClient, error)
 }
 
 type protocolServiceClient struct {
@@ -179,6 +234,42 @@ func (c *protocolServiceClient) InstanceGetConfiguration(ctx context.Context, in
 	return out, nil
 }
 
+func (c *protocolServiceClient) DeviceRevoke(ctx context.Context, in *bertytypes.DeviceRevoke_Request, opts ...grpc.CallOption) (*bertytypes.DeviceRevoke_Reply, error) {
+	out := new(bertytypes.DeviceRevoke_Reply)
+	err := c.cc.Invoke(ctx, "/berty.protocol.ProtocolService/DeviceRevoke", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *protocolServiceClient) KeystoreLock(ctx context.Context, in *bertytypes.KeystoreLock_Request, opts ...grpc.CallOption) (*bertytypes.KeystoreLock_Reply, error) {
+	out := new(bertytypes.KeystoreLock_Reply)
+	err := c.cc.Invoke(ctx, "/berty.protocol.ProtocolService/KeystoreLock", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *protocolServiceClient) KeystoreUnlock(ctx context.Context, in *bertytypes.KeystoreUnlock_Request, opts ...grpc.CallOption) (*bertytypes.KeystoreUnlock_Reply, error) {
+	out := new(bertytypes.KeystoreUnlock_Reply)
+	err := c.cc.Invoke(ctx, "/berty.protocol.ProtocolService/KeystoreUnlock", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *protocolServiceClient) KeystorePassphraseChange(ctx context.Context, in *bertytypes.KeystorePassphraseChange_Request, opts ...grpc.CallOption) (*bertytypes.KeystorePassphraseChange_Reply, error) {
+	out := new(bertytypes.KeystorePassphraseChange_Reply)
+	err := c.cc.Invoke(ctx, "/berty.protocol.ProtocolService/KeystorePassphraseChange", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *protocolServiceClient) ContactRequestReference(ctx context.Context, in *bertytypes.ContactRequestReference_Request, opts ...grpc.CallOption) (*bertytypes.ContactRequestReference_Reply, error) {
 	out := new(bertytypes.ContactRequestReference_Reply)
 	err := c.cc.Invoke(ctx, "/berty.protocol.ProtocolService/ContactRequestReference", in, out, opts...)
@@ -260,6 +351,69 @@ func (c *protocolServiceClient) ContactUnblock(ctx context.Context, in *bertytyp
 	return out, nil
 }
 
+func (c *protocolServiceClient) ContactVerificationCode(ctx context.Context, in *bertytypes.ContactVerificationCode_Request, opts ...grpc.CallOption) (*bertytypes.ContactVerificationCode_Reply, error) {
+	out := new(bertytypes.ContactVerificationCode_Reply)
+	err := c.cc.Invoke(ctx, "/berty.protocol.ProtocolService/ContactVerificationCode", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *protocolServiceClient) ContactVerify(ctx context.Context, in *bertytypes.ContactVerify_Request, opts ...grpc.CallOption) (*bertytypes.ContactVerify_Reply, error) {
+	out := new(bertytypes.ContactVerify_Reply)
+	err := c.cc.Invoke(ctx, "/berty.protocol.ProtocolService/ContactVerify", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *protocolServiceClient) AccountRecoverySharesSend(ctx context.Context, in *bertytypes.AccountRecoverySharesSend_Request, opts ...grpc.CallOption) (*bertytypes.AccountRecoverySharesSend_Reply, error) {
+	out := new(bertytypes.AccountRecoverySharesSend_Reply)
+	err := c.cc.Invoke(ctx, "/berty.protocol.ProtocolService/AccountRecoverySharesSend", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *protocolServiceClient) AccountRecoverySharesRequest(ctx context.Context, in *bertytypes.AccountRecoverySharesRequest_Request, opts ...grpc.CallOption) (*bertytypes.AccountRecoverySharesRequest_Reply, error) {
+	out := new(bertytypes.AccountRecoverySharesRequest_Reply)
+	err := c.cc.Invoke(ctx, "/berty.protocol.ProtocolService/AccountRecoverySharesRequest", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *protocolServiceClient) AccountRecoveryRequestList(ctx context.Context, in *bertytypes.AccountRecoveryRequestList_Request, opts ...grpc.CallOption) (*bertytypes.AccountRecoveryRequestList_Reply, error) {
+	out := new(bertytypes.AccountRecoveryRequestList_Reply)
+	err := c.cc.Invoke(ctx, "/berty.protocol.ProtocolService/AccountRecoveryRequestList", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *protocolServiceClient) AccountRecoveryShareRelease(ctx context.Context, in *bertytypes.AccountRecoveryShareRelease_Request, opts ...grpc.CallOption) (*bertytypes.AccountRecoveryShareRelease_Reply, error) {
+	out := new(bertytypes.AccountRecoveryShareRelease_Reply)
+	err := c.cc.Invoke(ctx, "/berty.protocol.ProtocolService/AccountRecoveryShareRelease", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *protocolServiceClient) AccountRecoverySharesCollect(ctx context.Context, in *bertytypes.AccountRecoverySharesCollect_Request, opts ...grpc.CallOption) (*bertytypes.AccountRecoverySharesCollect_Reply, error) {
+	out := new(bertytypes.AccountRecoverySharesCollect_Reply)
+	err := c.cc.Invoke(ctx, "/berty.protocol.ProtocolService/AccountRecoverySharesCollect", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *protocolServiceClient) ContactAliasKeySend(ctx context.Context, in *bertytypes.ContactAliasKeySend_Request, opts ...grpc.CallOption) (*bertytypes.ContactAliasKeySend_Reply, error) {
 	out := new(bertytypes.ContactAliasKeySend_Reply)
 	err := c.cc.Invoke(ctx, "/berty.protocol.ProtocolService/ContactAliasKeySend", in, out, opts...)
@@ -341,6 +495,24 @@ func (c *protocolServiceClient) AppMessageSend(ctx context.Context, in *bertytyp
 	return out, nil
 }
 
+func (c *protocolServiceClient) AppMessageRedact(ctx context.Context, in *bertytypes.AppMessageRedact_Request, opts ...grpc.CallOption) (*bertytypes.AppMessageRedact_Reply, error) {
+	out := new(bertytypes.AppMessageRedact_Reply)
+	err := c.cc.Invoke(ctx, "/berty.protocol.ProtocolService/AppMessageRedact", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *protocolServiceClient) AppMessageEdit(ctx context.Context, in *bertytypes.AppMessageEdit_Request, opts ...grpc.CallOption) (*bertytypes.AppMessageEdit_Reply, error) {
+	out := new(bertytypes.AppMessageEdit_Reply)
+	err := c.cc.Invoke(ctx, "/berty.protocol.ProtocolService/AppMessageEdit", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *protocolServiceClient) GroupMetadataSubscribe(ctx context.Context, in *bertytypes.GroupMetadataSubscribe_Request, opts ...grpc.CallOption) (ProtocolService_GroupMetadataSubscribeClient, error) {
 	stream, err := c.cc.NewStream(ctx, &_ProtocolService_serviceDesc.Streams[0], "/berty.protocol.ProtocolService/GroupMetadataSubscribe", opts...)
 	if err != nil {
@@ -478,6 +650,24 @@ func (c *protocolServiceClient) GroupInfo(ctx context.Context, in *bertytypes.Gr
 	return out, nil
 }
 
+func (c *protocolServiceClient) GroupEphemeralSettingsSet(ctx context.Context, in *bertytypes.GroupEphemeralSettingsSet_Request, opts ...grpc.CallOption) (*bertytypes.GroupEphemeralSettingsSet_Reply, error) {
+	out := new(bertytypes.GroupEphemeralSettingsSet_Reply)
+	err := c.cc.Invoke(ctx, "/berty.protocol.ProtocolService/GroupEphemeralSettingsSet", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *protocolServiceClient) GroupVersionUpgrade(ctx context.Context, in *bertytypes.GroupVersionUpgrade_Request, opts ...grpc.CallOption) (*bertytypes.GroupVersionUpgrade_Reply, error) {
+	out := new(bertytypes.GroupVersionUpgrade_Reply)
+	err := c.cc.Invoke(ctx, "/berty.protocol.ProtocolService/GroupVersionUpgrade", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *protocolServiceClient) ActivateGroup(ctx context.Context, in *bertytypes.ActivateGroup_Request, opts ...grpc.CallOption) (*bertytypes.ActivateGroup_Reply, error) {
 	out := new(bertytypes.ActivateGroup_Reply)
 	err := c.cc.Invoke(ctx, "/berty.protocol.ProtocolService/ActivateGroup", in, out, opts...)
@@ -569,12 +759,61 @@ func (c *protocolServiceClient) DebugGroup(ctx context.Context, in *bertytypes.D
 	return out, nil
 }
 
+func (c *protocolServiceClient) DebugDiscovery(ctx context.Context, in *bertytypes.DebugDiscovery_Request, opts ...grpc.CallOption) (*bertytypes.DebugDiscovery_Reply, error) {
+	out := new(bertytypes.DebugDiscovery_Reply)
+	err := c.cc.Invoke(ctx, "/berty.protocol.ProtocolService/DebugDiscovery", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *protocolServiceClient) DebugListUndecryptableMessages(ctx context.Context, in *bertytypes.DebugListUndecryptableMessages_Request, opts ...grpc.CallOption) (ProtocolService_DebugListUndecryptableMessagesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_ProtocolService_serviceDesc.Streams[6], "/berty.protocol.ProtocolService/DebugListUndecryptableMessages", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &protocolServiceDebugListUndecryptableMessagesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type ProtocolService_DebugListUndecryptableMessagesClient interface {
+	Recv() (*bertytypes.DebugListUndecryptableMessages_Reply, error)
+	grpc.ClientStream
+}
+
+type protocolServiceDebugListUndecryptableMessagesClient struct {
+	grpc.ClientStream
+}
+
+func (x *protocolServiceDebugListUndecryptableMessagesClient) Recv() (*bertytypes.DebugListUndecryptableMessages_Reply, error) {
+	m := new(bertytypes.DebugListUndecryptableMessages_Reply)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // ProtocolServiceServer is the server API for ProtocolService service.
 type ProtocolServiceServer interface {
 	// InstanceExportData exports instance data
 	InstanceExportData(context.Context, *bertytypes.InstanceExportData_Request) (*bertytypes.InstanceExportData_Reply, error)
 	// InstanceGetConfiguration gets current configuration of this protocol instance
 	InstanceGetConfiguration(context.Context, *bertytypes.InstanceGetConfiguration_Request) (*bertytypes.InstanceGetConfiguration_Reply, error)
+	// DeviceRevoke revokes a lost or compromised device of the account in the account group and in contact groups
+	DeviceRevoke(context.Context, *bertytypes.DeviceRevoke_Request) (*bertytypes.DeviceRevoke_Reply, error)
+	// KeystoreLock forgets the key protecting the device keystore, no key can be read from it until it is unlocked, keys already in use by the instance are kept in memory
+	KeystoreLock(context.Context, *bertytypes.KeystoreLock_Request) (*bertytypes.KeystoreLock_Reply, error)
+	// KeystoreUnlock unlocks the device keystore using its passphrase
+	KeystoreUnlock(context.Context, *bertytypes.KeystoreUnlock_Request) (*bertytypes.KeystoreUnlock_Reply, error)
+	// KeystorePassphraseChange re-encrypts the device keystore using a new passphrase
+	KeystorePassphraseChange(context.Context, *bertytypes.KeystorePassphraseChange_Request) (*bertytypes.KeystorePassphraseChange_Reply, error)
 	// ContactRequestReference retrieves the information required to create a reference (types.ie. included in a shareable link) to the current account
 	ContactRequestReference(context.Context, *bertytypes.ContactRequestReference_Request) (*bertytypes.ContactRequestReference_Reply, error)
 	// ContactRequestDisable disables incoming contact requests
@@ -593,6 +832,20 @@ type ProtocolServiceServer interface {
 	ContactBlock(context.Context, *bertytypes.ContactBlock_Request) (*bertytypes.ContactBlock_Reply, error)
 	// ContactUnblock unblocks a contact from sending requests
 	ContactUnblock(context.Context, *bertytypes.ContactUnblock_Request) (*bertytypes.ContactUnblock_Reply, error)
+	// ContactVerificationCode returns the safety number shared with a contact and its verification state
+	ContactVerificationCode(context.Context, *bertytypes.ContactVerificationCode_Request) (*bertytypes.ContactVerificationCode_Reply, error)
+	// ContactVerify marks the safety number shared with a contact as verified
+	ContactVerify(context.Context, *bertytypes.ContactVerify_Request) (*bertytypes.ContactVerify_Reply, error)
+	// AccountRecoverySharesSend splits the account recovery secret and entrusts a share to each of the given contacts
+	AccountRecoverySharesSend(context.Context, *bertytypes.AccountRecoverySharesSend_Request) (*bertytypes.AccountRecoverySharesSend_Reply, error)
+	// AccountRecoverySharesRequest asks the contacts holding the shares of a lost account to release them, over streams authenticated to the current account
+	AccountRecoverySharesRequest(context.Context, *bertytypes.AccountRecoverySharesRequest_Request) (*bertytypes.AccountRecoverySharesRequest_Reply, error)
+	// AccountRecoveryRequestList lists the requests to release a share received from contacts
+	AccountRecoveryRequestList(context.Context, *bertytypes.AccountRecoveryRequestList_Request) (*bertytypes.AccountRecoveryRequestList_Reply, error)
+	// AccountRecoveryShareRelease gives back the share entrusted by a lost account to the new account of its owner, once it has requested it
+	AccountRecoveryShareRelease(context.Context, *bertytypes.AccountRecoveryShareRelease_Request) (*bertytypes.AccountRecoveryShareRelease_Reply, error)
+	// AccountRecoverySharesCollect gathers the shares released by contacts and rebuilds the recovery secret of a lost account
+	AccountRecoverySharesCollect(context.Context, *bertytypes.AccountRecoverySharesCollect_Request) (*bertytypes.AccountRecoverySharesCollect_Reply, error)
 	// ContactAliasKeySend send an alias key to a contact, the contact will be able to assert that your account is being present on a multi-member group
 	ContactAliasKeySend(context.Context, *bertytypes.ContactAliasKeySend_Request) (*bertytypes.ContactAliasKeySend_Reply, error)
 	// MultiMemberGroupCreate creates a new multi-member group
@@ -611,6 +864,10 @@ type ProtocolServiceServer interface {
 	AppMetadataSend(context.Context, *bertytypes.AppMetadataSend_Request) (*bertytypes.AppMetadataSend_Reply, error)
 	// AppMessageSend adds an app event to the message store, the message is encrypted using a derived key and readable by current group members
 	AppMessageSend(context.Context, *bertytypes.AppMessageSend_Request) (*bertytypes.AppMessageSend_Reply, error)
+	// AppMessageRedact retracts a message previously sent by the current device
+	AppMessageRedact(context.Context, *bertytypes.AppMessageRedact_Request) (*bertytypes.AppMessageRedact_Reply, error)
+	// AppMessageEdit replaces the payload of a message previously sent by the current device
+	AppMessageEdit(context.Context, *bertytypes.AppMessageEdit_Request) (*bertytypes.AppMessageEdit_Reply, error)
 	// GroupMetadataSubscribe subscribes to a group metadata updates (types.or it can also retrieve the history)
 	GroupMetadataSubscribe(*bertytypes.GroupMetadataSubscribe_Request, ProtocolService_GroupMetadataSubscribeServer) error
 	// GroupMessageSubscribe subscribes to a group message updates (types.or it can also retrieve the history)
@@ -621,6 +878,10 @@ type ProtocolServiceServer interface {
 	GroupMessageList(*bertytypes.GroupMessageList_Request, ProtocolService_GroupMessageListServer) error
 	// GroupInfo retrieves information about a group
 	GroupInfo(context.Context, *bertytypes.GroupInfo_Request) (*bertytypes.GroupInfo_Reply, error)
+	// GroupEphemeralSettingsSet sets the lifetime of the messages of a group, messages are hidden and their keys deleted once expired
+	GroupEphemeralSettingsSet(context.Context, *bertytypes.GroupEphemeralSettingsSet_Request) (*bertytypes.GroupEphemeralSettingsSet_Reply, error)
+	// GroupVersionUpgrade upgrades a group to a new version of the group format once every device of the group supports it
+	GroupVersionUpgrade(context.Context, *bertytypes.GroupVersionUpgrade_Request) (*bertytypes.GroupVersionUpgrade_Reply, error)
 	// ActivateGroup explicitly opens a group, groups are automatically enabled when actions are performed on them
 	ActivateGroup(context.Context, *bertytypes.ActivateGroup_Request) (*bertytypes.ActivateGroup_Reply, error)
 	// DeactivateGroup closes a group
@@ -628,6 +889,10 @@ type ProtocolServiceServer interface {
 	DebugListGroups(*bertytypes.DebugListGroups_Request, ProtocolService_DebugListGroupsServer) error
 	DebugInspectGroupStore(*bertytypes.DebugInspectGroupStore_Request, ProtocolService_DebugInspectGroupStoreServer) error
 	DebugGroup(context.Context, *bertytypes.DebugGroup_Request) (*bertytypes.DebugGroup_Reply, error)
+	// DebugDiscovery returns the statistics of the discovery drivers, such as the peers found by each of them
+	DebugDiscovery(context.Context, *bertytypes.DebugDiscovery_Request) (*bertytypes.DebugDiscovery_Reply, error)
+	// DebugListUndecryptableMessages lists the messages of a group which can't be opened yet, they are retried when new chain keys are received
+	DebugListUndecryptableMessages(*bertytypes.DebugListUndecryptableMessages_Request, ProtocolService_DebugListUndecryptableMessagesServer) error
 }
 
 // UnimplementedProtocolServiceServer can be embedded to have forward compatible implementations.
@@ -640,6 +905,18 @@ func (*UnimplementedProtocolServiceServer) InstanceExportData(ctx context.Contex
 func (*UnimplementedProtocolServiceServer) InstanceGetConfiguration(ctx context.Context, req *bertytypes.InstanceGetConfiguration_Request) (*bertytypes.InstanceGetConfiguration_Reply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method InstanceGetConfiguration not implemented")
 }
+func (*UnimplementedProtocolServiceServer) DeviceRevoke(ctx context.Context, req *bertytypes.DeviceRevoke_Request) (*bertytypes.DeviceRevoke_Reply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeviceRevoke not implemented")
+}
+func (*UnimplementedProtocolServiceServer) KeystoreLock(ctx context.Context, req *bertytypes.KeystoreLock_Request) (*bertytypes.KeystoreLock_Reply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method KeystoreLock not implemented")
+}
+func (*UnimplementedProtocolServiceServer) KeystoreUnlock(ctx context.Context, req *bertytypes.KeystoreUnlock_Request) (*bertytypes.KeystoreUnlock_Reply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method KeystoreUnlock not implemented")
+}
+func (*UnimplementedProtocolServiceServer) KeystorePassphraseChange(ctx context.Context, req *bertytypes.KeystorePassphraseChange_Request) (*bertytypes.KeystorePassphraseChange_Reply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method KeystorePassphraseChange not implemented")
+}
 func (*UnimplementedProtocolServiceServer) ContactRequestReference(ctx context.Context, req *bertytypes.ContactRequestReference_Request) (*bertytypes.ContactRequestReference_Reply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ContactRequestReference not implemented")
 }
@@ -667,6 +944,27 @@ func (*UnimplementedProtocolServiceServer) ContactBlock(ctx context.Context, req
 func (*UnimplementedProtocolServiceServer) ContactUnblock(ctx context.Context, req *bertytypes.ContactUnblock_Request) (*bertytypes.ContactUnblock_Reply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ContactUnblock not implemented")
 }
+func (*UnimplementedProtocolServiceServer) ContactVerificationCode(ctx context.Context, req *bertytypes.ContactVerificationCode_Request) (*bertytypes.ContactVerificationCode_Reply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ContactVerificationCode not implemented")
+}
+func (*UnimplementedProtocolServiceServer) ContactVerify(ctx context.Context, req *bertytypes.ContactVerify_Request) (*bertytypes.ContactVerify_Reply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ContactVerify not implemented")
+}
+func (*UnimplementedProtocolServiceServer) AccountRecoverySharesSend(ctx context.Context, req *bertytypes.AccountRecoverySharesSend_Request) (*bertytypes.AccountRecoverySharesSend_Reply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AccountRecoverySharesSend not implemented")
+}
+func (*UnimplementedProtocolServiceServer) AccountRecoverySharesRequest(ctx context.Context, req *bertytypes.AccountRecoverySharesRequest_Request) (*bertytypes.AccountRecoverySharesRequest_Reply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AccountRecoverySharesRequest not implemented")
+}
+func (*UnimplementedProtocolServiceServer) AccountRecoveryRequestList(ctx context.Context, req *bertytypes.AccountRecoveryRequestList_Request) (*bertytypes.AccountRecoveryRequestList_Reply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AccountRecoveryRequestList not implemented")
+}
+func (*UnimplementedProtocolServiceServer) AccountRecoveryShareRelease(ctx context.Context, req *bertytypes.AccountRecoveryShareRelease_Request) (*bertytypes.AccountRecoveryShareRelease_Reply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AccountRecoveryShareRelease not implemented")
+}
+func (*UnimplementedProtocolServiceServer) AccountRecoverySharesCollect(ctx context.Context, req *bertytypes.AccountRecoverySharesCollect_Request) (*bertytypes.AccountRecoverySharesCollect_Reply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AccountRecoverySharesCollect not implemented")
+}
 func (*UnimplementedProtocolServiceServer) ContactAliasKeySend(ctx context.Context, req *bertytypes.ContactAliasKeySend_Request) (*bertytypes.ContactAliasKeySend_Reply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ContactAliasKeySend not implemented")
 }
@@ -694,6 +992,12 @@ func (*UnimplementedProtocolServiceServer) AppMetadataSend(ctx context.Context,
 func (*UnimplementedProtocolServiceServer) AppMessageSend(ctx context.Context, req *bertytypes.AppMessageSend_Request) (*bertytypes.AppMessageSend_Reply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method AppMessageSend not implemented")
 }
+func (*UnimplementedProtocolServiceServer) AppMessageRedact(ctx context.Context, req *bertytypes.AppMessageRedact_Request) (*bertytypes.AppMessageRedact_Reply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AppMessageRedact not implemented")
+}
+func (*UnimplementedProtocolServiceServer) AppMessageEdit(ctx context.Context, req *bertytypes.AppMessageEdit_Request) (*bertytypes.AppMessageEdit_Reply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method AppMessageEdit not implemented")
+}
 func (*UnimplementedProtocolServiceServer) GroupMetadataSubscribe(req *bertytypes.GroupMetadataSubscribe_Request, srv ProtocolService_GroupMetadataSubscribeServer) error {
 	return status.Errorf(codes.Unimplemented, "method GroupMetadataSubscribe not implemented")
 }
@@ -709,6 +1013,12 @@ func (*UnimplementedProtocolServiceServer) GroupMessageList(req *bertytypes.Grou
 func (*UnimplementedProtocolServiceServer) GroupInfo(ctx context.Context, req *bertytypes.GroupInfo_Request) (*bertytypes.GroupInfo_Reply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GroupInfo not implemented")
 }
+func (*UnimplementedProtocolServiceServer) GroupEphemeralSettingsSet(ctx context.Context, req *bertytypes.GroupEphemeralSettingsSet_Request) (*bertytypes.GroupEphemeralSettingsSet_Reply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GroupEphemeralSettingsSet not implemented")
+}
+func (*UnimplementedProtocolServiceServer) GroupVersionUpgrade(ctx context.Context, req *bertytypes.GroupVersionUpgrade_Request) (*bertytypes.GroupVersionUpgrade_Reply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GroupVersionUpgrade not implemented")
+}
 func (*UnimplementedProtocolServiceServer) ActivateGroup(ctx context.Context, req *bertytypes.ActivateGroup_Request) (*bertytypes.ActivateGroup_Reply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ActivateGroup not implemented")
 }
@@ -724,6 +1034,12 @@ func (*UnimplementedProtocolServiceServer) DebugInspectGroupStore(req *bertytype
 func (*UnimplementedProtocolServiceServer) DebugGroup(ctx context.Context, req *bertytypes.DebugGroup_Request) (*bertytypes.DebugGroup_Reply, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DebugGroup not implemented")
 }
+func (*UnimplementedProtocolServiceServer) DebugDiscovery(ctx context.Context, req *bertytypes.DebugDiscovery_Request) (*bertytypes.DebugDiscovery_Reply, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DebugDiscovery not implemented")
+}
+func (*UnimplementedProtocolServiceServer) DebugListUndecryptableMessages(req *bertytypes.DebugListUndecryptableMessages_Request, srv ProtocolService_DebugListUndecryptableMessagesServer) error {
+	return status.Errorf(codes.Unimplemented, "method DebugListUndecryptableMessages not implemented")
+}
 
 func RegisterProtocolServiceServer(s *grpc.Server, srv ProtocolServiceServer) {
 	s.RegisterService(&_ProtocolService_serviceDesc, srv)
@@ -765,6 +1081,78 @@ func _ProtocolService_InstanceGetConfiguration_Handler(srv interface{}, ctx cont
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ProtocolService_DeviceRevoke_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(bertytypes.DeviceRevoke_Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProtocolServiceServer).DeviceRevoke(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/berty.protocol.ProtocolService/DeviceRevoke",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProtocolServiceServer).DeviceRevoke(ctx, req.(*bertytypes.DeviceRevoke_Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProtocolService_KeystoreLock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(bertytypes.KeystoreLock_Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProtocolServiceServer).KeystoreLock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/berty.protocol.ProtocolService/KeystoreLock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProtocolServiceServer).KeystoreLock(ctx, req.(*bertytypes.KeystoreLock_Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProtocolService_KeystoreUnlock_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(bertytypes.KeystoreUnlock_Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProtocolServiceServer).KeystoreUnlock(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/berty.protocol.ProtocolService/KeystoreUnlock",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProtocolServiceServer).KeystoreUnlock(ctx, req.(*bertytypes.KeystoreUnlock_Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProtocolService_KeystorePassphraseChange_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(bertytypes.KeystorePassphraseChange_Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProtocolServiceServer).KeystorePassphraseChange(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/berty.protocol.ProtocolService/KeystorePassphraseChange",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProtocolServiceServer).KeystorePassphraseChange(ctx, req.(*bertytypes.KeystorePassphraseChange_Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ProtocolService_ContactRequestReference_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(bertytypes.ContactRequestReference_Request)
 	if err := dec(in); err != nil {
@@ -927,6 +1315,132 @@ func _ProtocolService_ContactUnblock_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ProtocolService_ContactVerificationCode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(bertytypes.ContactVerificationCode_Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProtocolServiceServer).ContactVerificationCode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/berty.protocol.ProtocolService/ContactVerificationCode",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProtocolServiceServer).ContactVerificationCode(ctx, req.(*bertytypes.ContactVerificationCode_Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProtocolService_ContactVerify_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(bertytypes.ContactVerify_Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProtocolServiceServer).ContactVerify(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/berty.protocol.ProtocolService/ContactVerify",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProtocolServiceServer).ContactVerify(ctx, req.(*bertytypes.ContactVerify_Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProtocolService_AccountRecoverySharesSend_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(bertytypes.AccountRecoverySharesSend_Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProtocolServiceServer).AccountRecoverySharesSend(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/berty.protocol.ProtocolService/AccountRecoverySharesSend",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProtocolServiceServer).AccountRecoverySharesSend(ctx, req.(*bertytypes.AccountRecoverySharesSend_Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProtocolService_AccountRecoverySharesRequest_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(bertytypes.AccountRecoverySharesRequest_Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProtocolServiceServer).AccountRecoverySharesRequest(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/berty.protocol.ProtocolService/AccountRecoverySharesRequest",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProtocolServiceServer).AccountRecoverySharesRequest(ctx, req.(*bertytypes.AccountRecoverySharesRequest_Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProtocolService_AccountRecoveryRequestList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(bertytypes.AccountRecoveryRequestList_Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProtocolServiceServer).AccountRecoveryRequestList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/berty.protocol.ProtocolService/AccountRecoveryRequestList",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProtocolServiceServer).AccountRecoveryRequestList(ctx, req.(*bertytypes.AccountRecoveryRequestList_Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProtocolService_AccountRecoveryShareRelease_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(bertytypes.AccountRecoveryShareRelease_Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProtocolServiceServer).AccountRecoveryShareRelease(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/berty.protocol.ProtocolService/AccountRecoveryShareRelease",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProtocolServiceServer).AccountRecoveryShareRelease(ctx, req.(*bertytypes.AccountRecoveryShareRelease_Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProtocolService_AccountRecoverySharesCollect_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(bertytypes.AccountRecoverySharesCollect_Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProtocolServiceServer).AccountRecoverySharesCollect(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/berty.protocol.ProtocolService/AccountRecoverySharesCollect",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProtocolServiceServer).AccountRecoverySharesCollect(ctx, req.(*bertytypes.AccountRecoverySharesCollect_Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ProtocolService_ContactAliasKeySend_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(bertytypes.ContactAliasKeySend_Request)
 	if err := dec(in); err != nil {
@@ -1089,6 +1603,42 @@ func _ProtocolService_AppMessageSend_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ProtocolService_AppMessageRedact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(bertytypes.AppMessageRedact_Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProtocolServiceServer).AppMessageRedact(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/berty.protocol.ProtocolService/AppMessageRedact",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProtocolServiceServer).AppMessageRedact(ctx, req.(*bertytypes.AppMessageRedact_Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProtocolService_AppMessageEdit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(bertytypes.AppMessageEdit_Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProtocolServiceServer).AppMessageEdit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/berty.protocol.ProtocolService/AppMessageEdit",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProtocolServiceServer).AppMessageEdit(ctx, req.(*bertytypes.AppMessageEdit_Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ProtocolService_GroupMetadataSubscribe_Handler(srv interface{}, stream grpc.ServerStream) error {
 	m := new(bertytypes.GroupMetadataSubscribe_Request)
 	if err := stream.RecvMsg(m); err != nil {
@@ -1191,6 +1741,42 @@ func _ProtocolService_GroupInfo_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ProtocolService_GroupEphemeralSettingsSet_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(bertytypes.GroupEphemeralSettingsSet_Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProtocolServiceServer).GroupEphemeralSettingsSet(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/berty.protocol.ProtocolService/GroupEphemeralSettingsSet",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProtocolServiceServer).GroupEphemeralSettingsSet(ctx, req.(*bertytypes.GroupEphemeralSettingsSet_Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProtocolService_GroupVersionUpgrade_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(bertytypes.GroupVersionUpgrade_Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProtocolServiceServer).GroupVersionUpgrade(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/berty.protocol.ProtocolService/GroupVersionUpgrade",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProtocolServiceServer).GroupVersionUpgrade(ctx, req.(*bertytypes.GroupVersionUpgrade_Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ProtocolService_ActivateGroup_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(bertytypes.ActivateGroup_Request)
 	if err := dec(in); err != nil {
@@ -1287,6 +1873,45 @@ func _ProtocolService_DebugGroup_Handler(srv interface{}, ctx context.Context, d
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ProtocolService_DebugDiscovery_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(bertytypes.DebugDiscovery_Request)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProtocolServiceServer).DebugDiscovery(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/berty.protocol.ProtocolService/DebugDiscovery",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProtocolServiceServer).DebugDiscovery(ctx, req.(*bertytypes.DebugDiscovery_Request))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProtocolService_DebugListUndecryptableMessages_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(bertytypes.DebugListUndecryptableMessages_Request)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ProtocolServiceServer).DebugListUndecryptableMessages(m, &protocolServiceDebugListUndecryptableMessagesServer{stream})
+}
+
+type ProtocolService_DebugListUndecryptableMessagesServer interface {
+	Send(*bertytypes.DebugListUndecryptableMessages_Reply) error
+	grpc.ServerStream
+}
+
+type protocolServiceDebugListUndecryptableMessagesServer struct {
+	grpc.ServerStream
+}
+
+func (x *protocolServiceDebugListUndecryptableMessagesServer) Send(m *bertytypes.DebugListUndecryptableMessages_Reply) error {
+	return x.ServerStream.SendMsg(m)
+}
+
 var _ProtocolService_serviceDesc = grpc.ServiceDesc{
 	ServiceName: "berty.protocol.ProtocolService",
 	HandlerType: (*ProtocolServiceServer)(nil),
@@ -1299,6 +1924,22 @@ var _ProtocolService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "InstanceGetConfiguration",
 			Handler:    _ProtocolService_InstanceGetConfiguration_Handler,
 		},
+		{
+			MethodName: "DeviceRevoke",
+			Handler:    _ProtocolService_DeviceRevoke_Handler,
+		},
+		{
+			MethodName: "KeystoreLock",
+			Handler:    _ProtocolService_KeystoreLock_Handler,
+		},
+		{
+			MethodName: "KeystoreUnlock",
+			Handler:    _ProtocolService_KeystoreUnlock_Handler,
+		},
+		{
+			MethodName: "KeystorePassphraseChange",
+			Handler:    _ProtocolService_KeystorePassphraseChange_Handler,
+		},
 		{
 			MethodName: "ContactRequestReference",
 			Handler:    _ProtocolService_ContactRequestReference_Handler,
@@ -1335,6 +1976,34 @@ var _ProtocolService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "ContactUnblock",
 			Handler:    _ProtocolService_ContactUnblock_Handler,
 		},
+		{
+			MethodName: "ContactVerificationCode",
+			Handler:    _ProtocolService_ContactVerificationCode_Handler,
+		},
+		{
+			MethodName: "ContactVerify",
+			Handler:    _ProtocolService_ContactVerify_Handler,
+		},
+		{
+			MethodName: "AccountRecoverySharesSend",
+			Handler:    _ProtocolService_AccountRecoverySharesSend_Handler,
+		},
+		{
+			MethodName: "AccountRecoverySharesRequest",
+			Handler:    _ProtocolService_AccountRecoverySharesRequest_Handler,
+		},
+		{
+			MethodName: "AccountRecoveryRequestList",
+			Handler:    _ProtocolService_AccountRecoveryRequestList_Handler,
+		},
+		{
+			MethodName: "AccountRecoveryShareRelease",
+			Handler:    _ProtocolService_AccountRecoveryShareRelease_Handler,
+		},
+		{
+			MethodName: "AccountRecoverySharesCollect",
+			Handler:    _ProtocolService_AccountRecoverySharesCollect_Handler,
+		},
 		{
 			MethodName: "ContactAliasKeySend",
 			Handler:    _ProtocolService_ContactAliasKeySend_Handler,
@@ -1371,10 +2040,26 @@ var _ProtocolService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "AppMessageSend",
 			Handler:    _ProtocolService_AppMessageSend_Handler,
 		},
+		{
+			MethodName: "AppMessageRedact",
+			Handler:    _ProtocolService_AppMessageRedact_Handler,
+		},
+		{
+			MethodName: "AppMessageEdit",
+			Handler:    _ProtocolService_AppMessageEdit_Handler,
+		},
 		{
 			MethodName: "GroupInfo",
 			Handler:    _ProtocolService_GroupInfo_Handler,
 		},
+		{
+			MethodName: "GroupEphemeralSettingsSet",
+			Handler:    _ProtocolService_GroupEphemeralSettingsSet_Handler,
+		},
+		{
+			MethodName: "GroupVersionUpgrade",
+			Handler:    _ProtocolService_GroupVersionUpgrade_Handler,
+		},
 		{
 			MethodName: "ActivateGroup",
 			Handler:    _ProtocolService_ActivateGroup_Handler,
@@ -1387,6 +2072,10 @@ var _ProtocolService_serviceDesc = grpc.ServiceDesc{
 			MethodName: "DebugGroup",
 			Handler:    _ProtocolService_DebugGroup_Handler,
 		},
+		{
+			MethodName: "DebugDiscovery",
+			Handler:    _ProtocolService_DebugDiscovery_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -1419,6 +2108,11 @@ var _ProtocolService_serviceDesc = grpc.ServiceDesc{
 			Handler:       _ProtocolService_DebugInspectGroupStore_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "DebugListUndecryptableMessages",
+			Handler:       _ProtocolService_DebugListUndecryptableMessages_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "bertyprotocol.proto",
 }
@@ -0,0 +1,191 @@
+package bertyprotocol
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"berty.tech/berty/v2/go/internal/handshake"
+	"berty.tech/berty/v2/go/pkg/errcode"
+	"github.com/libp2p/go-libp2p-core/peer"
+)
+
+// ContactRequestsLimits holds the anti-spam settings applied to incoming
+// contact requests, a zero value disables the associated limit
+type ContactRequestsLimits struct {
+	// ProofOfWorkDifficulty is the number of leading zero bits required from
+	// the hashcash-style proof of work attached by requesters in the handshake,
+	// requesters using the legacy handshake aren't asked for it
+	ProofOfWorkDifficulty uint32
+
+	// PeerInterval is the time needed by a single peer to regain one request,
+	// up to PeerBurst requests
+	PeerInterval time.Duration
+	PeerBurst    int
+
+	// GlobalInterval is the time needed to regain one request across all
+	// peers, up to GlobalBurst requests
+	GlobalInterval time.Duration
+	GlobalBurst    int
+
+	// MaxPendingIncoming is the maximum number of received requests waiting
+	// to be accepted or discarded, requests above it are refused
+	MaxPendingIncoming int
+
+	// HandshakeStepTimeout is the time given to each step of the handshake,
+	// peers stalling longer are disconnected
+	HandshakeStepTimeout time.Duration
+
+	// HandshakeTimeout is the time given to the whole handshake, including
+	// the exchange of the contact information
+	HandshakeTimeout time.Duration
+}
+
+// DefaultContactRequestsLimits returns the limits used when none are
+// specified in Opts
+func DefaultContactRequestsLimits() *ContactRequestsLimits {
+	return &ContactRequestsLimits{
+		ProofOfWorkDifficulty: 18,
+		PeerInterval:          time.Minute,
+		PeerBurst:             3,
+		GlobalInterval:        time.Second * 5,
+		GlobalBurst:           20,
+		MaxPendingIncoming:    100,
+		HandshakeStepTimeout:  handshake.DefaultStepTimeout,
+		HandshakeTimeout:      time.Second * 30,
+	}
+}
+
+// handshakeContext returns the context bounding a handshake and the exchange
+// of messages which follows it
+func (l *ContactRequestsLimits) handshakeContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if l.HandshakeTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, l.HandshakeTimeout)
+}
+
+// incomingLimiter applies the per peer and the global rate limits to the
+// streams opened by other peers
+type incomingLimiter struct {
+	peer   *rateLimiter
+	global *rateLimiter
+}
+
+func newIncomingLimiter(limits *ContactRequestsLimits) *incomingLimiter {
+	return &incomingLimiter{
+		peer:   newRateLimiter(limits.PeerInterval, limits.PeerBurst),
+		global: newRateLimiter(limits.GlobalInterval, limits.GlobalBurst),
+	}
+}
+
+// allow must be called before doing any work for an incoming stream, tokens
+// are only consumed if the stream is allowed by both limiters
+func (l *incomingLimiter) allow(remotePeer peer.ID) error {
+	if !l.peer.available(string(remotePeer)) {
+		return errcode.ErrContactRequestRateLimited.Wrap(fmt.Errorf("too many requests from peer"))
+	}
+
+	if !l.global.allow("") {
+		return errcode.ErrContactRequestRateLimited.Wrap(fmt.Errorf("too many requests"))
+	}
+
+	if !l.peer.allow(string(remotePeer)) {
+		return errcode.ErrContactRequestRateLimited.Wrap(fmt.Errorf("too many requests from peer"))
+	}
+
+	return nil
+}
+
+// maxRateLimiterBuckets is the number of tracked keys above which fully
+// refilled buckets are pruned
+const maxRateLimiterBuckets = 1024
+
+type rateBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// rateLimiter is a keyed token bucket, each key regains one token every
+// interval up to burst tokens
+type rateLimiter struct {
+	lock     sync.Mutex
+	interval time.Duration
+	burst    int
+	buckets  map[string]*rateBucket
+	now      func() time.Time
+}
+
+func (r *rateLimiter) refill(b *rateBucket, now time.Time) {
+	b.tokens += float64(now.Sub(b.last)) / float64(r.interval)
+	if b.tokens > float64(r.burst) {
+		b.tokens = float64(r.burst)
+	}
+
+	b.last = now
+}
+
+// allow consumes a token for key, returns false if none is available
+func (r *rateLimiter) allow(key string) bool {
+	return r.take(key, true)
+}
+
+// available returns true if a token is available for key without consuming
+// it
+func (r *rateLimiter) available(key string) bool {
+	return r.take(key, false)
+}
+
+func (r *rateLimiter) take(key string, consume bool) bool {
+	if r == nil || r.interval <= 0 || r.burst <= 0 {
+		return true
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	now := r.now()
+
+	b, ok := r.buckets[key]
+	if !ok {
+		if len(r.buckets) >= maxRateLimiterBuckets {
+			r.prune(now)
+		}
+
+		b = &rateBucket{tokens: float64(r.burst), last: now}
+		r.buckets[key] = b
+	}
+
+	r.refill(b, now)
+
+	if b.tokens < 1 {
+		return false
+	}
+
+	if consume {
+		b.tokens--
+	}
+
+	return true
+}
+
+// prune removes the buckets which have been fully refilled
+func (r *rateLimiter) prune(now time.Time) {
+	for key, b := range r.buckets {
+		r.refill(b, now)
+		if b.tokens >= float64(r.burst) {
+			delete(r.buckets, key)
+		}
+	}
+}
+
+func newRateLimiter(interval time.Duration, burst int) *rateLimiter {
+	return &rateLimiter{
+		interval: interval,
+		burst:    burst,
+		buckets:  map[string]*rateBucket{},
+		now:      time.Now,
+	}
+}
@@ -14,6 +14,7 @@ import (
 	p2phelpers "github.com/libp2p/go-libp2p-core/helpers"
 	"github.com/libp2p/go-libp2p-core/network"
 	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
 	"go.uber.org/zap"
 )
 
@@ -29,7 +30,7 @@ type pendingRequest struct {
 func (p *pendingRequest) update(ctx context.Context, contact *bertytypes.ShareableContact, ownMetadata []byte) {
 	p.lock.Lock()
 
-	if p.contact != nil && bytes.Equal(p.contact.PublicRendezvousSeed, contact.PublicRendezvousSeed) {
+	if p.contact != nil && bytes.Equal(p.contact.PublicRendezvousSeed, contact.PublicRendezvousSeed) && isSameAddrs(p.contact.Addrs, contact.Addrs) {
 		p.lock.Unlock()
 		return
 	}
@@ -46,6 +47,20 @@ func (p *pendingRequest) update(ctx context.Context, contact *bertytypes.Shareab
 
 	p.swiper.logger.Info("enqueued request updated")
 
+	// Dial the direct addresses first if any, then fall back to rendezvous
+	addrs, err := contact.GetAddrInfos()
+	if err != nil {
+		p.swiper.logger.Warn("unable to parse contact direct addresses", zap.Error(err))
+	}
+
+	for _, addr := range addrs {
+		p.lock.Lock()
+		if ctx.Err() == nil {
+			p.ch <- addr
+		}
+		p.lock.Unlock()
+	}
+
 	for addr := range p.swiper.watch(ctx, contact.PK, contact.PublicRendezvousSeed) {
 		p.lock.Lock()
 		if ctx.Err() == nil {
@@ -55,6 +70,20 @@ func (p *pendingRequest) update(ctx context.Context, contact *bertytypes.Shareab
 	}
 }
 
+func isSameAddrs(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
 func (p *pendingRequest) Close() error {
 	p.lock.Lock()
 	if p.cancel != nil {
@@ -92,6 +121,8 @@ type contactRequestsManager struct {
 	logger         *zap.Logger
 	swiper         *swiper
 	toAdd          map[string]*pendingRequest
+	limits         *ContactRequestsLimits
+	limiter        *incomingLimiter
 }
 
 func (c *contactRequestsManager) metadataRequestDisabled(_ *bertytypes.GroupMetadataEvent) error {
@@ -99,6 +130,7 @@ func (c *contactRequestsManager) metadataRequestDisabled(_ *bertytypes.GroupMeta
 	if c.announceCancel != nil {
 		c.announceCancel()
 		c.ipfs.RemoveStreamHandler(contactRequestV1)
+		c.ipfs.RemoveStreamHandler(contactRequestV2)
 	}
 
 	c.announceCancel = nil
@@ -108,6 +140,7 @@ func (c *contactRequestsManager) metadataRequestDisabled(_ *bertytypes.GroupMeta
 
 func (c *contactRequestsManager) metadataRequestEnabled(_ *bertytypes.GroupMetadataEvent) error {
 	c.ipfs.SetStreamHandler(contactRequestV1, c.incomingHandler)
+	c.ipfs.SetStreamHandler(contactRequestV2, c.incomingHandler)
 
 	c.enabled = true
 
@@ -142,6 +175,7 @@ func (c *contactRequestsManager) metadataRequestEnqueued(evt *bertytypes.GroupMe
 	return c.enqueueRequest(&bertytypes.ShareableContact{
 		PK:                   e.Contact.PK,
 		PublicRendezvousSeed: e.Contact.PublicRendezvousSeed,
+		Addrs:                e.Contact.Addrs,
 	}, e.OwnMetadata)
 }
 
@@ -198,7 +232,7 @@ func (c *contactRequestsManager) enqueueRequest(contact *bertytypes.ShareableCon
 					continue
 				}
 
-				stream, err := c.ipfs.NewStream(context.TODO(), addr.ID, contactRequestV1)
+				stream, err := c.ipfs.NewStream(context.TODO(), addr.ID, contactRequestV2, contactRequestV1)
 				if err != nil {
 					c.logger.Error("error while opening stream with other peer", zap.Error(err))
 					continue
@@ -265,7 +299,22 @@ func (c *contactRequestsManager) metadataWatcher(ctx context.Context) {
 	}()
 }
 
-const contactRequestV1 = "/berty/contact_req/1.0.0"
+const (
+	// contactRequestV1 uses the handshake predating the version negotiation,
+	// it is kept until every peer supports contactRequestV2
+	contactRequestV1 = "/berty/contact_req/1.0.0"
+	contactRequestV2 = "/berty/contact_req/2.0.0"
+)
+
+// handshakeVersionForProtocol returns the latest handshake version that can
+// be used on a contact request stream
+func handshakeVersionForProtocol(pid protocol.ID) uint32 {
+	if pid == contactRequestV1 {
+		return handshake.LegacyVersion
+	}
+
+	return handshake.CurrentVersion
+}
 
 func (c *contactRequestsManager) incomingHandler(stream network.Stream) {
 	defer func() {
@@ -274,15 +323,55 @@ func (c *contactRequestsManager) incomingHandler(stream network.Stream) {
 		}
 	}()
 
+	remotePeer := stream.Conn().RemotePeer()
+	if err := c.checkIncomingRequestAllowed(remotePeer); err != nil {
+		c.logger.Warn("incoming contact request refused", zap.String("peer", remotePeer.Pretty()), zap.Error(err))
+		return
+	}
+
 	reader := ggio.NewDelimitedReader(stream, 2048)
 	writer := ggio.NewDelimitedWriter(stream)
 
-	otherPK, err := handshake.ResponseUsingReaderWriter(reader, writer, c.accSK)
+	ctx, cancel := c.limits.handshakeContext(c.ctx)
+	defer cancel()
+
+	session := &handshake.Session{}
+	version := handshakeVersionForProtocol(stream.Protocol())
+	opts := []handshake.Option{
+		handshake.WithVersion(version),
+		handshake.WithStepTimeout(c.limits.HandshakeStepTimeout),
+		handshake.WithSession(session),
+	}
+
+	// requesters using the legacy handshake can't solve a proof of work, they
+	// are only limited by the rate limiter
+	if version != handshake.LegacyVersion {
+		opts = append(opts, handshake.WithProofOfWorkDifficulty(c.limits.ProofOfWorkDifficulty))
+	}
+
+	otherPK, err := handshake.ResponseContext(ctx, stream, reader, writer, c.accSK, opts...)
 	if err != nil {
 		c.logger.Error("an error occurred during handshake", zap.Error(err))
 		return
 	}
 
+	// The contact must be received before the end of the handshake timeout
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = stream.SetDeadline(deadline)
+	}
+
+	// The contact is sealed using the session key if both peers support it
+	contactReader := ggio.Reader(reader)
+	if session.HasCapability(handshake.HandshakeCapabilitySecureChannel) {
+		sc, err := handshake.NewSecureChannel(session, reader, writer)
+		if err != nil {
+			c.logger.Error("unable to open secure channel", zap.Error(err))
+			return
+		}
+
+		contactReader = sc
+	}
+
 	otherPKBytes, err := otherPK.Raw()
 	if err != nil {
 		c.logger.Error("an error occurred during serialization", zap.Error(err))
@@ -291,7 +380,7 @@ func (c *contactRequestsManager) incomingHandler(stream network.Stream) {
 
 	contact := &bertytypes.ShareableContact{}
 
-	if err := reader.ReadMsg(contact); err != nil {
+	if err := contactReader.ReadMsg(contact); err != nil {
 		c.logger.Error("an error occurred while retrieving contact information", zap.Error(err))
 		return
 	}
@@ -306,6 +395,15 @@ func (c *contactRequestsManager) incomingHandler(stream network.Stream) {
 		return
 	}
 
+	// The pending requests cap only applies to unknown requesters, contacts
+	// waiting to be requested are accepted right away
+	if !c.metadataStore.checkContactStatus(otherPK, bertytypes.ContactStateToRequest) {
+		if err := c.checkPendingIncomingLimit(); err != nil {
+			c.logger.Warn("incoming contact request refused", zap.String("peer", remotePeer.Pretty()), zap.Error(err))
+			return
+		}
+	}
+
 	if _, err = c.metadataStore.ContactRequestIncomingReceived(c.ctx, &bertytypes.ShareableContact{
 		PK:                   otherPKBytes,
 		PublicRendezvousSeed: contact.PublicRendezvousSeed,
@@ -316,6 +414,24 @@ func (c *contactRequestsManager) incomingHandler(stream network.Stream) {
 	}
 }
 
+// checkIncomingRequestAllowed applies the rate limits, it must be called
+// before doing any work for an incoming request
+func (c *contactRequestsManager) checkIncomingRequestAllowed(remotePeer peer.ID) error {
+	return c.limiter.allow(remotePeer)
+}
+
+func (c *contactRequestsManager) checkPendingIncomingLimit() error {
+	if c.limits.MaxPendingIncoming <= 0 {
+		return nil
+	}
+
+	if len(c.metadataStore.ListContactsByStatus(bertytypes.ContactStateReceived)) >= c.limits.MaxPendingIncoming {
+		return errcode.ErrContactRequestPendingLimit
+	}
+
+	return nil
+}
+
 func (c *contactRequestsManager) performSend(otherPK crypto.PubKey, stream network.Stream) {
 	defer func() {
 		if err := p2phelpers.FullClose(stream); err != nil {
@@ -353,12 +469,37 @@ func (c *contactRequestsManager) performSend(otherPK crypto.PubKey, stream netwo
 	reader := ggio.NewDelimitedReader(stream, 2048)
 	writer := ggio.NewDelimitedWriter(stream)
 
-	if err := handshake.RequestUsingReaderWriter(reader, writer, c.accSK, otherPK); err != nil {
+	ctx, cancel := c.limits.handshakeContext(c.ctx)
+	defer cancel()
+
+	session := &handshake.Session{}
+	if err := handshake.RequestContext(ctx, stream, reader, writer, c.accSK, otherPK,
+		handshake.WithVersion(handshakeVersionForProtocol(stream.Protocol())),
+		handshake.WithStepTimeout(c.limits.HandshakeStepTimeout),
+		handshake.WithSession(session),
+	); err != nil {
 		c.logger.Error("an error occurred during handshake", zap.Error(err))
 		return
 	}
 
-	if err := writer.WriteMsg(contact); err != nil {
+	// The contact must be sent before the end of the handshake timeout
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = stream.SetDeadline(deadline)
+	}
+
+	// The contact is sealed using the session key if both peers support it
+	contactWriter := ggio.Writer(writer)
+	if session.HasCapability(handshake.HandshakeCapabilitySecureChannel) {
+		sc, err := handshake.NewSecureChannel(session, reader, writer)
+		if err != nil {
+			c.logger.Error("unable to open secure channel", zap.Error(err))
+			return
+		}
+
+		contactWriter = sc
+	}
+
+	if err := contactWriter.WriteMsg(contact); err != nil {
 		c.logger.Error("an error occurred while sending own contact information", zap.Error(err))
 		return
 	}
@@ -387,12 +528,16 @@ func (c *contactRequestsManager) enableIncomingRequests() error {
 	return nil
 }
 
-func initContactRequestsManager(ctx context.Context, s *swiper, store *metadataStore, ipfs ipfsutil.ExtendedCoreAPI, logger *zap.Logger) error {
+func initContactRequestsManager(ctx context.Context, s *swiper, store *metadataStore, ipfs ipfsutil.ExtendedCoreAPI, logger *zap.Logger, limits *ContactRequestsLimits) error {
 	sk, err := store.devKS.AccountPrivKey()
 	if err != nil {
 		return err
 	}
 
+	if limits == nil {
+		limits = DefaultContactRequestsLimits()
+	}
+
 	cm := &contactRequestsManager{
 		metadataStore: store,
 		ipfs:          ipfs,
@@ -401,6 +546,8 @@ func initContactRequestsManager(ctx context.Context, s *swiper, store *metadataS
 		ctx:           ctx,
 		swiper:        s,
 		toAdd:         map[string]*pendingRequest{},
+		limits:        limits,
+		limiter:       newIncomingLimiter(limits),
 	}
 
 	go cm.metadataWatcher(ctx)
@@ -8,6 +8,8 @@ import (
 
 	"berty.tech/berty/v2/go/internal/testutil"
 	"berty.tech/berty/v2/go/pkg/bertytypes"
+	"berty.tech/berty/v2/go/pkg/errcode"
+	"github.com/libp2p/go-libp2p-core/peer"
 	libp2p_mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
 	"github.com/stretchr/testify/require"
 )
@@ -134,3 +136,56 @@ func TestContactRequestFlow(t *testing.T) {
 
 	require.NoError(t, err)
 }
+
+func TestContactRequestsRateLimiter(t *testing.T) {
+	now := time.Now()
+
+	l := newRateLimiter(time.Minute, 2)
+	l.now = func() time.Time { return now }
+
+	require.True(t, l.allow("peer1"))
+	require.True(t, l.allow("peer1"))
+	require.False(t, l.allow("peer1"))
+
+	// other keys have their own bucket
+	require.True(t, l.allow("peer2"))
+
+	// checking a bucket doesn't consume its tokens
+	require.True(t, l.available("peer3"))
+	require.True(t, l.available("peer3"))
+	require.True(t, l.allow("peer3"))
+	require.True(t, l.allow("peer3"))
+	require.False(t, l.available("peer3"))
+
+	now = now.Add(time.Second * 30)
+	require.False(t, l.allow("peer1"))
+
+	now = now.Add(time.Second * 30)
+	require.True(t, l.allow("peer1"))
+	require.False(t, l.allow("peer1"))
+
+	// tokens are capped to burst
+	now = now.Add(time.Hour)
+	require.True(t, l.allow("peer1"))
+	require.True(t, l.allow("peer1"))
+	require.False(t, l.allow("peer1"))
+
+	// a zero limit disables the limiter
+	require.True(t, newRateLimiter(0, 0).allow("peer1"))
+}
+
+func TestIncomingLimiter(t *testing.T) {
+	l := newIncomingLimiter(&ContactRequestsLimits{
+		PeerInterval:   time.Minute,
+		PeerBurst:      1,
+		GlobalInterval: time.Minute,
+		GlobalBurst:    2,
+	})
+
+	require.NoError(t, l.allow(peer.ID("peer1")))
+	testSameErrcodes(t, errcode.ErrContactRequestRateLimited, l.allow(peer.ID("peer1")))
+
+	// a peer refused by its own limit doesn't consume a global token
+	require.NoError(t, l.allow(peer.ID("peer2")))
+	testSameErrcodes(t, errcode.ErrContactRequestRateLimited, l.allow(peer.ID("peer3")))
+}
@@ -16,6 +16,11 @@ var eventTypesMapper = map[bertytypes.EventType]struct {
 }{
 	bertytypes.EventTypeGroupMemberDeviceAdded:                 {Message: &bertytypes.GroupAddMemberDevice{}, SigChecker: sigCheckerMemberDeviceAdded},
 	bertytypes.EventTypeGroupDeviceSecretAdded:                 {Message: &bertytypes.GroupAddDeviceSecret{}, SigChecker: sigCheckerDeviceSigned},
+	bertytypes.EventTypeGroupDeviceRevoked:                     {Message: &bertytypes.GroupRevokeDevice{}, SigChecker: sigCheckerDeviceRevoked},
+	bertytypes.EventTypeGroupEphemeralSettingsUpdated:          {Message: &bertytypes.GroupSetEphemeralSettings{}, SigChecker: sigCheckerDeviceSigned},
+	bertytypes.EventTypeGroupMemberLeft:                        {Message: &bertytypes.GroupMemberLeft{}, SigChecker: sigCheckerMemberLeft},
+	bertytypes.EventTypeGroupDeviceCapabilitiesAnnounced:       {Message: &bertytypes.GroupAnnounceCapabilities{}, SigChecker: sigCheckerDeviceSigned},
+	bertytypes.EventTypeGroupVersionUpgraded:                   {Message: &bertytypes.GroupUpgradeVersion{}, SigChecker: sigCheckerDeviceSigned},
 	bertytypes.EventTypeAccountGroupJoined:                     {Message: &bertytypes.AccountGroupJoined{}, SigChecker: sigCheckerDeviceSigned},
 	bertytypes.EventTypeAccountGroupLeft:                       {Message: &bertytypes.AccountGroupLeft{}, SigChecker: sigCheckerDeviceSigned},
 	bertytypes.EventTypeAccountContactRequestDisabled:          {Message: &bertytypes.AccountContactRequestDisabled{}, SigChecker: sigCheckerDeviceSigned},
@@ -28,7 +33,11 @@ var eventTypesMapper = map[bertytypes.EventType]struct {
 	bertytypes.EventTypeAccountContactRequestIncomingAccepted:  {Message: &bertytypes.AccountContactRequestAccepted{}, SigChecker: sigCheckerDeviceSigned},
 	bertytypes.EventTypeAccountContactBlocked:                  {Message: &bertytypes.AccountContactBlocked{}, SigChecker: sigCheckerDeviceSigned},
 	bertytypes.EventTypeAccountContactUnblocked:                {Message: &bertytypes.AccountContactUnblocked{}, SigChecker: sigCheckerDeviceSigned},
+	bertytypes.EventTypeAccountContactVerified:                 {Message: &bertytypes.AccountContactVerified{}, SigChecker: sigCheckerDeviceSigned},
+	bertytypes.EventTypeAccountGroupDeviceLinked:               {Message: &bertytypes.AccountGroupDeviceLinked{}, SigChecker: sigCheckerGroupDeviceLinked},
 	bertytypes.EventTypeContactAliasKeyAdded:                   {Message: &bertytypes.ContactAddAliasKey{}, SigChecker: sigCheckerDeviceSigned},
+	bertytypes.EventTypeContactRecoveryShareAdded:              {Message: &bertytypes.ContactAddRecoveryShare{}, SigChecker: sigCheckerDeviceSigned},
+	bertytypes.EventTypeContactRecoveryShareReleased:           {Message: &bertytypes.ContactReleaseRecoveryShare{}, SigChecker: sigCheckerDeviceSigned},
 	bertytypes.EventTypeMultiMemberGroupAliasResolverAdded:     {Message: &bertytypes.MultiMemberGroupAddAliasResolver{}, SigChecker: sigCheckerDeviceSigned},
 	bertytypes.EventTypeMultiMemberGroupInitialMemberAnnounced: {Message: &bertytypes.MultiMemberInitialMember{}, SigChecker: sigCheckerGroupSigned},
 	bertytypes.EventTypeMultiMemberGroupAdminRoleGranted:       {Message: &bertytypes.MultiMemberGrantAdminRole{}, SigChecker: sigCheckerDeviceSigned},
@@ -80,12 +89,29 @@ func getParentsForCID(log ipfslog.Log, c cid.Cid) []cid.Cid {
 func newGroupMetadataEventFromEntry(log ipfslog.Log, e ipfslog.Entry, metadata *bertytypes.GroupMetadata, event proto.Message, g *bertytypes.Group) (*bertytypes.GroupMetadataEvent, error) {
 	// TODO: if parent is a merge node we should return the next nodes of it
 
+	evtCtx := newEventContext(e.GetHash(), getParentsForCID(log, e.GetHash()), g)
+
+	// events of an unknown type are passed through with their raw payload
+	if _, ok := eventTypesMapper[metadata.EventType]; !ok {
+		metaEvent := &bertytypes.GroupMetadataEvent{
+			EventContext: evtCtx,
+			Metadata:     metadata,
+			Event:        metadata.Payload,
+			UnknownType:  true,
+		}
+
+		if signed, ok := event.(*bertytypes.DeviceSignedEvent); ok {
+			metaEvent.SignerDevicePK = signed.DevicePK
+		}
+
+		return metaEvent, nil
+	}
+
 	eventBytes, err := proto.Marshal(event)
 	if err != nil {
 		return nil, errcode.ErrSerialization
 	}
 
-	evtCtx := newEventContext(e.GetHash(), getParentsForCID(log, e.GetHash()), g)
 	return &bertytypes.GroupMetadataEvent{
 		EventContext: evtCtx,
 		Metadata:     metadata,
@@ -104,6 +130,10 @@ func openGroupEnvelope(g *bertytypes.Group, envelopeBytes []byte) (*bertytypes.G
 		return nil, nil, errcode.ErrInvalidInput.Wrap(err)
 	}
 
+	if env.Version > CurrentGroupVersion {
+		return nil, nil, errcode.ErrGroupUnsupportedVersion
+	}
+
 	nonce, err := cryptoutil.NonceSliceToArray(env.Nonce)
 	if err != nil {
 		return nil, nil, errcode.ErrSerialization.Wrap(err)
@@ -121,9 +151,12 @@ func openGroupEnvelope(g *bertytypes.Group, envelopeBytes []byte) (*bertytypes.G
 		return nil, nil, errcode.TODO.Wrap(err)
 	}
 
+	// events added by newer versions are kept, the metadata is returned along
+	// with the error so they can be surfaced, their signature is verified when
+	// they are signed by a device and the returned event is nil otherwise
 	et, ok := eventTypesMapper[metadataEvent.EventType]
 	if !ok {
-		return nil, nil, errcode.ErrInvalidInput
+		return metadataEvent, openUnknownEvent(metadataEvent), errcode.ErrGroupUnknownEventType
 	}
 
 	payload := proto.Clone(et.Message)
@@ -138,6 +171,22 @@ func openGroupEnvelope(g *bertytypes.Group, envelopeBytes []byte) (*bertytypes.G
 	return metadataEvent, payload, nil
 }
 
+// openUnknownEvent checks the signature of an event of an unknown type using
+// the layout shared by device signed events, nil is returned if the event
+// doesn't follow this layout or if the signature is invalid
+func openUnknownEvent(metadata *bertytypes.GroupMetadata) proto.Message {
+	event := &bertytypes.DeviceSignedEvent{}
+	if err := event.Unmarshal(metadata.Payload); err != nil || len(event.DevicePK) == 0 {
+		return nil
+	}
+
+	if err := sigCheckerDeviceSigned(nil, metadata, event); err != nil {
+		return nil
+	}
+
+	return event
+}
+
 func sealGroupEnvelope(g *bertytypes.Group, eventType bertytypes.EventType, payload proto.Marshaler, payloadSig []byte) ([]byte, error) {
 	payloadBytes, err := payload.Marshal()
 	if err != nil {
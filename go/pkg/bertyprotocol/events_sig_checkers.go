@@ -77,3 +77,94 @@ func sigCheckerMemberDeviceAdded(g *bertytypes.Group, metadata *bertytypes.Group
 
 	return sigCheckerDeviceSigned(g, metadata, message)
 }
+
+// deviceRevocationPayload returns the data signed by a member to revoke a
+// device, it differs from the one signed to add a device so the signatures
+// can't be swapped
+func deviceRevocationPayload(devicePK []byte) []byte {
+	return append([]byte("berty device revocation:"), devicePK...)
+}
+
+func sigCheckerDeviceRevoked(g *bertytypes.Group, metadata *bertytypes.GroupMetadata, message proto.Message) error {
+	msg, ok := message.(*bertytypes.GroupRevokeDevice)
+	if !ok {
+		return errcode.ErrDeserialization
+	}
+
+	memPK, err := crypto.UnmarshalEd25519PublicKey(msg.MemberPK)
+	if err != nil {
+		return errcode.ErrDeserialization.Wrap(err)
+	}
+
+	ok, err = memPK.Verify(deviceRevocationPayload(msg.RevokedDevicePK), msg.MemberSig)
+	if err != nil {
+		return errcode.ErrCryptoSignatureVerification.Wrap(err)
+	}
+
+	if !ok {
+		return errcode.ErrCryptoSignatureVerification
+	}
+
+	return sigCheckerDeviceSigned(g, metadata, message)
+}
+
+// groupDeviceLinkPayload returns the data signed by the key used by a device
+// in a multi-member group to link it to the device key of the account
+func groupDeviceLinkPayload(devicePK []byte) []byte {
+	return append([]byte("berty group device link:"), devicePK...)
+}
+
+func sigCheckerGroupDeviceLinked(g *bertytypes.Group, metadata *bertytypes.GroupMetadata, message proto.Message) error {
+	msg, ok := message.(*bertytypes.AccountGroupDeviceLinked)
+	if !ok {
+		return errcode.ErrDeserialization
+	}
+
+	groupDevPK, err := crypto.UnmarshalEd25519PublicKey(msg.GroupDevicePK)
+	if err != nil {
+		return errcode.ErrDeserialization.Wrap(err)
+	}
+
+	ok, err = groupDevPK.Verify(groupDeviceLinkPayload(msg.DevicePK), msg.GroupDeviceSig)
+	if err != nil {
+		return errcode.ErrCryptoSignatureVerification.Wrap(err)
+	}
+
+	if !ok {
+		return errcode.ErrCryptoSignatureVerification
+	}
+
+	return sigCheckerDeviceSigned(g, metadata, message)
+}
+
+// memberDeparturePayload returns the data signed by a member to leave a group,
+// it references the entry which made the member join so the departure can't
+// be replayed once the member joined again
+func memberDeparturePayload(groupPK []byte, joinCID []byte) []byte {
+	payload := append([]byte("berty member departure:"), groupPK...)
+
+	return append(payload, joinCID...)
+}
+
+func sigCheckerMemberLeft(g *bertytypes.Group, metadata *bertytypes.GroupMetadata, message proto.Message) error {
+	msg, ok := message.(*bertytypes.GroupMemberLeft)
+	if !ok {
+		return errcode.ErrDeserialization
+	}
+
+	memPK, err := crypto.UnmarshalEd25519PublicKey(msg.MemberPK)
+	if err != nil {
+		return errcode.ErrDeserialization.Wrap(err)
+	}
+
+	ok, err = memPK.Verify(memberDeparturePayload(g.PublicKey, msg.JoinCID), msg.MemberSig)
+	if err != nil {
+		return errcode.ErrCryptoSignatureVerification.Wrap(err)
+	}
+
+	if !ok {
+		return errcode.ErrCryptoSignatureVerification
+	}
+
+	return sigCheckerDeviceSigned(g, metadata, message)
+}
@@ -22,8 +22,20 @@ import (
 	"golang.org/x/crypto/nacl/box"
 )
 
+// CurrentGroupVersion is the latest version of the group envelope and event
+// formats supported by this implementation, it is announced to the other
+// devices of a group and a group is only upgraded once all of them support it
 const CurrentGroupVersion = 1
 
+// supportedGroupCapabilities are the features announced to the other devices
+// of a group
+var supportedGroupCapabilities = []bertytypes.GroupCapability{
+	bertytypes.GroupCapabilityDeviceRevocation,
+	bertytypes.GroupCapabilityEphemeralMessages,
+	bertytypes.GroupCapabilityMessageRecords,
+	bertytypes.GroupCapabilityMemberDeparture,
+}
+
 // NewGroupMultiMember creates a new Group object and an invitation to be used by
 // the first member of the group
 func NewGroupMultiMember() (*bertytypes.Group, crypto.PrivKey, error) {
@@ -149,10 +161,15 @@ func getGroupForAccount(priv, signing crypto.PrivKey) (*bertytypes.Group, error)
 }
 
 func metadataStoreListSecrets(ctx context.Context, gc *groupContext) map[crypto.PubKey]*bertytypes.DeviceSecret {
-	publishedSecrets := map[crypto.PubKey]*bertytypes.DeviceSecret{}
+	type publishedSecret struct {
+		pk      crypto.PubKey
+		ds      *bertytypes.DeviceSecret
+		rotated bool
+	}
+
+	secrets := map[string]*publishedSecret{}
 
 	m := gc.MetadataStore()
-	ownSK := gc.getMemberPrivKey()
 	g := gc.Group()
 
 	for meta := range m.ListEvents(ctx) {
@@ -160,7 +177,7 @@ func metadataStoreListSecrets(ctx context.Context, gc *groupContext) map[crypto.
 			continue
 		}
 
-		pk, ds, err := openDeviceSecret(meta.Metadata, ownSK, g)
+		pk, ds, err := openDeviceSecret(meta.Metadata, gc.memberDevice, g)
 		if errcode.Code(err) == errcode.ErrInvalidInput.Code() || errcode.Code(err) == errcode.ErrGroupSecretOtherDestMember.Code() {
 			continue
 		}
@@ -170,7 +187,23 @@ func metadataStoreListSecrets(ctx context.Context, gc *groupContext) map[crypto.
 			continue
 		}
 
-		publishedSecrets[pk] = ds
+		pkRaw, err := pk.Raw()
+		if err != nil {
+			continue
+		}
+
+		// a rotated secret supersedes the one initially sent by the device
+		rotated := isRotatedDeviceSecret(meta.Metadata)
+		if known, ok := secrets[string(pkRaw)]; ok && known.rotated && !rotated {
+			continue
+		}
+
+		secrets[string(pkRaw)] = &publishedSecret{pk: pk, ds: ds, rotated: rotated}
+	}
+
+	publishedSecrets := make(map[crypto.PubKey]*bertytypes.DeviceSecret, len(secrets))
+	for _, secret := range secrets {
+		publishedSecrets[secret.pk] = secret.ds
 	}
 
 	return publishedSecrets
@@ -186,7 +219,7 @@ func FillMessageKeysHolderUsingNewData(ctx context.Context, gc *groupContext) {
 				return
 			}
 
-			pk, ds, err := openDeviceSecret(e.Metadata, gc.getMemberPrivKey(), gc.Group())
+			pk, ds, err := openDeviceSecret(e.Metadata, gc.memberDevice, gc.Group())
 			if errcode.Code(err) == errcode.ErrInvalidInput.Code() || errcode.Code(err) == errcode.ErrGroupSecretOtherDestMember.Code() {
 				return
 			}
@@ -196,10 +229,17 @@ func FillMessageKeysHolderUsingNewData(ctx context.Context, gc *groupContext) {
 				return
 			}
 
-			if err = gc.MessageKeystore().RegisterChainKey(gc.Group(), pk, ds, gc.DevicePubKey().Equals(pk)); err != nil {
+			if isRotatedDeviceSecret(e.Metadata) {
+				if err = gc.MessageKeystore().RotateChainKey(gc.Group(), pk, ds, gc.DevicePubKey().Equals(pk)); err != nil {
+					gc.logger.Error("unable to rotate chain key", zap.Error(err))
+					return
+				}
+			} else if err = gc.MessageKeystore().RegisterChainKey(gc.Group(), pk, ds, gc.DevicePubKey().Equals(pk)); err != nil {
 				gc.logger.Error("unable to register chain key", zap.Error(err))
 				return
 			}
+
+			retryUndecryptableMessages(ctx, gc, pk)
 		}(evt)
 	}
 }
@@ -219,11 +259,32 @@ func FillMessageKeysHolderUsingPreviousData(ctx context.Context, gc *groupContex
 	}
 
 	wg.Wait()
+
+	retryUndecryptableMessages(ctx, gc, nil)
+}
+
+// retryUndecryptableMessages tries to open the messages of a device again
+// once its chain key is known, all the devices if pk is nil
+func retryUndecryptableMessages(ctx context.Context, gc *groupContext, pk crypto.PubKey) {
+	ms := gc.MessageStore()
+	if ms == nil {
+		return
+	}
+
+	var pkBytes []byte
+	if pk != nil {
+		var err error
+		if pkBytes, err = pk.Raw(); err != nil {
+			return
+		}
+	}
+
+	ms.RetryUndecryptableMessages(ctx, pkBytes)
 }
 
 func ActivateGroupContext(ctx context.Context, gc *groupContext) error {
 	wg := sync.WaitGroup{}
-	wg.Add(2)
+	wg.Add(3)
 
 	// Fill keystore
 	go func() {
@@ -236,6 +297,11 @@ func ActivateGroupContext(ctx context.Context, gc *groupContext) error {
 		WatchNewMembersAndSendSecrets(ctx, gc.logger, gc)
 	}()
 
+	go func() {
+		wg.Done()
+		WatchRevokedDevicesAndRotateSecrets(ctx, gc.logger, gc)
+	}()
+
 	wg.Wait()
 	wg.Add(2)
 
@@ -263,6 +329,10 @@ func ActivateGroupContext(ctx context.Context, gc *groupContext) error {
 	}
 	gc.logger.Info(fmt.Sprintf("AddDeviceToGroup took %s", time.Since(start)))
 
+	if _, err := gc.MetadataStore().AnnounceCapabilities(ctx); err != nil {
+		gc.logger.Warn("unable to announce device capabilities", zap.Error(err))
+	}
+
 	return nil
 }
 
@@ -319,16 +389,141 @@ func WatchNewMembersAndSendSecrets(ctx context.Context, logger *zap.Logger, gctx
 				return
 			}
 
+			if gctx.MetadataStore().HasMemberLeft(memberPK) {
+				return
+			}
+
 			if _, err := gctx.MetadataStore().SendSecret(ctx, memberPK); err != nil {
 				if errcode.Code(err) != errcode.ErrGroupSecretAlreadySentToMember.Code() {
 					logger.Error("unable to send secret to member", zap.Error(err))
 				}
 			}
+
+			devicePK, err := crypto.UnmarshalEd25519PublicKey(event.DevicePK)
+			if err != nil {
+				logger.Error("unable to unmarshal sender device pk", zap.Error(err))
+				return
+			}
+
+			if gctx.DevicePubKey().Equals(devicePK) {
+				return
+			}
+
+			// rotated secrets are only sent to the devices known at the time
+			// of the rotation
+			if _, err := gctx.MetadataStore().SendCurrentSecretToDevice(ctx, memberPK, devicePK); err != nil {
+				if errcode.Code(err) != errcode.ErrGroupSecretAlreadySentToMember.Code() {
+					logger.Error("unable to send secret to device", zap.Error(err))
+				}
+			}
 		}(evt)
 	}
 }
 
-func openDeviceSecret(m *bertytypes.GroupMetadata, localMemberPrivateKey crypto.PrivKey, group *bertytypes.Group) (crypto.PubKey, *bertytypes.DeviceSecret, error) {
+// WatchRevokedDevicesAndRotateSecrets replaces the chain key of the current
+// device when a device of the group is revoked or when a member leaves it and
+// sends it to the remaining devices, messages sent afterwards can't be opened
+// using the previous chain key
+func WatchRevokedDevicesAndRotateSecrets(ctx context.Context, logger *zap.Logger, gctx *groupContext) {
+	for evt := range gctx.MetadataStore().Subscribe(ctx) {
+		e, ok := evt.(*bertytypes.GroupMetadataEvent)
+		if !ok {
+			continue
+		}
+
+		switch e.Metadata.EventType {
+		case bertytypes.EventTypeGroupDeviceRevoked:
+			event := &bertytypes.GroupRevokeDevice{}
+			if err := event.Unmarshal(e.Event); err != nil {
+				logger.Error("unable to unmarshal payload", zap.Error(err))
+				continue
+			}
+
+			revokedPK, err := crypto.UnmarshalEd25519PublicKey(event.RevokedDevicePK)
+			if err != nil {
+				logger.Error("unable to unmarshal revoked device pk", zap.Error(err))
+				continue
+			}
+
+			if gctx.DevicePubKey().Equals(revokedPK) {
+				logger.Warn("current device has been revoked")
+				continue
+			}
+
+		case bertytypes.EventTypeGroupMemberLeft:
+			event := &bertytypes.GroupMemberLeft{}
+			if err := event.Unmarshal(e.Event); err != nil {
+				logger.Error("unable to unmarshal payload", zap.Error(err))
+				continue
+			}
+
+			memberPK, err := crypto.UnmarshalEd25519PublicKey(event.MemberPK)
+			if err != nil {
+				logger.Error("unable to unmarshal member pk", zap.Error(err))
+				continue
+			}
+
+			// the member has joined the group again or is the current one
+			if gctx.MemberPubKey().Equals(memberPK) || !gctx.MetadataStore().HasMemberLeft(memberPK) {
+				continue
+			}
+
+		default:
+			continue
+		}
+
+		if err := rotateDeviceSecret(ctx, gctx); err != nil {
+			logger.Error("unable to rotate device secret", zap.Error(err))
+		}
+	}
+}
+
+func rotateDeviceSecret(ctx context.Context, gctx *groupContext) error {
+	ds, err := newDeviceSecret()
+	if err != nil {
+		return err
+	}
+
+	if err := gctx.MessageKeystore().RotateChainKey(gctx.Group(), gctx.DevicePubKey(), ds, true); err != nil {
+		return err
+	}
+
+	// the secret is sent to each remaining device rather than to their
+	// member, revoked devices still own the key of their member
+	for _, devicePK := range gctx.MetadataStore().ListDevices() {
+		if devicePK.Equals(gctx.DevicePubKey()) || gctx.MetadataStore().IsDeviceRevoked(devicePK) {
+			continue
+		}
+
+		memberPK, err := gctx.MetadataStore().GetMemberByDevice(devicePK)
+		if err != nil || gctx.MetadataStore().HasMemberLeft(memberPK) {
+			continue
+		}
+
+		if _, err := gctx.MetadataStore().SendRotatedSecret(ctx, memberPK, devicePK, ds); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// isRotatedDeviceSecret checks if a device secret event replaces a secret
+// previously sent by the same device
+func isRotatedDeviceSecret(m *bertytypes.GroupMetadata) bool {
+	if m == nil || m.EventType != bertytypes.EventTypeGroupDeviceSecretAdded {
+		return false
+	}
+
+	s := &bertytypes.GroupAddDeviceSecret{}
+	if err := s.Unmarshal(m.Payload); err != nil {
+		return false
+	}
+
+	return len(s.Nonce) > 0
+}
+
+func openDeviceSecret(m *bertytypes.GroupMetadata, localMemberDevice *ownMemberDevice, group *bertytypes.Group) (crypto.PubKey, *bertytypes.DeviceSecret, error) {
 	if m == nil || m.EventType != bertytypes.EventTypeGroupDeviceSecretAdded {
 		return nil, nil, errcode.ErrInvalidInput
 	}
@@ -348,16 +543,37 @@ func openDeviceSecret(m *bertytypes.GroupMetadata, localMemberPrivateKey crypto.
 		return nil, nil, errcode.ErrDeserialization.Wrap(err)
 	}
 
-	if !localMemberPrivateKey.GetPublic().Equals(destMemberPubKey) {
+	if !localMemberDevice.member.GetPublic().Equals(destMemberPubKey) {
 		return nil, nil, errcode.ErrGroupSecretOtherDestMember
 	}
 
-	mongPriv, mongPub, err := cryptoutil.EdwardsToMontgomery(localMemberPrivateKey, senderDevicePubKey)
+	// rotated secrets are encrypted for a single device of the member
+	localPrivateKey := localMemberDevice.member
+	if len(s.DestDevicePK) > 0 {
+		destDevicePubKey, err := crypto.UnmarshalEd25519PublicKey(s.DestDevicePK)
+		if err != nil {
+			return nil, nil, errcode.ErrDeserialization.Wrap(err)
+		}
+
+		if !localMemberDevice.device.GetPublic().Equals(destDevicePubKey) {
+			return nil, nil, errcode.ErrGroupSecretOtherDestMember
+		}
+
+		localPrivateKey = localMemberDevice.device
+	}
+
+	mongPriv, mongPub, err := cryptoutil.EdwardsToMontgomery(localPrivateKey, senderDevicePubKey)
 	if err != nil {
 		return nil, nil, errcode.ErrCryptoKeyConversion.Wrap(err)
 	}
 
 	nonce := groupIDToNonce(group)
+	if len(s.Nonce) > 0 {
+		if nonce, err = cryptoutil.NonceSliceToArray(s.Nonce); err != nil {
+			return nil, nil, errcode.ErrDeserialization.Wrap(err)
+		}
+	}
+
 	decryptedSecret := &bertytypes.DeviceSecret{}
 	decryptedMessage, ok := box.Open(nil, s.Payload, nonce, mongPub, mongPriv)
 	if !ok {
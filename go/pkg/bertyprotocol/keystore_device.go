@@ -3,19 +3,24 @@ package bertyprotocol
 import (
 	"crypto/ed25519"
 	crand "crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
+	"fmt"
+	"io"
 	"strings"
 	"sync"
 
 	"math"
 	"math/big"
 
+	"berty.tech/berty/v2/go/internal/bip39"
 	"berty.tech/berty/v2/go/internal/cryptoutil"
 	"berty.tech/berty/v2/go/pkg/bertytypes"
 	"berty.tech/berty/v2/go/pkg/errcode"
 	"github.com/aead/ecdh"
 	"github.com/ipfs/go-ipfs/keystore"
 	"github.com/libp2p/go-libp2p-core/crypto"
+	"golang.org/x/crypto/hkdf"
 )
 
 type DeviceKeystore interface {
@@ -263,3 +268,84 @@ func NewWithExistingKeys(ks keystore.Keystore, sk crypto.PrivKey, proofSK crypto
 
 	return acc, nil
 }
+
+// GenerateAccountMnemonic returns a new random mnemonic sentence, to be used
+// with NewDeviceKeystoreFromMnemonic
+func GenerateAccountMnemonic() (string, error) {
+	entropy := make([]byte, bip39.EntropySize)
+	if _, err := crand.Read(entropy); err != nil {
+		return "", errcode.ErrCryptoRandomGeneration.Wrap(err)
+	}
+
+	return bip39.NewMnemonic(entropy)
+}
+
+// HasAccountKeys returns whether ks already holds the keys of an account
+func HasAccountKeys(ks keystore.Keystore) (bool, error) {
+	return ks.Has(keyAccount)
+}
+
+// NewDeviceKeystoreFromMnemonic creates a new deviceKeystore instance whose account keys are derived from mnemonic, the device key is generated when required
+func NewDeviceKeystoreFromMnemonic(ks keystore.Keystore, mnemonic string) (DeviceKeystore, error) {
+	sk, proofSK, err := accountKeysFromMnemonic(mnemonic)
+	if err != nil {
+		return nil, err
+	}
+
+	return newDeviceKeystoreWithAccountKeys(ks, sk, proofSK)
+}
+
+// newDeviceKeystoreWithAccountKeys registers the supplied account keys unless
+// ks already holds them, fails if ks holds another account
+func newDeviceKeystoreWithAccountKeys(ks keystore.Keystore, sk crypto.PrivKey, proofSK crypto.PrivKey) (DeviceKeystore, error) {
+	existingSK, err := ks.Get(keyAccount)
+	if err == nil {
+		if !existingSK.Equals(sk) {
+			return nil, errcode.ErrInvalidInput.Wrap(fmt.Errorf("keystore already holds another account"))
+		}
+
+		return NewDeviceKeystore(ks), nil
+	} else if err.Error() != keystore.ErrNoSuchKey.Error() {
+		return nil, err
+	}
+
+	return NewWithExistingKeys(ks, sk, proofSK)
+}
+
+// accountKeysFromMnemonic derives the account key and the account proof key
+// from the seed of mnemonic
+func accountKeysFromMnemonic(mnemonic string) (crypto.PrivKey, crypto.PrivKey, error) {
+	if !bip39.IsMnemonicValid(mnemonic) {
+		return nil, nil, errcode.ErrInvalidInput.Wrap(fmt.Errorf("invalid mnemonic"))
+	}
+
+	seed := bip39.NewSeed(mnemonic, "")
+
+	sk, err := deriveEd25519Key(seed, keyAccount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	proofSK, err := deriveEd25519Key(seed, keyAccountProof)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return sk, proofSK, nil
+}
+
+func deriveEd25519Key(seed []byte, info string) (crypto.PrivKey, error) {
+	keySeed := make([]byte, ed25519.SeedSize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, seed, nil, []byte(info)), keySeed); err != nil {
+		return nil, errcode.ErrCryptoKeyGeneration.Wrap(err)
+	}
+
+	stdKey := ed25519.NewKeyFromSeed(keySeed)
+
+	sk, _, err := crypto.KeyPairFromStdKey(&stdKey)
+	if err != nil {
+		return nil, errcode.ErrCryptoKeyConversion.Wrap(err)
+	}
+
+	return sk, nil
+}
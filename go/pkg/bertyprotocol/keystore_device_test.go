@@ -1,8 +1,15 @@
 package bertyprotocol
 
 import (
+	"strings"
 	"testing"
+	"time"
 
+	"berty.tech/berty/v2/go/internal/ipfsutil"
+	"berty.tech/berty/v2/go/pkg/bertytypes"
+	"berty.tech/berty/v2/go/pkg/errcode"
+	datastore "github.com/ipfs/go-datastore"
+	ds_sync "github.com/ipfs/go-datastore/sync"
 	keystore "github.com/ipfs/go-ipfs-keystore"
 	"github.com/stretchr/testify/assert"
 )
@@ -171,3 +178,210 @@ func Test_MemberDeviceForGroup_multimember(t *testing.T) {
 	assert.Equal(t, omd1MB, omd2MB)
 	assert.NotEqual(t, omd1DB, omd2DB)
 }
+
+func Test_EncryptedKeystore(t *testing.T) {
+	ds := ds_sync.MutexWrap(datastore.NewMapDatastore())
+
+	// existing plaintext entries are encrypted on first use
+	sk1, err := NewDeviceKeystore(ipfsutil.NewDatastoreKeystore(ds)).AccountPrivKey()
+	assert.NoError(t, err)
+
+	plainBytes, err := ds.Get(datastore.NewKey(keyAccount))
+	assert.NoError(t, err)
+
+	eks, err := ipfsutil.NewEncryptedDatastoreKeystore(ds, []byte("passphrase"))
+	assert.NoError(t, err)
+	assert.False(t, eks.IsLocked())
+
+	encryptedBytes, err := ds.Get(datastore.NewKey(keyAccount))
+	assert.NoError(t, err)
+	assert.NotEqual(t, plainBytes, encryptedBytes)
+
+	acc := NewDeviceKeystore(eks)
+
+	sk2, err := acc.AccountPrivKey()
+	assert.NoError(t, err)
+	assert.True(t, sk1.Equals(sk2))
+
+	devSK1, err := acc.DevicePrivKey()
+	assert.NoError(t, err)
+
+	// keys can't be used while locked
+	eks.Lock()
+	assert.True(t, eks.IsLocked())
+
+	_, err = acc.AccountPrivKey()
+	testSameErrcodes(t, errcode.ErrKeystoreLocked, err)
+
+	_, err = eks.Has(keyAccount)
+	testSameErrcodes(t, errcode.ErrKeystoreLocked, err)
+
+	_, err = eks.List()
+	testSameErrcodes(t, errcode.ErrKeystoreLocked, err)
+
+	testSameErrcodes(t, errcode.ErrKeystoreInvalidPassphrase, eks.Unlock([]byte("invalid")))
+	assert.True(t, eks.IsLocked())
+
+	assert.NoError(t, eks.Unlock([]byte("passphrase")))
+
+	sk3, err := acc.AccountPrivKey()
+	assert.NoError(t, err)
+	assert.True(t, sk1.Equals(sk3))
+
+	// changing the passphrase keeps the existing keys
+	assert.Error(t, eks.ChangePassphrase([]byte("invalid"), []byte("new passphrase")))
+	assert.NoError(t, eks.ChangePassphrase([]byte("passphrase"), []byte("new passphrase")))
+
+	_, err = ipfsutil.NewEncryptedDatastoreKeystore(ds, []byte("passphrase"))
+	assert.Error(t, err)
+
+	eks2, err := ipfsutil.NewEncryptedDatastoreKeystore(ds, []byte("new passphrase"))
+	assert.NoError(t, err)
+
+	acc2 := NewDeviceKeystore(eks2)
+
+	sk4, err := acc2.AccountPrivKey()
+	assert.NoError(t, err)
+	assert.True(t, sk1.Equals(sk4))
+
+	devSK2, err := acc2.DevicePrivKey()
+	assert.NoError(t, err)
+	assert.True(t, devSK1.Equals(devSK2))
+}
+
+func Test_DeviceKeystoreFromMnemonic(t *testing.T) {
+	mnemonic, err := GenerateAccountMnemonic()
+	assert.NoError(t, err)
+	assert.Len(t, strings.Fields(mnemonic), 24)
+
+	_, err = NewDeviceKeystoreFromMnemonic(keystore.NewMemKeystore(), "invalid mnemonic")
+	assert.Error(t, err)
+
+	acc1, err := NewDeviceKeystoreFromMnemonic(keystore.NewMemKeystore(), mnemonic)
+	assert.NoError(t, err)
+
+	acc2, err := NewDeviceKeystoreFromMnemonic(keystore.NewMemKeystore(), mnemonic)
+	assert.NoError(t, err)
+
+	sk1, err := acc1.AccountPrivKey()
+	assert.NoError(t, err)
+
+	sk2, err := acc2.AccountPrivKey()
+	assert.NoError(t, err)
+	assert.True(t, sk1.Equals(sk2))
+
+	skProof1, err := acc1.AccountProofPrivKey()
+	assert.NoError(t, err)
+
+	skProof2, err := acc2.AccountProofPrivKey()
+	assert.NoError(t, err)
+	assert.True(t, skProof1.Equals(skProof2))
+	assert.False(t, sk1.Equals(skProof1))
+
+	// each restored instance gets its own device key
+	dev1, err := acc1.DevicePrivKey()
+	assert.NoError(t, err)
+
+	dev2, err := acc2.DevicePrivKey()
+	assert.NoError(t, err)
+	assert.False(t, dev1.Equals(dev2))
+
+	// a keystore holding another account can't be used
+	ks := keystore.NewMemKeystore()
+	exists, err := HasAccountKeys(ks)
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	_, err = NewDeviceKeystore(ks).AccountPrivKey()
+	assert.NoError(t, err)
+
+	exists, err = HasAccountKeys(ks)
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	_, err = NewDeviceKeystoreFromMnemonic(ks, mnemonic)
+	assert.Error(t, err)
+}
+
+func Test_DeviceKeystoreFromRecoverySecret(t *testing.T) {
+	acc := NewDeviceKeystore(keystore.NewMemKeystore())
+	contact := NewDeviceKeystore(keystore.NewMemKeystore())
+
+	sk, err := acc.AccountPrivKey()
+	assert.NoError(t, err)
+
+	contactSK, err := contact.AccountPrivKey()
+	assert.NoError(t, err)
+
+	accountPK, err := sk.GetPublic().Raw()
+	assert.NoError(t, err)
+
+	secret, err := accountRecoverySecret(acc)
+	assert.NoError(t, err)
+	assert.True(t, checkRecoverySecret(secret, accountPK))
+
+	// shares are only readable by their recipient
+	share := &bertytypes.RecoveryShare{AccountPK: accountPK, Threshold: 2, Share: secret[:8]}
+	nonce, encrypted, err := sealRecoveryShare(sk, contactSK.GetPublic(), share)
+	assert.NoError(t, err)
+
+	opened, err := openRecoveryShare(contactSK, sk.GetPublic(), nonce, encrypted)
+	assert.NoError(t, err)
+	assert.Equal(t, share.Share, opened.Share)
+
+	_, err = openRecoveryShare(sk, sk.GetPublic(), nonce, encrypted)
+	assert.Error(t, err)
+
+	restored, err := NewDeviceKeystoreFromRecoverySecret(keystore.NewMemKeystore(), secret)
+	assert.NoError(t, err)
+
+	restoredSK, err := restored.AccountPrivKey()
+	assert.NoError(t, err)
+	assert.True(t, sk.Equals(restoredSK))
+
+	restoredProofSK, err := restored.AccountProofPrivKey()
+	assert.NoError(t, err)
+
+	proofSK, err := acc.AccountProofPrivKey()
+	assert.NoError(t, err)
+	assert.True(t, proofSK.Equals(restoredProofSK))
+
+	_, err = NewDeviceKeystoreFromRecoverySecret(keystore.NewMemKeystore(), secret[1:])
+	assert.Error(t, err)
+}
+
+func Test_RecoveryRequests(t *testing.T) {
+	lostPK := make([]byte, 32)
+	newPK := make([]byte, 32)
+	otherPK := make([]byte, 32)
+	lostPK[0], newPK[0], otherPK[0] = 1, 2, 3
+
+	now := time.Now()
+	requests := newRecoveryRequests()
+	assert.False(t, requests.has(lostPK, newPK, now))
+
+	requests.add(lostPK, newPK, now)
+	assert.True(t, requests.has(lostPK, newPK, now))
+
+	// a request only allows to release the shares to the account which sent it
+	assert.False(t, requests.has(lostPK, otherPK, now))
+	assert.False(t, requests.has(otherPK, newPK, now))
+
+	list := requests.list(now)
+	if assert.Len(t, list, 1) {
+		assert.Equal(t, lostPK, list[0].AccountPK)
+		assert.Equal(t, newPK, list[0].ContactPK)
+		assert.Equal(t, now.Unix(), list[0].ReceivedAt)
+	}
+
+	// requests expire
+	later := now.Add(recoveryRequestTTL)
+	assert.False(t, requests.has(lostPK, newPK, later))
+	assert.Empty(t, requests.list(later))
+	assert.False(t, requests.has(lostPK, newPK, now))
+
+	requests.add(lostPK, newPK, now)
+	requests.remove(lostPK, newPK)
+	assert.False(t, requests.has(lostPK, newPK, now))
+	assert.Empty(t, requests.list(now))
+}
@@ -2,7 +2,11 @@ package bertyprotocol
 
 import (
 	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"strconv"
 	"sync"
+	"time"
 
 	"fmt"
 
@@ -11,6 +15,7 @@ import (
 	"berty.tech/berty/v2/go/pkg/errcode"
 	cid "github.com/ipfs/go-cid"
 	datastore "github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
 	dssync "github.com/ipfs/go-datastore/sync"
 	"github.com/libp2p/go-libp2p-core/crypto"
 	"golang.org/x/crypto/nacl/secretbox"
@@ -18,10 +23,34 @@ import (
 
 type MessageKeystore struct {
 	lock                 sync.Mutex
-	preComputedKeysCount int
+	preComputedKeysCount map[bertytypes.GroupType]int
+	forwardSecure        bool
+	keyTTL               time.Duration
 	store                datastore.Datastore
 }
 
+// DefaultPreComputedKeysCount is the number of message keys computed in
+// advance for each device of a group when not configured for its type
+const DefaultPreComputedKeysCount = 100
+
+// MessageKeystoreOpts contains optional configuration flags for building a
+// new MessageKeystore
+type MessageKeystoreOpts struct {
+	// PreComputedKeysCount is the number of message keys computed in advance
+	// for each device of a group, by group type
+	PreComputedKeysCount map[bertytypes.GroupType]int
+
+	// ForwardSecure deletes the key of a message once it has been opened, its
+	// plaintext is cached instead so the key can't be recovered afterwards
+	ForwardSecure bool
+
+	// KeyTTL delays the deletion of message keys in forward secure mode, they
+	// are deleted by PurgeKeys once expired. Precomputed keys left behind the
+	// chain key of their device are also deleted once stale for KeyTTL, the
+	// late messages they were computed for can't be opened afterwards
+	KeyTTL time.Duration
+}
+
 type decryptInfo struct {
 	NewlyDecrypted bool
 	MK             *[32]byte
@@ -70,6 +99,10 @@ func (m *MessageKeystore) delPrecomputedKey(device crypto.PubKey, counter uint64
 		return errcode.ErrMessageKeyPersistencePut.Wrap(err)
 	}
 
+	if err := m.store.Delete(idForCachedKeyExpiry(id)); err != nil && err != datastore.ErrNotFound {
+		return errcode.ErrMessageKeyPersistencePut.Wrap(err)
+	}
+
 	return nil
 }
 
@@ -166,6 +199,28 @@ func (m *MessageKeystore) RegisterChainKey(g *bertytypes.Group, devicePK crypto.
 	return m.registerChainKey(g, devicePK, ds, isOwnPK)
 }
 
+// RotateChainKey replaces the chain key of a device, message keys derived from
+// the previous chain key are kept so pending messages can still be opened
+func (m *MessageKeystore) RotateChainKey(g *bertytypes.Group, devicePK crypto.PubKey, ds *bertytypes.DeviceSecret, isOwnPK bool) error {
+	if m == nil {
+		return errcode.ErrInvalidInput
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	deviceRaw, err := devicePK.Raw()
+	if err != nil {
+		return errcode.ErrSerialization.Wrap(err)
+	}
+
+	if err := m.store.Delete(idForCurrentCK(deviceRaw)); err != nil && err != datastore.ErrNotFound {
+		return errcode.ErrMessageKeyPersistencePut.Wrap(err)
+	}
+
+	return m.registerChainKey(g, devicePK, ds, isOwnPK)
+}
+
 func (m *MessageKeystore) registerChainKey(g *bertytypes.Group, devicePK crypto.PubKey, ds *bertytypes.DeviceSecret, isOwnPK bool) error {
 	if m == nil {
 		return errcode.ErrInvalidInput
@@ -211,7 +266,7 @@ func (m *MessageKeystore) preComputeKeys(device crypto.PubKey, g *bertytypes.Gro
 		return nil, errcode.ErrInternal.Wrap(err)
 	}
 
-	for i := 1; i <= m.getPrecomputedKeyExpectedCount(); i++ {
+	for i := 1; i <= m.getPrecomputedKeyExpectedCount(g); i++ {
 		counter++
 
 		knownMK, err := m.getPrecomputedKey(device, counter)
@@ -301,11 +356,26 @@ func (m *MessageKeystore) putKeyForCID(id cid.Cid, key *[32]byte) error {
 		return nil
 	}
 
+	// in forward secure mode the plaintext has been cached, the key is
+	// either dropped or kept until its expiry
+	if m.forwardSecure && m.keyTTL <= 0 {
+		return nil
+	}
+
 	err := m.store.Put(idForCID(id), key[:])
 	if err != nil {
 		return errcode.ErrMessageKeyPersistencePut.Wrap(err)
 	}
 
+	if m.forwardSecure {
+		expiry := make([]byte, 8)
+		binary.BigEndian.PutUint64(expiry, uint64(time.Now().Add(m.keyTTL).UnixNano()))
+
+		if err := m.store.Put(idForCIDExpiry(id), expiry); err != nil {
+			return errcode.ErrMessageKeyPersistencePut.Wrap(err)
+		}
+	}
+
 	return nil
 }
 
@@ -322,11 +392,21 @@ func (m *MessageKeystore) OpenEnvelope(ctx context.Context, g *bertytypes.Group,
 		return nil, nil, errcode.ErrCryptoDecrypt.Wrap(err)
 	}
 
+	if msg, err := m.getPlaintextForCID(id); err == nil {
+		return headers, msg, nil
+	}
+
 	msg, decryptInfo, err := m.openPayload(id, env.Message, headers)
 	if err != nil {
 		return nil, nil, errcode.ErrCryptoDecrypt.Wrap(err)
 	}
 
+	if m.forwardSecure && decryptInfo.NewlyDecrypted {
+		if err := m.putPlaintextForCID(id, msg); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	if err := m.postDecryptActions(decryptInfo, g, ownPK, headers); err != nil {
 		return nil, nil, errcode.TODO.Wrap(err)
 	}
@@ -371,6 +451,56 @@ func (m *MessageKeystore) openPayload(id cid.Cid, payload []byte, headers *berty
 	return msg, di, nil
 }
 
+// undecryptableReason checks why a message can't be opened, it also returns
+// the last counter for which a key has been computed for its device
+func (m *MessageKeystore) undecryptableReason(headers *bertytypes.MessageHeaders) (bertytypes.UndecryptableMessageReason, uint64) {
+	if m == nil {
+		return bertytypes.UndecryptableMessageReasonUndefined, 0
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	pk, err := crypto.UnmarshalEd25519PublicKey(headers.DevicePK)
+	if err != nil {
+		return bertytypes.UndecryptableMessageReasonInvalidEnvelope, 0
+	}
+
+	ds, err := m.getDeviceChainKey(pk)
+	if err != nil {
+		return bertytypes.UndecryptableMessageReasonUnknownDevice, 0
+	}
+
+	if _, err := m.getPrecomputedKey(pk, headers.Counter); err != nil {
+		return bertytypes.UndecryptableMessageReasonCounterOutOfWindow, ds.Counter
+	}
+
+	return bertytypes.UndecryptableMessageReasonDecryptionFailed, ds.Counter
+}
+
+// deviceKnownCounter returns the counter of the last message key computed for
+// a device
+func (m *MessageKeystore) deviceKnownCounter(devicePK []byte) (uint64, error) {
+	if m == nil {
+		return 0, errcode.ErrInvalidInput
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	pk, err := crypto.UnmarshalEd25519PublicKey(devicePK)
+	if err != nil {
+		return 0, errcode.ErrDeserialization.Wrap(err)
+	}
+
+	ds, err := m.getDeviceChainKey(pk)
+	if err != nil {
+		return 0, err
+	}
+
+	return ds.Counter, nil
+}
+
 func (m *MessageKeystore) getKeyForCID(id cid.Cid) (*[32]byte, error) {
 	if m == nil {
 		return nil, errcode.ErrInvalidInput
@@ -393,12 +523,279 @@ func (m *MessageKeystore) getKeyForCID(id cid.Cid) (*[32]byte, error) {
 	return keyArray, nil
 }
 
-func (m *MessageKeystore) getPrecomputedKeyExpectedCount() int {
+func (m *MessageKeystore) getPrecomputedKeyExpectedCount(g *bertytypes.Group) int {
 	if m == nil {
 		return 0
 	}
 
-	return m.preComputedKeysCount
+	if count, ok := m.preComputedKeysCount[g.GetGroupType()]; ok {
+		return count
+	}
+
+	return DefaultPreComputedKeysCount
+}
+
+// maxPrecomputedKeyExpectedCount returns the largest precompute window among
+// group types
+func (m *MessageKeystore) maxPrecomputedKeyExpectedCount() int {
+	max := DefaultPreComputedKeysCount
+	for _, count := range m.preComputedKeysCount {
+		if count > max {
+			max = count
+		}
+	}
+
+	return max
+}
+
+func (m *MessageKeystore) putPlaintextForCID(id cid.Cid, payload []byte) error {
+	if !id.Defined() {
+		return nil
+	}
+
+	if err := m.store.Put(idForCIDPlaintext(id), payload); err != nil {
+		return errcode.ErrMessageKeyPersistencePut.Wrap(err)
+	}
+
+	return nil
+}
+
+// getPlaintextForCID returns the plaintext cached in forward secure mode, it
+// is looked up regardless of the current mode as the key might be gone
+func (m *MessageKeystore) getPlaintextForCID(id cid.Cid) ([]byte, error) {
+	if !id.Defined() {
+		return nil, errcode.ErrInvalidInput
+	}
+
+	payload, err := m.store.Get(idForCIDPlaintext(id))
+	if err == datastore.ErrNotFound {
+		return nil, errcode.ErrMissingInput
+	} else if err != nil {
+		return nil, errcode.ErrMessageKeyPersistenceGet.Wrap(err)
+	}
+
+	return payload, nil
+}
+
+// PurgeKeys deletes the message keys expired in forward secure mode, along
+// with the precomputed keys left far behind the chain key of their device for
+// longer than the key TTL
+func (m *MessageKeystore) PurgeKeys() error {
+	if m == nil {
+		return errcode.ErrInvalidInput
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if err := m.purgeExpiredKeys(); err != nil {
+		return err
+	}
+
+	return m.purgeStalePrecomputedKeys()
+}
+
+// PurgeKeysPeriodically calls PurgeKeys at every interval until ctx is done
+func (m *MessageKeystore) PurgeKeysPeriodically(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_ = m.PurgeKeys()
+		}
+	}
+}
+
+// MessageReceivedAt returns the time at which a message has been seen for the
+// first time by the current device, now is recorded if it hasn't been seen
+// yet. The receipt is kept when the message is forgotten so its expiry can't
+// be postponed.
+func (m *MessageKeystore) MessageReceivedAt(id cid.Cid, now time.Time) (time.Time, error) {
+	if m == nil {
+		return time.Time{}, errcode.ErrInvalidInput
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	receipt, err := m.store.Get(idForCIDReceipt(id))
+	if err == nil && len(receipt) == 8 {
+		return time.Unix(0, int64(binary.BigEndian.Uint64(receipt))), nil
+	} else if err != nil && err != datastore.ErrNotFound {
+		return time.Time{}, errcode.ErrMessageKeyPersistenceGet.Wrap(err)
+	}
+
+	receipt = make([]byte, 8)
+	binary.BigEndian.PutUint64(receipt, uint64(now.UnixNano()))
+
+	if err := m.store.Put(idForCIDReceipt(id), receipt); err != nil {
+		return time.Time{}, errcode.ErrMessageKeyPersistencePut.Wrap(err)
+	}
+
+	return now, nil
+}
+
+// ForgetMessage deletes the key and the plaintext cached for a message, it
+// won't be readable anymore
+func (m *MessageKeystore) ForgetMessage(id cid.Cid) error {
+	if m == nil {
+		return errcode.ErrInvalidInput
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	for _, key := range []datastore.Key{idForCID(id), idForCIDExpiry(id), idForCIDPlaintext(id)} {
+		if err := m.store.Delete(key); err != nil && err != datastore.ErrNotFound {
+			return errcode.ErrMessageKeyPersistencePut.Wrap(err)
+		}
+	}
+
+	return nil
+}
+
+func (m *MessageKeystore) purgeExpiredKeys() error {
+	res, err := m.store.Query(query.Query{Prefix: idForCIDExpiryPrefix().String()})
+	if err != nil {
+		return errcode.ErrMessageKeyPersistenceGet.Wrap(err)
+	}
+
+	entries, err := res.Rest()
+	if err != nil {
+		return errcode.ErrMessageKeyPersistenceGet.Wrap(err)
+	}
+
+	now := time.Now()
+
+	for _, e := range entries {
+		if len(e.Value) != 8 || now.Before(time.Unix(0, int64(binary.BigEndian.Uint64(e.Value)))) {
+			continue
+		}
+
+		expiryKey := datastore.NewKey(e.Key)
+
+		id, err := cid.Decode(expiryKey.BaseNamespace())
+		if err != nil {
+			continue
+		}
+
+		if err := m.store.Delete(idForCID(id)); err != nil && err != datastore.ErrNotFound {
+			return errcode.ErrMessageKeyPersistencePut.Wrap(err)
+		}
+
+		if err := m.store.Delete(expiryKey); err != nil {
+			return errcode.ErrMessageKeyPersistencePut.Wrap(err)
+		}
+	}
+
+	return nil
+}
+
+// purgeStalePrecomputedKeys deletes the precomputed keys left behind the chain
+// key of their device, only in forward secure mode with a key TTL as messages
+// can be received long after being sent. Stale keys are first marked with an
+// expiry and deleted by a later purge once it is reached
+func (m *MessageKeystore) purgeStalePrecomputedKeys() error {
+	if !m.forwardSecure || m.keyTTL <= 0 {
+		return nil
+	}
+
+	res, err := m.store.Query(query.Query{Prefix: idForCachedKeyPrefix().String(), KeysOnly: true})
+	if err != nil {
+		return errcode.ErrMessageKeyPersistenceGet.Wrap(err)
+	}
+
+	entries, err := res.Rest()
+	if err != nil {
+		return errcode.ErrMessageKeyPersistenceGet.Wrap(err)
+	}
+
+	// keys behind the current counter by more than twice the window can't be
+	// used by a message still in flight
+	limit := uint64(m.maxPrecomputedKeyExpectedCount() * 2)
+	currentCounters := map[string]*uint64{}
+	now := time.Now()
+
+	for _, e := range entries {
+		key := datastore.NewKey(e.Key)
+
+		namespaces := key.Namespaces()
+		if len(namespaces) != 3 {
+			continue
+		}
+
+		counter, err := strconv.ParseUint(namespaces[2], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		current, ok := currentCounters[namespaces[1]]
+		if !ok {
+			current = m.getCurrentCounter(namespaces[1])
+			currentCounters[namespaces[1]] = current
+		}
+
+		// counters are compared using wrapping arithmetic as they start at a
+		// random value
+		if current == nil || *current-counter <= limit {
+			continue
+		}
+
+		expiryKey := idForCachedKeyExpiry(key)
+
+		expiry, err := m.store.Get(expiryKey)
+		if err == datastore.ErrNotFound {
+			expiry = make([]byte, 8)
+			binary.BigEndian.PutUint64(expiry, uint64(now.Add(m.keyTTL).UnixNano()))
+
+			if err := m.store.Put(expiryKey, expiry); err != nil {
+				return errcode.ErrMessageKeyPersistencePut.Wrap(err)
+			}
+
+			continue
+		} else if err != nil {
+			return errcode.ErrMessageKeyPersistenceGet.Wrap(err)
+		}
+
+		if len(expiry) == 8 && now.Before(time.Unix(0, int64(binary.BigEndian.Uint64(expiry)))) {
+			continue
+		}
+
+		if err := m.store.Delete(key); err != nil {
+			return errcode.ErrMessageKeyPersistencePut.Wrap(err)
+		}
+
+		if err := m.store.Delete(expiryKey); err != nil {
+			return errcode.ErrMessageKeyPersistencePut.Wrap(err)
+		}
+	}
+
+	return nil
+}
+
+// getCurrentCounter returns the counter of the current chain key of a device
+// identified by its hex encoded key, nil if unknown
+func (m *MessageKeystore) getCurrentCounter(hexPK string) *uint64 {
+	pk, err := hex.DecodeString(hexPK)
+	if err != nil {
+		return nil
+	}
+
+	dsBytes, err := m.store.Get(idForCurrentCK(pk))
+	if err != nil {
+		return nil
+	}
+
+	ds := &bertytypes.DeviceSecret{}
+	if err := ds.Unmarshal(dsBytes); err != nil {
+		return nil
+	}
+
+	return &ds.Counter
 }
 
 func (m *MessageKeystore) putDeviceChainKey(device crypto.PubKey, ds *bertytypes.DeviceSecret) error {
@@ -427,6 +824,12 @@ func (m *MessageKeystore) putDeviceChainKey(device crypto.PubKey, ds *bertytypes
 }
 
 func (m *MessageKeystore) SealEnvelope(ctx context.Context, g *bertytypes.Group, deviceSK crypto.PrivKey, payload []byte) ([]byte, error) {
+	return m.SealEnvelopeWithMetadata(ctx, g, deviceSK, payload, nil)
+}
+
+// SealEnvelopeWithMetadata seals a message and adds the given metadata to its
+// headers, they are only readable by the group members
+func (m *MessageKeystore) SealEnvelopeWithMetadata(ctx context.Context, g *bertytypes.Group, deviceSK crypto.PrivKey, payload []byte, metadata map[string]string) ([]byte, error) {
 	if m == nil {
 		return nil, errcode.ErrInvalidInput
 	}
@@ -443,7 +846,7 @@ func (m *MessageKeystore) SealEnvelope(ctx context.Context, g *bertytypes.Group,
 		return nil, errcode.ErrInternal.Wrap(err)
 	}
 
-	env, err := sealEnvelopeInternal(ctx, payload, ds, deviceSK, g)
+	env, err := sealEnvelopeInternal(ctx, payload, metadata, ds, deviceSK, g)
 	if err != nil {
 		return nil, errcode.ErrCryptoEncrypt.Wrap(err)
 	}
@@ -511,8 +914,20 @@ func (m *MessageKeystore) updateCurrentKey(pk crypto.PubKey, ds *bertytypes.Devi
 
 // NewMessageKeystore instantiate a new MessageKeystore
 func NewMessageKeystore(s datastore.Datastore) *MessageKeystore {
+	return NewMessageKeystoreWithOpts(s, nil)
+}
+
+// NewMessageKeystoreWithOpts instantiate a new MessageKeystore using the
+// supplied options
+func NewMessageKeystoreWithOpts(s datastore.Datastore, opts *MessageKeystoreOpts) *MessageKeystore {
+	if opts == nil {
+		opts = &MessageKeystoreOpts{}
+	}
+
 	return &MessageKeystore{
-		preComputedKeysCount: 100,
+		preComputedKeysCount: opts.PreComputedKeysCount,
+		forwardSecure:        opts.ForwardSecure,
+		keyTTL:               opts.KeyTTL,
 		store:                s,
 	}
 }
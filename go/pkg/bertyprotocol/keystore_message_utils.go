@@ -39,7 +39,7 @@ func sealPayload(payload []byte, ds *bertytypes.DeviceSecret, deviceSK crypto.Pr
 	return secretbox.Seal(nil, payload, uint64AsNonce(ds.Counter+1), &msgKey), sig, nil
 }
 
-func sealEnvelopeInternal(ctx context.Context, payload []byte, ds *bertytypes.DeviceSecret, deviceSK crypto.PrivKey, g *bertytypes.Group) ([]byte, error) {
+func sealEnvelopeInternal(ctx context.Context, payload []byte, metadata map[string]string, ds *bertytypes.DeviceSecret, deviceSK crypto.PrivKey, g *bertytypes.Group) ([]byte, error) {
 	encryptedPayload, sig, err := sealPayload(payload, ds, deviceSK, g)
 	if err != nil {
 		return nil, errcode.ErrCryptoEncrypt.Wrap(err)
@@ -56,6 +56,13 @@ func sealEnvelopeInternal(ctx context.Context, payload []byte, ds *bertytypes.De
 		Sig:      sig,
 	}
 
+	if len(metadata) > 0 {
+		h.Metadata = make(map[string]string, len(metadata))
+		for k, v := range metadata {
+			h.Metadata[k] = v
+		}
+	}
+
 	tracer.InjectSpanContextToMessageHeaders(ctx, h)
 
 	headers, err := proto.Marshal(h)
@@ -94,6 +101,10 @@ func openEnvelopeHeaders(data []byte, g *bertytypes.Group) (*bertytypes.MessageE
 		return nil, nil, errcode.ErrDeserialization.Wrap(err)
 	}
 
+	if env.Version > CurrentGroupVersion {
+		return nil, nil, errcode.ErrGroupUnsupportedVersion
+	}
+
 	sk, err := g.GetSharedSecret()
 	if err != nil {
 		return nil, nil, errcode.ErrSerialization.Wrap(err)
@@ -166,6 +177,32 @@ func idForCID(id cid.Cid) datastore.Key {
 	return datastore.KeyWithNamespaces([]string{"cid", id.String()})
 }
 
+func idForCIDExpiry(id cid.Cid) datastore.Key {
+	return idForCIDExpiryPrefix().ChildString(id.String())
+}
+
+func idForCIDExpiryPrefix() datastore.Key {
+	return datastore.NewKey("cidExpiry")
+}
+
+func idForCIDReceipt(id cid.Cid) datastore.Key {
+	return datastore.KeyWithNamespaces([]string{"cidReceipt", id.String()})
+}
+
+func idForCIDPlaintext(id cid.Cid) datastore.Key {
+	return datastore.KeyWithNamespaces([]string{"plaintext", id.String()})
+}
+
+func idForCachedKeyPrefix() datastore.Key {
+	return datastore.NewKey("cachedCKs")
+}
+
+// idForCachedKeyExpiry returns the key holding the expiry of a stale
+// precomputed key, cachedKey is returned by idForCachedKey
+func idForCachedKeyExpiry(cachedKey datastore.Key) datastore.Key {
+	return datastore.NewKey("cachedCKExpiry").Child(cachedKey)
+}
+
 func uint64AsNonce(val uint64) *[24]byte {
 	var nonce [24]byte
 
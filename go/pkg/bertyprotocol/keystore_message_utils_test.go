@@ -2,6 +2,7 @@ package bertyprotocol
 
 import (
 	"context"
+	crand "crypto/rand"
 	"encoding/hex"
 	"fmt"
 	"os"
@@ -9,11 +10,16 @@ import (
 	"testing"
 	"time"
 
+	"berty.tech/berty/v2/go/internal/cryptoutil"
 	"berty.tech/berty/v2/go/internal/testutil"
 	"berty.tech/berty/v2/go/pkg/bertytypes"
+	"berty.tech/berty/v2/go/pkg/errcode"
 	cid "github.com/ipfs/go-cid"
+	datastore "github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
 	keystore "github.com/ipfs/go-ipfs-keystore"
 	"github.com/libp2p/go-libp2p-core/crypto"
+	mh "github.com/multiformats/go-multihash"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -281,7 +287,7 @@ func Test_EncryptMessageEnvelope(t *testing.T) {
 	err = mkh2.RegisterChainKey(g, omd1.device.GetPublic(), ds1, false)
 	assert.NoError(t, err)
 
-	env1, err := sealEnvelopeInternal(ctx, payloadRef1, ds1, omd1.device, g)
+	env1, err := sealEnvelopeInternal(ctx, payloadRef1, nil, ds1, omd1.device, g)
 	assert.NoError(t, err)
 
 	headers, payloadClr1, err := mkh2.OpenEnvelope(ctx, g, omd2.device.GetPublic(), env1, cid.Undef)
@@ -358,6 +364,205 @@ func Test_EncryptMessageEnvelopeAndDerive(t *testing.T) {
 	}
 }
 
+func Test_RotateChainKey(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	g, _, err := NewGroupMultiMember()
+	assert.NoError(t, err)
+
+	acc1 := NewDeviceKeystore(keystore.NewMemKeystore())
+	acc2 := NewDeviceKeystore(keystore.NewMemKeystore())
+
+	omd1, err := acc1.MemberDeviceForGroup(g)
+	assert.NoError(t, err)
+
+	omd2, err := acc2.MemberDeviceForGroup(g)
+	assert.NoError(t, err)
+
+	mkh1 := NewInMemMessageKeystore()
+	mkh2 := NewInMemMessageKeystore()
+
+	ds1, err := newDeviceSecret()
+	assert.NoError(t, err)
+
+	assert.NoError(t, mkh1.RegisterChainKey(g, omd1.device.GetPublic(), ds1, true))
+	assert.NoError(t, mkh2.RegisterChainKey(g, omd1.device.GetPublic(), ds1, false))
+
+	pending, err := mkh1.SealEnvelope(ctx, g, omd1.device, []byte("sent before rotation"))
+	assert.NoError(t, err)
+
+	// the rotated secret is sent to a device using its own nonce
+	rotated, err := newDeviceSecret()
+	assert.NoError(t, err)
+
+	nonce, err := cryptoutil.GenerateNonce()
+	assert.NoError(t, err)
+
+	payload, err := sealSecretEntryPayload(omd1.device, omd2.device.GetPublic(), rotated, nonce)
+	assert.NoError(t, err)
+
+	devRaw, err := omd1.device.GetPublic().Raw()
+	assert.NoError(t, err)
+
+	memberRaw, err := omd2.member.GetPublic().Raw()
+	assert.NoError(t, err)
+
+	destDevRaw, err := omd2.device.GetPublic().Raw()
+	assert.NoError(t, err)
+
+	eventBytes, err := (&bertytypes.GroupAddDeviceSecret{
+		DevicePK:     devRaw,
+		DestMemberPK: memberRaw,
+		DestDevicePK: destDevRaw,
+		Payload:      payload,
+		Nonce:        nonce[:],
+	}).Marshal()
+	assert.NoError(t, err)
+
+	meta := &bertytypes.GroupMetadata{EventType: bertytypes.EventTypeGroupDeviceSecretAdded, Payload: eventBytes}
+	assert.True(t, isRotatedDeviceSecret(meta))
+
+	_, opened, err := openDeviceSecret(meta, omd2, g)
+	assert.NoError(t, err)
+	assert.Equal(t, rotated.ChainKey, opened.ChainKey)
+
+	// another device of the member, i.e. a revoked one, can't open it
+	otherDevice, _, err := crypto.GenerateEd25519Key(crand.Reader)
+	assert.NoError(t, err)
+
+	_, _, err = openDeviceSecret(meta, &ownMemberDevice{member: omd2.member, device: otherDevice}, g)
+	testSameErrcodes(t, errcode.ErrGroupSecretOtherDestMember, err)
+
+	assert.NoError(t, mkh1.RotateChainKey(g, omd1.device.GetPublic(), rotated, true))
+	assert.NoError(t, mkh2.RotateChainKey(g, omd1.device.GetPublic(), opened, false))
+
+	env, err := mkh1.SealEnvelope(ctx, g, omd1.device, []byte("sent after rotation"))
+	assert.NoError(t, err)
+
+	_, payloadClr, err := mkh2.OpenEnvelope(ctx, g, omd2.device.GetPublic(), env, cid.Undef)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("sent after rotation"), payloadClr)
+
+	// keys derived from the previous chain key are kept
+	_, payloadClr, err = mkh2.OpenEnvelope(ctx, g, omd2.device.GetPublic(), pending, cid.Undef)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("sent before rotation"), payloadClr)
+}
+
+func Test_ForwardSecureMessageKeystore(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	g, _, err := NewGroupMultiMember()
+	assert.NoError(t, err)
+
+	omd1, err := NewDeviceKeystore(keystore.NewMemKeystore()).MemberDeviceForGroup(g)
+	assert.NoError(t, err)
+
+	omd2, err := NewDeviceKeystore(keystore.NewMemKeystore()).MemberDeviceForGroup(g)
+	assert.NoError(t, err)
+
+	hash, err := mh.Sum([]byte("message"), mh.SHA2_256, -1)
+	assert.NoError(t, err)
+
+	id := cid.NewCidV1(cid.Raw, hash)
+
+	for _, ttl := range []time.Duration{0, time.Millisecond} {
+		mkh1 := NewInMemMessageKeystore()
+		mkh2 := NewMessageKeystoreWithOpts(dssync.MutexWrap(datastore.NewMapDatastore()), &MessageKeystoreOpts{
+			PreComputedKeysCount: map[bertytypes.GroupType]int{bertytypes.GroupTypeMultiMember: 10},
+			ForwardSecure:        true,
+			KeyTTL:               ttl,
+		})
+		assert.Equal(t, 10, mkh2.getPrecomputedKeyExpectedCount(g))
+
+		ds, err := newDeviceSecret()
+		assert.NoError(t, err)
+
+		assert.NoError(t, mkh1.RegisterChainKey(g, omd1.device.GetPublic(), ds, true))
+		assert.NoError(t, mkh2.RegisterChainKey(g, omd1.device.GetPublic(), ds, false))
+
+		env, err := mkh1.SealEnvelope(ctx, g, omd1.device, []byte("payload"))
+		assert.NoError(t, err)
+
+		_, payloadClr, err := mkh2.OpenEnvelope(ctx, g, omd2.device.GetPublic(), env, id)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("payload"), payloadClr)
+
+		_, err = mkh2.getKeyForCID(id)
+		if ttl == 0 {
+			assert.Error(t, err)
+		} else {
+			assert.NoError(t, err)
+
+			time.Sleep(ttl * 2)
+			assert.NoError(t, mkh2.PurgeKeys())
+
+			_, err = mkh2.getKeyForCID(id)
+			assert.Error(t, err)
+		}
+
+		// the plaintext is still available
+		_, payloadClr, err = mkh2.OpenEnvelope(ctx, g, omd2.device.GetPublic(), env, id)
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("payload"), payloadClr)
+	}
+}
+
+func Test_PurgeStalePrecomputedKeys(t *testing.T) {
+	g, _, err := NewGroupMultiMember()
+	assert.NoError(t, err)
+
+	omd, err := NewDeviceKeystore(keystore.NewMemKeystore()).MemberDeviceForGroup(g)
+	assert.NoError(t, err)
+
+	const ttl = time.Millisecond * 10
+
+	for _, forwardSecure := range []bool{false, true} {
+		mkh := NewMessageKeystoreWithOpts(dssync.MutexWrap(datastore.NewMapDatastore()), &MessageKeystoreOpts{
+			ForwardSecure: forwardSecure,
+			KeyTTL:        ttl,
+		})
+
+		ds, err := newDeviceSecret()
+		assert.NoError(t, err)
+
+		assert.NoError(t, mkh.RegisterChainKey(g, omd.device.GetPublic(), ds, false))
+
+		_, err = mkh.getPrecomputedKey(omd.device.GetPublic(), ds.Counter+1)
+		assert.NoError(t, err)
+
+		// keys close to the current counter are kept
+		assert.NoError(t, mkh.PurgeKeys())
+
+		_, err = mkh.getPrecomputedKey(omd.device.GetPublic(), ds.Counter+1)
+		assert.NoError(t, err)
+
+		assert.NoError(t, mkh.putDeviceChainKey(omd.device.GetPublic(), &bertytypes.DeviceSecret{
+			ChainKey: ds.ChainKey,
+			Counter:  ds.Counter + uint64(DefaultPreComputedKeysCount*10),
+		}))
+
+		// stale keys are kept for the TTL, late messages can still be opened
+		assert.NoError(t, mkh.PurgeKeys())
+
+		_, err = mkh.getPrecomputedKey(omd.device.GetPublic(), ds.Counter+1)
+		assert.NoError(t, err)
+
+		time.Sleep(ttl * 2)
+		assert.NoError(t, mkh.PurgeKeys())
+
+		// stale keys are only deleted in forward secure mode
+		_, err = mkh.getPrecomputedKey(omd.device.GetPublic(), ds.Counter+1)
+		if forwardSecure {
+			assert.Error(t, err)
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+}
+
 func testMessageKeyHolderCatchUp(t *testing.T, expectedNewDevices int, isSlow bool) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -389,7 +594,7 @@ func testMessageKeyHolderCatchUp(t *testing.T, expectedNewDevices int, isSlow bo
 	for i, dPK := range devicesPK {
 		ds, err := mkh1.getDeviceChainKey(dPK)
 		if assert.NoError(t, err) {
-			assert.Equal(t, deviceSecrets[i].Counter+uint64(mkh1.getPrecomputedKeyExpectedCount()), ds.Counter)
+			assert.Equal(t, deviceSecrets[i].Counter+uint64(mkh1.getPrecomputedKeyExpectedCount(peer.GC.Group())), ds.Counter)
 			// Not testing chain key value as we need to derive it from the generated value
 		} else {
 			t.Fatalf("failed at iteration %d", i)
@@ -449,7 +654,7 @@ func testMessageKeyHolderSubscription(t *testing.T, expectedNewDevices int, isSl
 	for i, dPK := range devicesPK {
 		ds, err := mkh1.getDeviceChainKey(dPK)
 		if assert.NoError(t, err) {
-			assert.Equal(t, deviceSecrets[i].Counter+uint64(mkh1.getPrecomputedKeyExpectedCount()), ds.Counter)
+			assert.Equal(t, deviceSecrets[i].Counter+uint64(mkh1.getPrecomputedKeyExpectedCount(peer.GC.Group())), ds.Counter)
 			// Not testing chain key value as we need to derive it from the generated value
 		}
 	}
@@ -15,6 +15,10 @@ import (
 	"github.com/ipfs/go-datastore"
 	ds_sync "github.com/ipfs/go-datastore/sync"
 	ipfs_core "github.com/ipfs/go-ipfs/core"
+	"github.com/ipfs/go-ipfs/keystore"
+	"github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
 	"go.uber.org/zap"
 )
 
@@ -26,20 +30,37 @@ type Service interface {
 
 	Close() error
 	Status() Status
+
+	// SetAuthenticatedStreamHandler registers a handler for the streams
+	// opened by contacts using pid, see AuthenticatedStream
+	SetAuthenticatedStreamHandler(pid protocol.ID, handler AuthenticatedStreamHandler, allowedStates ...bertytypes.ContactState)
+
+	// RemoveAuthenticatedStreamHandler unregisters the handler of pid
+	RemoveAuthenticatedStreamHandler(pid protocol.ID)
+
+	// NewAuthenticatedStream opens a stream authenticated to the account of
+	// a contact using pid
+	NewAuthenticatedStream(ctx context.Context, p peer.ID, contactPK crypto.PubKey, pid protocol.ID, allowedStates ...bertytypes.ContactState) (*AuthenticatedStream, error)
 }
 
 type service struct {
 	// variables
-	ctx            context.Context
-	logger         *zap.Logger
-	ipfsCoreAPI    ipfsutil.ExtendedCoreAPI
-	odb            *bertyOrbitDB
-	accountGroup   *groupContext
-	deviceKeystore DeviceKeystore
-	openedGroups   map[string]*groupContext
-	groups         map[string]*bertytypes.Group
-	lock           sync.RWMutex
-	close          func() error
+	ctx               context.Context
+	logger            *zap.Logger
+	ipfsCoreAPI       ipfsutil.ExtendedCoreAPI
+	tinderDriver      tinder.Driver
+	odb               *bertyOrbitDB
+	accountGroup      *groupContext
+	deviceKeystore    DeviceKeystore
+	encryptedKeystore ipfsutil.EncryptedKeystore
+	openedGroups      map[string]*groupContext
+	groups            map[string]*bertytypes.Group
+	lock              sync.RWMutex
+	close             func() error
+
+	contactRequestsLimits *ContactRequestsLimits
+	streamsLimiter        *incomingLimiter
+	recoveryRequests      *recoveryRequests
 }
 
 // Opts contains optional configuration flags for building a new Client
@@ -54,7 +75,25 @@ type Opts struct {
 	OrbitCache             cache.Interface
 	TinderDriver           tinder.Driver
 	RendezvousRotationBase time.Duration
-	close                  func() error
+	ContactRequestsLimits  *ContactRequestsLimits
+
+	// KeystorePassphrase encrypts the default device keystore, it is ignored
+	// if a DeviceKeystore is provided
+	KeystorePassphrase []byte
+
+	// EncryptedKeystore is the keystore used by DeviceKeystore if encrypted,
+	// it is required by the keystore lock and unlock methods
+	EncryptedKeystore ipfsutil.EncryptedKeystore
+
+	// MessageKeysPurgeInterval is the interval at which unneeded message keys
+	// are deleted from the MessageKeystore
+	MessageKeysPurgeInterval time.Duration
+
+	// AccountMnemonic derives the account keys of the default device keystore
+	// from a mnemonic sentence, see GenerateAccountMnemonic
+	AccountMnemonic string
+
+	close func() error
 }
 
 func (opts *Opts) applyDefaults() error {
@@ -75,8 +114,26 @@ func (opts *Opts) applyDefaults() error {
 	}
 
 	if opts.DeviceKeystore == nil {
-		ks := ipfsutil.NewDatastoreKeystore(ipfsutil.NewNamespacedDatastore(opts.RootDatastore, datastore.NewKey("accountGroup")))
-		opts.DeviceKeystore = NewDeviceKeystore(ks)
+		ksDS := ipfsutil.NewNamespacedDatastore(opts.RootDatastore, datastore.NewKey("accountGroup"))
+		ks := ipfsutil.NewDatastoreKeystore(ksDS)
+
+		if len(opts.KeystorePassphrase) > 0 {
+			eks, err := ipfsutil.NewEncryptedDatastoreKeystore(ksDS, opts.KeystorePassphrase)
+			if err != nil {
+				return err
+			}
+
+			opts.EncryptedKeystore, ks = eks, eks
+		}
+
+		if opts.AccountMnemonic != "" {
+			var err error
+			if opts.DeviceKeystore, err = NewDeviceKeystoreFromMnemonic(ks, opts.AccountMnemonic); err != nil {
+				return err
+			}
+		} else {
+			opts.DeviceKeystore = NewDeviceKeystore(ks)
+		}
 	}
 
 	if opts.MessageKeystore == nil {
@@ -84,10 +141,18 @@ func (opts *Opts) applyDefaults() error {
 		opts.MessageKeystore = NewMessageKeystore(mk)
 	}
 
+	if opts.MessageKeysPurgeInterval <= 0 {
+		opts.MessageKeysPurgeInterval = time.Hour
+	}
+
 	if opts.RendezvousRotationBase.Nanoseconds() <= 0 {
 		opts.RendezvousRotationBase = time.Hour * 24
 	}
 
+	if opts.ContactRequestsLimits == nil {
+		opts.ContactRequestsLimits = DefaultContactRequestsLimits()
+	}
+
 	if opts.IpfsCoreAPI == nil {
 		var err error
 		var createdIPFSNode *ipfs_core.IpfsNode
@@ -132,32 +197,86 @@ func New(opts Opts) (Service, error) {
 		return nil, errcode.TODO.Wrap(err)
 	}
 
+	go opts.MessageKeystore.PurgeKeysPeriodically(opts.RootContext, opts.MessageKeysPurgeInterval)
+
 	if opts.TinderDriver != nil {
 		s := newSwiper(opts.TinderDriver, opts.Logger, opts.RendezvousRotationBase)
 		opts.Logger.Debug("tinder swiper is enabled")
 
-		if err := initContactRequestsManager(opts.RootContext, s, acc.metadataStore, opts.IpfsCoreAPI, opts.Logger); err != nil {
+		if err := initContactRequestsManager(opts.RootContext, s, acc.metadataStore, opts.IpfsCoreAPI, opts.Logger, opts.ContactRequestsLimits); err != nil {
 			return nil, errcode.TODO.Wrap(err)
 		}
 	} else {
 		opts.Logger.Warn("no tinder driver provided, incoming and outgoing contact requests won't be enabled")
 	}
 
-	return &service{
-		ctx:            opts.RootContext,
-		ipfsCoreAPI:    opts.IpfsCoreAPI,
-		logger:         opts.Logger,
-		odb:            odb,
-		deviceKeystore: opts.DeviceKeystore,
-		close:          opts.close,
-		accountGroup:   acc,
+	s := &service{
+		ctx:                   opts.RootContext,
+		ipfsCoreAPI:           opts.IpfsCoreAPI,
+		tinderDriver:          opts.TinderDriver,
+		logger:                opts.Logger,
+		odb:                   odb,
+		deviceKeystore:        opts.DeviceKeystore,
+		encryptedKeystore:     opts.EncryptedKeystore,
+		close:                 opts.close,
+		accountGroup:          acc,
+		contactRequestsLimits: opts.ContactRequestsLimits,
+		streamsLimiter:        newIncomingLimiter(opts.ContactRequestsLimits),
+		recoveryRequests:      newRecoveryRequests(),
 		groups: map[string]*bertytypes.Group{
 			string(acc.Group().PublicKey): acc.Group(),
 		},
 		openedGroups: map[string]*groupContext{
 			string(acc.Group().PublicKey): acc,
 		},
-	}, nil
+	}
+
+	s.SetAuthenticatedStreamHandler(recoveryRequestProtocol, s.handleRecoveryRequest)
+
+	return s, nil
+}
+
+// InstanceRestoreFromMnemonic initializes a new Service for an existing
+// account, its keys are derived from mnemonic and stored in ks along with a new
+// device key, the account group is then synchronized with the other devices
+func InstanceRestoreFromMnemonic(ks keystore.Keystore, mnemonic string, opts Opts) (Service, error) {
+	devKS, err := NewDeviceKeystoreFromMnemonic(ks, mnemonic)
+	if err != nil {
+		return nil, err
+	}
+
+	return instanceRestore(devKS, opts)
+}
+
+// InstanceRestoreFromRecoverySecret initializes a new Service for an existing
+// account, its keys are rebuilt from a secret returned by
+// AccountRecoverySharesCollect, see InstanceRestoreFromMnemonic
+func InstanceRestoreFromRecoverySecret(ks keystore.Keystore, secret []byte, opts Opts) (Service, error) {
+	devKS, err := NewDeviceKeystoreFromRecoverySecret(ks, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	return instanceRestore(devKS, opts)
+}
+
+func instanceRestore(devKS DeviceKeystore, opts Opts) (Service, error) {
+	opts.DeviceKeystore = devKS
+
+	svc, err := New(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	s := svc.(*service)
+
+	// announce the new device and exchange secrets with the other devices of the account
+	if err := ActivateGroupContext(s.ctx, s.accountGroup); err != nil {
+		_ = s.Close()
+		return nil, errcode.TODO.Wrap(err)
+	}
+
+	return s, nil
 }
 
 func (s *service) Close() error {
@@ -1,14 +1,22 @@
 package bertyprotocol
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"berty.tech/berty/v2/go/pkg/bertytypes"
 	"berty.tech/berty/v2/go/pkg/errcode"
 	"berty.tech/go-orbit-db/stores"
+	ipfs_options "github.com/ipfs/interface-go-ipfs-core/options"
 	"github.com/libp2p/go-libp2p-core/crypto"
+	"go.uber.org/zap"
 )
 
+// groupLeaveExchangeTimeout is the maximum time during which a left group is
+// kept active while waiting for a peer to receive its latest events
+const groupLeaveExchangeTimeout = time.Minute * 5
+
 func (s *service) indexGroups() error {
 	s.lock.Lock()
 	defer s.lock.Unlock()
@@ -70,6 +78,25 @@ func (s *service) getContactGroup(key crypto.PubKey) (*bertytypes.Group, error)
 	return g, nil
 }
 
+// activateContactGroup opens the group shared with a contact if needed
+func (s *service) activateContactGroup(contactPK crypto.PubKey) (*groupContext, error) {
+	g, err := s.getContactGroup(contactPK)
+	if err != nil {
+		return nil, err
+	}
+
+	pk, err := g.GetPubKey()
+	if err != nil {
+		return nil, errcode.ErrDeserialization.Wrap(err)
+	}
+
+	if err := s.activateGroup(pk); err != nil {
+		return nil, err
+	}
+
+	return s.getContextGroupForID(g.PublicKey)
+}
+
 func (s *service) getGroupForPK(pk crypto.PubKey) (*bertytypes.Group, error) {
 	id, err := pk.Raw()
 	if err != nil {
@@ -122,6 +149,34 @@ func (s *service) deactivateGroup(pk crypto.PubKey) error {
 	return nil
 }
 
+// deactivateGroupOnceExchanged deactivates a group once a peer of its metadata
+// store has received the latest heads, so the other members learn that the
+// current member left
+func (s *service) deactivateGroupOnceExchanged(pk crypto.PubKey, cg *groupContext) {
+	ctx, cancel := context.WithTimeout(s.ctx, groupLeaveExchangeTimeout)
+	defer cancel()
+
+	metaStore := cg.MetadataStore()
+	sub := metaStore.Subscribe(ctx)
+
+	// heads are published to the peers already listening on the store and
+	// exchanged with the ones joining it
+	peers, err := s.ipfsCoreAPI.PubSub().Peers(ctx, ipfs_options.PubSub.Topic(metaStore.Address().String()))
+	if err != nil || len(peers) == 0 {
+		for e := range sub {
+			if _, ok := e.(*stores.EventNewPeer); ok {
+				cancel()
+			}
+		}
+
+		if ctx.Err() == context.DeadlineExceeded {
+			s.logger.Warn("no peer received the latest events of the left group")
+		}
+	}
+
+	_ = s.deactivateGroup(pk)
+}
+
 func (s *service) activateGroup(pk crypto.PubKey) error {
 	id, err := pk.Raw()
 	if err != nil {
@@ -155,6 +210,10 @@ func (s *service) activateGroup(pk crypto.PubKey) error {
 
 		s.openedGroups[string(id)] = cg
 
+		if g.GroupType == bertytypes.GroupTypeMultiMember {
+			s.linkGroupDevice(pk, cg)
+		}
+
 		go func() {
 			for e := range cg.metadataStore.Subscribe(s.ctx) {
 				if evt, ok := e.(*stores.EventNewPeer); ok {
@@ -179,6 +238,19 @@ func (s *service) activateGroup(pk crypto.PubKey) error {
 	return errcode.ErrInternal.Wrap(fmt.Errorf("unknown group type"))
 }
 
+// linkGroupDevice announces the device key used in a multi-member group on the
+// account group, allowing the other devices to revoke it
+func (s *service) linkGroupDevice(pk crypto.PubKey, cg *groupContext) {
+	accountMeta := s.accountGroup.MetadataStore()
+	if _, err := accountMeta.GetGroupDevice(pk, s.accountGroup.DevicePubKey()); err == nil {
+		return
+	}
+
+	if _, err := accountMeta.GroupDeviceLink(s.ctx, pk, cg.memberDevice.device); err != nil {
+		s.logger.Warn("unable to link group device", zap.Error(err))
+	}
+}
+
 func (s *service) getContextGroupForID(id []byte) (*groupContext, error) {
 	if len(id) == 0 {
 		return nil, errcode.ErrInternal.Wrap(fmt.Errorf("no group id provided"))
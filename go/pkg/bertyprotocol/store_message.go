@@ -1,8 +1,13 @@
 package bertyprotocol
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
 
 	"encoding/base64"
 
@@ -22,6 +27,27 @@ import (
 
 const groupMessageStoreType = "berty_group_messages"
 
+// maxUndecryptableMessages is the maximum number of messages kept in the
+// retry queue of a group
+const maxUndecryptableMessages = 1000
+
+const (
+	// messageHeaderSentAt is the time at which a message has been sent
+	// according to the clock of its sender, in seconds since the epoch
+	messageHeaderSentAt = "berty-sent-at"
+
+	// messageHeaderTTL is the lifetime of a message in seconds
+	messageHeaderTTL = "berty-ttl"
+
+	// messageHeaderRecord indicates that the payload of a message is a
+	// MessageRecord
+	messageHeaderRecord = "berty-record"
+
+	// expiredMessagesPurgeInterval is the interval at which the keys of
+	// expired messages are deleted
+	expiredMessagesPurgeInterval = time.Minute
+)
+
 type messageStore struct {
 	basestore.BaseStore
 
@@ -29,6 +55,25 @@ type messageStore struct {
 	mks    *MessageKeystore
 	g      *bertytypes.Group
 	logger *zap.Logger
+
+	undecryptable   map[string]*undecryptableMessage
+	muUndecryptable sync.Mutex
+
+	expired   map[string]struct{}
+	muExpired sync.Mutex
+}
+
+// undecryptableMessage is a message which couldn't be opened, it is retried
+// when new keys are available for its device
+type undecryptableMessage struct {
+	entry        ipfslog.Entry
+	devicePK     []byte
+	counter      uint64
+	reason       bertytypes.UndecryptableMessageReason
+	knownCounter uint64
+	err          error
+	attempts     uint32
+	firstSeen    time.Time
 }
 
 func (m *messageStore) setLogger(l *zap.Logger) {
@@ -40,14 +85,38 @@ func (m *messageStore) setLogger(l *zap.Logger) {
 }
 
 func (m *messageStore) openMessage(ctx context.Context, e ipfslog.Entry) (*bertytypes.GroupMessageEvent, error) {
+	messageEvent, data, err := m.openEntry(ctx, e)
+	if errcode.Code(err) == errcode.ErrMessageExpired.Code() {
+		return nil, err
+	} else if err != nil {
+		m.queueUndecryptableMessage(e, data, err)
+		return nil, err
+	}
+
+	m.muUndecryptable.Lock()
+	delete(m.undecryptable, e.GetHash().String())
+	m.muUndecryptable.Unlock()
+
+	return messageEvent, nil
+}
+
+// openEntry opens the envelope of an entry, it also returns the raw envelope
+// so callers can inspect its headers when it can't be opened
+func (m *messageStore) openEntry(ctx context.Context, e ipfslog.Entry) (*bertytypes.GroupMessageEvent, []byte, error) {
 	if e == nil {
-		return nil, errcode.ErrInvalidInput
+		return nil, nil, errcode.ErrInvalidInput
 	}
 
 	op, err := operation.ParseOperation(e)
 	if err != nil {
 		m.logger.Error("unable to parse operation", zap.Error(err))
-		return nil, err
+		return nil, nil, err
+	}
+
+	// headers are readable without the message key
+	if _, headers, err := openEnvelopeHeaders(op.GetValue(), m.g); err == nil && m.isEntryExpired(e, headers, time.Now()) {
+		m.expireMessage(e)
+		return nil, nil, errcode.ErrMessageExpired
 	}
 
 	ownPK := crypto.PubKey(nil)
@@ -59,7 +128,7 @@ func (m *messageStore) openMessage(ctx context.Context, e ipfslog.Entry) (*berty
 	headers, payload, err := m.mks.OpenEnvelope(ctx, m.g, ownPK, op.GetValue(), e.GetHash())
 	if err != nil {
 		m.logger.Error("unable to open envelope", zap.Error(err))
-		return nil, err
+		return nil, op.GetValue(), err
 	}
 
 	eventContext := newEventContext(e.GetHash(), e.GetNext(), m.g)
@@ -67,7 +136,7 @@ func (m *messageStore) openMessage(ctx context.Context, e ipfslog.Entry) (*berty
 		EventContext: eventContext,
 		Headers:      headers,
 		Message:      payload,
-	}, err
+	}, op.GetValue(), nil
 }
 
 func (m *messageStore) ListMessages(ctx context.Context) (<-chan *bertytypes.GroupMessageEvent, error) {
@@ -77,7 +146,9 @@ func (m *messageStore) ListMessages(ctx context.Context) (<-chan *bertytypes.Gro
 	go func() {
 		for _, e := range m.OpLog().GetEntries().Slice() {
 			evt, err := m.openMessage(ctx, e)
-			if err != nil {
+			if errcode.Code(err) == errcode.ErrMessageExpired.Code() {
+				continue
+			} else if err != nil {
 				m.logger.Error("unable to open message", zap.Error(err))
 				continue
 			}
@@ -91,13 +162,316 @@ func (m *messageStore) ListMessages(ctx context.Context) (<-chan *bertytypes.Gro
 	return out, nil
 }
 
+// messageExpiry returns the time at which a message expires, the lifetime set
+// by its sender starts when the current device received it so the deadline
+// doesn't depend on the skew between the clock of the sender and the local one
+func messageExpiry(headers *bertytypes.MessageHeaders, receivedAt time.Time) (time.Time, bool) {
+	ttl, ok := messageTTL(headers)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	return receivedAt.Add(ttl), true
+}
+
+// messageTTL returns the lifetime of a message set by its sender
+func messageTTL(headers *bertytypes.MessageHeaders) (time.Duration, bool) {
+	ttl, err := strconv.ParseInt(headers.GetMetadata()[messageHeaderTTL], 10, 64)
+	if err != nil || ttl <= 0 {
+		return 0, false
+	}
+
+	return time.Duration(ttl) * time.Second, true
+}
+
+func isMessageExpired(headers *bertytypes.MessageHeaders, receivedAt time.Time, now time.Time) bool {
+	expiry, ok := messageExpiry(headers, receivedAt)
+
+	return ok && !now.Before(expiry)
+}
+
+// isEntryExpired checks if the message of an entry has expired, the receipt
+// time of ephemeral messages is persisted the first time they are seen
+func (m *messageStore) isEntryExpired(e ipfslog.Entry, headers *bertytypes.MessageHeaders, now time.Time) bool {
+	if _, ok := messageTTL(headers); !ok {
+		return false
+	}
+
+	receivedAt, err := m.mks.MessageReceivedAt(e.GetHash(), now)
+	if err != nil {
+		m.logger.Error("unable to get message receipt time", zap.Error(err))
+		return false
+	}
+
+	return isMessageExpired(headers, receivedAt, now)
+}
+
+// expireMessage deletes the keys of an expired message, its entry is left in
+// the log but can't be opened anymore
+func (m *messageStore) expireMessage(e ipfslog.Entry) {
+	id := e.GetHash().String()
+
+	m.muExpired.Lock()
+	_, ok := m.expired[id]
+	m.expired[id] = struct{}{}
+	m.muExpired.Unlock()
+
+	if ok {
+		return
+	}
+
+	m.muUndecryptable.Lock()
+	delete(m.undecryptable, id)
+	m.muUndecryptable.Unlock()
+
+	if err := m.mks.ForgetMessage(e.GetHash()); err != nil {
+		m.logger.Error("unable to delete keys of expired message", zap.Error(err))
+	}
+}
+
+// PurgeExpiredMessages deletes the keys of the messages which have expired
+func (m *messageStore) PurgeExpiredMessages(ctx context.Context) {
+	now := time.Now()
+
+	for _, e := range m.OpLog().GetEntries().Slice() {
+		m.muExpired.Lock()
+		_, ok := m.expired[e.GetHash().String()]
+		m.muExpired.Unlock()
+
+		if ok {
+			continue
+		}
+
+		op, err := operation.ParseOperation(e)
+		if err != nil {
+			continue
+		}
+
+		if _, headers, err := openEnvelopeHeaders(op.GetValue(), m.g); err == nil && m.isEntryExpired(e, headers, now) {
+			m.expireMessage(e)
+		}
+	}
+}
+
+func (m *messageStore) purgeExpiredMessagesPeriodically(ctx context.Context) {
+	ticker := time.NewTicker(expiredMessagesPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		m.PurgeExpiredMessages(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// queueUndecryptableMessage keeps a message which can't be opened so it can
+// be retried later
+func (m *messageStore) queueUndecryptableMessage(e ipfslog.Entry, data []byte, openErr error) {
+	m.muUndecryptable.Lock()
+	defer m.muUndecryptable.Unlock()
+
+	id := e.GetHash().String()
+
+	u, ok := m.undecryptable[id]
+	if !ok {
+		if len(m.undecryptable) >= maxUndecryptableMessages {
+			m.logger.Warn("too many undecryptable messages, dropping message")
+			return
+		}
+
+		u = &undecryptableMessage{entry: e, firstSeen: time.Now()}
+		m.undecryptable[id] = u
+	}
+
+	m.updateUndecryptableMessage(u, data, openErr)
+}
+
+// requeueUndecryptableMessage puts back a message taken out of the queue
+// which still can't be opened, it is merged with the entry queued meanwhile
+// for the same message if any
+func (m *messageStore) requeueUndecryptableMessage(u *undecryptableMessage, data []byte, openErr error) {
+	m.muUndecryptable.Lock()
+	defer m.muUndecryptable.Unlock()
+
+	id := u.entry.GetHash().String()
+
+	if queued, ok := m.undecryptable[id]; ok {
+		queued.attempts += u.attempts
+		if u.firstSeen.Before(queued.firstSeen) {
+			queued.firstSeen = u.firstSeen
+		}
+
+		u = queued
+	} else {
+		if len(m.undecryptable) >= maxUndecryptableMessages {
+			m.logger.Warn("too many undecryptable messages, dropping message")
+			return
+		}
+
+		m.undecryptable[id] = u
+	}
+
+	m.updateUndecryptableMessage(u, data, openErr)
+}
+
+// updateUndecryptableMessage records a failed attempt to open a message and
+// why it failed, muUndecryptable must be held
+func (m *messageStore) updateUndecryptableMessage(u *undecryptableMessage, data []byte, openErr error) {
+	u.err = openErr
+	u.attempts++
+
+	_, headers, err := openEnvelopeHeaders(data, m.g)
+	if err != nil {
+		u.reason = bertytypes.UndecryptableMessageReasonInvalidEnvelope
+		return
+	}
+
+	u.devicePK, u.counter = headers.DevicePK, headers.Counter
+	u.reason, u.knownCounter = m.mks.undecryptableReason(headers)
+}
+
+// RetryUndecryptableMessages tries to open the queued messages again, only
+// those sent by devicePK if specified, the opened messages are emitted. It
+// is meant to be called once new keys are available for the device.
+func (m *messageStore) RetryUndecryptableMessages(ctx context.Context, devicePK []byte) {
+	m.retryUndecryptableMessages(ctx, m.takeUndecryptableMessages(devicePK))
+}
+
+// retryOutOfWindowMessages tries to open the queued messages of devicePK
+// which were ahead of its known keys and are reached by the keys computed
+// since, it is meant to be called once a message of the device is opened
+func (m *messageStore) retryOutOfWindowMessages(ctx context.Context, devicePK []byte) {
+	knownCounter, err := m.mks.deviceKnownCounter(devicePK)
+	if err != nil {
+		return
+	}
+
+	m.retryUndecryptableMessages(ctx, m.takeOutOfWindowMessages(devicePK, knownCounter))
+}
+
+func (m *messageStore) retryUndecryptableMessages(ctx context.Context, msgs []*undecryptableMessage) {
+	for _, u := range msgs {
+		messageEvent, data, err := m.openEntry(ctx, u.entry)
+		if errcode.Code(err) == errcode.ErrMessageExpired.Code() {
+			continue
+		} else if err != nil {
+			m.requeueUndecryptableMessage(u, data, err)
+			continue
+		}
+
+		m.logger.Info("opened previously undecryptable message", zap.Uint32("attempts", u.attempts))
+		m.Emit(ctx, messageEvent)
+	}
+}
+
+// takeUndecryptableMessages removes from the queue the messages sent by
+// devicePK, or all of them if nil, so they are retried only once when
+// retries run concurrently. They are sorted by device and counter.
+func (m *messageStore) takeUndecryptableMessages(devicePK []byte) []*undecryptableMessage {
+	m.muUndecryptable.Lock()
+	defer m.muUndecryptable.Unlock()
+
+	ret := []*undecryptableMessage(nil)
+	for id, u := range m.undecryptable {
+		if devicePK != nil && !bytes.Equal(u.devicePK, devicePK) {
+			continue
+		}
+
+		delete(m.undecryptable, id)
+		ret = append(ret, u)
+	}
+
+	sortUndecryptableMessages(ret)
+
+	return ret
+}
+
+// takeOutOfWindowMessages removes from the queue the messages sent by
+// devicePK which were ahead of its known keys, only those whose counter is
+// now reached by knownCounter. They are sorted by counter.
+func (m *messageStore) takeOutOfWindowMessages(devicePK []byte, knownCounter uint64) []*undecryptableMessage {
+	m.muUndecryptable.Lock()
+	defer m.muUndecryptable.Unlock()
+
+	ret := []*undecryptableMessage(nil)
+	for id, u := range m.undecryptable {
+		if u.reason != bertytypes.UndecryptableMessageReasonCounterOutOfWindow || !bytes.Equal(u.devicePK, devicePK) {
+			continue
+		}
+
+		if u.knownCounter >= knownCounter || u.counter > knownCounter {
+			continue
+		}
+
+		delete(m.undecryptable, id)
+		ret = append(ret, u)
+	}
+
+	sortUndecryptableMessages(ret)
+
+	return ret
+}
+
+// listUndecryptableMessages returns a copy of the queued messages sorted by
+// device and counter
+func (m *messageStore) listUndecryptableMessages() []*undecryptableMessage {
+	m.muUndecryptable.Lock()
+	defer m.muUndecryptable.Unlock()
+
+	ret := make([]*undecryptableMessage, 0, len(m.undecryptable))
+	for _, u := range m.undecryptable {
+		cpy := *u
+		ret = append(ret, &cpy)
+	}
+
+	sortUndecryptableMessages(ret)
+
+	return ret
+}
+
+func sortUndecryptableMessages(msgs []*undecryptableMessage) {
+	sort.Slice(msgs, func(i, j int) bool {
+		if c := bytes.Compare(msgs[i].devicePK, msgs[j].devicePK); c != 0 {
+			return c < 0
+		}
+
+		return msgs[i].counter < msgs[j].counter
+	})
+}
+
 func (m *messageStore) AddMessage(ctx context.Context, payload []byte) (operation.Operation, error) {
+	return m.AddEphemeralMessage(ctx, payload, 0)
+}
+
+// AddEphemeralMessage adds a message which expires after ttl, rounded up to
+// the second, a zero ttl means that the message never expires
+func (m *messageStore) AddEphemeralMessage(ctx context.Context, payload []byte, ttl time.Duration) (operation.Operation, error) {
+	if ttl < 0 {
+		return nil, errcode.ErrInvalidInput
+	}
+
+	var metadata map[string]string
+	if ttl > 0 {
+		metadata = map[string]string{
+			messageHeaderSentAt: strconv.FormatInt(time.Now().Unix(), 10),
+			messageHeaderTTL:    strconv.FormatInt(int64((ttl+time.Second-1)/time.Second), 10),
+		}
+	}
+
+	return m.addMessage(ctx, payload, metadata)
+}
+
+func (m *messageStore) addMessage(ctx context.Context, payload []byte, metadata map[string]string) (operation.Operation, error) {
 	md, err := m.devKS.MemberDeviceForGroup(m.g)
 	if err != nil {
 		return nil, errcode.ErrInternal.Wrap(err)
 	}
 
-	env, err := m.mks.SealEnvelope(ctx, m.g, md.device, payload)
+	env, err := m.mks.SealEnvelopeWithMetadata(ctx, m.g, md.device, payload, metadata)
 	if err != nil {
 		return nil, errcode.ErrCryptoEncrypt.Wrap(err)
 	}
@@ -125,10 +499,12 @@ func constructorFactoryGroupMessage(s *bertyOrbitDB) iface.StoreConstructor {
 		}
 
 		store := &messageStore{
-			devKS:  s.deviceKeystore,
-			mks:    s.messageKeystore,
-			g:      g,
-			logger: zap.NewNop(),
+			devKS:         s.deviceKeystore,
+			mks:           s.messageKeystore,
+			g:             g,
+			logger:        zap.NewNop(),
+			undecryptable: map[string]*undecryptableMessage{},
+			expired:       map[string]struct{}{},
 		}
 
 		options.Index = basestore.NewBaseIndex
@@ -156,16 +532,25 @@ func constructorFactoryGroupMessage(s *bertyOrbitDB) iface.StoreConstructor {
 				store.logger.Debug("received store event", zap.Any("raw event", e))
 
 				messageEvent, err := store.openMessage(ctx, entry)
-				if err != nil {
+				if errcode.Code(err) == errcode.ErrMessageExpired.Code() {
+					continue
+				} else if err != nil {
 					store.logger.Error("unable to open message", zap.Error(err))
 					continue
 				}
 
 				store.logger.Debug("received payload", zap.String("payload", string(messageEvent.Message)))
 				store.Emit(ctx, messageEvent)
+
+				// opening a message computes the keys of the next counters of
+				// its device, messages which were ahead of the known keys can
+				// be opened now
+				store.retryOutOfWindowMessages(ctx, messageEvent.Headers.DevicePK)
 			}
 		}()
 
+		go store.purgeExpiredMessagesPeriodically(ctx)
+
 		return store, nil
 	}
 }
@@ -0,0 +1,255 @@
+package bertyprotocol
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"berty.tech/berty/v2/go/pkg/bertytypes"
+	"berty.tech/berty/v2/go/pkg/errcode"
+	ipfslog "berty.tech/go-ipfs-log"
+	"berty.tech/go-orbit-db/stores/operation"
+	cid "github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p-core/crypto"
+	"go.uber.org/zap"
+)
+
+// messageRecordPayload returns the data signed by a device to redact or edit
+// one of its messages, the timestamp makes each record unique
+func messageRecordPayload(record *bertytypes.MessageRecord) ([]byte, error) {
+	unsigned := &bertytypes.MessageRecord{
+		Type:      record.Type,
+		TargetID:  record.TargetID,
+		Payload:   record.Payload,
+		Timestamp: record.Timestamp,
+	}
+
+	data, err := unsigned.Marshal()
+	if err != nil {
+		return nil, errcode.ErrSerialization.Wrap(err)
+	}
+
+	return append([]byte("berty message record:"), data...), nil
+}
+
+func isMessageRecord(headers *bertytypes.MessageHeaders) bool {
+	return headers.GetMetadata()[messageHeaderRecord] != ""
+}
+
+// getMessageHeaders returns the headers of a message of the store, they are
+// readable without the message key
+func (m *messageStore) getMessageHeaders(id cid.Cid) (*bertytypes.MessageHeaders, error) {
+	e, ok := m.OpLog().GetEntries().Get(id.String())
+	if !ok {
+		return nil, errcode.ErrMissingMapKey.Wrap(fmt.Errorf("message not found"))
+	}
+
+	op, err := operation.ParseOperation(e)
+	if err != nil {
+		return nil, errcode.ErrOrbitDBDeserialization.Wrap(err)
+	}
+
+	_, headers, err := openEnvelopeHeaders(op.GetValue(), m.g)
+	if err != nil {
+		return nil, err
+	}
+
+	return headers, nil
+}
+
+// AddRedaction retracts a message previously sent by the current device
+func (m *messageStore) AddRedaction(ctx context.Context, target cid.Cid) (operation.Operation, error) {
+	return m.addMessageRecord(ctx, bertytypes.MessageRecordTypeRedaction, target, nil)
+}
+
+// AddEdit replaces the payload of a message previously sent by the current
+// device
+func (m *messageStore) AddEdit(ctx context.Context, target cid.Cid, payload []byte) (operation.Operation, error) {
+	return m.addMessageRecord(ctx, bertytypes.MessageRecordTypeEdit, target, payload)
+}
+
+func (m *messageStore) addMessageRecord(ctx context.Context, recordType bertytypes.MessageRecordType, target cid.Cid, payload []byte) (operation.Operation, error) {
+	md, err := m.devKS.MemberDeviceForGroup(m.g)
+	if err != nil {
+		return nil, errcode.ErrInternal.Wrap(err)
+	}
+
+	devicePK, err := md.device.GetPublic().Raw()
+	if err != nil {
+		return nil, errcode.ErrSerialization.Wrap(err)
+	}
+
+	headers, err := m.getMessageHeaders(target)
+	if err != nil {
+		return nil, errcode.ErrInvalidInput.Wrap(err)
+	}
+
+	if isMessageRecord(headers) {
+		return nil, errcode.ErrInvalidInput.Wrap(fmt.Errorf("a redaction or an edit can't be targeted"))
+	}
+
+	if !bytes.Equal(headers.DevicePK, devicePK) {
+		return nil, errcode.ErrInvalidInput.Wrap(fmt.Errorf("only the device which sent a message can redact or edit it"))
+	}
+
+	record := &bertytypes.MessageRecord{
+		Type:      recordType,
+		TargetID:  target.Bytes(),
+		Payload:   payload,
+		Timestamp: time.Now().UnixNano(),
+	}
+
+	data, err := messageRecordPayload(record)
+	if err != nil {
+		return nil, err
+	}
+
+	if record.Sig, err = md.device.Sign(data); err != nil {
+		return nil, errcode.ErrCryptoSignature.Wrap(err)
+	}
+
+	recordBytes, err := record.Marshal()
+	if err != nil {
+		return nil, errcode.ErrSerialization.Wrap(err)
+	}
+
+	// records expire with the message they target
+	metadata := map[string]string{messageHeaderRecord: "1"}
+	for _, k := range []string{messageHeaderSentAt, messageHeaderTTL} {
+		if v, ok := headers.GetMetadata()[k]; ok {
+			metadata[k] = v
+		}
+	}
+
+	return m.addMessage(ctx, recordBytes, metadata)
+}
+
+// openMessageRecord returns the record carried by a message, it must have been
+// sent and signed by the device which sent the target message
+func (m *messageStore) openMessageRecord(evt *bertytypes.GroupMessageEvent) (*bertytypes.MessageRecord, error) {
+	record := &bertytypes.MessageRecord{}
+	if err := record.Unmarshal(evt.Message); err != nil {
+		return nil, errcode.ErrDeserialization.Wrap(err)
+	}
+
+	if record.Type != bertytypes.MessageRecordTypeRedaction && record.Type != bertytypes.MessageRecordTypeEdit {
+		return nil, errcode.ErrInvalidInput.Wrap(fmt.Errorf("unknown record type"))
+	}
+
+	target, err := cid.Cast(record.TargetID)
+	if err != nil {
+		return nil, errcode.ErrDeserialization.Wrap(err)
+	}
+
+	headers, err := m.getMessageHeaders(target)
+	if err != nil {
+		return nil, err
+	}
+
+	if isMessageRecord(headers) {
+		return nil, errcode.ErrInvalidInput.Wrap(fmt.Errorf("a redaction or an edit can't be targeted"))
+	}
+
+	// the signature alone could be replayed by any member in a new message
+	if !bytes.Equal(evt.Headers.DevicePK, headers.DevicePK) {
+		return nil, errcode.ErrInvalidInput.Wrap(fmt.Errorf("record not sent by the device which sent the target message"))
+	}
+
+	pk, err := crypto.UnmarshalEd25519PublicKey(headers.DevicePK)
+	if err != nil {
+		return nil, errcode.ErrDeserialization.Wrap(err)
+	}
+
+	data, err := messageRecordPayload(record)
+	if err != nil {
+		return nil, err
+	}
+
+	if ok, err := pk.Verify(data, record.Sig); err != nil || !ok {
+		return nil, errcode.ErrCryptoSignatureVerification
+	}
+
+	return record, nil
+}
+
+// messageEdit is the latest edit of a message, edits are ordered using their
+// signed timestamp and their id as a tie breaker
+type messageEdit struct {
+	id        []byte
+	timestamp int64
+	payload   []byte
+}
+
+// ListLatestMessages lists the latest version of the messages, edits are
+// applied while redacted messages and the records themselves are omitted
+func (m *messageStore) ListLatestMessages(ctx context.Context) (<-chan *bertytypes.GroupMessageEvent, error) {
+	out := make(chan *bertytypes.GroupMessageEvent)
+
+	go func() {
+		defer close(out)
+
+		var (
+			messages []*bertytypes.GroupMessageEvent
+			redacted = map[string]struct{}{}
+			edits    = map[string]*messageEdit{}
+		)
+
+		for _, e := range m.OpLog().GetEntries().Slice() {
+			evt, err := m.openMessage(ctx, e)
+			if errcode.Code(err) == errcode.ErrMessageExpired.Code() {
+				continue
+			} else if err != nil {
+				m.logger.Error("unable to open message", zap.Error(err))
+				continue
+			}
+
+			if !isMessageRecord(evt.Headers) {
+				messages = append(messages, evt)
+				continue
+			}
+
+			record, err := m.openMessageRecord(evt)
+			if err != nil {
+				m.logger.Warn("ignoring invalid message record", zap.Error(err))
+				continue
+			}
+
+			switch record.Type {
+			case bertytypes.MessageRecordTypeRedaction:
+				redacted[string(record.TargetID)] = struct{}{}
+
+			case bertytypes.MessageRecordTypeEdit:
+				applyMessageEdit(edits, e, record)
+			}
+		}
+
+		for _, evt := range messages {
+			if _, ok := redacted[string(evt.EventContext.ID)]; ok {
+				continue
+			}
+
+			if edit, ok := edits[string(evt.EventContext.ID)]; ok {
+				evt.Message, evt.EditID = edit.payload, edit.id
+			}
+
+			out <- evt
+		}
+	}()
+
+	return out, nil
+}
+
+func applyMessageEdit(edits map[string]*messageEdit, e ipfslog.Entry, record *bertytypes.MessageRecord) {
+	id, t := e.GetHash().Bytes(), record.Timestamp
+
+	if cur, ok := edits[string(record.TargetID)]; ok && (cur.timestamp > t || (cur.timestamp == t && bytes.Compare(cur.id, id) > 0)) {
+		return
+	}
+
+	edits[string(record.TargetID)] = &messageEdit{
+		id:        id,
+		timestamp: t,
+		payload:   record.Payload,
+	}
+}
@@ -3,12 +3,20 @@ package bertyprotocol
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"testing"
 	"time"
 
 	"berty.tech/berty/v2/go/internal/testutil"
 	"berty.tech/berty/v2/go/pkg/bertytypes"
+	"berty.tech/berty/v2/go/pkg/errcode"
+	ipfslog "berty.tech/go-ipfs-log"
+	"berty.tech/go-orbit-db/stores/operation"
+	cid "github.com/ipfs/go-cid"
+	keystore "github.com/ipfs/go-ipfs-keystore"
+	mh "github.com/multiformats/go-multihash"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func countEntries(out <-chan *bertytypes.GroupMessageEvent) int {
@@ -94,3 +102,343 @@ func Test_AddMessage_ListMessages_manually_supplying_secrets(t *testing.T) {
 	// TODO: check that message parents IDs are valid
 	// TODO: check that message IDs are valid
 }
+
+// testMessageEntry is an entry which isn't added to the log of the store, so
+// messages are only opened when the tests ask for it
+type testMessageEntry struct {
+	ipfslog.Entry
+
+	payload []byte
+	hash    cid.Cid
+}
+
+func (e *testMessageEntry) GetPayload() []byte { return e.payload }
+func (e *testMessageEntry) GetHash() cid.Cid   { return e.hash }
+func (e *testMessageEntry) GetNext() []cid.Cid { return nil }
+
+func newTestMessageEntry(t *testing.T, env []byte) ipfslog.Entry {
+	t.Helper()
+
+	payload, err := operation.NewOperation(nil, "ADD", env).Marshal()
+	require.NoError(t, err)
+
+	hash, err := mh.Sum(payload, mh.SHA2_256, -1)
+	require.NoError(t, err)
+
+	return &testMessageEntry{payload: payload, hash: cid.NewCidV1(cid.Raw, hash)}
+}
+
+func Test_UndecryptableMessages_Retry(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	peers, _, cleanup := createPeersWithGroup(ctx, t, "/tmp/message_test", 1, 1)
+	defer cleanup()
+
+	g := peers[0].GC.Group()
+	ms := peers[0].GC.MessageStore()
+	window := peers[0].MKS.getPrecomputedKeyExpectedCount(g)
+
+	md, err := NewDeviceKeystore(keystore.NewMemKeystore()).MemberDeviceForGroup(g)
+	require.NoError(t, err)
+
+	dPKRaw, err := md.device.GetPublic().Raw()
+	require.NoError(t, err)
+
+	ds, err := newDeviceSecret()
+	require.NoError(t, err)
+
+	mks := NewInMemMessageKeystore()
+	require.NoError(t, mks.RegisterChainKey(g, md.device.GetPublic(), ds, true))
+
+	entries := make([]ipfslog.Entry, 2*window+2)
+	for i := range entries {
+		env, err := mks.SealEnvelope(ctx, g, md.device, []byte(fmt.Sprintf("message %d", i)))
+		require.NoError(t, err)
+
+		entries[i] = newTestMessageEntry(t, env)
+	}
+
+	// the keys of the first window of messages are known
+	require.NoError(t, peers[0].MKS.RegisterChainKey(g, md.device.GetPublic(), ds, false))
+
+	for _, e := range []ipfslog.Entry{entries[window], entries[2*window+1]} {
+		_, err = ms.openMessage(ctx, e)
+		require.Error(t, err)
+	}
+
+	pending := ms.listUndecryptableMessages()
+	require.Len(t, pending, 2)
+	for _, u := range pending {
+		require.Equal(t, bertytypes.UndecryptableMessageReasonCounterOutOfWindow, u.reason)
+		require.Equal(t, ds.Counter+uint64(window), u.knownCounter)
+	}
+
+	// nothing is retried until new keys are computed
+	ms.retryOutOfWindowMessages(ctx, dPKRaw)
+	require.Len(t, ms.listUndecryptableMessages(), 2)
+
+	subCtx, subCancel := context.WithTimeout(ctx, time.Second*5)
+	defer subCancel()
+
+	sub := ms.Subscribe(subCtx)
+
+	// opening a message computes the keys of the next window, only the
+	// message reached by them is retried
+	evt, err := ms.openMessage(ctx, entries[0])
+	require.NoError(t, err)
+	require.Equal(t, []byte("message 0"), evt.Message)
+
+	ms.retryOutOfWindowMessages(ctx, dPKRaw)
+
+	pending = ms.listUndecryptableMessages()
+	require.Len(t, pending, 1)
+	require.Equal(t, ds.Counter+uint64(2*window+2), pending[0].counter)
+	require.Equal(t, uint32(1), pending[0].attempts)
+
+	for evt := range sub {
+		if messageEvent, ok := evt.(*bertytypes.GroupMessageEvent); ok {
+			require.Equal(t, []byte(fmt.Sprintf("message %d", window)), messageEvent.Message)
+			subCancel()
+		}
+	}
+
+	require.Equal(t, context.Canceled, subCtx.Err())
+}
+
+func Test_UndecryptableMessages_Queue(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	peers, _, cleanup := createPeersWithGroup(ctx, t, "/tmp/message_test", 1, 1)
+	defer cleanup()
+
+	g := peers[0].GC.Group()
+	ms := peers[0].GC.MessageStore()
+
+	// a message sealed by another device whose chain key is not known yet
+	md, err := NewDeviceKeystore(keystore.NewMemKeystore()).MemberDeviceForGroup(g)
+	assert.NoError(t, err)
+
+	dPK := md.device.GetPublic()
+	dPKRaw, err := dPK.Raw()
+	assert.NoError(t, err)
+
+	ds, err := newDeviceSecret()
+	assert.NoError(t, err)
+
+	mks := NewInMemMessageKeystore()
+	assert.NoError(t, mks.RegisterChainKey(g, dPK, ds, true))
+
+	env, err := mks.SealEnvelope(ctx, g, md.device, []byte("early message"))
+	assert.NoError(t, err)
+
+	_, err = ms.AddOperation(ctx, operation.NewOperation(nil, "ADD", env), nil)
+	assert.NoError(t, err)
+
+	var pending []*undecryptableMessage
+	for i := 0; i < 20; i++ {
+		if pending = ms.listUndecryptableMessages(); len(pending) > 0 {
+			break
+		}
+
+		<-time.After(time.Millisecond * 50)
+	}
+
+	if !assert.Len(t, pending, 1) {
+		return
+	}
+
+	assert.Equal(t, dPKRaw, pending[0].devicePK)
+	assert.Equal(t, bertytypes.UndecryptableMessageReasonUnknownDevice, pending[0].reason)
+	assert.Equal(t, uint32(1), pending[0].attempts)
+
+	firstSeen := pending[0].firstSeen
+
+	// the message is put back in the queue if it still can't be opened
+	ms.RetryUndecryptableMessages(ctx, dPKRaw)
+
+	pending = ms.listUndecryptableMessages()
+	if !assert.Len(t, pending, 1) {
+		return
+	}
+
+	assert.Equal(t, uint32(2), pending[0].attempts)
+	assert.Equal(t, firstSeen, pending[0].firstSeen)
+
+	// only the messages of the given device are retried
+	assert.NoError(t, peers[0].MKS.RegisterChainKey(g, dPK, ds, false))
+
+	ms.RetryUndecryptableMessages(ctx, []byte("another device"))
+	assert.Len(t, ms.listUndecryptableMessages(), 1)
+
+	subCtx, subCancel := context.WithTimeout(ctx, time.Second*5)
+	defer subCancel()
+
+	sub := ms.Subscribe(subCtx)
+
+	ms.RetryUndecryptableMessages(ctx, dPKRaw)
+	assert.Empty(t, ms.listUndecryptableMessages())
+
+	for evt := range sub {
+		if messageEvent, ok := evt.(*bertytypes.GroupMessageEvent); ok {
+			assert.Equal(t, []byte("early message"), messageEvent.Message)
+			subCancel()
+		}
+	}
+
+	assert.Equal(t, context.Canceled, subCtx.Err())
+}
+
+func Test_MessageExpiry(t *testing.T) {
+	receivedAt := time.Unix(1600000000, 0)
+
+	headers := &bertytypes.MessageHeaders{}
+	_, ok := messageExpiry(headers, receivedAt)
+	assert.False(t, ok)
+
+	// the time set by the sender is ignored, its clock may be skewed
+	headers.Metadata = map[string]string{
+		messageHeaderSentAt: strconv.FormatInt(receivedAt.Add(time.Hour).Unix(), 10),
+		messageHeaderTTL:    "60",
+	}
+
+	expiry, ok := messageExpiry(headers, receivedAt)
+	assert.True(t, ok)
+	assert.Equal(t, receivedAt.Add(time.Minute), expiry)
+
+	assert.False(t, isMessageExpired(headers, receivedAt, receivedAt.Add(59*time.Second)))
+	assert.True(t, isMessageExpired(headers, receivedAt, receivedAt.Add(time.Minute)))
+
+	headers.Metadata[messageHeaderTTL] = "0"
+	assert.False(t, isMessageExpired(headers, receivedAt, receivedAt.Add(time.Hour)))
+}
+
+func Test_MessageReceivedAt(t *testing.T) {
+	hash, err := mh.Sum([]byte("message"), mh.SHA2_256, -1)
+	require.NoError(t, err)
+
+	id := cid.NewCidV1(cid.Raw, hash)
+	mks := NewInMemMessageKeystore()
+	receivedAt := time.Unix(1600000000, 0)
+
+	at, err := mks.MessageReceivedAt(id, receivedAt)
+	require.NoError(t, err)
+	require.True(t, receivedAt.Equal(at))
+
+	// the first receipt is kept, even once the message has been forgotten
+	require.NoError(t, mks.ForgetMessage(id))
+
+	at, err = mks.MessageReceivedAt(id, receivedAt.Add(time.Hour))
+	require.NoError(t, err)
+	require.True(t, receivedAt.Equal(at))
+}
+
+func Test_AddEphemeralMessage(t *testing.T) {
+	testutil.SkipSlow(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	peers, _, cleanup := createPeersWithGroup(ctx, t, "/tmp/message_test", 1, 1)
+	defer cleanup()
+
+	_, err := peers[0].GC.MessageStore().AddMessage(ctx, []byte("lasting message"))
+	assert.NoError(t, err)
+
+	// the lifetime leaves enough time to list the message before it expires
+	_, err = peers[0].GC.MessageStore().AddEphemeralMessage(ctx, []byte("ephemeral message"), time.Second*5)
+	assert.NoError(t, err)
+
+	out, err := peers[0].GC.MessageStore().ListMessages(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, countEntries(out))
+
+	<-time.After(time.Second * 6)
+
+	out, err = peers[0].GC.MessageStore().ListMessages(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, countEntries(out))
+}
+
+func Test_MessageRecords(t *testing.T) {
+	testutil.SkipSlow(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	peers, _, cleanup := createPeersWithGroup(ctx, t, "/tmp/message_test", 1, 1)
+	defer cleanup()
+
+	ms := peers[0].GC.MessageStore()
+
+	op1, err := ms.AddMessage(ctx, []byte("first message"))
+	assert.NoError(t, err)
+
+	op2, err := ms.AddMessage(ctx, []byte("second message"))
+	assert.NoError(t, err)
+
+	_, err = ms.AddEdit(ctx, op1.GetEntry().GetHash(), []byte("first message, edited"))
+	assert.NoError(t, err)
+
+	editOp, err := ms.AddEdit(ctx, op1.GetEntry().GetHash(), []byte("first message, edited twice"))
+	assert.NoError(t, err)
+
+	// records can't be targeted
+	_, err = ms.AddRedaction(ctx, editOp.GetEntry().GetHash())
+	assert.Error(t, err)
+
+	_, err = ms.AddRedaction(ctx, op2.GetEntry().GetHash())
+	assert.NoError(t, err)
+
+	out, err := ms.ListLatestMessages(ctx)
+	assert.NoError(t, err)
+
+	latest := []*bertytypes.GroupMessageEvent(nil)
+	for evt := range out {
+		latest = append(latest, evt)
+	}
+
+	if assert.Len(t, latest, 1) {
+		assert.Equal(t, []byte("first message, edited twice"), latest[0].Message)
+		assert.Equal(t, editOp.GetEntry().GetHash().Bytes(), latest[0].EditID)
+	}
+
+	out, err = ms.ListMessages(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 5, countEntries(out))
+
+	// a record sent by another device is refused even if correctly signed
+	md, err := peers[0].DevKS.MemberDeviceForGroup(peers[0].GC.Group())
+	assert.NoError(t, err)
+
+	record := &bertytypes.MessageRecord{
+		Type:      bertytypes.MessageRecordTypeRedaction,
+		TargetID:  op1.GetEntry().GetHash().Bytes(),
+		Timestamp: time.Now().UnixNano(),
+	}
+
+	data, err := messageRecordPayload(record)
+	assert.NoError(t, err)
+
+	record.Sig, err = md.device.Sign(data)
+	assert.NoError(t, err)
+
+	recordBytes, err := record.Marshal()
+	assert.NoError(t, err)
+
+	ownPK, err := md.device.GetPublic().Raw()
+	assert.NoError(t, err)
+
+	_, err = ms.openMessageRecord(&bertytypes.GroupMessageEvent{
+		Headers: &bertytypes.MessageHeaders{DevicePK: ownPK},
+		Message: recordBytes,
+	})
+	assert.NoError(t, err)
+
+	_, err = ms.openMessageRecord(&bertytypes.GroupMessageEvent{
+		Headers: &bertytypes.MessageHeaders{DevicePK: []byte("another device")},
+		Message: recordBytes,
+	})
+	testSameErrcodes(t, errcode.ErrInvalidInput, err)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"time"
 
 	"encoding/base64"
 
@@ -76,8 +77,9 @@ func openMetadataEntry(log ipfslog.Log, e ipfslog.Entry, g *bertytypes.Group) (*
 		return nil, nil, err
 	}
 
+	// events of an unknown type are passed through with their raw payload
 	meta, event, err := openGroupEnvelope(g, op.GetValue())
-	if err != nil {
+	if err != nil && errcode.Code(err) != errcode.ErrGroupUnknownEventType.Code() {
 		return nil, nil, err
 	}
 
@@ -218,6 +220,187 @@ func metadataStoreSendSecret(ctx context.Context, m *metadataStore, g *bertytype
 	return metadataStoreAddEvent(ctx, m, g, bertytypes.EventTypeGroupDeviceSecretAdded, event, sig)
 }
 
+// RevokeDevice announces that a device of the current member must not be
+// trusted anymore, it can't be the current device
+func (m *metadataStore) RevokeDevice(ctx context.Context, devicePK crypto.PubKey) (operation.Operation, error) {
+	md, err := m.devKS.MemberDeviceForGroup(m.g)
+	if err != nil {
+		return nil, errcode.ErrInternal.Wrap(err)
+	}
+
+	if md.device.GetPublic().Equals(devicePK) {
+		return nil, errcode.ErrInvalidInput.Wrap(fmt.Errorf("can't revoke the current device"))
+	}
+
+	if memberPK, err := m.GetMemberByDevice(devicePK); err != nil || !memberPK.Equals(md.member.GetPublic()) {
+		return nil, errcode.ErrInvalidInput.Wrap(fmt.Errorf("device is not a device of the current member"))
+	}
+
+	revokedDevice, err := devicePK.Raw()
+	if err != nil {
+		return nil, errcode.ErrSerialization.Wrap(err)
+	}
+
+	device, err := md.device.GetPublic().Raw()
+	if err != nil {
+		return nil, errcode.ErrSerialization.Wrap(err)
+	}
+
+	member, err := md.member.GetPublic().Raw()
+	if err != nil {
+		return nil, errcode.ErrSerialization.Wrap(err)
+	}
+
+	memberSig, err := md.member.Sign(deviceRevocationPayload(revokedDevice))
+	if err != nil {
+		return nil, errcode.ErrCryptoSignature.Wrap(err)
+	}
+
+	event := &bertytypes.GroupRevokeDevice{
+		MemberPK:        member,
+		DevicePK:        device,
+		RevokedDevicePK: revokedDevice,
+		MemberSig:       memberSig,
+	}
+
+	sig, err := signProto(event, md.device)
+	if err != nil {
+		return nil, errcode.ErrCryptoSignature.Wrap(err)
+	}
+
+	return metadataStoreAddEvent(ctx, m, m.g, bertytypes.EventTypeGroupDeviceRevoked, event, sig)
+}
+
+// MemberLeave announces that the current member leaves the group, the other
+// members stop sending their secrets to its devices
+func (m *metadataStore) MemberLeave(ctx context.Context) (operation.Operation, error) {
+	if !m.typeChecker(isMultiMemberGroup) {
+		return nil, errcode.ErrGroupInvalidType
+	}
+
+	md, err := m.devKS.MemberDeviceForGroup(m.g)
+	if err != nil {
+		return nil, errcode.ErrInternal.Wrap(err)
+	}
+
+	device, err := md.device.GetPublic().Raw()
+	if err != nil {
+		return nil, errcode.ErrSerialization.Wrap(err)
+	}
+
+	member, err := md.member.GetPublic().Raw()
+	if err != nil {
+		return nil, errcode.ErrSerialization.Wrap(err)
+	}
+
+	joinCID, err := m.Index().(*metadataStoreIndex).memberLastJoinID(md.member.GetPublic())
+	if err != nil {
+		return nil, err
+	}
+
+	memberSig, err := md.member.Sign(memberDeparturePayload(m.g.PublicKey, joinCID))
+	if err != nil {
+		return nil, errcode.ErrCryptoSignature.Wrap(err)
+	}
+
+	event := &bertytypes.GroupMemberLeft{
+		MemberPK:  member,
+		DevicePK:  device,
+		MemberSig: memberSig,
+		JoinCID:   joinCID,
+	}
+
+	sig, err := signProto(event, md.device)
+	if err != nil {
+		return nil, errcode.ErrCryptoSignature.Wrap(err)
+	}
+
+	return metadataStoreAddEvent(ctx, m, m.g, bertytypes.EventTypeGroupMemberLeft, event, sig)
+}
+
+// HasMemberLeft checks if a member left the group and hasn't joined it again
+func (m *metadataStore) HasMemberLeft(pk crypto.PubKey) bool {
+	return m.Index().(*metadataStoreIndex).hasMemberLeft(pk)
+}
+
+// IsDeviceRevoked checks if a device has been revoked by its member
+func (m *metadataStore) IsDeviceRevoked(pk crypto.PubKey) bool {
+	return m.Index().(*metadataStoreIndex).isDeviceRevoked(pk)
+}
+
+// SendRotatedSecret sends a new device secret to a device of a member which
+// already received a previous one, it is encrypted for the device so the
+// other devices of the member can't open it
+func (m *metadataStore) SendRotatedSecret(ctx context.Context, memberPK crypto.PubKey, devicePK crypto.PubKey, ds *bertytypes.DeviceSecret) (operation.Operation, error) {
+	md, err := m.devKS.MemberDeviceForGroup(m.g)
+	if err != nil {
+		return nil, errcode.ErrInternal.Wrap(err)
+	}
+
+	// a random nonce is used as the group id has already been used for the
+	// previous secret
+	nonce, err := cryptoutil.GenerateNonce()
+	if err != nil {
+		return nil, errcode.ErrCryptoNonceGeneration.Wrap(err)
+	}
+
+	payload, err := sealSecretEntryPayload(md.device, devicePK, ds, nonce)
+	if err != nil {
+		return nil, errcode.ErrInternal.Wrap(err)
+	}
+
+	devicePKRaw, err := md.device.GetPublic().Raw()
+	if err != nil {
+		return nil, errcode.ErrSerialization.Wrap(err)
+	}
+
+	memberPKRaw, err := memberPK.Raw()
+	if err != nil {
+		return nil, errcode.ErrSerialization.Wrap(err)
+	}
+
+	destDevicePKRaw, err := devicePK.Raw()
+	if err != nil {
+		return nil, errcode.ErrSerialization.Wrap(err)
+	}
+
+	event := &bertytypes.GroupAddDeviceSecret{
+		DevicePK:     devicePKRaw,
+		DestMemberPK: memberPKRaw,
+		DestDevicePK: destDevicePKRaw,
+		Payload:      payload,
+		Nonce:        nonce[:],
+	}
+
+	sig, err := signProto(event, md.device)
+	if err != nil {
+		return nil, errcode.ErrCryptoSignature.Wrap(err)
+	}
+
+	return metadataStoreAddEvent(ctx, m, m.g, bertytypes.EventTypeGroupDeviceSecretAdded, event, sig)
+}
+
+// SendCurrentSecretToDevice sends the current device secret to a device
+// joining the group after it has been rotated, the secret previously sent to
+// its member is outdated
+func (m *metadataStore) SendCurrentSecretToDevice(ctx context.Context, memberPK crypto.PubKey, devicePK crypto.PubKey) (operation.Operation, error) {
+	ok, err := m.Index().(*metadataStoreIndex).needsRotatedSecret(devicePK)
+	if err != nil {
+		return nil, errcode.ErrInvalidInput.Wrap(err)
+	}
+
+	if !ok {
+		return nil, errcode.ErrGroupSecretAlreadySentToMember
+	}
+
+	ds, err := m.mks.GetDeviceSecret(m.g, m.devKS)
+	if err != nil {
+		return nil, errcode.ErrInvalidInput.Wrap(err)
+	}
+
+	return m.SendRotatedSecret(ctx, memberPK, devicePK, ds)
+}
+
 func (m *metadataStore) ClaimGroupOwnership(ctx context.Context, groupSK crypto.PrivKey) (operation.Operation, error) {
 	if !m.typeChecker(isMultiMemberGroup) {
 		return nil, errcode.ErrGroupInvalidType
@@ -288,6 +471,17 @@ func (m *metadataStore) GetDevicesForMember(pk crypto.PubKey) ([]crypto.PubKey,
 	return m.Index().(*metadataStoreIndex).getDevicesForMember(pk)
 }
 
+// GetContactVerification returns the digest of the last verified safety
+// number of a contact, keyChanged is true if another key has been verified for
+// the same contact
+func (m *metadataStore) GetContactVerification(pk crypto.PubKey) (digest []byte, keyChanged bool, err error) {
+	if !m.typeChecker(isAccountGroup) {
+		return nil, false, errcode.ErrGroupInvalidType
+	}
+
+	return m.Index().(*metadataStoreIndex).getContactVerification(pk)
+}
+
 func (m *metadataStore) ListAdmins() []crypto.PubKey {
 	if m.typeChecker(isContactGroup, isAccountGroup) {
 		return m.ListMembers()
@@ -452,6 +646,59 @@ func (m *metadataStore) GroupJoin(ctx context.Context, g *bertytypes.Group) (ope
 	}, bertytypes.EventTypeAccountGroupJoined)
 }
 
+// GroupDeviceLink announces the device key used by the current device in a
+// multi-member group, so the other devices of the account can revoke it
+func (m *metadataStore) GroupDeviceLink(ctx context.Context, groupPK crypto.PubKey, groupDevice crypto.PrivKey) (operation.Operation, error) {
+	if !m.typeChecker(isAccountGroup) {
+		return nil, errcode.ErrGroupInvalidType
+	}
+
+	md, err := m.devKS.MemberDeviceForGroup(m.g)
+	if err != nil {
+		return nil, errcode.ErrInternal.Wrap(err)
+	}
+
+	if _, err := m.GetGroupDevice(groupPK, md.device.GetPublic()); err == nil {
+		return nil, errcode.ErrInvalidInput.Wrap(fmt.Errorf("device already linked to group"))
+	}
+
+	device, err := md.device.GetPublic().Raw()
+	if err != nil {
+		return nil, errcode.ErrSerialization.Wrap(err)
+	}
+
+	groupPKBytes, err := groupPK.Raw()
+	if err != nil {
+		return nil, errcode.ErrSerialization.Wrap(err)
+	}
+
+	groupDevicePK, err := groupDevice.GetPublic().Raw()
+	if err != nil {
+		return nil, errcode.ErrSerialization.Wrap(err)
+	}
+
+	groupDeviceSig, err := groupDevice.Sign(groupDeviceLinkPayload(device))
+	if err != nil {
+		return nil, errcode.ErrCryptoSignature.Wrap(err)
+	}
+
+	return m.attributeSignAndAddEvent(ctx, &bertytypes.AccountGroupDeviceLinked{
+		GroupPK:        groupPKBytes,
+		GroupDevicePK:  groupDevicePK,
+		GroupDeviceSig: groupDeviceSig,
+	}, bertytypes.EventTypeAccountGroupDeviceLinked)
+}
+
+// GetGroupDevice returns the device key used in a multi-member group by a
+// device of the account
+func (m *metadataStore) GetGroupDevice(groupPK crypto.PubKey, devicePK crypto.PubKey) (crypto.PubKey, error) {
+	if !m.typeChecker(isAccountGroup) {
+		return nil, errcode.ErrGroupInvalidType
+	}
+
+	return m.Index().(*metadataStoreIndex).getGroupDevice(groupPK, devicePK)
+}
+
 // GroupLeave indicates the payload includes that the deviceKeystore has left a group
 func (m *metadataStore) GroupLeave(ctx context.Context, pk crypto.PubKey) (operation.Operation, error) {
 	if !m.typeChecker(isAccountGroup) {
@@ -541,6 +788,7 @@ func (m *metadataStore) ContactRequestOutgoingEnqueue(ctx context.Context, conta
 			PK:                   contact.PK,
 			PublicRendezvousSeed: contact.PublicRendezvousSeed,
 			Metadata:             contact.Metadata,
+			Addrs:                contact.Addrs,
 		},
 		OwnMetadata: ownMetadata,
 	}, bertytypes.EventTypeAccountContactRequestOutgoingEnqueued)
@@ -660,6 +908,33 @@ func (m *metadataStore) ContactUnblock(ctx context.Context, pk crypto.PubKey) (o
 	return m.contactAction(ctx, pk, &bertytypes.AccountContactUnblocked{}, bertytypes.EventTypeAccountContactUnblocked)
 }
 
+// ContactVerified indicates the payload includes that the deviceKeystore has verified the safety number of a contact
+func (m *metadataStore) ContactVerified(ctx context.Context, pk crypto.PubKey, digest []byte) (operation.Operation, error) {
+	if !m.typeChecker(isAccountGroup) {
+		return nil, errcode.ErrGroupInvalidType
+	}
+
+	if len(digest) == 0 {
+		return nil, errcode.ErrInvalidInput
+	}
+
+	if !m.checkContactStatus(pk, bertytypes.ContactStateAdded, bertytypes.ContactStateToRequest, bertytypes.ContactStateReceived) {
+		return nil, errcode.ErrInvalidInput
+	}
+
+	contact, err := m.Index().(*metadataStoreIndex).getContact(pk)
+	if err != nil {
+		return nil, errcode.ErrContactNotFound.Wrap(err)
+	}
+
+	evt := &bertytypes.AccountContactVerified{VerificationDigest: digest}
+	if contact.contact != nil {
+		evt.ContactRendezvousSeed = contact.contact.PublicRendezvousSeed
+	}
+
+	return m.contactAction(ctx, pk, evt, bertytypes.EventTypeAccountContactVerified)
+}
+
 func (m *metadataStore) ContactSendAliasKey(ctx context.Context) (operation.Operation, error) {
 	if !m.typeChecker(isContactGroup) {
 		return nil, errcode.ErrGroupInvalidType
@@ -680,6 +955,83 @@ func (m *metadataStore) ContactSendAliasKey(ctx context.Context) (operation.Oper
 	}, bertytypes.EventTypeContactAliasKeyAdded)
 }
 
+// ContactSendRecoveryShare entrusts a share of the account recovery secret to the contact recipientPK
+func (m *metadataStore) ContactSendRecoveryShare(ctx context.Context, recipientPK crypto.PubKey, share *bertytypes.RecoveryShare) (operation.Operation, error) {
+	if !m.typeChecker(isContactGroup) {
+		return nil, errcode.ErrGroupInvalidType
+	}
+
+	recipient, nonce, encryptedShare, err := m.sealRecoveryShare(recipientPK, share)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.attributeSignAndAddEvent(ctx, &bertytypes.ContactAddRecoveryShare{
+		RecipientPK:    recipient,
+		Nonce:          nonce,
+		EncryptedShare: encryptedShare,
+	}, bertytypes.EventTypeContactRecoveryShareAdded)
+}
+
+// ContactReleaseRecoveryShare gives back a share of a lost account recovery secret to the contact recipientPK
+func (m *metadataStore) ContactReleaseRecoveryShare(ctx context.Context, recipientPK crypto.PubKey, share *bertytypes.RecoveryShare) (operation.Operation, error) {
+	if !m.typeChecker(isContactGroup) {
+		return nil, errcode.ErrGroupInvalidType
+	}
+
+	recipient, nonce, encryptedShare, err := m.sealRecoveryShare(recipientPK, share)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.attributeSignAndAddEvent(ctx, &bertytypes.ContactReleaseRecoveryShare{
+		RecipientPK:    recipient,
+		Nonce:          nonce,
+		EncryptedShare: encryptedShare,
+	}, bertytypes.EventTypeContactRecoveryShareReleased)
+}
+
+func (m *metadataStore) sealRecoveryShare(recipientPK crypto.PubKey, share *bertytypes.RecoveryShare) ([]byte, []byte, []byte, error) {
+	if recipientPK == nil || share == nil {
+		return nil, nil, nil, errcode.ErrInvalidInput
+	}
+
+	recipient, err := recipientPK.Raw()
+	if err != nil {
+		return nil, nil, nil, errcode.ErrSerialization.Wrap(err)
+	}
+
+	accSK, err := m.devKS.AccountPrivKey()
+	if err != nil {
+		return nil, nil, nil, errcode.ErrInternal.Wrap(err)
+	}
+
+	nonce, encryptedShare, err := sealRecoveryShare(accSK, recipientPK, share)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return recipient, nonce, encryptedShare, nil
+}
+
+// ListRecoveryShares returns the recovery shares entrusted by the contact
+func (m *metadataStore) ListRecoveryShares() []*bertytypes.ContactAddRecoveryShare {
+	if !m.typeChecker(isContactGroup) {
+		return nil
+	}
+
+	return m.Index().(*metadataStoreIndex).listRecoveryShares()
+}
+
+// ListReleasedRecoveryShares returns the recovery shares given back by the contact
+func (m *metadataStore) ListReleasedRecoveryShares() []*bertytypes.ContactReleaseRecoveryShare {
+	if !m.typeChecker(isContactGroup) {
+		return nil
+	}
+
+	return m.Index().(*metadataStoreIndex).listReleasedRecoveryShares()
+}
+
 func (m *metadataStore) SendAliasProof(ctx context.Context) (operation.Operation, error) {
 	if !m.typeChecker(isMultiMemberGroup) {
 		return nil, errcode.ErrGroupInvalidType
@@ -694,6 +1046,97 @@ func (m *metadataStore) SendAliasProof(ctx context.Context) (operation.Operation
 	}, bertytypes.EventTypeMultiMemberGroupAliasResolverAdded)
 }
 
+// SetEphemeralSettings sets the lifetime of the messages sent to the group,
+// a zero ttl disables disappearing messages
+func (m *metadataStore) SetEphemeralSettings(ctx context.Context, ttl time.Duration) (operation.Operation, error) {
+	if m.typeChecker(isAccountGroup) {
+		return nil, errcode.ErrGroupInvalidType
+	}
+
+	if ttl < 0 || ttl%time.Second != 0 {
+		return nil, errcode.ErrInvalidInput.Wrap(fmt.Errorf("ttl must be a positive number of seconds"))
+	}
+
+	return m.attributeSignAndAddEvent(ctx, &bertytypes.GroupSetEphemeralSettings{
+		MessageTTL: int64(ttl / time.Second),
+	}, bertytypes.EventTypeGroupEphemeralSettingsUpdated)
+}
+
+// GetMessageTTL returns the lifetime of the messages of the group, 0 if
+// disappearing messages are disabled
+func (m *metadataStore) GetMessageTTL() time.Duration {
+	return m.Index().(*metadataStoreIndex).messageTTL()
+}
+
+// AnnounceCapabilities announces the latest version of the group format and
+// the features supported by the current device, nothing is sent if they have
+// already been announced
+func (m *metadataStore) AnnounceCapabilities(ctx context.Context) (operation.Operation, error) {
+	md, err := m.devKS.MemberDeviceForGroup(m.g)
+	if err != nil {
+		return nil, errcode.ErrInternal.Wrap(err)
+	}
+
+	devicePK, err := md.device.Raw()
+	if err != nil {
+		return nil, errcode.ErrSerialization.Wrap(err)
+	}
+
+	maxVersion, capabilities, ok := m.Index().(*metadataStoreIndex).getDeviceCapabilities(devicePK)
+	if ok && maxVersion == CurrentGroupVersion && equalGroupCapabilities(capabilities, supportedGroupCapabilities) {
+		return nil, nil
+	}
+
+	return m.attributeSignAndAddEvent(ctx, &bertytypes.GroupAnnounceCapabilities{
+		MaxVersion:   CurrentGroupVersion,
+		Capabilities: supportedGroupCapabilities,
+	}, bertytypes.EventTypeGroupDeviceCapabilitiesAnnounced)
+}
+
+func equalGroupCapabilities(a, b []bertytypes.GroupCapability) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// UpgradeVersion upgrades the group to a new version of the group format, the
+// version must be supported by every device of the group and by the current
+// implementation
+func (m *metadataStore) UpgradeVersion(ctx context.Context, version uint32) (operation.Operation, error) {
+	idx := m.Index().(*metadataStoreIndex)
+
+	if version <= idx.getGroupVersion() {
+		return nil, errcode.ErrInvalidInput.Wrap(fmt.Errorf("group is already using version %d", idx.getGroupVersion()))
+	}
+
+	if version > CurrentGroupVersion || version > idx.getUpgradableGroupVersion() {
+		return nil, errcode.ErrGroupUnsupportedVersion.Wrap(fmt.Errorf("version %d is not supported by every device of the group", version))
+	}
+
+	return m.attributeSignAndAddEvent(ctx, &bertytypes.GroupUpgradeVersion{
+		Version: version,
+	}, bertytypes.EventTypeGroupVersionUpgraded)
+}
+
+// GetGroupVersion returns the version of the group format currently in use
+func (m *metadataStore) GetGroupVersion() uint32 {
+	return m.Index().(*metadataStoreIndex).getGroupVersion()
+}
+
+// GetUpgradableGroupVersion returns the latest version of the group format
+// supported by every device of the group
+func (m *metadataStore) GetUpgradableGroupVersion() uint32 {
+	return m.Index().(*metadataStoreIndex).getUpgradableGroupVersion()
+}
+
 func (m *metadataStore) SendAppMetadata(ctx context.Context, message []byte) (operation.Operation, error) {
 	return m.attributeSignAndAddEvent(ctx, &bertytypes.AppMetadata{
 		Message: message,
@@ -858,17 +1301,20 @@ func constructorFactoryGroupMetadata(s *bertyOrbitDB) iface.StoreConstructor {
 }
 
 func newSecretEntryPayload(localDevicePrivKey crypto.PrivKey, remoteMemberPubKey crypto.PubKey, secret *bertytypes.DeviceSecret, group *bertytypes.Group) ([]byte, error) {
+	return sealSecretEntryPayload(localDevicePrivKey, remoteMemberPubKey, secret, groupIDToNonce(group))
+}
+
+func sealSecretEntryPayload(localDevicePrivKey crypto.PrivKey, remotePubKey crypto.PubKey, secret *bertytypes.DeviceSecret, nonce *[cryptoutil.NonceSize]byte) ([]byte, error) {
 	message, err := secret.Marshal()
 	if err != nil {
 		return nil, errcode.ErrSerialization.Wrap(err)
 	}
 
-	mongPriv, mongPub, err := cryptoutil.EdwardsToMontgomery(localDevicePrivKey, remoteMemberPubKey)
+	mongPriv, mongPub, err := cryptoutil.EdwardsToMontgomery(localDevicePrivKey, remotePubKey)
 	if err != nil {
 		return nil, errcode.ErrCryptoKeyConversion.Wrap(err)
 	}
 
-	nonce := groupIDToNonce(group)
 	encryptedSecret := box.Seal(nil, message, nonce, mongPub, mongPriv)
 
 	return encryptedSecret, nil
@@ -1,9 +1,11 @@
 package bertyprotocol
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"berty.tech/berty/v2/go/pkg/bertytypes"
 	"berty.tech/berty/v2/go/pkg/errcode"
@@ -19,10 +21,23 @@ type metadataStoreIndex struct {
 	members                  map[string][]*memberDevice
 	devices                  map[string]*memberDevice
 	handledEvents            map[string]struct{}
+	revokedDevices           map[string]map[string]*deviceRevocation
+	memberJoins              map[string]*memberJoin
+	deviceJoins              map[string]int
+	memberDepartures         map[string]map[string]int
+	ephemeralSettings        *ephemeralSettings
+	deviceCapabilities       map[string]*deviceCapabilities
+	groupVersion             uint32
+	versionUpgrades          map[string]*groupVersionUpgrade
 	sentSecrets              map[string]struct{}
+	sentDeviceSecrets        map[string]struct{}
+	ownSecretRotated         bool
 	admins                   map[crypto.PubKey]struct{}
 	contacts                 map[string]*accountContact
+	contactsVerified         map[string][]byte
+	contactsVerifiedBySeed   map[string]*verifiedContact
 	groups                   map[string]*accountGroup
+	groupDevices             map[string]map[string][]byte
 	contactRequestMetadata   map[string][]byte
 	contactRequestSeed       []byte
 	contactRequestEnabled    *bool
@@ -31,6 +46,8 @@ type metadataStoreIndex struct {
 	eventsContactAddAliasKey []*bertytypes.ContactAddAliasKey
 	ownAliasKeySent          bool
 	otherAliasKey            []byte
+	recoveryShares           []*bertytypes.ContactAddRecoveryShare
+	releasedRecoveryShares   []*bertytypes.ContactReleaseRecoveryShare
 	g                        *bertytypes.Group
 	ownMemberDevice          *memberDevice
 	ctx                      context.Context
@@ -59,11 +76,16 @@ func (m *metadataStoreIndex) UpdateIndex(log ipfslog.Log, _ []ipfslog.Entry) err
 
 	// Resetting state
 	m.contacts = map[string]*accountContact{}
+	m.contactsVerified = map[string][]byte{}
+	m.contactsVerifiedBySeed = map[string]*verifiedContact{}
 	m.groups = map[string]*accountGroup{}
+	m.groupDevices = map[string]map[string][]byte{}
 	m.contactRequestMetadata = map[string][]byte{}
 	m.contactRequestEnabled = nil
 	m.contactRequestSeed = []byte(nil)
 
+	opened := make([]*openedMetadataEntry, 0, len(entries))
+
 	for i := len(entries) - 1; i >= 0; i-- {
 		e := entries[i]
 
@@ -80,6 +102,89 @@ func (m *metadataStoreIndex) UpdateIndex(log ipfslog.Log, _ []ipfslog.Entry) err
 			continue
 		}
 
+		opened = append(opened, &openedMetadataEntry{entry: e, metaEvent: metaEvent, event: event})
+	}
+
+	// Devices and their revocations are handled first, entries are processed
+	// from the newest to the oldest so those signed by a revoked device would
+	// be handled before the revocation itself otherwise
+	for _, o := range opened {
+		if o.metaEvent.Metadata.EventType != bertytypes.EventTypeGroupMemberDeviceAdded {
+			continue
+		}
+
+		if err := m.handleGroupAddMemberDevice(o.event); err != nil {
+			m.logger.Error("unable to handle event", zap.Error(err))
+		}
+
+		m.trackMemberJoin(o.entry, o.event)
+		m.trackDeviceJoin(o.entry, o.event)
+	}
+
+	for _, o := range opened {
+		if o.metaEvent.Metadata.EventType != bertytypes.EventTypeGroupDeviceRevoked {
+			continue
+		}
+
+		if err := m.handleGroupDeviceRevoked(o.entry, o.event); err != nil {
+			m.logger.Error("unable to handle device revocation", zap.Error(err))
+		}
+	}
+
+	for _, o := range opened {
+		if o.metaEvent.Metadata.EventType != bertytypes.EventTypeGroupMemberLeft || m.isSignedByRevokedDevice(o.entry, o.event) {
+			continue
+		}
+
+		if err := m.handleGroupMemberLeft(o.entry, o.event); err != nil {
+			m.logger.Error("unable to handle member departure", zap.Error(err))
+		}
+	}
+
+	for _, o := range opened {
+		e, metaEvent, event := o.entry, o.metaEvent, o.event
+
+		switch {
+		case metaEvent.Metadata.EventType == bertytypes.EventTypeGroupMemberDeviceAdded,
+			metaEvent.Metadata.EventType == bertytypes.EventTypeGroupDeviceRevoked,
+			metaEvent.Metadata.EventType == bertytypes.EventTypeGroupMemberLeft:
+			m.handledEvents[e.GetHash().String()] = struct{}{}
+			continue
+
+		case m.isSignedByRevokedDevice(e, event):
+			m.handledEvents[e.GetHash().String()] = struct{}{}
+			m.logger.Warn("ignoring entry signed by a revoked device", zap.String("event-type", metaEvent.Metadata.EventType.String()))
+			continue
+
+		case metaEvent.Metadata.EventType == bertytypes.EventTypeGroupEphemeralSettingsUpdated:
+			m.handledEvents[e.GetHash().String()] = struct{}{}
+			if err := m.handleGroupEphemeralSettingsUpdated(e, event); err != nil {
+				m.logger.Error("unable to handle ephemeral settings", zap.Error(err))
+			}
+			continue
+
+		case metaEvent.Metadata.EventType == bertytypes.EventTypeGroupDeviceCapabilitiesAnnounced:
+			m.handledEvents[e.GetHash().String()] = struct{}{}
+			if err := m.handleGroupDeviceCapabilitiesAnnounced(e, event); err != nil {
+				m.logger.Error("unable to handle device capabilities", zap.Error(err))
+			}
+			continue
+
+		case metaEvent.Metadata.EventType == bertytypes.EventTypeGroupVersionUpgraded:
+			m.handledEvents[e.GetHash().String()] = struct{}{}
+			if err := m.handleGroupVersionUpgraded(e, event); err != nil {
+				m.logger.Error("unable to handle group version upgrade", zap.Error(err))
+			}
+			continue
+
+		case metaEvent.UnknownType:
+			// events added by a newer version are kept in the log but don't
+			// alter the state of the group
+			m.handledEvents[e.GetHash().String()] = struct{}{}
+			m.logger.Debug("ignoring event of unknown type", zap.String("event-type", metaEvent.Metadata.EventType.String()))
+			continue
+		}
+
 		handlers, ok := m.eventHandlers[metaEvent.Metadata.EventType]
 		if !ok {
 			m.handledEvents[e.GetHash().String()] = struct{}{}
@@ -90,7 +195,7 @@ func (m *metadataStoreIndex) UpdateIndex(log ipfslog.Log, _ []ipfslog.Entry) err
 		var lastErr error
 
 		for _, h := range handlers {
-			err = h(event)
+			err := h(event)
 			if err != nil {
 				m.logger.Error("unable to handle event", zap.Error(err))
 				lastErr = err
@@ -105,6 +210,9 @@ func (m *metadataStoreIndex) UpdateIndex(log ipfslog.Log, _ []ipfslog.Entry) err
 		m.handledEvents[e.GetHash().String()] = struct{}{}
 	}
 
+	// upgrades are applied once the capabilities of every device are known
+	m.applyGroupVersionUpgrades()
+
 	for _, h := range m.postIndexActions {
 		if err := h(); err != nil {
 			return errcode.ErrInternal.Wrap(err)
@@ -147,6 +255,484 @@ func (m *metadataStoreIndex) handleGroupAddMemberDevice(event proto.Message) err
 	return nil
 }
 
+type openedMetadataEntry struct {
+	entry     ipfslog.Entry
+	metaEvent *bertytypes.GroupMetadataEvent
+	event     proto.Message
+}
+
+// deviceRevocation holds the member who revoked a device and the lamport time
+// of the revocation, entries can't be ordered more precisely than that
+type deviceRevocation struct {
+	member    crypto.PubKey
+	time      int
+	confirmed bool
+}
+
+func (m *metadataStoreIndex) handleGroupDeviceRevoked(entry ipfslog.Entry, event proto.Message) error {
+	e, ok := event.(*bertytypes.GroupRevokeDevice)
+	if !ok {
+		return errcode.ErrInvalidInput
+	}
+
+	member, err := crypto.UnmarshalEd25519PublicKey(e.MemberPK)
+	if err != nil {
+		return errcode.ErrDeserialization.Wrap(err)
+	}
+
+	if _, err := crypto.UnmarshalEd25519PublicKey(e.RevokedDevicePK); err != nil {
+		return errcode.ErrDeserialization.Wrap(err)
+	}
+
+	// the device may not have been announced yet, revocations are kept by
+	// member so one made by another member can't shadow the one of the owner
+	revocations, ok := m.revokedDevices[string(e.RevokedDevicePK)]
+	if !ok {
+		revocations = map[string]*deviceRevocation{}
+		m.revokedDevices[string(e.RevokedDevicePK)] = revocations
+	}
+
+	// keep the earliest revocation of each member
+	if r, ok := revocations[string(e.MemberPK)]; ok && r.time <= entry.GetClock().GetTime() {
+		return nil
+	}
+
+	revocations[string(e.MemberPK)] = &deviceRevocation{
+		member: member,
+		time:   entry.GetClock().GetTime(),
+	}
+
+	return nil
+}
+
+// deviceRevocationByOwner returns the revocation of device made by the member
+// owning it, if any
+func (m *metadataStoreIndex) deviceRevocationByOwner(device []byte) (*deviceRevocation, bool) {
+	for _, r := range m.revokedDevices[string(device)] {
+		if r.confirmed || m.isDeviceOwnedBy(device, r.member) {
+			return r, true
+		}
+	}
+
+	return nil, false
+}
+
+// isSignedByRevokedDevice checks if an entry has been signed by a device after
+// its revocation by the member owning it
+func (m *metadataStoreIndex) isSignedByRevokedDevice(entry ipfslog.Entry, event proto.Message) bool {
+	evt, ok := event.(eventDeviceSigned)
+	if !ok {
+		return false
+	}
+
+	r, ok := m.deviceRevocationByOwner(evt.GetDevicePK())
+
+	return ok && entry.GetClock().GetTime() > r.time
+}
+
+// isDeviceOwnedBy checks if device has been announced by member, a member can
+// only revoke its own devices
+func (m *metadataStoreIndex) isDeviceOwnedBy(device []byte, member crypto.PubKey) bool {
+	md, ok := m.devices[string(device)]
+
+	return ok && md.member.Equals(member)
+}
+
+func (m *metadataStoreIndex) postHandlerRevokedDevices() error {
+	for device := range m.revokedDevices {
+		r, ok := m.deviceRevocationByOwner([]byte(device))
+		if !ok {
+			continue
+		}
+
+		// the device won't be listed anymore, remember that the revocation
+		// has been checked
+		r.confirmed = true
+
+		delete(m.devices, device)
+
+		memberPK, err := r.member.Raw()
+		if err != nil {
+			return errcode.ErrSerialization.Wrap(err)
+		}
+
+		mds := m.members[string(memberPK)]
+		for i, md := range mds {
+			if pk, err := md.device.Raw(); err == nil && string(pk) == device {
+				mds = append(mds[:i], mds[i+1:]...)
+				break
+			}
+		}
+
+		if len(mds) == 0 {
+			delete(m.members, string(memberPK))
+		} else {
+			m.members[string(memberPK)] = mds
+		}
+	}
+
+	return nil
+}
+
+// memberJoin holds the last entry adding a device of a member, a departure
+// only applies to the membership started by the entry it references
+type memberJoin struct {
+	time int
+	id   string
+}
+
+// trackMemberJoin keeps the last entry adding a device of a member, a member
+// which left the group can join it again
+func (m *metadataStoreIndex) trackMemberJoin(entry ipfslog.Entry, event proto.Message) {
+	e, ok := event.(*bertytypes.GroupAddMemberDevice)
+	if !ok {
+		return
+	}
+
+	// the hash breaks the ties so every device settles on the same entry
+	id := string(entry.GetHash().Bytes())
+	if j, ok := m.memberJoins[string(e.MemberPK)]; !ok || j.time < entry.GetClock().GetTime() || (j.time == entry.GetClock().GetTime() && j.id < id) {
+		m.memberJoins[string(e.MemberPK)] = &memberJoin{time: entry.GetClock().GetTime(), id: id}
+	}
+}
+
+// trackDeviceJoin keeps the lamport time of the first entry adding a device
+func (m *metadataStoreIndex) trackDeviceJoin(entry ipfslog.Entry, event proto.Message) {
+	e, ok := event.(*bertytypes.GroupAddMemberDevice)
+	if !ok {
+		return
+	}
+
+	if t, ok := m.deviceJoins[string(e.DevicePK)]; !ok || t > entry.GetClock().GetTime() {
+		m.deviceJoins[string(e.DevicePK)] = entry.GetClock().GetTime()
+	}
+}
+
+func (m *metadataStoreIndex) handleGroupMemberLeft(entry ipfslog.Entry, event proto.Message) error {
+	e, ok := event.(*bertytypes.GroupMemberLeft)
+	if !ok {
+		return errcode.ErrInvalidInput
+	}
+
+	member, err := crypto.UnmarshalEd25519PublicKey(e.MemberPK)
+	if err != nil {
+		return errcode.ErrDeserialization.Wrap(err)
+	}
+
+	// the member signature proves the departure, the device must not belong
+	// to another member though
+	if md, ok := m.devices[string(e.DevicePK)]; ok && !md.member.Equals(member) {
+		return errcode.ErrInvalidInput.Wrap(fmt.Errorf("departure sent by a device of another member"))
+	}
+
+	// a departure can't precede the membership it ends, it would be a
+	// replayed one
+	if j, ok := m.memberJoins[string(e.MemberPK)]; ok && j.time > entry.GetClock().GetTime() {
+		return errcode.ErrInvalidInput.Wrap(fmt.Errorf("departure older than the last join of the member"))
+	}
+
+	departures, ok := m.memberDepartures[string(e.MemberPK)]
+	if !ok {
+		departures = map[string]int{}
+		m.memberDepartures[string(e.MemberPK)] = departures
+	}
+
+	// departures are kept by membership, a replayed one can't shadow the
+	// departure following a later join
+	if t, ok := departures[string(e.JoinCID)]; !ok || t < entry.GetClock().GetTime() {
+		departures[string(e.JoinCID)] = entry.GetClock().GetTime()
+	}
+
+	return nil
+}
+
+// unsafeHasMemberLeft checks if a member left the group and hasn't joined it
+// again since
+func (m *metadataStoreIndex) unsafeHasMemberLeft(member []byte) bool {
+	departures, ok := m.memberDepartures[string(member)]
+	if !ok || len(departures) == 0 {
+		return false
+	}
+
+	joined, ok := m.memberJoins[string(member)]
+	if !ok {
+		return true
+	}
+
+	left, ok := departures[joined.id]
+
+	return ok && joined.time < left
+}
+
+// memberLastJoinID returns the hash of the last entry adding a device of a
+// member, it is referenced by the departure of the member
+func (m *metadataStoreIndex) memberLastJoinID(pk crypto.PubKey) ([]byte, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	id, err := pk.Raw()
+	if err != nil {
+		return nil, errcode.ErrSerialization.Wrap(err)
+	}
+
+	joined, ok := m.memberJoins[string(id)]
+	if !ok {
+		return nil, errcode.ErrInvalidInput.Wrap(fmt.Errorf("member hasn't joined the group"))
+	}
+
+	return []byte(joined.id), nil
+}
+
+func (m *metadataStoreIndex) hasMemberLeft(pk crypto.PubKey) bool {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	id, err := pk.Raw()
+	if err != nil {
+		return false
+	}
+
+	return m.unsafeHasMemberLeft(id)
+}
+
+func (m *metadataStoreIndex) postHandlerLeftMembers() error {
+	for member := range m.memberDepartures {
+		if !m.unsafeHasMemberLeft([]byte(member)) {
+			continue
+		}
+
+		for _, md := range m.members[member] {
+			if pk, err := md.device.Raw(); err == nil {
+				delete(m.devices, string(pk))
+				delete(m.sentDeviceSecrets, string(pk))
+			}
+		}
+
+		delete(m.members, member)
+
+		// secrets will have to be sent again if the member joins back
+		delete(m.sentSecrets, member)
+	}
+
+	return nil
+}
+
+func (m *metadataStoreIndex) isDeviceRevoked(pk crypto.PubKey) bool {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	id, err := pk.Raw()
+	if err != nil {
+		return false
+	}
+
+	r, ok := m.deviceRevocationByOwner(id)
+
+	return ok && r.confirmed
+}
+
+// ephemeralSettings holds the lifetime of the messages of a group, the latest
+// setting is kept using the lamport time of its entry and its hash as a tie
+// breaker, so every device settles on the same value
+type ephemeralSettings struct {
+	messageTTL time.Duration
+	time       int
+	id         string
+}
+
+func (m *metadataStoreIndex) handleGroupEphemeralSettingsUpdated(entry ipfslog.Entry, event proto.Message) error {
+	e, ok := event.(*bertytypes.GroupSetEphemeralSettings)
+	if !ok {
+		return errcode.ErrInvalidInput
+	}
+
+	if _, ok := m.devices[string(e.DevicePK)]; !ok {
+		return errcode.ErrInvalidInput.Wrap(fmt.Errorf("ephemeral settings sent by an unknown device"))
+	}
+
+	if e.MessageTTL < 0 {
+		return errcode.ErrInvalidInput.Wrap(fmt.Errorf("invalid message ttl"))
+	}
+
+	t, id := entry.GetClock().GetTime(), entry.GetHash().String()
+	if s := m.ephemeralSettings; s != nil && (s.time > t || (s.time == t && s.id > id)) {
+		return nil
+	}
+
+	m.ephemeralSettings = &ephemeralSettings{
+		messageTTL: time.Duration(e.MessageTTL) * time.Second,
+		time:       t,
+		id:         id,
+	}
+
+	return nil
+}
+
+// deviceCapabilities holds the latest capabilities announced by a device,
+// ordered like ephemeralSettings
+type deviceCapabilities struct {
+	maxVersion   uint32
+	capabilities []bertytypes.GroupCapability
+	time         int
+	id           string
+}
+
+func (m *metadataStoreIndex) handleGroupDeviceCapabilitiesAnnounced(entry ipfslog.Entry, event proto.Message) error {
+	e, ok := event.(*bertytypes.GroupAnnounceCapabilities)
+	if !ok {
+		return errcode.ErrInvalidInput
+	}
+
+	if _, ok := m.devices[string(e.DevicePK)]; !ok {
+		return errcode.ErrInvalidInput.Wrap(fmt.Errorf("capabilities announced by an unknown device"))
+	}
+
+	t, id := entry.GetClock().GetTime(), entry.GetHash().String()
+	if c, ok := m.deviceCapabilities[string(e.DevicePK)]; ok && (c.time > t || (c.time == t && c.id > id)) {
+		return nil
+	}
+
+	m.deviceCapabilities[string(e.DevicePK)] = &deviceCapabilities{
+		maxVersion:   e.MaxVersion,
+		capabilities: e.Capabilities,
+		time:         t,
+		id:           id,
+	}
+
+	return nil
+}
+
+// groupVersionUpgrade is an upgrade of the group format waiting for the
+// capabilities of the devices of the group to be known
+type groupVersionUpgrade struct {
+	version uint32
+	time    int
+}
+
+func (m *metadataStoreIndex) handleGroupVersionUpgraded(entry ipfslog.Entry, event proto.Message) error {
+	e, ok := event.(*bertytypes.GroupUpgradeVersion)
+	if !ok {
+		return errcode.ErrInvalidInput
+	}
+
+	if _, ok := m.devices[string(e.DevicePK)]; !ok {
+		return errcode.ErrInvalidInput.Wrap(fmt.Errorf("group upgraded by an unknown device"))
+	}
+
+	if e.Version > CurrentGroupVersion {
+		return errcode.ErrGroupUnsupportedVersion.Wrap(fmt.Errorf("group upgraded to version %d", e.Version))
+	}
+
+	m.versionUpgrades[entry.GetHash().String()] = &groupVersionUpgrade{
+		version: e.Version,
+		time:    entry.GetClock().GetTime(),
+	}
+
+	return nil
+}
+
+// applyGroupVersionUpgrades applies the upgrades supported by every device
+// which was part of the group when they were sent, the others are kept until
+// the missing capabilities are replicated. Upgrades can't be reverted, the
+// highest version is kept.
+func (m *metadataStoreIndex) applyGroupVersionUpgrades() {
+	for id, u := range m.versionUpgrades {
+		if u.version <= m.unsafeGetGroupVersion() {
+			delete(m.versionUpgrades, id)
+			continue
+		}
+
+		if u.version > m.unsafeGetUpgradableGroupVersion(u.time) {
+			continue
+		}
+
+		m.groupVersion = u.version
+		delete(m.versionUpgrades, id)
+	}
+}
+
+// getGroupVersion returns the version of the group format, groups which have
+// never been upgraded use the initial version
+func (m *metadataStoreIndex) getGroupVersion() uint32 {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	return m.unsafeGetGroupVersion()
+}
+
+func (m *metadataStoreIndex) unsafeGetGroupVersion() uint32 {
+	if m.groupVersion < 1 {
+		return 1
+	}
+
+	return m.groupVersion
+}
+
+// getUpgradableGroupVersion returns the latest version of the group format
+// supported by every device of the group, devices which haven't announced
+// their capabilities are considered to only support the initial version
+func (m *metadataStoreIndex) getUpgradableGroupVersion() uint32 {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	return m.unsafeGetUpgradableGroupVersion(-1)
+}
+
+// unsafeGetUpgradableGroupVersion returns the latest version supported by
+// every device added before the given lamport time, or by every device if
+// it is negative
+func (m *metadataStoreIndex) unsafeGetUpgradableGroupVersion(before int) uint32 {
+	version := uint32(0)
+
+	for pk := range m.devices {
+		if t, ok := m.deviceJoins[pk]; before >= 0 && ok && t >= before {
+			continue
+		}
+
+		deviceVersion := uint32(1)
+		if c, ok := m.deviceCapabilities[pk]; ok && c.maxVersion > 1 {
+			deviceVersion = c.maxVersion
+		}
+
+		if version == 0 || deviceVersion < version {
+			version = deviceVersion
+		}
+	}
+
+	if current := m.unsafeGetGroupVersion(); version < current {
+		return current
+	}
+
+	return version
+}
+
+// getDeviceCapabilities returns the latest version and the capabilities
+// announced by a device
+func (m *metadataStoreIndex) getDeviceCapabilities(devicePK []byte) (uint32, []bertytypes.GroupCapability, bool) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	c, ok := m.deviceCapabilities[string(devicePK)]
+	if !ok {
+		return 0, nil, false
+	}
+
+	return c.maxVersion, c.capabilities, true
+}
+
+// messageTTL returns the lifetime of the messages of the group, 0 if
+// disappearing messages are disabled
+func (m *metadataStoreIndex) messageTTL() time.Duration {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	if m.ephemeralSettings == nil {
+		return 0
+	}
+
+	return m.ephemeralSettings.messageTTL
+}
+
 func (m *metadataStoreIndex) handleGroupAddDeviceSecret(event proto.Message) error {
 	e, ok := event.(*bertytypes.GroupAddDeviceSecret)
 	if !ok {
@@ -163,7 +749,15 @@ func (m *metadataStoreIndex) handleGroupAddDeviceSecret(event proto.Message) err
 		return errcode.ErrDeserialization.Wrap(err)
 	}
 
-	if m.ownMemberDevice.device.Equals(senderPK) {
+	if !m.ownMemberDevice.device.Equals(senderPK) {
+		return nil
+	}
+
+	// rotated secrets are sent to each device rather than to their member
+	if len(e.DestDevicePK) > 0 {
+		m.sentDeviceSecrets[string(e.DestDevicePK)] = struct{}{}
+		m.ownSecretRotated = true
+	} else {
 		m.sentSecrets[string(e.DestMemberPK)] = struct{}{}
 	}
 
@@ -270,6 +864,22 @@ func (m *metadataStoreIndex) areSecretsAlreadySent(pk crypto.PubKey) (bool, erro
 	return ok, nil
 }
 
+// needsRotatedSecret checks if the secret of the current device has been
+// rotated without being sent to a device, the secret sent to its member
+// beforehand is outdated
+func (m *metadataStoreIndex) needsRotatedSecret(pk crypto.PubKey) (bool, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	key, err := pk.Raw()
+	if err != nil {
+		return false, errcode.ErrInvalidInput.Wrap(err)
+	}
+
+	_, ok := m.sentDeviceSecrets[string(key)]
+	return m.ownSecretRotated && !ok, nil
+}
+
 type accountGroupJoinedState uint32
 
 const (
@@ -287,6 +897,53 @@ type accountContact struct {
 	contact *bertytypes.ShareableContact
 }
 
+// verifiedContact is the key of a contact at the time of its verification
+type verifiedContact struct {
+	contactPK []byte
+	digest    []byte
+}
+
+func (m *metadataStoreIndex) handleGroupDeviceLinked(event proto.Message) error {
+	evt, ok := event.(*bertytypes.AccountGroupDeviceLinked)
+	if !ok {
+		return errcode.ErrInvalidInput
+	}
+
+	devices, ok := m.groupDevices[string(evt.GroupPK)]
+	if !ok {
+		devices = map[string][]byte{}
+		m.groupDevices[string(evt.GroupPK)] = devices
+	}
+
+	if _, ok := devices[string(evt.DevicePK)]; !ok {
+		devices[string(evt.DevicePK)] = evt.GroupDevicePK
+	}
+
+	return nil
+}
+
+func (m *metadataStoreIndex) getGroupDevice(groupPK crypto.PubKey, devicePK crypto.PubKey) (crypto.PubKey, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	groupPKBytes, err := groupPK.Raw()
+	if err != nil {
+		return nil, errcode.ErrSerialization.Wrap(err)
+	}
+
+	devicePKBytes, err := devicePK.Raw()
+	if err != nil {
+		return nil, errcode.ErrSerialization.Wrap(err)
+	}
+
+	groupDevicePK, ok := m.groupDevices[string(groupPKBytes)][string(devicePKBytes)]
+	if !ok {
+		return nil, errcode.ErrMissingMapKey
+	}
+
+	return crypto.UnmarshalEd25519PublicKey(groupDevicePK)
+}
+
 func (m *metadataStoreIndex) handleGroupJoined(event proto.Message) error {
 	evt, ok := event.(*bertytypes.AccountGroupJoined)
 	if !ok {
@@ -386,6 +1043,10 @@ func (m *metadataStoreIndex) handleContactRequestOutgoingEnqueued(event proto.Me
 			m.contacts[string(evt.Contact.PK)].contact.PublicRendezvousSeed = evt.Contact.PublicRendezvousSeed
 		}
 
+		if m.contacts[string(evt.Contact.PK)].contact.Addrs == nil {
+			m.contacts[string(evt.Contact.PK)].contact.Addrs = evt.Contact.Addrs
+		}
+
 		return nil
 	}
 
@@ -399,6 +1060,7 @@ func (m *metadataStoreIndex) handleContactRequestOutgoingEnqueued(event proto.Me
 			PK:                   evt.Contact.PK,
 			Metadata:             evt.Contact.Metadata,
 			PublicRendezvousSeed: evt.Contact.PublicRendezvousSeed,
+			Addrs:                evt.Contact.Addrs,
 		},
 	}
 
@@ -539,6 +1201,27 @@ func (m *metadataStoreIndex) handleContactUnblocked(event proto.Message) error {
 	return nil
 }
 
+func (m *metadataStoreIndex) handleContactVerified(event proto.Message) error {
+	evt, ok := event.(*bertytypes.AccountContactVerified)
+	if !ok {
+		return errcode.ErrInvalidInput
+	}
+
+	if _, ok := m.contactsVerified[string(evt.ContactPK)]; !ok {
+		m.contactsVerified[string(evt.ContactPK)] = evt.VerificationDigest
+	}
+
+	seed := string(evt.ContactRendezvousSeed)
+	if _, ok := m.contactsVerifiedBySeed[seed]; !ok && seed != "" {
+		m.contactsVerifiedBySeed[seed] = &verifiedContact{
+			contactPK: evt.ContactPK,
+			digest:    evt.VerificationDigest,
+		}
+	}
+
+	return nil
+}
+
 func (m *metadataStoreIndex) handleContactAliasKeyAdded(event proto.Message) error {
 	evt, ok := event.(*bertytypes.ContactAddAliasKey)
 	if !ok {
@@ -550,6 +1233,51 @@ func (m *metadataStoreIndex) handleContactAliasKeyAdded(event proto.Message) err
 	return nil
 }
 
+// isOwnRecoveryShare checks if a recovery share has been encrypted for the
+// current member
+func (m *metadataStoreIndex) isOwnRecoveryShare(recipientPK []byte) bool {
+	if m.ownMemberDevice == nil {
+		return false
+	}
+
+	ownPK, err := m.ownMemberDevice.member.Raw()
+	if err != nil {
+		return false
+	}
+
+	return bytes.Equal(ownPK, recipientPK)
+}
+
+func (m *metadataStoreIndex) handleContactRecoveryShareAdded(event proto.Message) error {
+	evt, ok := event.(*bertytypes.ContactAddRecoveryShare)
+	if !ok {
+		return errcode.ErrInvalidInput
+	}
+
+	if !m.isOwnRecoveryShare(evt.RecipientPK) {
+		return nil
+	}
+
+	m.recoveryShares = append(m.recoveryShares, evt)
+
+	return nil
+}
+
+func (m *metadataStoreIndex) handleContactRecoveryShareReleased(event proto.Message) error {
+	evt, ok := event.(*bertytypes.ContactReleaseRecoveryShare)
+	if !ok {
+		return errcode.ErrInvalidInput
+	}
+
+	if !m.isOwnRecoveryShare(evt.RecipientPK) {
+		return nil
+	}
+
+	m.releasedRecoveryShares = append(m.releasedRecoveryShares, evt)
+
+	return nil
+}
+
 func (m *metadataStoreIndex) handleMultiMemberInitialMember(event proto.Message) error {
 	e, ok := event.(*bertytypes.MultiMemberInitialMember)
 	if !ok {
@@ -622,6 +1350,51 @@ func (m *metadataStoreIndex) getContact(pk crypto.PubKey) (*accountContact, erro
 	return contact, nil
 }
 
+// getContactVerification returns the digest verified for a contact, or
+// keyChanged if another key has been verified for the same contact
+func (m *metadataStoreIndex) getContactVerification(pk crypto.PubKey) ([]byte, bool, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	raw, err := pk.Raw()
+	if err != nil {
+		return nil, false, errcode.ErrSerialization.Wrap(err)
+	}
+
+	contact, ok := m.contacts[string(raw)]
+	if !ok {
+		return nil, false, errcode.ErrContactNotFound
+	}
+
+	if digest, ok := m.contactsVerified[string(raw)]; ok {
+		return digest, false, nil
+	}
+
+	if contact.contact == nil {
+		return nil, false, nil
+	}
+
+	if verified, ok := m.contactsVerifiedBySeed[string(contact.contact.PublicRendezvousSeed)]; ok && !bytes.Equal(verified.contactPK, raw) {
+		return nil, true, nil
+	}
+
+	return nil, false, nil
+}
+
+func (m *metadataStoreIndex) listRecoveryShares() []*bertytypes.ContactAddRecoveryShare {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	return append([]*bertytypes.ContactAddRecoveryShare(nil), m.recoveryShares...)
+}
+
+func (m *metadataStoreIndex) listReleasedRecoveryShares() []*bertytypes.ContactReleaseRecoveryShare {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	return append([]*bertytypes.ContactReleaseRecoveryShare(nil), m.releasedRecoveryShares...)
+}
+
 func (m *metadataStoreIndex) postHandlerSentAliases() error {
 	for _, evt := range m.eventsContactAddAliasKey {
 		pk, err := crypto.UnmarshalEd25519PublicKey(evt.DevicePK)
@@ -659,9 +1432,19 @@ func newMetadataIndex(ctx context.Context, eventEmitter events.EmitterInterface,
 			devices:                map[string]*memberDevice{},
 			admins:                 map[crypto.PubKey]struct{}{},
 			sentSecrets:            map[string]struct{}{},
+			sentDeviceSecrets:      map[string]struct{}{},
 			handledEvents:          map[string]struct{}{},
+			revokedDevices:         map[string]map[string]*deviceRevocation{},
+			memberJoins:            map[string]*memberJoin{},
+			deviceJoins:            map[string]int{},
+			memberDepartures:       map[string]map[string]int{},
+			deviceCapabilities:     map[string]*deviceCapabilities{},
+			versionUpgrades:        map[string]*groupVersionUpgrade{},
 			contacts:               map[string]*accountContact{},
+			contactsVerified:       map[string][]byte{},
+			contactsVerifiedBySeed: map[string]*verifiedContact{},
 			groups:                 map[string]*accountGroup{},
+			groupDevices:           map[string]map[string][]byte{},
 			contactRequestMetadata: map[string][]byte{},
 			g:                      g,
 			eventEmitter:           eventEmitter,
@@ -681,9 +1464,13 @@ func newMetadataIndex(ctx context.Context, eventEmitter events.EmitterInterface,
 			bertytypes.EventTypeAccountContactRequestOutgoingSent:      {m.handleContactRequestOutgoingSent},
 			bertytypes.EventTypeAccountContactRequestReferenceReset:    {m.handleContactRequestReferenceReset},
 			bertytypes.EventTypeAccountContactUnblocked:                {m.handleContactUnblocked},
+			bertytypes.EventTypeAccountContactVerified:                 {m.handleContactVerified},
+			bertytypes.EventTypeAccountGroupDeviceLinked:               {m.handleGroupDeviceLinked},
 			bertytypes.EventTypeAccountGroupJoined:                     {m.handleGroupJoined},
 			bertytypes.EventTypeAccountGroupLeft:                       {m.handleGroupLeft},
 			bertytypes.EventTypeContactAliasKeyAdded:                   {m.handleContactAliasKeyAdded},
+			bertytypes.EventTypeContactRecoveryShareAdded:              {m.handleContactRecoveryShareAdded},
+			bertytypes.EventTypeContactRecoveryShareReleased:           {m.handleContactRecoveryShareReleased},
 			bertytypes.EventTypeGroupDeviceSecretAdded:                 {m.handleGroupAddDeviceSecret},
 			bertytypes.EventTypeGroupMemberDeviceAdded:                 {m.handleGroupAddMemberDevice},
 			bertytypes.EventTypeMultiMemberGroupAdminRoleGranted:       {m.handleMultiMemberGrantAdminRole},
@@ -691,6 +1478,8 @@ func newMetadataIndex(ctx context.Context, eventEmitter events.EmitterInterface,
 		}
 
 		m.postIndexActions = []func() error{
+			m.postHandlerRevokedDevices,
+			m.postHandlerLeftMembers,
 			m.postHandlerSentAliases,
 		}
 
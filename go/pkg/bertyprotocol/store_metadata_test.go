@@ -9,8 +9,10 @@ import (
 	"testing"
 	"time"
 
+	"berty.tech/berty/v2/go/internal/cryptoutil"
 	"berty.tech/berty/v2/go/internal/testutil"
 	"berty.tech/berty/v2/go/pkg/bertytypes"
+	"berty.tech/berty/v2/go/pkg/errcode"
 	"github.com/libp2p/go-libp2p-core/crypto"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -483,6 +485,44 @@ func TestMetadataContactLifecycle(t *testing.T) {
 	require.Error(t, err)
 	require.Equal(t, len(meta[2].Index().(*metadataStoreIndex).contacts), 1)
 	require.Equal(t, meta[2].Index().(*metadataStoreIndex).contacts[string(contacts[0].PK)].state, bertytypes.ContactStateRemoved)
+
+	// Verify contact
+
+	code, err := cryptoutil.SafetyNumber(ownCG[1].MemberPubKey(), ownCG[2].MemberPubKey())
+	require.NoError(t, err)
+	require.Len(t, code, 60)
+
+	reversedCode, err := cryptoutil.SafetyNumber(ownCG[2].MemberPubKey(), ownCG[1].MemberPubKey())
+	require.NoError(t, err)
+	require.Equal(t, code, reversedCode)
+
+	otherCode, err := cryptoutil.SafetyNumber(ownCG[1].MemberPubKey(), ownCG[3].MemberPubKey())
+	require.NoError(t, err)
+	require.NotEqual(t, code, otherCode)
+
+	digest := cryptoutil.SafetyNumberDigest(code)
+
+	_, err = meta[1].ContactVerified(ctx, ownCG[2].MemberPubKey(), nil)
+	require.Error(t, err)
+
+	_, err = meta[1].ContactVerified(ctx, ownCG[3].MemberPubKey(), digest)
+	require.Error(t, err)
+
+	_, err = meta[2].ContactVerified(ctx, ownCG[0].MemberPubKey(), digest)
+	require.Error(t, err)
+
+	verified, keyChanged, err := meta[1].GetContactVerification(ownCG[2].MemberPubKey())
+	require.NoError(t, err)
+	require.Nil(t, verified)
+	require.False(t, keyChanged)
+
+	_, err = meta[1].ContactVerified(ctx, ownCG[2].MemberPubKey(), digest)
+	require.NoError(t, err)
+
+	verified, keyChanged, err = meta[1].GetContactVerification(ownCG[2].MemberPubKey())
+	require.NoError(t, err)
+	require.Equal(t, digest, verified)
+	require.False(t, keyChanged)
 }
 
 func TestMetadataAliasLifecycle(t *testing.T) {
@@ -652,4 +692,283 @@ func TestMetadataGroupsLifecycle(t *testing.T) {
 	require.Equal(t, groups[0].Secret, g2.Secret)
 	require.Equal(t, groups[0].SecretSig, g2.SecretSig)
 	require.Equal(t, groups[0].GroupType, g2.GroupType)
+
+	// Link the device key used in a multi-member group
+
+	g2PK, err := g2.GetPubKey()
+	require.NoError(t, err)
+
+	groupDeviceSK, _, err := crypto.GenerateEd25519Key(crand.Reader)
+	require.NoError(t, err)
+
+	_, err = ownCG.MetadataStore().GetGroupDevice(g2PK, ownCG.DevicePubKey())
+	require.Error(t, err)
+
+	_, err = ownCG.MetadataStore().GroupDeviceLink(ctx, g2PK, groupDeviceSK)
+	require.NoError(t, err)
+
+	groupDevicePK, err := ownCG.MetadataStore().GetGroupDevice(g2PK, ownCG.DevicePubKey())
+	require.NoError(t, err)
+	require.True(t, groupDevicePK.Equals(groupDeviceSK.GetPublic()))
+
+	_, err = ownCG.MetadataStore().GetGroupDevice(g3PK, ownCG.DevicePubKey())
+	require.Error(t, err)
+
+	_, err = ownCG.MetadataStore().GroupDeviceLink(ctx, g2PK, groupDeviceSK)
+	require.Error(t, err)
+}
+
+func TestMetadataMemberLeftLifecycle(t *testing.T) {
+	testutil.SkipSlow(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	peers, _, cleanup := createPeersWithGroup(ctx, t, "/tmp/member_left_test", 1, 1)
+	defer cleanup()
+
+	ms := peers[0].GC.MetadataStore()
+	memberPK := peers[0].GC.MemberPubKey()
+
+	_, err := ms.AddDeviceToGroup(ctx)
+	require.NoError(t, err)
+	require.Len(t, ms.ListMembers(), 1)
+	require.False(t, ms.HasMemberLeft(memberPK))
+
+	_, err = ms.MemberLeave(ctx)
+	require.NoError(t, err)
+	require.True(t, ms.HasMemberLeft(memberPK))
+	require.Len(t, ms.ListMembers(), 0)
+	require.Len(t, ms.ListDevices(), 0)
+
+	// joining the group again
+	_, err = ms.AddDeviceToGroup(ctx)
+	require.NoError(t, err)
+	require.False(t, ms.HasMemberLeft(memberPK))
+	require.Len(t, ms.ListMembers(), 1)
+}
+
+func TestMetadataMemberDepartureReplay(t *testing.T) {
+	m := &metadataStoreIndex{
+		memberJoins: map[string]*memberJoin{
+			"member": {time: 1, id: "join1"},
+		},
+		memberDepartures: map[string]map[string]int{
+			"member": {"join1": 2},
+		},
+	}
+
+	require.True(t, m.unsafeHasMemberLeft([]byte("member")))
+
+	// the member joined again, the previous departure is replayed afterwards
+	m.memberJoins["member"] = &memberJoin{time: 3, id: "join2"}
+	m.memberDepartures["member"]["join1"] = 4
+	require.False(t, m.unsafeHasMemberLeft([]byte("member")))
+
+	m.memberDepartures["member"]["join2"] = 5
+	require.True(t, m.unsafeHasMemberLeft([]byte("member")))
+}
+
+func TestMetadataGroupVersion(t *testing.T) {
+	testutil.SkipSlow(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	peers, _, cleanup := createPeersWithGroup(ctx, t, "/tmp/group_version_test", 1, 1)
+	defer cleanup()
+
+	ms := peers[0].GC.MetadataStore()
+
+	_, err := ms.AddDeviceToGroup(ctx)
+	require.NoError(t, err)
+
+	op, err := ms.AnnounceCapabilities(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, op)
+
+	// capabilities are only announced once
+	op, err = ms.AnnounceCapabilities(ctx)
+	require.NoError(t, err)
+	require.Nil(t, op)
+
+	require.Equal(t, uint32(CurrentGroupVersion), ms.GetGroupVersion())
+	require.Equal(t, uint32(CurrentGroupVersion), ms.GetUpgradableGroupVersion())
+
+	_, err = ms.UpgradeVersion(ctx, CurrentGroupVersion)
+	require.Equal(t, errcode.ErrInvalidInput.Code(), errcode.Code(err))
+
+	_, err = ms.UpgradeVersion(ctx, CurrentGroupVersion+1)
+	require.Equal(t, errcode.ErrGroupUnsupportedVersion.Code(), errcode.Code(err))
+
+	// upgrades to an unsupported version sent by other implementations are
+	// ignored
+	_, err = ms.attributeSignAndAddEvent(ctx, &bertytypes.GroupUpgradeVersion{
+		Version: CurrentGroupVersion + 1,
+	}, bertytypes.EventTypeGroupVersionUpgraded)
+	require.NoError(t, err)
+	require.Equal(t, uint32(CurrentGroupVersion), ms.GetGroupVersion())
+}
+
+func TestMetadataGroupVersionUpgrades(t *testing.T) {
+	m := &metadataStoreIndex{
+		devices: map[string]*memberDevice{
+			"dev1": {},
+			"dev2": {},
+		},
+		deviceJoins: map[string]int{
+			"dev1": 1,
+			"dev2": 5,
+		},
+		deviceCapabilities: map[string]*deviceCapabilities{
+			"dev1": {maxVersion: 3},
+		},
+		versionUpgrades: map[string]*groupVersionUpgrade{
+			"upgrade1": {version: 2, time: 3},
+			"upgrade2": {version: 3, time: 6},
+		},
+	}
+
+	// dev2 joined after the first upgrade but doesn't support the second one
+	m.applyGroupVersionUpgrades()
+	require.Equal(t, uint32(2), m.unsafeGetGroupVersion())
+	require.Len(t, m.versionUpgrades, 1)
+
+	m.deviceCapabilities["dev2"] = &deviceCapabilities{maxVersion: 3}
+
+	m.applyGroupVersionUpgrades()
+	require.Equal(t, uint32(3), m.unsafeGetGroupVersion())
+	require.Empty(t, m.versionUpgrades)
+}
+
+func TestMetadataDeviceRevocationOwnership(t *testing.T) {
+	_, ownerPK, err := crypto.GenerateEd25519Key(crand.Reader)
+	require.NoError(t, err)
+
+	_, otherPK, err := crypto.GenerateEd25519Key(crand.Reader)
+	require.NoError(t, err)
+
+	_, devPK, err := crypto.GenerateEd25519Key(crand.Reader)
+	require.NoError(t, err)
+
+	dev, err := devPK.Raw()
+	require.NoError(t, err)
+
+	m := &metadataStoreIndex{
+		devices: map[string]*memberDevice{
+			string(dev): {member: ownerPK, device: devPK},
+		},
+		members:        map[string][]*memberDevice{},
+		revokedDevices: map[string]map[string]*deviceRevocation{},
+	}
+
+	// an earlier revocation by a member not owning the device
+	m.revokedDevices[string(dev)] = map[string]*deviceRevocation{
+		"other": {member: otherPK, time: 1},
+	}
+
+	_, ok := m.deviceRevocationByOwner(dev)
+	require.False(t, ok)
+
+	m.revokedDevices[string(dev)]["owner"] = &deviceRevocation{member: ownerPK, time: 5}
+
+	r, ok := m.deviceRevocationByOwner(dev)
+	require.True(t, ok)
+	require.Equal(t, 5, r.time)
+
+	require.NoError(t, m.postHandlerRevokedDevices())
+	require.True(t, m.isDeviceRevoked(devPK))
+	require.Empty(t, m.devices)
+}
+
+func TestMetadataRotatedSecretsSentToDevices(t *testing.T) {
+	keys := make([][]byte, 4)
+	pks := make([]crypto.PubKey, 4)
+	for i := range pks {
+		_, pk, err := crypto.GenerateEd25519Key(crand.Reader)
+		require.NoError(t, err)
+
+		pks[i] = pk
+		keys[i], err = pk.Raw()
+		require.NoError(t, err)
+	}
+
+	ownDevice, member, dev1, dev2 := keys[0], keys[1], keys[2], keys[3]
+
+	m := &metadataStoreIndex{
+		ownMemberDevice:   &memberDevice{device: pks[0]},
+		sentSecrets:       map[string]struct{}{},
+		sentDeviceSecrets: map[string]struct{}{},
+	}
+
+	// secrets sent to the member can be opened by all its devices
+	require.NoError(t, m.handleGroupAddDeviceSecret(&bertytypes.GroupAddDeviceSecret{DevicePK: ownDevice, DestMemberPK: member}))
+
+	needed, err := m.needsRotatedSecret(pks[3])
+	require.NoError(t, err)
+	require.False(t, needed)
+
+	// once rotated, the secret is only sent to the devices known at the time
+	require.NoError(t, m.handleGroupAddDeviceSecret(&bertytypes.GroupAddDeviceSecret{DevicePK: ownDevice, DestMemberPK: member, DestDevicePK: dev1, Nonce: []byte("nonce")}))
+
+	needed, err = m.needsRotatedSecret(pks[2])
+	require.NoError(t, err)
+	require.False(t, needed)
+
+	needed, err = m.needsRotatedSecret(pks[3])
+	require.NoError(t, err)
+	require.True(t, needed)
+
+	require.NoError(t, m.handleGroupAddDeviceSecret(&bertytypes.GroupAddDeviceSecret{DevicePK: ownDevice, DestMemberPK: member, DestDevicePK: dev2, Nonce: []byte("nonce")}))
+
+	needed, err = m.needsRotatedSecret(pks[3])
+	require.NoError(t, err)
+	require.False(t, needed)
+}
+
+func TestOpenGroupEnvelopeUnknownEventType(t *testing.T) {
+	g, _, err := NewGroupMultiMember()
+	require.NoError(t, err)
+
+	unknownType := bertytypes.EventType(9999)
+
+	// unsigned event
+	env, err := sealGroupEnvelope(g, unknownType, &bertytypes.AppMetadata{Message: []byte("test")}, nil)
+	require.NoError(t, err)
+
+	meta, event, err := openGroupEnvelope(g, env)
+	require.Equal(t, errcode.ErrGroupUnknownEventType.Code(), errcode.Code(err))
+	require.Nil(t, event)
+	require.Equal(t, unknownType, meta.EventType)
+	require.NotEmpty(t, meta.Payload)
+
+	// event signed by a device
+	devSK, devPK, err := crypto.GenerateEd25519Key(crand.Reader)
+	require.NoError(t, err)
+
+	devPKBytes, err := devPK.Raw()
+	require.NoError(t, err)
+
+	payload := &bertytypes.AppMetadata{DevicePK: devPKBytes, Message: []byte("test")}
+	payloadBytes, err := payload.Marshal()
+	require.NoError(t, err)
+
+	sig, err := devSK.Sign(payloadBytes)
+	require.NoError(t, err)
+
+	env, err = sealGroupEnvelope(g, unknownType, payload, sig)
+	require.NoError(t, err)
+
+	_, event, err = openGroupEnvelope(g, env)
+	require.Equal(t, errcode.ErrGroupUnknownEventType.Code(), errcode.Code(err))
+	require.NotNil(t, event)
+	require.Equal(t, devPKBytes, event.(*bertytypes.DeviceSignedEvent).DevicePK)
+
+	// invalid signature
+	env, err = sealGroupEnvelope(g, unknownType, payload, []byte("invalid"))
+	require.NoError(t, err)
+
+	_, event, err = openGroupEnvelope(g, env)
+	require.Equal(t, errcode.ErrGroupUnknownEventType.Code(), errcode.Code(err))
+	require.Nil(t, event)
 }
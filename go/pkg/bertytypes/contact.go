@@ -5,6 +5,8 @@ import (
 
 	"berty.tech/berty/v2/go/pkg/errcode"
 	"github.com/libp2p/go-libp2p-core/crypto"
+	"github.com/libp2p/go-libp2p-core/peer"
+	ma "github.com/multiformats/go-multiaddr"
 )
 
 const RendezvousSeedLength = 32
@@ -52,9 +54,37 @@ func (m *ShareableContact) CheckFormat(options ...ShareableContactOptions) error
 		}
 	}
 
+	if _, err := m.GetAddrInfos(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// GetAddrInfos parses the optional direct addresses of the contact, grouped by peer
+func (m *ShareableContact) GetAddrInfos() ([]peer.AddrInfo, error) {
+	if len(m.Addrs) == 0 {
+		return nil, nil
+	}
+
+	maddrs := make([]ma.Multiaddr, len(m.Addrs))
+	for i, addr := range m.Addrs {
+		maddr, err := ma.NewMultiaddr(addr)
+		if err != nil {
+			return nil, errcode.ErrInvalidInput.Wrap(err)
+		}
+
+		maddrs[i] = maddr
+	}
+
+	infos, err := peer.AddrInfosFromP2pAddrs(maddrs...)
+	if err != nil {
+		return nil, errcode.ErrInvalidInput.Wrap(err)
+	}
+
+	return infos, nil
+}
+
 func (m *ShareableContact) IsSamePK(otherPK crypto.PubKey) bool {
 	pk, err := m.GetPubKey()
 	if err != nil {
@@ -48,6 +48,14 @@ func (m *AccountContactUnblocked) SetDevicePK(pk []byte) {
 	m.DevicePK = pk
 }
 
+func (m *AccountContactVerified) SetDevicePK(pk []byte) {
+	m.DevicePK = pk
+}
+
+func (m *AccountGroupDeviceLinked) SetDevicePK(pk []byte) {
+	m.DevicePK = pk
+}
+
 func (m *AccountContactRequestSent) SetContactPK(pk []byte) {
 	m.ContactPK = pk
 }
@@ -68,6 +76,10 @@ func (m *AccountContactUnblocked) SetContactPK(pk []byte) {
 	m.ContactPK = pk
 }
 
+func (m *AccountContactVerified) SetContactPK(pk []byte) {
+	m.ContactPK = pk
+}
+
 func (m *AccountGroupLeft) SetGroupPK(pk []byte) {
 	m.GroupPK = pk
 }
@@ -76,6 +88,14 @@ func (m *ContactAddAliasKey) SetDevicePK(pk []byte) {
 	m.DevicePK = pk
 }
 
+func (m *ContactAddRecoveryShare) SetDevicePK(pk []byte) {
+	m.DevicePK = pk
+}
+
+func (m *ContactReleaseRecoveryShare) SetDevicePK(pk []byte) {
+	m.DevicePK = pk
+}
+
 func (m *MultiMemberGroupAddAliasResolver) SetDevicePK(pk []byte) {
 	m.DevicePK = pk
 }
@@ -87,3 +107,15 @@ func (m *MultiMemberGrantAdminRole) SetDevicePK(pk []byte) {
 func (m *AppMetadata) SetDevicePK(pk []byte) {
 	m.DevicePK = pk
 }
+
+func (m *GroupSetEphemeralSettings) SetDevicePK(pk []byte) {
+	m.DevicePK = pk
+}
+
+func (m *GroupAnnounceCapabilities) SetDevicePK(pk []byte) {
+	m.DevicePK = pk
+}
+
+func (m *GroupUpgradeVersion) SetDevicePK(pk []byte) {
+	m.DevicePK = pk
+}
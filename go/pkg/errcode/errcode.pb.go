@@ -58,6 +58,9 @@ const (
 	ErrHandshakeRequesterAuthenticate          ErrCode = 1106
 	ErrHandshakeResponderAccept                ErrCode = 1107
 	ErrHandshakeRequesterAcknowledge           ErrCode = 1108
+	ErrHandshakeProofOfWork                    ErrCode = 1109
+	ErrHandshakeNegotiation                    ErrCode = 1110
+	ErrHandshakeTimeout                        ErrCode = 1111
 	ErrGroupMemberLogEventOpen                 ErrCode = 1200
 	ErrGroupMemberLogEventSignature            ErrCode = 1201
 	ErrGroupMemberUnknownGroupID               ErrCode = 1202
@@ -65,10 +68,20 @@ const (
 	ErrGroupSecretAlreadySentToMember          ErrCode = 1204
 	ErrGroupInvalidType                        ErrCode = 1205
 	ErrGroupMissing                            ErrCode = 1206
+	ErrGroupUnknownEventType                   ErrCode = 1207
+	ErrGroupUnsupportedVersion                 ErrCode = 1208
 	ErrMessageKeyPersistencePut                ErrCode = 1300
 	ErrMessageKeyPersistenceGet                ErrCode = 1301
+	ErrMessageExpired                          ErrCode = 1302
 	ErrBridgeInterrupted                       ErrCode = 1400
 	ErrBridgeNotRunning                        ErrCode = 1401
+	ErrContactRequestRateLimited               ErrCode = 1500
+	ErrContactRequestPendingLimit              ErrCode = 1501
+	ErrContactStreamRefused                    ErrCode = 1502
+	ErrContactNotFound                         ErrCode = 1503
+	ErrKeystoreLocked                          ErrCode = 1600
+	ErrKeystoreInvalidPassphrase               ErrCode = 1601
+	ErrKeystoreNotEncrypted                    ErrCode = 1602
 	ErrMessengerInvalidDeepLink                ErrCode = 2001
 	ErrCLINoTermcaps                           ErrCode = 3001
 )
@@ -107,6 +120,9 @@ var ErrCode_name = map[int32]string{
 	1106: "ErrHandshakeRequesterAuthenticate",
 	1107: "ErrHandshakeResponderAccept",
 	1108: "ErrHandshakeRequesterAcknowledge",
+	1109: "ErrHandshakeProofOfWork",
+	1110: "ErrHandshakeNegotiation",
+	1111: "ErrHandshakeTimeout",
 	1200: "ErrGroupMemberLogEventOpen",
 	1201: "ErrGroupMemberLogEventSignature",
 	1202: "ErrGroupMemberUnknownGroupID",
@@ -114,10 +130,20 @@ var ErrCode_name = map[int32]string{
 	1204: "ErrGroupSecretAlreadySentToMember",
 	1205: "ErrGroupInvalidType",
 	1206: "ErrGroupMissing",
+	1207: "ErrGroupUnknownEventType",
+	1208: "ErrGroupUnsupportedVersion",
 	1300: "ErrMessageKeyPersistencePut",
 	1301: "ErrMessageKeyPersistenceGet",
+	1302: "ErrMessageExpired",
 	1400: "ErrBridgeInterrupted",
 	1401: "ErrBridgeNotRunning",
+	1500: "ErrContactRequestRateLimited",
+	1501: "ErrContactRequestPendingLimit",
+	1502: "ErrContactStreamRefused",
+	1503: "ErrContactNotFound",
+	1600: "ErrKeystoreLocked",
+	1601: "ErrKeystoreInvalidPassphrase",
+	1602: "ErrKeystoreNotEncrypted",
 	2001: "ErrMessengerInvalidDeepLink",
 	3001: "ErrCLINoTermcaps",
 }
@@ -156,6 +182,9 @@ var ErrCode_value = map[string]int32{
 	"ErrHandshakeRequesterAuthenticate":          1106,
 	"ErrHandshakeResponderAccept":                1107,
 	"ErrHandshakeRequesterAcknowledge":           1108,
+	"ErrHandshakeProofOfWork":                    1109,
+	"ErrHandshakeNegotiation":                    1110,
+	"ErrHandshakeTimeout":                        1111,
 	"ErrGroupMemberLogEventOpen":                 1200,
 	"ErrGroupMemberLogEventSignature":            1201,
 	"ErrGroupMemberUnknownGroupID":               1202,
@@ -163,10 +192,20 @@ var ErrCode_value = map[string]int32{
 	"ErrGroupSecretAlreadySentToMember":          1204,
 	"ErrGroupInvalidType":                        1205,
 	"ErrGroupMissing":                            1206,
+	"ErrGroupUnknownEventType":                   1207,
+	"ErrGroupUnsupportedVersion":                 1208,
 	"ErrMessageKeyPersistencePut":                1300,
 	"ErrMessageKeyPersistenceGet":                1301,
+	"ErrMessageExpired":                          1302,
 	"ErrBridgeInterrupted":                       1400,
 	"ErrBridgeNotRunning":                        1401,
+	"ErrContactRequestRateLimited":               1500,
+	"ErrContactRequestPendingLimit":              1501,
+	"ErrContactStreamRefused":                    1502,
+	"ErrContactNotFound":                         1503,
+	"ErrKeystoreLocked":                          1600,
+	"ErrKeystoreInvalidPassphrase":               1601,
+	"ErrKeystoreNotEncrypted":                    1602,
 	"ErrMessengerInvalidDeepLink":                2001,
 	"ErrCLINoTermcaps":                           3001,
 }
@@ -186,55 +225,66 @@ func init() {
 func init() { proto.RegisterFile("errcode.proto", fileDescriptor_4240057316120df7) }
 
 var fileDescriptor_4240057316120df7 = []byte{
-	// 795 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x54, 0xcb, 0x72, 0xdc, 0x44,
-	0x14, 0xcd, 0x54, 0x81, 0x67, 0xd4, 0x94, 0x49, 0xe7, 0xda, 0x38, 0x2f, 0x62, 0x27, 0x21, 0x81,
-	0x22, 0x55, 0x78, 0x16, 0x7c, 0x81, 0xed, 0x51, 0x39, 0x53, 0x7e, 0x8c, 0x6b, 0xc6, 0x81, 0x2a,
-	0x76, 0x1a, 0xe9, 0x58, 0xd3, 0x58, 0xea, 0x16, 0x57, 0x2d, 0x87, 0xe1, 0x0f, 0xd8, 0xc3, 0x86,
-	0xaf, 0xe0, 0x5d, 0x95, 0x3f, 0xe0, 0x91, 0x27, 0xf0, 0x03, 0xec, 0x78, 0x7d, 0x40, 0xd8, 0x51,
-	0x52, 0xcb, 0xf6, 0x0c, 0x76, 0x39, 0x2b, 0x49, 0xe7, 0x9e, 0x3e, 0xf7, 0xe8, 0x76, 0xf7, 0x11,
-	0xb3, 0x60, 0x0e, 0x4d, 0x84, 0xe5, 0x8c, 0x8d, 0x35, 0x34, 0x3b, 0x04, 0xdb, 0xf1, 0x72, 0x0d,
-	0x5e, 0x79, 0x27, 0x56, 0x76, 0x54, 0x0c, 0x97, 0x43, 0x93, 0xb6, 0x63, 0x13, 0x9b, 0x76, 0xc5,
-	0x1a, 0x16, 0x7b, 0xd5, 0x57, 0xf5, 0x51, 0xbd, 0xb9, 0xd5, 0x77, 0x1e, 0x08, 0xd1, 0xf4, 0x99,
-	0xd7, 0x4c, 0x04, 0x9a, 0x15, 0xde, 0x3d, 0x1d, 0x61, 0x4f, 0x69, 0x44, 0xf2, 0x1c, 0x79, 0xe2,
-	0xa5, 0xdd, 0x5e, 0xa7, 0x27, 0xbf, 0x78, 0x99, 0x16, 0xc4, 0x05, 0x9f, 0x79, 0xdb, 0xd8, 0x6e,
-	0x9a, 0x25, 0x48, 0xa1, 0x2d, 0x22, 0xf9, 0xe9, 0x0c, 0x49, 0xf1, 0x8a, 0xcf, 0xdc, 0xd5, 0x16,
-	0xac, 0x83, 0x44, 0x3e, 0x9f, 0xa1, 0x39, 0x71, 0xbe, 0x42, 0x0e, 0x82, 0x44, 0x45, 0x5d, 0x9d,
-	0x15, 0x56, 0x46, 0x35, 0xb8, 0xa5, 0xf2, 0x5c, 0xe9, 0xd8, 0x81, 0xa0, 0x79, 0x21, 0x7d, 0xe6,
-	0x01, 0x58, 0x05, 0x89, 0xfa, 0x24, 0xb0, 0xca, 0x68, 0xb9, 0x47, 0x0b, 0x82, 0x7c, 0xe6, 0x0e,
-	0xf2, 0x29, 0x3c, 0xa6, 0x0b, 0x62, 0xb6, 0x64, 0x5b, 0x46, 0x90, 0xf6, 0x11, 0x44, 0x72, 0x44,
-	0x24, 0x5e, 0x3d, 0x82, 0xde, 0x67, 0x65, 0x21, 0x55, 0x2d, 0x5a, 0x77, 0xda, 0x0a, 0xb2, 0x0d,
-	0x8c, 0xe5, 0x87, 0xb4, 0x28, 0x2e, 0x97, 0xff, 0xc8, 0xe3, 0xcc, 0x9a, 0x7e, 0xa0, 0x23, 0x93,
-	0xae, 0x43, 0x83, 0x9d, 0xf6, 0x0f, 0x0d, 0xba, 0x2a, 0x16, 0x8e, 0xea, 0x1b, 0x18, 0x4f, 0x14,
-	0x7f, 0x6c, 0xd0, 0x35, 0x71, 0xe9, 0xa8, 0xb8, 0x6d, 0x74, 0x88, 0x89, 0xf2, 0x4f, 0x0d, 0xba,
-	0x58, 0x19, 0x76, 0xe5, 0x81, 0x8a, 0x75, 0x60, 0x0b, 0x86, 0xfc, 0xb9, 0x41, 0x6f, 0x88, 0xc5,
-	0x93, 0x85, 0xf7, 0xc0, 0x6a, 0x4f, 0x85, 0x6e, 0xf5, 0xc3, 0x06, 0xbd, 0x56, 0xf9, 0x75, 0xa4,
-	0x0e, 0xc2, 0xf2, 0x29, 0x1f, 0x4d, 0xc3, 0xbe, 0x76, 0xf0, 0xe3, 0x13, 0x3e, 0xd7, 0x8c, 0x3e,
-	0x00, 0xe7, 0xa5, 0xd4, 0x93, 0x06, 0xcd, 0x55, 0xe3, 0xe8, 0xf1, 0x50, 0xd9, 0xce, 0x6a, 0x57,
-	0x2b, 0x2b, 0xff, 0x68, 0x4e, 0x83, 0xbd, 0x0c, 0x5a, 0xfe, 0xd9, 0xac, 0xd5, 0x6b, 0x70, 0x25,
-	0xcb, 0xa0, 0x23, 0xf9, 0x57, 0xb3, 0x9e, 0x52, 0x0d, 0xff, 0x7f, 0x07, 0xfe, 0x6e, 0xd2, 0x25,
-	0x31, 0x77, 0x5c, 0x1f, 0x58, 0xc3, 0x58, 0x0b, 0x72, 0x2b, 0xff, 0x69, 0xd2, 0x5b, 0xe2, 0xa6,
-	0xcf, 0x7c, 0x37, 0xd0, 0x51, 0x3e, 0x0a, 0xf6, 0xd1, 0xbb, 0xaf, 0xfd, 0x6c, 0x84, 0x14, 0x1c,
-	0x24, 0x6e, 0x9c, 0x83, 0xb2, 0xc5, 0xc3, 0x16, 0xdd, 0x16, 0xd7, 0x27, 0x89, 0x3b, 0x00, 0x4f,
-	0x32, 0xfb, 0x08, 0x0f, 0xe4, 0xa3, 0x16, 0xb5, 0xc5, 0x9d, 0x49, 0x5a, 0x1f, 0x1f, 0x15, 0xc8,
-	0x2d, 0x78, 0xa5, 0xb0, 0x23, 0x68, 0x5b, 0xce, 0x0f, 0xab, 0xe6, 0x63, 0xa7, 0x2d, 0x1f, 0xb7,
-	0xe8, 0x6d, 0x71, 0x6b, 0x7a, 0x41, 0x9e, 0x19, 0x1d, 0x81, 0x57, 0xc2, 0x10, 0x99, 0x3d, 0xa6,
-	0x3e, 0x69, 0xd1, 0x92, 0xb8, 0x72, 0xaa, 0xf6, 0x5d, 0x24, 0x89, 0x91, 0x4f, 0x4f, 0x21, 0xd4,
-	0x5a, 0x8e, 0xf0, 0xac, 0x45, 0x6f, 0x8a, 0x1b, 0x2f, 0x74, 0x27, 0x7f, 0x69, 0xd1, 0x75, 0x71,
-	0xf5, 0x0c, 0x53, 0xf2, 0xd7, 0x13, 0xe3, 0x38, 0x56, 0x0a, 0xf7, 0xb5, 0xb9, 0x9f, 0x20, 0x8a,
-	0x21, 0x7f, 0x3b, 0x74, 0xb4, 0xce, 0xa6, 0xc8, 0xb6, 0x90, 0x0e, 0xc1, 0x9b, 0x26, 0xf6, 0x0f,
-	0xa0, 0x6d, 0xb5, 0xa1, 0x5f, 0x7a, 0x74, 0x4b, 0x2c, 0x9d, 0x4e, 0x38, 0x3e, 0x90, 0x5f, 0x79,
-	0x74, 0x43, 0xbc, 0x3e, 0xcd, 0xba, 0xa7, 0xcb, 0x36, 0xba, 0x42, 0xba, 0x1d, 0xf9, 0xb5, 0x47,
-	0x37, 0xc5, 0xb5, 0x43, 0xca, 0x00, 0x21, 0xc3, 0xf6, 0xec, 0x08, 0xe5, 0x6d, 0xb4, 0x6e, 0x85,
-	0xfc, 0xc6, 0xab, 0x7f, 0x7f, 0x82, 0xb3, 0x92, 0x30, 0x82, 0x68, 0x3c, 0x80, 0xb6, 0xbb, 0xa6,
-	0xe6, 0x7d, 0xeb, 0xd5, 0xc7, 0xc5, 0x89, 0xbb, 0x38, 0xd8, 0x1d, 0x67, 0x90, 0xdf, 0x79, 0x34,
-	0x5f, 0xc5, 0x81, 0x33, 0xe2, 0x6e, 0xaa, 0xfc, 0xde, 0xab, 0xc7, 0xb5, 0x85, 0x3c, 0x0f, 0x62,
-	0x6c, 0x60, 0xbc, 0x53, 0x1e, 0xed, 0xdc, 0x42, 0x87, 0xd8, 0x29, 0xac, 0xfc, 0x4c, 0x9c, 0xc5,
-	0x58, 0x87, 0x95, 0x9f, 0x0b, 0xba, 0x2c, 0xe6, 0x7d, 0xe6, 0x55, 0x56, 0x51, 0x8c, 0x2a, 0x95,
-	0xb8, 0xc8, 0xca, 0xa8, 0x7a, 0x2e, 0x6a, 0x3b, 0xae, 0xb4, 0x6d, 0x6c, 0xbf, 0xd0, 0xba, 0x6c,
-	0xfc, 0xef, 0xa4, 0x2c, 0x74, 0x8c, 0xc3, 0xec, 0xea, 0x00, 0xd9, 0xa6, 0xd2, 0xfb, 0xf2, 0xd9,
-	0xf9, 0xc3, 0xeb, 0xb8, 0xd9, 0xdd, 0x36, 0xbb, 0xe0, 0x34, 0x0c, 0xb2, 0x5c, 0x3e, 0xb8, 0xb8,
-	0x7a, 0xfb, 0xe9, 0xef, 0x8b, 0xe7, 0x3e, 0x58, 0x72, 0xf9, 0x6b, 0x11, 0x8e, 0xda, 0xd5, 0x6b,
-	0xbb, 0x0c, 0xdd, 0xfd, 0xb8, 0x5d, 0x27, 0xf2, 0x70, 0xa6, 0x4a, 0xda, 0x77, 0xff, 0x0b, 0x00,
-	0x00, 0xff, 0xff, 0x0e, 0xbe, 0x73, 0x56, 0xb8, 0x05, 0x00, 0x00,
+	// 972 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x84, 0x55, 0x49, 0x73, 0x1b, 0x45,
+	0x14, 0x8e, 0xaa, 0xc0, 0xd2, 0xb4, 0x6d, 0xfc, 0xd2, 0x36, 0x76, 0x56, 0x3b, 0x09, 0x09, 0x14,
+	0xa9, 0xc2, 0x3a, 0xf0, 0x0b, 0x6c, 0x6b, 0x70, 0x54, 0xb6, 0x25, 0x97, 0x64, 0x27, 0x55, 0xdc,
+	0x46, 0xd3, 0x4f, 0xa3, 0x46, 0x52, 0x77, 0xf3, 0xa6, 0xc7, 0x89, 0xf8, 0x07, 0xdc, 0x81, 0x03,
+	0xbf, 0x82, 0x7d, 0xb9, 0x50, 0xc0, 0x89, 0x25, 0x2b, 0xeb, 0x85, 0xa5, 0xb8, 0xb1, 0xfd, 0x80,
+	0x70, 0xa3, 0x66, 0xa6, 0x25, 0x4b, 0xd8, 0x15, 0x4e, 0xd2, 0x7c, 0xdf, 0xf7, 0x5e, 0xbf, 0xf7,
+	0x75, 0xf7, 0x6b, 0x36, 0x8b, 0x44, 0xa1, 0x16, 0xb8, 0x6a, 0x48, 0x5b, 0xcd, 0x67, 0x5b, 0x48,
+	0x76, 0xb0, 0xea, 0xc0, 0x33, 0xcf, 0x45, 0xd2, 0x76, 0x92, 0xd6, 0x6a, 0xa8, 0xfb, 0xe5, 0x48,
+	0x47, 0xba, 0x9c, 0xa9, 0x5a, 0x49, 0x3b, 0xfb, 0xca, 0x3e, 0xb2, 0x7f, 0x79, 0xf4, 0xd5, 0x4f,
+	0x66, 0x59, 0xd1, 0x27, 0xda, 0xd0, 0x02, 0xf9, 0x2c, 0xf3, 0xf6, 0x95, 0xc0, 0xb6, 0x54, 0x28,
+	0xe0, 0x04, 0xf7, 0xd8, 0x63, 0x7b, 0xf5, 0x4a, 0x1d, 0xde, 0x7c, 0x9c, 0x2f, 0xb2, 0x93, 0x3e,
+	0x51, 0x4d, 0xdb, 0x6a, 0xdf, 0xf4, 0xb0, 0x8f, 0xca, 0xa2, 0x80, 0x57, 0xa7, 0x38, 0xb0, 0x69,
+	0x9f, 0xa8, 0xaa, 0x2c, 0x92, 0x0a, 0x7a, 0xf0, 0x70, 0x8a, 0xcf, 0xb3, 0xb9, 0x0c, 0x39, 0x08,
+	0x7a, 0x52, 0x54, 0x95, 0x49, 0x2c, 0x08, 0x07, 0xee, 0xc8, 0x38, 0x96, 0x2a, 0xca, 0x41, 0xe4,
+	0x0b, 0x0c, 0x7c, 0xa2, 0x26, 0x92, 0x0c, 0x7a, 0xf2, 0x95, 0xc0, 0x4a, 0xad, 0xa0, 0xcd, 0x17,
+	0x19, 0xf7, 0x89, 0x2a, 0x18, 0x4f, 0xe0, 0x11, 0x3f, 0xc9, 0x66, 0x53, 0xb5, 0x25, 0x0c, 0xfa,
+	0x0d, 0x0c, 0x04, 0x74, 0x38, 0x67, 0x4f, 0x8c, 0xa0, 0x1b, 0x24, 0x2d, 0x82, 0x74, 0x49, 0xdd,
+	0x4a, 0x3b, 0x81, 0xd9, 0xc2, 0x01, 0xbc, 0xc4, 0x97, 0xd9, 0xe9, 0xb4, 0x47, 0x1a, 0x18, 0xab,
+	0x1b, 0x81, 0x12, 0xba, 0xbf, 0x89, 0x0a, 0x29, 0xcf, 0xfd, 0x45, 0x81, 0x9f, 0x65, 0x8b, 0x23,
+	0x7e, 0x0b, 0x07, 0x63, 0xe4, 0x97, 0x05, 0x7e, 0x9e, 0x9d, 0x1a, 0x91, 0x35, 0xad, 0x42, 0x1c,
+	0xa3, 0xbf, 0x2a, 0xf0, 0x25, 0xc6, 0x47, 0x74, 0x53, 0x46, 0x2a, 0xb0, 0x09, 0x21, 0x7c, 0x5d,
+	0xe0, 0x4f, 0xb1, 0xe5, 0xa3, 0xc4, 0x75, 0x24, 0xd9, 0x96, 0x61, 0x1e, 0x7d, 0xbb, 0xc0, 0x9f,
+	0x64, 0x30, 0x12, 0x55, 0x30, 0x4c, 0x7f, 0xe1, 0xce, 0x24, 0xec, 0xab, 0x1c, 0xbe, 0x7b, 0xa4,
+	0xce, 0x0d, 0xad, 0x0e, 0x90, 0xe2, 0x34, 0xd5, 0xbd, 0x02, 0x9f, 0xcf, 0xec, 0xa8, 0x53, 0x4b,
+	0xda, 0xca, 0x7a, 0x55, 0x49, 0x0b, 0xbf, 0x17, 0x27, 0xc1, 0xba, 0x41, 0x05, 0x7f, 0x14, 0x5d,
+	0x76, 0x07, 0xae, 0x19, 0x83, 0x4a, 0xc0, 0x9f, 0x45, 0xe7, 0x92, 0x83, 0xff, 0xbb, 0x03, 0x7f,
+	0x15, 0xf9, 0x29, 0x36, 0x7f, 0xc8, 0x37, 0xad, 0x26, 0xdc, 0x08, 0x62, 0x0b, 0x7f, 0x17, 0xf9,
+	0x33, 0xec, 0x92, 0x4f, 0x74, 0x2d, 0x50, 0x22, 0xee, 0x04, 0x5d, 0xac, 0xdf, 0x54, 0xbe, 0xe9,
+	0x60, 0x1f, 0x29, 0xe8, 0xe5, 0x76, 0x36, 0xd3, 0x25, 0x6e, 0x97, 0xf8, 0x15, 0x76, 0x61, 0x5c,
+	0xb8, 0x8b, 0x48, 0xe3, 0xca, 0x06, 0x86, 0x07, 0x70, 0xa7, 0xc4, 0xcb, 0xec, 0xea, 0xb8, 0xac,
+	0x81, 0x2f, 0x27, 0x18, 0x5b, 0xa4, 0xb5, 0xc4, 0x76, 0x50, 0xd9, 0xd4, 0x3f, 0x5c, 0xd7, 0xb7,
+	0xf2, 0xdc, 0x70, 0xb7, 0xc4, 0x9f, 0x65, 0x97, 0x27, 0x03, 0x62, 0xa3, 0x95, 0x40, 0x5a, 0x0b,
+	0x43, 0x34, 0xf6, 0x50, 0x7a, 0xaf, 0xc4, 0x57, 0xd8, 0x99, 0x63, 0x73, 0x5f, 0xc3, 0x5e, 0x4f,
+	0xc3, 0xfd, 0x63, 0x04, 0x2e, 0x57, 0x2e, 0x78, 0x50, 0xe2, 0x4f, 0xb3, 0x8b, 0xff, 0x5b, 0x1d,
+	0x7c, 0x53, 0xe2, 0x17, 0xd8, 0xd9, 0x47, 0x14, 0x05, 0xdf, 0x1e, 0xb1, 0xe3, 0x30, 0x53, 0xd8,
+	0x55, 0xfa, 0x66, 0x0f, 0x45, 0x84, 0xf0, 0x5d, 0x89, 0x9f, 0x63, 0x4b, 0x13, 0xae, 0x91, 0xd6,
+	0xed, 0x7a, 0xfb, 0x86, 0xa6, 0x2e, 0x7c, 0x7f, 0x84, 0xad, 0x61, 0xa4, 0xad, 0xcc, 0x37, 0xed,
+	0x87, 0x92, 0xdb, 0xb4, 0x11, 0xbb, 0x27, 0xfb, 0xa8, 0x13, 0x0b, 0x3f, 0x0e, 0xfb, 0xdc, 0x24,
+	0x9d, 0x98, 0x1d, 0xec, 0xb7, 0x90, 0xb6, 0x75, 0xe4, 0x1f, 0xa0, 0xb2, 0xd9, 0x31, 0x79, 0xcb,
+	0xe3, 0x97, 0xd9, 0xca, 0xf1, 0x82, 0xc3, 0x63, 0xfe, 0xb6, 0xc7, 0x2f, 0xb2, 0x73, 0x93, 0xaa,
+	0x7d, 0x95, 0x16, 0xaf, 0x32, 0xa4, 0x5a, 0x81, 0x77, 0x3c, 0x7e, 0x89, 0x9d, 0x1f, 0x4a, 0x9a,
+	0x18, 0x12, 0xda, 0xba, 0xed, 0x60, 0x7a, 0xc7, 0x6d, 0x1e, 0x01, 0xef, 0x7a, 0xce, 0xd4, 0x31,
+	0xcd, 0x5a, 0x8f, 0x30, 0x10, 0x83, 0x26, 0x2a, 0xbb, 0xa7, 0x9d, 0xee, 0x3d, 0xcf, 0xf5, 0x93,
+	0x27, 0xcf, 0x87, 0xcc, 0xde, 0xc0, 0x20, 0xbc, 0xef, 0xf1, 0x05, 0x36, 0x37, 0x64, 0xdc, 0xfd,
+	0x87, 0x0f, 0x3c, 0x77, 0x7b, 0x33, 0xd4, 0x15, 0x96, 0xb5, 0x90, 0x05, 0x7d, 0xe8, 0x8d, 0x9b,
+	0xb0, 0xaf, 0xe2, 0xc4, 0x18, 0x4d, 0x16, 0xc5, 0x75, 0x77, 0xab, 0x3e, 0xf2, 0xdc, 0x26, 0xee,
+	0x60, 0x1c, 0x07, 0x11, 0x6e, 0xe1, 0x60, 0x37, 0xa5, 0x62, 0x8b, 0x2a, 0xc4, 0xdd, 0xc4, 0xc2,
+	0x6b, 0xec, 0x51, 0x8a, 0x4d, 0xb4, 0xf0, 0x3a, 0x73, 0xd3, 0xd3, 0x29, 0xfc, 0x5b, 0x46, 0x12,
+	0x0a, 0x78, 0x83, 0xf1, 0xd3, 0x6c, 0xc1, 0x27, 0x5a, 0x27, 0x29, 0x22, 0xcc, 0x66, 0x28, 0x25,
+	0x26, 0x1d, 0xac, 0x0f, 0x99, 0x6b, 0x33, 0xa7, 0x6a, 0xda, 0x36, 0x12, 0xa5, 0xd2, 0x86, 0xfe,
+	0x61, 0xce, 0xef, 0x0d, 0xad, 0x6c, 0x10, 0x5a, 0x77, 0x62, 0x1a, 0x81, 0xc5, 0x6d, 0xd9, 0x97,
+	0x69, 0xf0, 0x4f, 0xd3, 0xce, 0xef, 0x49, 0xc9, 0x2e, 0x2a, 0x21, 0x55, 0x94, 0xa9, 0xe0, 0xe7,
+	0x69, 0x77, 0x6a, 0x9c, 0x66, 0x38, 0x56, 0xdb, 0x49, 0x8c, 0x02, 0x7e, 0x99, 0x1e, 0x0e, 0xb5,
+	0x9c, 0xad, 0x69, 0xfb, 0x82, 0x4e, 0x94, 0x80, 0x5f, 0xa7, 0x5d, 0x2b, 0x5b, 0x38, 0x88, 0xad,
+	0x26, 0xdc, 0xd6, 0x61, 0x17, 0x05, 0x7c, 0x3a, 0xe3, 0xaa, 0x1a, 0xe2, 0x6e, 0x67, 0x76, 0x83,
+	0x38, 0x36, 0x1d, 0x0a, 0x62, 0x84, 0xcf, 0x66, 0xdc, 0x8a, 0x43, 0x49, 0x4d, 0x5b, 0x37, 0xd8,
+	0x50, 0xc0, 0xe7, 0x33, 0x63, 0x2e, 0xa2, 0x8a, 0x70, 0xf8, 0x80, 0x54, 0x10, 0xcd, 0xb6, 0x54,
+	0x5d, 0x78, 0x30, 0x37, 0x9c, 0x89, 0xdb, 0xd5, 0x9a, 0xde, 0x43, 0xea, 0x87, 0x81, 0x89, 0xe1,
+	0xe3, 0xa5, 0xf5, 0x2b, 0xf7, 0x7f, 0x5b, 0x3e, 0xf1, 0xe2, 0x4a, 0xfe, 0x08, 0x5a, 0x0c, 0x3b,
+	0xe5, 0xec, 0x6f, 0x39, 0x7d, 0xf9, 0xba, 0x51, 0xd9, 0x3d, 0x8b, 0xad, 0xa9, 0xec, 0xb9, 0x7b,
+	0xfe, 0xdf, 0x01, 0x00, 0x12, 0x96, 0x6e, 0x95, 0x3d, 0x07, 0x00, 0x00,
 }